@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/backup"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	backupOutput   string
+	restoreReplace bool
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Writes a backup archive of every task of the storage",
+	Long: `Writes a backup archive of every task of the storage, with the schema version of the archive and its checksum.
+
+The archive can be restored into any storage with the restore command, or with POST /admin/restore.
+The views and the webhooks live in the server, back them up along with the tasks with GET /admin/backup.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, closeRepo, err := openTaskManager(nil)
+		if err != nil {
+			return err
+		}
+		defer closeRepo()
+
+		archive, err := backup.New(cmd.Context(), repo, time.Now())
+		if err != nil {
+			return err
+		}
+
+		if backupOutput == "-" {
+			return archive.Write(cmd.OutOrStdout())
+		}
+
+		file, err := os.Create(backupOutput)
+		if err != nil {
+			return err
+		}
+
+		if err := archive.Write(file); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.ErrOrStderr(), "backed up %d tasks to %s\n", archive.Count, backupOutput)
+
+		return nil
+	},
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [file]",
+	Short: "Restores the tasks of a backup archive into the storage",
+	Long: `Restores the tasks of a backup archive into the storage with their ids, read from the standard input without file.
+
+The archive must have a schema version up to the one of this release and match its checksum. The storage must
+be empty, unless --replace deletes its tasks first. The replace is not atomic: stop the server before replacing
+the tasks of its storage, otherwise the tasks written meanwhile are lost or mixed with the archive.
+
+The views and the webhooks of the archive are skipped, restore them into the server with POST /admin/restore.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.InOrStdin()
+		if len(args) == 1 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+
+		archive, err := backup.Read(r)
+		if err != nil {
+			return err
+		}
+
+		repo, closeRepo, err := openTaskManager(nil)
+		if err != nil {
+			return err
+		}
+		defer closeRepo()
+
+		if err := archive.Restore(cmd.Context(), repo, restoreReplace); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "restored %d tasks backed up at %s\n", archive.Count, archive.CreatedAt.Format(time.RFC3339))
+		if len(archive.Views) != 0 || len(archive.Webhooks) != 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "skipped %d views and %d webhooks, restore them with POST /admin/restore\n", len(archive.Views), len(archive.Webhooks))
+		}
+
+		return nil
+	},
+}
@@ -7,23 +7,97 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/idempotency"
 	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/redisstore"
 	taskmanager "github.com/brionac626/taskManager/internal/taskManager"
+	"github.com/brionac626/taskManager/internal/undo"
+	"github.com/brionac626/taskManager/internal/webhook"
 
 	"github.com/spf13/cobra"
 )
 
-var port string
+var (
+	port           string
+	idempotencyTTL time.Duration
+	eventBuffer    int
+	webhookRetries int
+	undoLimit      int
+	adminToken     string
+	dryRun         bool
+)
 
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Starts a task manager server",
 	Run: func(cmd *cobra.Command, args []string) {
 
+		if err := migrateStorage(dryRun); err != nil {
+			log.Println("migrate storage failed", err)
+			os.Exit(1)
+		}
+		if dryRun {
+			return
+		}
+
 		log.Println("Starting server...")
 
-		repo := repository.NewRepository()
-		router := taskmanager.NewRouter(repo)
+		bus := events.NewBus(eventBuffer)
+		dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+		defer stopDispatch()
+
+		var publisher repository.Publisher = bus
+		options := []taskmanager.RouterOption{
+			taskmanager.WithIdempotencyTTL(idempotencyTTL),
+			taskmanager.WithEventBus(bus),
+			taskmanager.WithUndoLimit(undoLimit),
+			taskmanager.WithAdminToken(adminToken),
+		}
+
+		// the replicas sharing a Redis storage share the task events, the idempotency keys, the views,
+		// the undo history and the webhooks
+		var webhooks repository.WebhookManager = repository.NewWebhookRepository()
+		var stream *events.RedisStream
+		if storage == storageRedis {
+			client, err := newRedisClient(redisURL)
+			if err != nil {
+				log.Println("open storage failed", err)
+				os.Exit(1)
+			}
+			defer client.Close()
+
+			stream = events.NewRedisStream(client, redisstore.DefaultPrefix, eventBuffer)
+			if err := stream.Start(dispatchCtx, bus); err != nil {
+				log.Println("read task events failed", err)
+				os.Exit(1)
+			}
+			publisher = stream
+			webhooks = redisstore.NewWebhookRepository(client, redisstore.DefaultPrefix)
+			options = append(options,
+				taskmanager.WithIdempotencyStore(idempotency.NewRedisStore(client, redisstore.DefaultPrefix, idempotencyTTL)),
+				taskmanager.WithViewManager(redisstore.NewViewRepository(client, redisstore.DefaultPrefix)),
+				taskmanager.WithUndoStacks(undo.NewRedisStacks(client, redisstore.DefaultPrefix)),
+			)
+		}
+
+		repo, closeRepo, err := openTaskManager(publisher)
+		if err != nil {
+			log.Println("open storage failed", err)
+			os.Exit(1)
+		}
+		defer closeRepo()
+
+		router := taskmanager.NewRouter(repo, append(options, taskmanager.WithWebhookManager(webhooks))...)
+
+		dispatcherOptions := []webhook.Option{webhook.WithMaxAttempts(webhookRetries + 1)}
+		if claimer, ok := webhooks.(webhook.Claimer); ok {
+			// every replica receives the shared events
+			dispatcherOptions = append(dispatcherOptions, webhook.WithClaimer(claimer))
+		}
+		dispatcher := webhook.NewDispatcher(webhooks, dispatcherOptions...)
+		dispatcher.Start(dispatchCtx, bus)
+
 		go func() {
 			if err := router.Start(":" + port); err != nil {
 				log.Println("Error starting server", err)
@@ -40,7 +114,13 @@ var serverCmd = &cobra.Command{
 
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
-		if err := router.Shutdown(ctx); err != nil {
+		err = router.Shutdown(ctx)
+		stopDispatch()
+		dispatcher.Wait()
+		if stream != nil {
+			stream.Wait()
+		}
+		if err != nil {
 			log.Println("shutdown server failed", err)
 			return
 		}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brionac626/taskManager/internal/migrate"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateFrom      string
+	migrateFromDir   string
+	migrateTo        string
+	migrateToDir     string
+	migrateBatchSize int
+)
+
+// errMigrateMemory represents an error when the memory storage is migrated, its tasks only live in the server
+var errMigrateMemory = errors.New("the memory storage only lives in the server process, " +
+	"restore a backup archive of the server with the restore command instead")
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copies every task of a storage to another storage",
+	Long: `Copies every task of a storage to another storage with its id, then verifies that both storages
+have the same number of tasks and the same checksum. The tasks are read page by page from both storages.
+
+The tasks already in the target storage are skipped, or updated when they differ, so that an interrupted
+migration resumes where it stopped when run again. The server should not run on either storage.
+
+The memory storage only lives in the process of the server, it can be neither the source nor the target.
+To move the tasks of a server running with the memory storage, download a backup archive from its
+/admin/backup endpoint and restore it into the persistent storage with the restore command.`,
+	Example: "  taskManager migrate --from eventlog --from-dir data --to sqlite --to-dir data\n" +
+		"  curl -H 'Authorization: Bearer <admin token>' localhost:8080/admin/backup > backup.json\n" +
+		"  taskManager restore --storage sqlite backup.json",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if migrateFrom == storageMemory || migrateTo == storageMemory {
+			return errMigrateMemory
+		}
+
+		// there is a single Redis server
+		sameDir := filepath.Clean(migrateFromDir) == filepath.Clean(migrateToDir)
+		if migrateFrom == migrateTo && (migrateFrom == storageRedis || sameDir) {
+			return errors.New("the source and the target are the same storage")
+		}
+
+		// opening a missing storage would create an empty one
+		if path := storagePath(migrateFrom, migrateFromDir); path != "" {
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("the source storage: %w", err)
+			}
+		}
+
+		source, closeSource, err := openStorage(migrateFrom, migrateFromDir, nil)
+		if err != nil {
+			return fmt.Errorf("open the source storage: %w", err)
+		}
+		defer closeSource()
+
+		target, closeTarget, err := openStorage(migrateTo, migrateToDir, nil)
+		if err != nil {
+			return fmt.Errorf("open the target storage: %w", err)
+		}
+		defer closeTarget()
+
+		out := cmd.OutOrStdout()
+		report, err := migrate.Migrate(cmd.Context(), source, target,
+			migrate.WithBatchSize(migrateBatchSize),
+			migrate.WithProgress(func(done int) {
+				fmt.Fprintf(out, "migrated %d tasks\n", done)
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("%w, run the command again to resume", err)
+		}
+
+		fmt.Fprintf(out, "%d tasks: %d copied, %d updated, %d already migrated\n", report.Total, report.Copied, report.Updated, report.Skipped)
+
+		if err := migrate.Verify(cmd.Context(), source, target, migrate.WithBatchSize(migrateBatchSize)); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "verified the task counts and checksums")
+
+		return nil
+	},
+}
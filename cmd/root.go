@@ -1,6 +1,14 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/idempotency"
+	"github.com/brionac626/taskManager/internal/migrate"
+	"github.com/brionac626/taskManager/internal/undo"
+	"github.com/brionac626/taskManager/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -20,5 +28,34 @@ func Execute() error {
 
 func init() {
 	serverCmd.Flags().StringVarP(&port, "port", "p", "8080", "Port to listen on")
+	serverCmd.Flags().DurationVar(&idempotencyTTL, "idempotency-ttl", idempotency.DefaultTTL, "How long responses are kept for replaying requests with the same Idempotency-Key")
+	serverCmd.Flags().IntVar(&eventBuffer, "event-buffer", events.DefaultBufferSize, "Number of task events kept for resuming event streams")
+	serverCmd.Flags().IntVar(&webhookRetries, "webhook-retries", webhook.DefaultMaxAttempts-1, "Number of retries of a failed webhook delivery")
+	serverCmd.Flags().IntVar(&undoLimit, "undo-limit", undo.DefaultLimit, "Number of task operations kept for undoing per user")
+	serverCmd.Flags().DurationVar(&historyRetention, "history-retention", defaultHistoryRetention, "How long the memory and redis storages keep past versions of the tasks for as_of queries, 0 keeps them forever (the event log keeps its whole history)")
+	serverCmd.Flags().StringVar(&adminToken, "admin-token", "", "Bearer token of the admin endpoints, such as backups and webhooks, which are not served without token")
+	serverCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report the schema migrations of the storage without running them, and exit without starting the server")
+	addStorageFlags(serverCmd)
 	rootCmd.AddCommand(serverCmd)
+
+	todoTxtExportCmd.Flags().StringVarP(&todoTxtOutput, "output", "o", "-", "File to write the tasks to, - writes to the standard output")
+	todoTxtImportCmd.Flags().BoolVar(&todoTxtDryRun, "dry-run", false, "Validate the tasks of the file without importing them")
+	addStorageFlags(todoTxtExportCmd)
+	addStorageFlags(todoTxtImportCmd)
+	todoTxtCmd.AddCommand(todoTxtExportCmd, todoTxtImportCmd)
+	rootCmd.AddCommand(todoTxtCmd)
+
+	backupCmd.Flags().StringVarP(&backupOutput, "output", "o", "-", "File to write the archive to, - writes to the standard output")
+	restoreCmd.Flags().BoolVar(&restoreReplace, "replace", false, "Delete the tasks of the storage before restoring")
+	addStorageFlags(backupCmd)
+	addStorageFlags(restoreCmd)
+	rootCmd.AddCommand(backupCmd, restoreCmd)
+
+	migrateCmd.Flags().StringVar(&migrateFrom, "from", storageEventLog, "Storage to copy the tasks from: eventlog, sqlite, bolt or redis")
+	migrateCmd.Flags().StringVar(&migrateFromDir, "from-dir", "data", "Data directory of the storage to copy the tasks from")
+	migrateCmd.Flags().StringVar(&migrateTo, "to", storageSQLite, "Storage to copy the tasks to: eventlog, sqlite, bolt or redis")
+	migrateCmd.Flags().StringVar(&migrateToDir, "to-dir", "data", "Data directory of the storage to copy the tasks to")
+	migrateCmd.Flags().IntVar(&migrateBatchSize, "batch-size", migrate.DefaultBatchSize, "Number of tasks copied at once")
+	addRedisFlag(migrateCmd)
+	rootCmd.AddCommand(migrateCmd)
 }
@@ -0,0 +1,269 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/boltstore"
+	"github.com/brionac626/taskManager/internal/repository/eventsourced"
+	"github.com/brionac626/taskManager/internal/repository/redisstore"
+	"github.com/brionac626/taskManager/internal/repository/sqlstore"
+	"github.com/brionac626/taskManager/internal/schema"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+	_ "modernc.org/sqlite"
+)
+
+const (
+	// storageMemory keeps the tasks in the memory of the server
+	storageMemory = "memory"
+	// storageEventLog stores the history of the tasks in an append-only event log
+	storageEventLog = "eventlog"
+	// storageSQLite stores the tasks in a SQLite database
+	storageSQLite = "sqlite"
+	// storageBolt stores the tasks in an embedded key-value file
+	storageBolt = "bolt"
+	// storageRedis stores the tasks in Redis, shared by the replicas of the server with their events, idempotency keys,
+	// views, undo history and webhooks
+	storageRedis = "redis"
+)
+
+const (
+	// sqliteFileName is the name of the SQLite database in the data directory
+	sqliteFileName = "tasks.db"
+	// boltFileName is the name of the key-value file in the data directory
+	boltFileName = "tasks.bolt"
+)
+
+// defaultHistoryRetention is the default time the versions of the tasks are kept by the memory and redis storages
+const defaultHistoryRetention = 30 * 24 * time.Hour
+
+var (
+	storage          string
+	dataDir          string
+	redisURL         string
+	historyRetention time.Duration
+)
+
+// migrateStorage runs the schema migrations of the configured storage, a dry run only logs the changes
+// the migrations would make. The memory storage persists nothing to migrate, and a storage without data yet
+// records the current schema version when it is opened.
+func migrateStorage(dryRun bool) error {
+	ctx := context.Background()
+	path := storagePath(storage, dataDir)
+	switch storage {
+	case storageMemory:
+		if dryRun {
+			log.Printf("the %s storage has no persisted data to migrate", storage)
+		}
+		return nil
+	case storageRedis:
+		// the keys are checked on the server
+	default:
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if dryRun {
+				log.Printf("%s has no data to migrate", path)
+			}
+			return nil
+		}
+	}
+
+	var steps []schema.Step
+	var current int
+	var err error
+	switch storage {
+	case storageEventLog:
+		steps, err = eventsourced.Migrations.Migrate(ctx, schema.Dir(dataDir), dryRun)
+		current = eventsourced.Migrations.Current()
+	case storageSQLite:
+		var db *sql.DB
+		db, err = openSQLiteDB(dataDir)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		steps, err = sqlstore.Migrations.Migrate(ctx, sqlstore.DB{DB: db}, dryRun)
+		current = sqlstore.Migrations.Current()
+	case storageBolt:
+		var db boltstore.DB
+		db, err = boltstore.OpenDB(path)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		steps, err = boltstore.Migrations.Migrate(ctx, db, dryRun)
+		current = boltstore.Migrations.Current()
+	case storageRedis:
+		var client *redis.Client
+		client, err = newRedisClient(redisURL)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		// the URL may hold a password
+		path = client.Options().Addr
+
+		steps, err = redisstore.Migrations.Migrate(ctx, redisstore.Keyspace{Client: client, Prefix: redisstore.DefaultPrefix}, dryRun)
+		current = redisstore.Migrations.Current()
+	}
+
+	for _, step := range steps {
+		if dryRun {
+			log.Printf("would migrate %s to schema version %d: %s (%s)", path, step.Version, step.Description, step.Changes)
+		} else {
+			log.Printf("migrated %s to schema version %d: %s (%s)", path, step.Version, step.Description, step.Changes)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(steps) == 0 {
+		log.Printf("%s has the current schema version %d", path, current)
+	}
+
+	return nil
+}
+
+// addStorageFlags adds the flags configuring the storage opened by openTaskManager to the command.
+func addStorageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&storage, "storage", storageMemory, "Storage of the tasks: memory, eventlog, sqlite, bolt or redis")
+	cmd.Flags().StringVar(&dataDir, "data-dir", "data", "Directory of the files of the eventlog, sqlite and bolt storages")
+	addRedisFlag(cmd)
+}
+
+// addRedisFlag adds the flag of the Redis server of the redis storage to the command.
+func addRedisFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&redisURL, "redis-url", "redis://localhost:6379/0", "URL of the Redis server when the storage is redis")
+}
+
+// openTaskManager opens the task manager of the configured storage, the returned function closes it.
+func openTaskManager(publisher repository.Publisher) (repository.TaskManager, func() error, error) {
+	return openStorage(storage, dataDir, publisher)
+}
+
+// openStorage opens the task manager of a storage kept in the data directory, the returned function closes it.
+func openStorage(storage, dataDir string, publisher repository.Publisher) (repository.TaskManager, func() error, error) {
+	switch storage {
+	case storageMemory:
+		repo := repository.NewRepository(
+			repository.WithPublisher(publisher),
+			repository.WithHistoryRetention(historyRetention),
+		)
+
+		return repo, func() error { return nil }, nil
+	case storageEventLog:
+		repo, err := eventsourced.Open(dataDir, eventsourced.WithPublisher(publisher))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return repo, repo.Close, nil
+	case storageSQLite:
+		return openSQLite(dataDir, publisher)
+	case storageBolt:
+		if err := os.MkdirAll(dataDir, 0o755); err != nil {
+			return nil, nil, err
+		}
+
+		repo, err := boltstore.Open(filepath.Join(dataDir, boltFileName), boltstore.WithPublisher(publisher))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return repo, repo.Close, nil
+	case storageRedis:
+		return openRedis(redisURL, publisher)
+	}
+
+	return nil, nil, fmt.Errorf("unknown storage %q, expected one of %s, %s, %s, %s, %s",
+		storage, storageMemory, storageEventLog, storageSQLite, storageBolt, storageRedis)
+}
+
+// storagePath returns the file or the directory of a storage kept in the data directory,
+// or an empty string when the storage has none.
+func storagePath(storage, dataDir string) string {
+	switch storage {
+	case storageEventLog:
+		return dataDir
+	case storageSQLite:
+		return filepath.Join(dataDir, sqliteFileName)
+	case storageBolt:
+		return filepath.Join(dataDir, boltFileName)
+	}
+
+	return ""
+}
+
+// openSQLite opens the task manager of the SQLite database of the data directory, creating it when missing.
+// The returned function closes the task manager and the database.
+func openSQLite(dataDir string, publisher repository.Publisher) (repository.TaskManager, func() error, error) {
+	db, err := openSQLiteDB(dataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := sqlstore.Open(context.Background(), db, sqlstore.WithPublisher(publisher))
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return repo, func() error { return errors.Join(repo.Close(), db.Close()) }, nil
+}
+
+// openSQLiteDB opens the SQLite database of the data directory, creating it when missing.
+func openSQLiteDB(dataDir string) (*sql.DB, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", filepath.Join(dataDir, sqliteFileName)+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite serializes the writes, a single connection avoids failing on a locked database
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// openRedis opens the task manager of the Redis server of the URL.
+// The returned function closes the connections to the server.
+func openRedis(url string, publisher repository.Publisher) (repository.TaskManager, func() error, error) {
+	client, err := newRedisClient(url)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := redisstore.Open(context.Background(), client,
+		redisstore.WithPublisher(publisher),
+		redisstore.WithHistoryRetention(historyRetention),
+	)
+	if err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+
+	return repo, client.Close, nil
+}
+
+// newRedisClient creates a client of the Redis server of the URL.
+func newRedisClient(url string) (*redis.Client, error) {
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+
+	return redis.NewClient(options), nil
+}
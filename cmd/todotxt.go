@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/brionac626/taskManager/internal/codec"
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/repository"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	todoTxtOutput string
+	todoTxtDryRun bool
+)
+
+// errTodoTxtMemory represents an error when the tasks of the memory storage are exported or imported,
+// its tasks only live in the server
+var errTodoTxtMemory = errors.New("the memory storage only lives in the server process, " +
+	"export or import the tasks with GET /tasks/export?format=todotxt or POST /tasks/import of the server instead")
+
+var todoTxtCmd = &cobra.Command{
+	Use:   "todotxt",
+	Short: "Exports and imports the tasks of the storage as todo.txt files",
+	Long: `Exports and imports the tasks of the storage as todo.txt files.
+
+Every task is a line, completed tasks start with "x " and the task id is kept in an id:<id> tag,
+so that an exported file can be edited with todo.txt tools and imported back. The server should
+not run on the same storage. The memory storage only lives in the process of the server, use the
+export and import endpoints of the server instead.`,
+}
+
+var todoTxtExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Writes the tasks of the storage as a todo.txt file",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if storage == storageMemory {
+			return errTodoTxtMemory
+		}
+
+		repo, closeRepo, err := openTaskManager(nil)
+		if err != nil {
+			return err
+		}
+		defer closeRepo()
+
+		tasks, err := repo.GetTasks(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if todoTxtOutput == "-" {
+			return codec.EncodeTodoTxt(cmd.OutOrStdout(), tasks)
+		}
+
+		file, err := os.Create(todoTxtOutput)
+		if err != nil {
+			return err
+		}
+
+		if err := codec.EncodeTodoTxt(file, tasks); err != nil {
+			file.Close()
+			return err
+		}
+
+		return file.Close()
+	},
+}
+
+var todoTxtImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Imports the tasks of a todo.txt file into the storage",
+	Long: `Imports the tasks of a todo.txt file into the storage, read from the standard input without file.
+
+The lines with the id:<id> tag of an existing task update the task, the other ids restore their task
+and the lines without id create new tasks.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		r := cmd.InOrStdin()
+		if len(args) == 1 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			r = file
+		}
+
+		tasks, err := codec.DecodeTodoTxt(r)
+		if err != nil {
+			return describeViolations(err)
+		}
+
+		if todoTxtDryRun {
+			fmt.Fprintf(cmd.OutOrStdout(), "%d tasks are valid, nothing was imported\n", len(tasks))
+			return nil
+		}
+
+		if storage == storageMemory {
+			return errTodoTxtMemory
+		}
+
+		repo, closeRepo, err := openTaskManager(nil)
+		if err != nil {
+			return err
+		}
+		defer closeRepo()
+
+		if err := repository.ImportTasks(cmd.Context(), repo, tasks); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "imported %d tasks\n", len(tasks))
+
+		return nil
+	},
+}
+
+// describeViolations returns an error listing the invalid fields of a validation error, such as the
+// rows of a file, or the error itself when it has no field.
+func describeViolations(err error) error {
+	violations := problem.Violations(err)
+	if len(violations) == 0 {
+		return err
+	}
+
+	lines := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		lines = append(lines, violation.Field+": "+violation.Message)
+	}
+
+	return errors.New(strings.Join(lines, "\n"))
+}
@@ -23,16 +23,251 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/tasks": {
+        "/admin/backup": {
             "get": {
-                "description": "Get all tasks.",
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Download an archive of every task, saved view and webhook subscription, with the schema version\nof the archive and its checksum. The webhooks are archived with their secrets.\nThe admin endpoints are only served when the server has an admin token.",
                 "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Back up every task.",
+                "responses": {
+                    "200": {
+                        "description": "backup archive",
+                        "schema": {
+                            "$ref": "#/definitions/backup.Archive"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Restore the tasks, views and webhooks of a backup archive with their ids. The archive must have\na schema version up to the one of the server and match its checksum. The stores must be empty,\nunless replace is set to delete their tasks, views and webhooks first. The archive is checked\nbefore anything is deleted, and the deleted contents are put back when the archive cannot be\nrestored. The replace is not atomic, the clients must not write while it runs.",
+                "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Restore the tasks of a backup.",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "delete the current tasks, views and webhooks before restoring",
+                        "name": "replace",
+                        "in": "query"
+                    },
+                    {
+                        "description": "backup archive",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/backup.Archive"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "restored archive",
+                        "schema": {
+                            "$ref": "#/definitions/models.RestoreResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid archive, invalid task, checksum mismatch or invalid replace",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task, view or webhook store not empty",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Incompatible schema version",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to restore the tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/redo": {
+            "post": {
+                "description": "Make again the last operation undone by the user identified by the X-User-ID header.\nAny new operation of the user clears the operations to redo.\nAn operation whose tasks were changed by someone else since the undo is discarded and answers 409.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Undo"
+                ],
+                "summary": "Redo the last task operation undone by the user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the redone operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Operation"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Nothing to redo",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task changed since the undo",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to redo the operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync": {
+            "get": {
+                "description": "Get the tasks created or updated and the ids of the tasks deleted since the token of the previous call,\nor every task when no token is given. The returned token is sent as since on the next call.\nAn expired token answers 410, the client then fetches every task again without a token.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Get the changes of the tasks since a sync token.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "token returned by the previous call",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "changes since the token",
+                        "schema": {
+                            "$ref": "#/definitions/models.SyncResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid sync token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Expired sync token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get the changes",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "Get all tasks, or the tasks matching a query such as: open AND (name:deploy OR name:release).\nPredicates compare the id, name and status fields and can be combined with AND, OR, NOT and parentheses.\nWith as_of, the tasks are rebuilt from the recorded history as they were at that time.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
                 "tags": [
                     "Tasks"
                 ],
                 "summary": "Get all tasks from the local storage.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"open AND name:deploy\"",
+                        "description": "task query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2026-10-12T09:00:00Z\"",
+                        "description": "RFC 3339 timestamp to get the tasks at",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "tasks retrieved successfully",
@@ -46,6 +281,18 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "Invalid task query or timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Timestamp before the retained history",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Filed to get tasks",
                         "schema": {
@@ -59,11 +306,20 @@ const docTemplate = `{
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/problem+json"
+                ],
                 "tags": [
                     "Tasks"
                 ],
                 "summary": "Create new tasks from the client request.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "client generated key to safely retry the request",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "tasks to create",
                         "name": "req",
@@ -84,6 +340,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "A request with the same idempotency key is in progress",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Idempotency key reused with a different request body",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Failed to create tasks",
                         "schema": {
@@ -91,109 +359,1509 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/tasks/:id": {
-            "put": {
-                "description": "Update an existing task fields' values.",
+            },
+            "delete": {
+                "description": "Delete the tasks selected by an id list or a filter, and return the outcome for every task.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
                 "tags": [
                     "Tasks"
                 ],
-                "summary": "Update an existing task by task id.",
+                "summary": "Delete several tasks at once.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "\"9bsv0s2hf8ng030mva9g\"",
-                        "example": "\"9bsv0s2hf8ng030mva9g\"",
-                        "description": "target task id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "task fields to update",
+                        "description": "tasks to delete",
                         "name": "req",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                            "$ref": "#/definitions/models.BatchDeleteTasksRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "no content returned when no changes"
+                        "description": "per-task outcomes",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid task fields values",
+                        "description": "Invalid request body",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Failed to update a task fields",
+                        "description": "Failed to delete tasks",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             },
-            "delete": {
-                "description": "Delete an existing task.",
+            "patch": {
+                "description": "Update the fields' values of the tasks selected by an id list or a filter, and return the outcome for every task.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
                 "tags": [
                     "Tasks"
                 ],
-                "summary": "Delete an existing task by task id.",
+                "summary": "Update several tasks at once.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "\"9bsv0s2hf8ng030mva9g\"",
-                        "example": "\"9bsv0s2hf8ng030mva9g\"",
-                        "description": "target task id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "tasks to update and fields to update",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchUpdateTasksRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "no content returned when successful"
+                        "description": "per-task outcomes",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
                     },
                     "500": {
-                        "description": "Failed to update a task fields",
+                        "description": "Failed to update tasks",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.CreateNewTasksRequest": {
-            "type": "object",
-            "properties": {
-                "tasks": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.NewTask"
-                    }
-                }
-            }
-        },
-        "models.ErrorResponse": {
-            "type": "object",
-            "properties": {
-                "code": {
-                    "type": "integer"
-                },
-                "message": {
-                    "type": "string"
-                }
-            }
+        },
+        "/tasks.ics": {
+            "get": {
+                "description": "Stream all tasks, or the tasks matching the query, as the VTODO components of an iCalendar file (RFC 5545).\nThe UID of a todo is the task id, and the status is NEEDS-ACTION or COMPLETED.",
+                "produces": [
+                    "text/calendar",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Export tasks as an iCalendar file.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"open AND name:deploy\"",
+                        "description": "task query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2026-10-12T09:00:00Z\"",
+                        "description": "RFC 3339 timestamp to export the tasks at",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "exported tasks",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid task query or timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Timestamp before the retained history",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/:id": {
+            "put": {
+                "description": "Update an existing task fields' values.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Update an existing task by task id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "\"9bsv0s2hf8ng030mva9g\"",
+                        "example": "\"9bsv0s2hf8ng030mva9g\"",
+                        "description": "target task id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "task fields to update",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTaskRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when no changes"
+                    },
+                    "400": {
+                        "description": "Invalid task fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update a task fields",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an existing task.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Delete an existing task by task id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "\"9bsv0s2hf8ng030mva9g\"",
+                        "example": "\"9bsv0s2hf8ng030mva9g\"",
+                        "description": "target task id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when successful"
+                    },
+                    "500": {
+                        "description": "Failed to update a task fields",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Apply a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document to an existing task.\nThe patched task is validated against the task model before it is stored.",
+                "consumes": [
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Patch an existing task by task id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "\"9bsv0s2hf8ng030mva9g\"",
+                        "example": "\"9bsv0s2hf8ng030mva9g\"",
+                        "description": "target task id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "patch document",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the patched task",
+                        "schema": {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid patch document",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Task not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task changed since it was read, patch it again",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported patch media type",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Patch cannot be applied or the patched task is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to patch a task",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/events": {
+            "get": {
+                "description": "Stream task.created, task.updated, task.completed and task.deleted events as Server-Sent Events.\nReconnecting clients send the Last-Event-ID header to resume from the replay buffer;\na reset event is sent when events were missed and the tasks have to be fetched again.",
+                "produces": [
+                    "text/event-stream",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Stream the changes of the tasks.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"42\"",
+                        "description": "id of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stream of task events",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaskEvent"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Last-Event-ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/export": {
+            "get": {
+                "description": "Stream all tasks, or the tasks matching the query, as a file to download.\nThe csv format has a header row followed by the id, name and status of every task,\nthe ics format has a VTODO component per task like GET /tasks.ics,\nthe todotxt format has a todo.txt line per task with the task id in an id:\u003cid\u003e tag,\nand the markdown format has a checklist item per task, grouped under a heading per +project\nor #tag word of the task names with the group parameter.",
+                "produces": [
+                    "text/csv",
+                    "text/calendar",
+                    "text/plain",
+                    "text/markdown",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Export tasks to a file.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ics",
+                            "todotxt",
+                            "markdown"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "project",
+                            "tag"
+                        ],
+                        "type": "string",
+                        "description": "marker grouping the tasks of the markdown format",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"open AND name:deploy\"",
+                        "description": "task query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2026-10-12T09:00:00Z\"",
+                        "description": "RFC 3339 timestamp to export the tasks at",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "exported tasks",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format, invalid group, task query or timestamp",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Timestamp before the retained history",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/import": {
+            "post": {
+                "description": "Create the tasks of a file. Every task is validated like the created tasks before any task is created,\nand the errors are reported per row as rows[\u003cline\u003e] fields with the line numbers of the file.\nThe csv format reads the name and status columns of the header row, or the columns mapped by\nthe columns parameter; a missing status is an open task.\nThe ics format reads the VTODO components of an iCalendar file: a todo whose UID is the id of\nan existing task updates the task, and the other UIDs created by GET /tasks.ics restore their task,\nso that a calendar can be exported, edited and imported back.\nThe todotxt format reads the lines of a todo.txt file, the lines starting with \"x \" are completed\ntasks, and the id:\u003cid\u003e tags written by the export update or restore their task like the UIDs of the ics format.\nPriorities and dates are skipped, the projects and contexts are kept in the task name.\nThe markdown format reads the \"- [ ]\" and \"- [x]\" checklist items of a Markdown document, nested\nitems included, and ignores the other lines.\nA dry run only validates the tasks and returns them without creating them.",
+                "consumes": [
+                    "text/csv",
+                    "text/calendar",
+                    "text/plain",
+                    "text/markdown"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Import tasks from a file.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ics",
+                            "todotxt",
+                            "markdown"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "file format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"name:Title,status:Done\"",
+                        "description": "csv header names of the task fields",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "validate without creating the tasks",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "file to import",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tasks validated by a dry run",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "created tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Unsupported format, invalid options or invalid rows",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task id repeated in the file",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/search": {
+            "get": {
+                "description": "Full-text search over the task names. Every query word matches the words it is a prefix of,\nand the results are ordered by relevance with the matched words highlighted.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Search tasks by the words in their names.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"task\"",
+                        "description": "search query",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 100,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "maximum number of results",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "matching tasks ordered by relevance",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.SearchResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search tasks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/undo": {
+            "post": {
+                "description": "Revert the last create, update or delete operation made by the user identified by the X-User-ID header.\nAn operation whose tasks were changed by someone else since is discarded and answers 409.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Undo"
+                ],
+                "summary": "Undo the last task operation of the user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the undone operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.Operation"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Nothing to undo",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Task changed since the operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to undo the operation",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/views": {
+            "get": {
+                "description": "Get the saved views owned by the user identified by the X-User-ID header.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Get the saved views of the user.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "views retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.View"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get views",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Save a named filter, sort and column configuration for the user identified by the X-User-ID header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Save a new view.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "view to save",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "the saved view",
+                        "schema": {
+                            "$ref": "#/definitions/models.View"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid view fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save a view",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/views/:id": {
+            "get": {
+                "description": "Get a saved view. Views can be read by anyone knowing their id, so they can be shared by link.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Get a saved view by view id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv1h8ms2hf8ng030mvb0\"",
+                        "description": "target view id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "view retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.View"
+                        }
+                    },
+                    "404": {
+                        "description": "View not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get a view",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace the name, query, sort and columns of a view owned by the user identified by the X-User-ID header.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Replace a saved view by view id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"cv1h8ms2hf8ng030mvb0\"",
+                        "description": "target view id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "view to save",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveViewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when successful"
+                    },
+                    "400": {
+                        "description": "Invalid view fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "View owned by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "View not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save a view",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a view owned by the user identified by the X-User-ID header.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Delete a saved view by view id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"alice\"",
+                        "description": "user id",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"cv1h8ms2hf8ng030mvb0\"",
+                        "description": "target view id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when successful"
+                    },
+                    "401": {
+                        "description": "Missing user id",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "View owned by another user",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "View not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete a view",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/views/:id/tasks": {
+            "get": {
+                "description": "Execute the query of a saved view and return the matching tasks, sorted and limited to the columns of the view.\nViews can be executed by anyone knowing their id, so they can be shared by link.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Views"
+                ],
+                "summary": "Get the tasks of a saved view.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv1h8ms2hf8ng030mvb0\"",
+                        "description": "target view id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "tasks with the columns of the view",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "View not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get the tasks of a view",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get every webhook subscription, their secrets are never returned.\nThe webhook endpoints are only served when the server has an admin token.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get the webhook subscriptions.",
+                "responses": {
+                    "200": {
+                        "description": "webhooks retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get webhooks",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Deliver the task events of the given types, or of every type when none is given, to the URL.\nEvery delivery is a JSON models.TaskEvent signed with the X-Webhook-Signature header,\nthe hex encoded HMAC-SHA256 of the body keyed by the secret and prefixed with sha256=.\nDeliveries answered with a non-2xx status are retried with an exponential backoff.\nThe deliveries to the loopback, private and link-local addresses of the URL host are refused.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Subscribe an URL to the task events.",
+                "parameters": [
+                    {
+                        "description": "webhook to subscribe",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "the subscribed webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid webhook fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to subscribe a webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/:id": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get a webhook subscription by webhook id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv2k1ts2hf8ng030mvc0\"",
+                        "description": "target webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "webhook retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get a webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replace the URL, secret and event types of a webhook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Replace a webhook subscription by webhook id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv2k1ts2hf8ng030mvc0\"",
+                        "description": "target webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "webhook to subscribe",
+                        "name": "req",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaveWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when successful"
+                    },
+                    "400": {
+                        "description": "Invalid webhook fields values",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update a webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Delete a webhook and its delivery log, the pending retries are abandoned.",
+                "produces": [
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete a webhook subscription by webhook id.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv2k1ts2hf8ng030mvc0\"",
+                        "description": "target webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "no content returned when successful"
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete a webhook",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/:id/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Get the latest delivery attempts of a webhook, the latest attempt first.",
+                "produces": [
+                    "application/json",
+                    "application/problem+json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get the delivery log of a webhook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"cv2k1ts2hf8ng030mvc0\"",
+                        "description": "target webhook id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "deliveries retrieved successfully",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid admin token",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Webhook not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get the deliveries",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "description": "Upgrade to a WebSocket exchanging models.SocketRequest and models.SocketMessage JSON messages.\nClients subscribe to the tasks, task:\u003cid\u003e and view:\u003cid\u003e channels to receive their task events,\nand send create, update and delete messages validated like the HTTP endpoints.\nEvery client message is replied with an ack or an error message carrying the same id.",
+                "tags": [
+                    "Tasks"
+                ],
+                "summary": "Open the real-time collaboration socket.",
+                "responses": {
+                    "101": {
+                        "description": "switching to the WebSocket protocol",
+                        "schema": {
+                            "$ref": "#/definitions/models.SocketMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Not a WebSocket handshake",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "backup.Archive": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "sha256 digest of the JSON arrays of the tasks, the views and the webhooks",
+                    "type": "string"
+                },
+                "count": {
+                    "description": "number of tasks",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "description": "time of the backup",
+                    "type": "string"
+                },
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Task"
+                    }
+                },
+                "version": {
+                    "description": "schema version of the archive",
+                    "type": "integer"
+                },
+                "views": {
+                    "description": "saved views of every user, since version 2",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.View"
+                    }
+                },
+                "webhooks": {
+                    "description": "webhook subscriptions, since version 2",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backup.Webhook"
+                    }
+                }
+            }
+        },
+        "backup.Webhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "events": {
+                    "description": "event types to deliver, empty for every type",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    },
+                    "example": [
+                        "task.completed",
+                        "task.deleted"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "cv2k1ts2hf8ng030mvc0"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/tasks"
+                }
+            }
+        },
+        "models.BatchDeleteTasksRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/models.TaskFilter"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "9bsv0s2hf8ng030mva9g"
+                    ]
+                }
+            }
+        },
+        "models.BatchOutcome": {
+            "type": "string",
+            "enum": [
+                "updated",
+                "deleted",
+                "not_found",
+                "conflict"
+            ],
+            "x-enum-varnames": [
+                "BatchOutcomeUpdated",
+                "BatchOutcomeDeleted",
+                "BatchOutcomeNotFound",
+                "BatchOutcomeConflict"
+            ]
+        },
+        "models.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchResult"
+                    }
+                }
+            }
+        },
+        "models.BatchResult": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "9bsv0s2hf8ng030mva9g"
+                },
+                "outcome": {
+                    "enum": [
+                        "updated",
+                        "deleted",
+                        "not_found",
+                        "conflict"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.BatchOutcome"
+                        }
+                    ],
+                    "example": "updated"
+                }
+            }
+        },
+        "models.BatchUpdateTasksRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "$ref": "#/definitions/models.TaskFilter"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "9bsv0s2hf8ng030mva9g"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "enum": [
+                        0,
+                        1
+                    ]
+                }
+            }
+        },
+        "models.CreateNewTasksRequest": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NewTask"
+                    }
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "description": "true when the tasks were only validated, not created",
+                    "type": "boolean"
+                },
+                "tasks": {
+                    "description": "created tasks, or the tasks that would be created by a dry run (without ids)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Task"
+                    }
+                }
+            }
         },
         "models.NewTask": {
             "type": "object",
@@ -216,6 +1884,233 @@ const docTemplate = `{
                 }
             }
         },
+        "models.Operation": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskChange"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9bsv0s2hf8ng030mva9g"
+                },
+                "kind": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.OperationKind"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "time": {
+                    "description": "when the operation was made",
+                    "type": "string"
+                }
+            }
+        },
+        "models.OperationKind": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "OperationCreate",
+                "OperationUpdate",
+                "OperationDelete"
+            ]
+        },
+        "models.RestoreResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "time of the backup",
+                    "type": "string",
+                    "example": "2026-10-18T09:00:00Z"
+                },
+                "tasks": {
+                    "description": "number of restored tasks",
+                    "type": "integer",
+                    "example": 42
+                },
+                "version": {
+                    "description": "schema version of the archive",
+                    "type": "integer",
+                    "example": 2
+                },
+                "views": {
+                    "description": "number of restored views",
+                    "type": "integer",
+                    "example": 3
+                },
+                "webhooks": {
+                    "description": "number of restored webhooks",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.SaveViewRequest": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id",
+                        "name"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My open tasks"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "open AND name:deploy"
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "-name"
+                }
+            }
+        },
+        "models.SaveWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    },
+                    "example": [
+                        "task.completed",
+                        "task.deleted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "s3cr3t"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/tasks"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "highlight": {
+                    "description": "HTML-escaped task name with the matched terms wrapped in \u003cmark\u003e",
+                    "type": "string",
+                    "example": "\u003cmark\u003eTask\u003c/mark\u003e 1"
+                },
+                "score": {
+                    "description": "relevance of the task, higher is better",
+                    "type": "number",
+                    "example": 1.38
+                },
+                "task": {
+                    "$ref": "#/definitions/models.Task"
+                }
+            }
+        },
+        "models.SocketMessage": {
+            "type": "object",
+            "properties": {
+                "channel": {
+                    "description": "channel of the event",
+                    "type": "string",
+                    "example": "tasks"
+                },
+                "error": {
+                    "$ref": "#/definitions/models.ErrorResponse"
+                },
+                "event": {
+                    "$ref": "#/definitions/models.TaskEvent"
+                },
+                "id": {
+                    "description": "id of the request replied to",
+                    "type": "string",
+                    "example": "1"
+                },
+                "type": {
+                    "enum": [
+                        "ack",
+                        "error",
+                        "event"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SocketMessageType"
+                        }
+                    ],
+                    "example": "event"
+                }
+            }
+        },
+        "models.SocketMessageType": {
+            "type": "string",
+            "enum": [
+                "subscribe",
+                "unsubscribe",
+                "create",
+                "update",
+                "delete",
+                "ack",
+                "error",
+                "event"
+            ],
+            "x-enum-varnames": [
+                "SocketSubscribe",
+                "SocketUnsubscribe",
+                "SocketCreateTasks",
+                "SocketUpdateTask",
+                "SocketDeleteTask",
+                "SocketAck",
+                "SocketError",
+                "SocketEvent"
+            ]
+        },
+        "models.SyncResponse": {
+            "type": "object",
+            "properties": {
+                "deleted": {
+                    "description": "tombstones, the ids of the deleted tasks",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "9bsv0s2hf8ng030mva9g"
+                    ]
+                },
+                "tasks": {
+                    "description": "created or updated tasks",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Task"
+                    }
+                },
+                "token": {
+                    "description": "opaque token to send as since on the next call",
+                    "type": "string",
+                    "example": "42"
+                }
+            }
+        },
         "models.Task": {
             "type": "object",
             "properties": {
@@ -240,6 +2135,97 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TaskChange": {
+            "type": "object",
+            "properties": {
+                "after": {
+                    "description": "nil when the operation deleted the task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "before": {
+                    "description": "nil when the operation created the task",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.TaskEvent": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "monotonically increasing event id",
+                    "type": "integer",
+                    "example": 42
+                },
+                "task": {
+                    "description": "the task after the change, or its last state when deleted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Task"
+                        }
+                    ]
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "type": {
+                    "enum": [
+                        "task.created",
+                        "task.updated",
+                        "task.completed",
+                        "task.deleted"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskEventType"
+                        }
+                    ],
+                    "example": "task.created"
+                }
+            }
+        },
+        "models.TaskEventType": {
+            "type": "string",
+            "enum": [
+                "task.created",
+                "task.updated",
+                "task.completed",
+                "task.deleted"
+            ],
+            "x-enum-varnames": [
+                "TaskCreated",
+                "TaskUpdated",
+                "TaskCompleted",
+                "TaskDeleted"
+            ]
+        },
+        "models.TaskFilter": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "description": "case-insensitive substring of the task name",
+                    "type": "string",
+                    "example": "Task"
+                },
+                "status": {
+                    "description": "exact task status",
+                    "type": "integer",
+                    "enum": [
+                        0,
+                        1
+                    ],
+                    "example": 0
+                }
+            }
+        },
         "models.UpdateTaskRequest": {
             "type": "object",
             "properties": {
@@ -254,6 +2240,145 @@ const docTemplate = `{
                     ]
                 }
             }
+        },
+        "models.View": {
+            "type": "object",
+            "properties": {
+                "columns": {
+                    "description": "task fields to return, empty for every field",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "id",
+                        "name"
+                    ]
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "cv1h8ms2hf8ng030mvb0"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My open tasks"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "query": {
+                    "description": "task query, empty for every task",
+                    "type": "string",
+                    "example": "open AND name:deploy"
+                },
+                "sort": {
+                    "description": "sort field, prefixed with - for descending order",
+                    "type": "string",
+                    "example": "-name"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                }
+            }
+        },
+        "models.Webhook": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "events": {
+                    "description": "event types to deliver, empty for every type",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TaskEventType"
+                    },
+                    "example": [
+                        "task.completed",
+                        "task.deleted"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "cv2k1ts2hf8ng030mvc0"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://ci.example.com/hooks/tasks"
+                }
+            }
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "description": "1 for the first attempt, incremented on every retry",
+                    "type": "integer",
+                    "example": 1
+                },
+                "duration": {
+                    "description": "in nanoseconds",
+                    "type": "integer",
+                    "example": 12000000
+                },
+                "error": {
+                    "description": "why the attempt failed",
+                    "type": "string",
+                    "example": "connection refused"
+                },
+                "eventId": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "eventType": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TaskEventType"
+                        }
+                    ],
+                    "example": "task.completed"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "cv2k2bs2hf8ng030mvcg"
+                },
+                "statusCode": {
+                    "description": "status code of the response, if any",
+                    "type": "integer",
+                    "example": 200
+                },
+                "succeeded": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2025-01-01T00:00:00Z"
+                },
+                "webhookId": {
+                    "type": "string",
+                    "example": "cv2k1ts2hf8ng030mvc0"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Admin token of the server as a bearer token: \"Bearer \u003ctoken\u003e\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -265,7 +2390,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "Task Manager API",
-	Description:      "This is a sample task manager server.",
+	Description:      "This is a sample task manager server.\nErrors are served as RFC 7807 problem details when the client accepts application/problem+json.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
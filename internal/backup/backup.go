@@ -0,0 +1,448 @@
+// Package backup writes the tasks of a task manager, with the saved views and the webhook subscriptions,
+// to versioned and checksummed archives, and restores them into any task manager.
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+// SchemaVersion is the version of the archives written, the archives of version 1 only hold the tasks and are
+// still restored, archives of newer versions are refused
+const SchemaVersion = 2
+
+// checksumPrefix is the algorithm prefixed to the hex digest of the checksums
+const checksumPrefix = "sha256:"
+
+var (
+	// ErrInvalidArchive represents an error when a file is not a backup archive
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	// ErrChecksumMismatch represents an error when the tasks of an archive do not match its checksum
+	ErrChecksumMismatch = errors.New("backup archive checksum mismatch, the archive is corrupted")
+	// ErrIncompatibleVersion represents an error when an archive was written with another schema version
+	ErrIncompatibleVersion = errors.New("incompatible backup archive schema version")
+	// ErrStoreNotEmpty represents an error when an archive is restored into a task manager that already has tasks,
+	// views or webhooks
+	ErrStoreNotEmpty = errors.New("the task store is not empty, restore with replace to delete its tasks")
+)
+
+// Archive represents a backup of every task of a task manager, with the saved views and the webhook subscriptions.
+type Archive struct {
+	Version   int           `json:"version"`   // schema version of the archive
+	CreatedAt time.Time     `json:"createdAt"` // time of the backup
+	Count     int           `json:"count"`     // number of tasks
+	Checksum  string        `json:"checksum"`  // sha256 digest of the JSON arrays of the tasks, the views and the webhooks
+	Tasks     []models.Task `json:"tasks"`
+	Views     []models.View `json:"views"`    // saved views of every user, since version 2
+	Webhooks  []Webhook     `json:"webhooks"` // webhook subscriptions, since version 2
+}
+
+// Webhook represents a webhook subscription of an archive, with the secret signing its deliveries.
+type Webhook struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// Option configures the stores of the views and the webhooks backed up by New and restored by Restore,
+// the views and the webhooks are left out of the stores without option.
+type Option func(*stores)
+
+// WithViews backs up and restores the saved views of the view manager.
+func WithViews(views repository.ViewManager) Option {
+	return func(s *stores) {
+		s.views = views
+	}
+}
+
+// WithWebhooks backs up and restores the webhook subscriptions of the webhook manager, without their delivery log.
+func WithWebhooks(webhooks repository.WebhookManager) Option {
+	return func(s *stores) {
+		s.webhooks = webhooks
+	}
+}
+
+// stores holds the stores of the contents of the archives.
+type stores struct {
+	repo     repository.TaskManager
+	views    repository.ViewManager
+	webhooks repository.WebhookManager
+}
+
+// contents holds the tasks, views and webhooks of an archive or of the stores.
+type contents struct {
+	tasks    []models.Task
+	views    []models.View
+	webhooks []models.Webhook
+}
+
+// empty reports whether there is no task, view or webhook.
+func (c contents) empty() bool {
+	return len(c.tasks) == 0 && len(c.views) == 0 && len(c.webhooks) == 0
+}
+
+// newStores returns the stores of the task manager and the options.
+func newStores(repo repository.TaskManager, options []Option) *stores {
+	s := &stores{repo: repo}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// New returns the archive of every task of the task manager, created at the given time. The views and the webhooks
+// of the options are backed up as well.
+func New(ctx context.Context, repo repository.TaskManager, createdAt time.Time, options ...Option) (*Archive, error) {
+	current, err := newStores(repo, options).current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks := make([]Webhook, 0, len(current.webhooks))
+	for _, webhook := range current.webhooks {
+		webhooks = append(webhooks, Webhook{Webhook: webhook, Secret: webhook.Secret})
+	}
+
+	archive := &Archive{
+		Version:   SchemaVersion,
+		CreatedAt: createdAt.UTC(),
+		Count:     len(current.tasks),
+		Tasks:     current.tasks,
+		Views:     orEmpty(current.views),
+		Webhooks:  webhooks,
+	}
+
+	if archive.Checksum, err = archive.checksum(); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// Write writes the archive as JSON.
+func (a *Archive) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(a)
+}
+
+// Read reads an archive and verifies it: the archive must have a schema version up to the current one,
+// and its contents must match its count and checksum.
+func Read(r io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	if archive.Version <= 0 || archive.Checksum == "" {
+		return nil, ErrInvalidArchive
+	}
+
+	if archive.Version > SchemaVersion {
+		return nil, fmt.Errorf("%w: %d, expected up to %d", ErrIncompatibleVersion, archive.Version, SchemaVersion)
+	}
+
+	// the checksum of the archives of version 1 does not cover the views and the webhooks
+	if archive.Version == 1 && (len(archive.Views) != 0 || len(archive.Webhooks) != 0) {
+		return nil, fmt.Errorf("%w: views or webhooks in an archive of version 1", ErrInvalidArchive)
+	}
+
+	checksum, err := archive.checksum()
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != archive.Checksum || len(archive.Tasks) != archive.Count {
+		return nil, ErrChecksumMismatch
+	}
+
+	return &archive, nil
+}
+
+// Restore restores the tasks of the archive into the task manager with their ids, and the views and the webhooks
+// into the stores of the options. The stores must be empty unless replace is set, in which case their tasks, views
+// and webhooks are deleted first.
+//
+// The views and the webhooks of the archive are skipped without the options of their stores.
+//
+// The contents of the archive are checked before anything is deleted, and the deleted contents are restored
+// back when the contents of the archive cannot be restored. The replace is not atomic: the contents are deleted
+// and restored by separate writes, and the writes of the other clients in between are lost or mixed with the
+// archive. The server must be stopped, or its clients must not write, while an archive replaces its contents.
+func (a *Archive) Restore(ctx context.Context, repo repository.TaskManager, replace bool, options ...Option) error {
+	if err := a.check(); err != nil {
+		return err
+	}
+
+	s := newStores(repo, options)
+	current, err := s.current(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !current.empty() && !replace {
+		return ErrStoreNotEmpty
+	}
+
+	if err := s.remove(ctx, current); err != nil {
+		return err
+	}
+
+	if err := s.restore(ctx, a.contents()); err != nil {
+		if current.empty() {
+			return err
+		}
+
+		// nothing of the archive is left, the deleted contents are put back even when the context is done
+		if rollbackErr := s.restore(context.WithoutCancel(ctx), current); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to restore the replaced tasks: %w", rollbackErr))
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// contents returns the tasks, views and webhooks of the archive.
+func (a *Archive) contents() contents {
+	webhooks := make([]models.Webhook, 0, len(a.Webhooks))
+	for _, webhook := range a.Webhooks {
+		restored := webhook.Webhook
+		restored.Secret = webhook.Secret
+		webhooks = append(webhooks, restored)
+	}
+
+	return contents{tasks: a.Tasks, views: a.Views, webhooks: webhooks}
+}
+
+// checksum returns the checksum of the contents of the archive: the Checksum of the tasks for the archives
+// of version 1, and the sha256 digest of the JSON arrays of the tasks, the views and the webhooks since version 2.
+func (a *Archive) checksum() (string, error) {
+	if a.Version == 1 {
+		return Checksum(a.Tasks)
+	}
+
+	hash := sha256.New()
+	for _, items := range []any{orEmpty(a.Tasks), orEmpty(a.Views), orEmpty(a.Webhooks)} {
+		data, err := json.Marshal(items)
+		if err != nil {
+			return "", err
+		}
+		hash.Write(data)
+	}
+
+	return checksumPrefix + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// orEmpty returns an empty slice for nil, so that a missing array is written as an empty JSON array.
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return make([]T, 0)
+	}
+
+	return items
+}
+
+// check returns ErrInvalidArchive when a task, view or webhook of the archive is invalid or cannot be restored
+// with its id.
+func (a *Archive) check() error {
+	seen := make(map[string]bool, len(a.Tasks))
+	for _, task := range a.Tasks {
+		if err := task.Validate(); err != nil {
+			return fmt.Errorf("%w: task %s: %w", ErrInvalidArchive, task.ID, err)
+		}
+
+		if err := checkID(seen, task.ID, repository.ErrTaskID, repository.ErrTaskExists); err != nil {
+			return err
+		}
+	}
+
+	seen = make(map[string]bool, len(a.Views))
+	for _, view := range a.Views {
+		request := models.SaveViewRequest{Name: view.Name, Query: view.Query, Sort: view.Sort, Columns: view.Columns}
+		if err := request.Validate(); err != nil {
+			return fmt.Errorf("%w: view %s: %w", ErrInvalidArchive, view.ID, err)
+		}
+
+		if err := checkID(seen, view.ID, errInvalidID, repository.ErrViewExists); err != nil {
+			return err
+		}
+	}
+
+	seen = make(map[string]bool, len(a.Webhooks))
+	for _, webhook := range a.Webhooks {
+		request := models.SaveWebhookRequest{URL: webhook.URL, Secret: webhook.Secret, Events: webhook.Events}
+		if err := request.Validate(); err != nil {
+			return fmt.Errorf("%w: webhook %s: %w", ErrInvalidArchive, webhook.ID, err)
+		}
+
+		if err := checkID(seen, webhook.ID, errInvalidID, repository.ErrWebhookExists); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// errInvalidID represents an error when a view or a webhook of an archive has an invalid id (not xid)
+var errInvalidID = errors.New("invalid id")
+
+// checkID returns ErrInvalidArchive wrapping invalid when the id is not an xid, and repeated when it was already seen.
+func checkID(seen map[string]bool, id string, invalid, repeated error) error {
+	if _, err := xid.FromString(id); err != nil {
+		return fmt.Errorf("%w: %w: %q", ErrInvalidArchive, invalid, id)
+	}
+
+	if seen[id] {
+		return fmt.Errorf("%w: %w: %s", ErrInvalidArchive, repeated, id)
+	}
+	seen[id] = true
+
+	return nil
+}
+
+// current returns the tasks of the task manager, and the views and the webhooks of the stores that are set.
+func (s *stores) current(ctx context.Context) (contents, error) {
+	var c contents
+	var err error
+	if c.tasks, err = s.repo.GetTasks(ctx); err != nil {
+		return contents{}, err
+	}
+
+	if s.views != nil {
+		if c.views, err = s.views.ListViews(ctx); err != nil {
+			return contents{}, err
+		}
+	}
+
+	if s.webhooks != nil {
+		if c.webhooks, err = s.webhooks.GetWebhooks(ctx); err != nil {
+			return contents{}, err
+		}
+	}
+
+	return c, nil
+}
+
+// remove deletes the contents from the stores.
+func (s *stores) remove(ctx context.Context, c contents) error {
+	if len(c.tasks) != 0 {
+		taskIDs := make([]string, 0, len(c.tasks))
+		for _, task := range c.tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+
+		if _, err := s.repo.BatchDeleteTasks(ctx, taskIDs); err != nil {
+			return err
+		}
+	}
+
+	for _, view := range c.views {
+		if err := s.views.DeleteView(ctx, view.ID); err != nil && !errors.Is(err, repository.ErrViewNotFound) {
+			return err
+		}
+	}
+
+	for _, webhook := range c.webhooks {
+		if err := s.webhooks.DeleteWebhook(ctx, webhook.ID); err != nil && !errors.Is(err, repository.ErrWebhookNotFound) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restore restores the contents into the stores that are set, the contents restored before a failure
+// are deleted again so that nothing of the contents is left.
+func (s *stores) restore(ctx context.Context, c contents) error {
+	var restored contents
+	if len(c.tasks) != 0 {
+		if err := s.repo.RestoreTasks(ctx, c.tasks); err != nil {
+			return err
+		}
+		restored.tasks = c.tasks
+	}
+
+	if s.views != nil && len(c.views) != 0 {
+		if err := s.views.RestoreViews(ctx, c.views); err != nil {
+			return errors.Join(err, s.remove(context.WithoutCancel(ctx), restored))
+		}
+		restored.views = c.views
+	}
+
+	if s.webhooks != nil && len(c.webhooks) != 0 {
+		if err := s.webhooks.RestoreWebhooks(ctx, c.webhooks); err != nil {
+			return errors.Join(err, s.remove(context.WithoutCancel(ctx), restored))
+		}
+	}
+
+	return nil
+}
+
+// Checksum returns the sha256 checksum of the JSON array of the tasks, which depends on the order of the tasks.
+func Checksum(tasks []models.Task) (string, error) {
+	checksum := NewChecksum()
+	for _, task := range tasks {
+		if err := checksum.Add(task); err != nil {
+			return "", err
+		}
+	}
+
+	return checksum.Sum(), nil
+}
+
+// TaskChecksum computes the checksum of tasks added one by one, so that the tasks do not have to be in memory
+// at once. The checksum of the added tasks is the Checksum of the slice of the tasks in the order they are added.
+type TaskChecksum struct {
+	hash  hash.Hash
+	count int
+}
+
+// NewChecksum returns the checksum of no task.
+func NewChecksum() *TaskChecksum {
+	return &TaskChecksum{hash: sha256.New()}
+}
+
+// Add adds the next task to the checksum.
+func (c *TaskChecksum) Add(task models.Task) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	// the bytes of the JSON array of the tasks
+	separator := ","
+	if c.count == 0 {
+		separator = "["
+	}
+	c.hash.Write([]byte(separator))
+	c.hash.Write(data)
+	c.count++
+
+	return nil
+}
+
+// Count returns the number of tasks added to the checksum.
+func (c *TaskChecksum) Count() int {
+	return c.count
+}
+
+// Sum returns the checksum of the added tasks, no task can be added after.
+func (c *TaskChecksum) Sum() string {
+	if c.count == 0 {
+		c.hash.Write([]byte("["))
+	}
+	c.hash.Write([]byte("]"))
+
+	return checksumPrefix + hex.EncodeToString(c.hash.Sum(nil))
+}
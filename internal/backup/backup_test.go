@@ -0,0 +1,314 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/eventsourced"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := eventsourced.Open(t.TempDir())
+	assert.NoError(t, err)
+	defer source.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2", Status: 1}}
+	assert.NoError(t, source.CreateTasks(ctx, tasks))
+
+	createdAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	archive, err := New(ctx, source, createdAt)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, archive.Version)
+	assert.Equal(t, createdAt.UTC(), archive.CreatedAt)
+	assert.Equal(t, 2, archive.Count)
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf))
+
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, archive, read)
+
+	// the archive restores into another kind of task manager with the same ids
+	target := repository.NewRepository()
+	assert.NoError(t, read.Restore(ctx, target, false))
+
+	got, err := target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	assert.ErrorIs(t, read.Restore(ctx, target, false), ErrStoreNotEmpty)
+
+	// replacing deletes the tasks created since the backup
+	assert.NoError(t, target.CreateTasks(ctx, []models.Task{{Name: "Task 3"}}))
+	assert.NoError(t, read.Restore(ctx, target, true))
+
+	got, err = target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+}
+
+// failingRestore fails the first restore of the task manager
+type failingRestore struct {
+	repository.TaskManager
+	failed bool
+}
+
+var errRestoreFailed = errors.New("restore failed")
+
+func (f *failingRestore) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	if !f.failed {
+		f.failed = true
+		return errRestoreFailed
+	}
+
+	return f.TaskManager.RestoreTasks(ctx, tasks)
+}
+
+func TestRestore_Replace(t *testing.T) {
+	ctx := context.Background()
+	archived := []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"}, {ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2"}}
+	repeated := []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"}, {ID: "9bsv0s2hf8ng030mva9g", Name: "Task 2"}}
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		repo    func(repo repository.TaskManager) repository.TaskManager
+		wantErr error
+	}{
+		{name: "repeated id", tasks: repeated, wantErr: repository.ErrTaskExists},
+		{name: "invalid id", tasks: []models.Task{{ID: "1", Name: "Task 1"}}, wantErr: repository.ErrTaskID},
+		{name: "invalid task", tasks: []models.Task{{ID: "9bsv0s2hf8ng030mva9g"}}, wantErr: ErrInvalidArchive},
+		{
+			name:    "failed restore",
+			tasks:   archived,
+			repo:    func(repo repository.TaskManager) repository.TaskManager { return &failingRestore{TaskManager: repo} },
+			wantErr: errRestoreFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := eventsourced.Open(t.TempDir())
+			assert.NoError(t, err)
+			defer target.Close()
+
+			current := []models.Task{{Name: "Task 3"}}
+			assert.NoError(t, target.CreateTasks(ctx, current))
+
+			var repo repository.TaskManager = target
+			if tt.repo != nil {
+				repo = tt.repo(target)
+			}
+
+			archive := &Archive{Version: SchemaVersion, Count: len(tt.tasks), Tasks: tt.tasks}
+			assert.ErrorIs(t, archive.Restore(ctx, repo, true), tt.wantErr)
+
+			// the current tasks are kept
+			got, err := target.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, current, got)
+		})
+	}
+}
+
+func TestBackupAndRestore_ViewsAndWebhooks(t *testing.T) {
+	ctx := context.Background()
+
+	source := eventsourcedRepository(t)
+	tasks := []models.Task{{Name: "Deploy the release"}}
+	assert.NoError(t, source.CreateTasks(ctx, tasks))
+
+	views := repository.NewViewRepository()
+	view, err := views.CreateView(ctx, models.View{Owner: "alice", Name: "Deploys", Query: "name:deploy", Columns: []string{"name"}})
+	assert.NoError(t, err)
+
+	webhooks := repository.NewWebhookRepository()
+	webhook, err := webhooks.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "s3cr3t", Events: []models.TaskEventType{models.TaskCompleted}})
+	assert.NoError(t, err)
+
+	archive, err := New(ctx, source, time.Now(), WithViews(views), WithWebhooks(webhooks))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, archive.Write(&buf))
+	// the secrets of the webhooks are kept so that the restored deliveries are signed alike
+	assert.Contains(t, buf.String(), `"secret":"s3cr3t"`)
+
+	read, err := Read(&buf)
+	assert.NoError(t, err)
+
+	targetViews, targetWebhooks := repository.NewViewRepository(), repository.NewWebhookRepository()
+	target := eventsourcedRepository(t)
+	assert.NoError(t, read.Restore(ctx, target, false, WithViews(targetViews), WithWebhooks(targetWebhooks)))
+
+	gotTasks, err := target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, gotTasks)
+
+	gotViews, err := targetViews.ListViews(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.View{view}, gotViews)
+
+	gotWebhooks, err := targetWebhooks.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Webhook{webhook}, gotWebhooks)
+
+	// a store with a view only is not empty
+	otherViews := repository.NewViewRepository()
+	_, err = otherViews.CreateView(ctx, models.View{Owner: "bob", Name: "Everything"})
+	assert.NoError(t, err)
+	err = read.Restore(ctx, eventsourcedRepository(t), false, WithViews(otherViews))
+	assert.ErrorIs(t, err, ErrStoreNotEmpty)
+
+	// replacing deletes the views and the webhooks created since the backup
+	_, err = targetViews.CreateView(ctx, models.View{Owner: "bob", Name: "Everything"})
+	assert.NoError(t, err)
+	_, err = targetWebhooks.CreateWebhook(ctx, models.Webhook{URL: "https://chat.example.com/hooks", Secret: "chat"})
+	assert.NoError(t, err)
+	assert.NoError(t, read.Restore(ctx, target, true, WithViews(targetViews), WithWebhooks(targetWebhooks)))
+
+	gotViews, err = targetViews.ListViews(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.View{view}, gotViews)
+
+	gotWebhooks, err = targetWebhooks.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Webhook{webhook}, gotWebhooks)
+}
+
+// failingViews fails the restores of the views
+type failingViews struct {
+	repository.ViewManager
+}
+
+func (f *failingViews) RestoreViews(ctx context.Context, views []models.View) error {
+	return errRestoreFailed
+}
+
+func TestRestore_ReplaceFailedViews(t *testing.T) {
+	ctx := context.Background()
+
+	target := eventsourcedRepository(t)
+	current := []models.Task{{Name: "Task 3"}}
+	assert.NoError(t, target.CreateTasks(ctx, current))
+
+	archive := &Archive{
+		Version:  SchemaVersion,
+		Count:    1,
+		Tasks:    []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"}},
+		Views:    []models.View{{ID: "cv1h8ms2hf8ng030mvb0", Owner: "alice", Name: "Open tasks"}},
+		Webhooks: []Webhook{},
+	}
+	err := archive.Restore(ctx, target, true, WithViews(&failingViews{ViewManager: repository.NewViewRepository()}))
+	assert.ErrorIs(t, err, errRestoreFailed)
+
+	// the restored tasks are deleted again and the current tasks are put back
+	got, err := target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, current, got)
+}
+
+// eventsourcedRepository returns an event-sourced task manager in a temporary directory.
+func eventsourcedRepository(t *testing.T) repository.TaskManager {
+	repo, err := eventsourced.Open(t.TempDir())
+	assert.NoError(t, err)
+	t.Cleanup(func() { repo.Close() })
+
+	return repo
+}
+
+func TestRead(t *testing.T) {
+	const checksum = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"   // of []
+	const checksumV2 = "sha256:fc74b20a1ac9cc7903780cf3e76d29d6099e1b56c565c2dfd886bf5d71d8f1af" // of [][][]
+
+	tests := []struct {
+		name    string
+		archive string
+		wantErr error
+	}{
+		{
+			name:    "empty archive",
+			archive: `{"version":2,"createdAt":"2026-10-18T07:00:00Z","count":0,"checksum":"` + checksumV2 + `","tasks":[],"views":[],"webhooks":[]}`,
+		},
+		{
+			name:    "empty archive of version 1",
+			archive: `{"version":1,"createdAt":"2026-10-18T07:00:00Z","count":0,"checksum":"` + checksum + `","tasks":[]}`,
+		},
+		{
+			name:    "views in an archive of version 1",
+			archive: `{"version":1,"count":0,"checksum":"` + checksum + `","tasks":[],"views":[{"id":"cv1h8ms2hf8ng030mvb0","owner":"alice","name":"Open tasks"}]}`,
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "modified view",
+			archive: `{"version":2,"count":0,"checksum":"` + checksumV2 + `","tasks":[],"views":[{"id":"cv1h8ms2hf8ng030mvb0","owner":"alice","name":"Open tasks"}],"webhooks":[]}`,
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:    "not json",
+			archive: "id,name,status\n",
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "json without version",
+			archive: `{"tasks":[]}`,
+			wantErr: ErrInvalidArchive,
+		},
+		{
+			name:    "newer schema version",
+			archive: `{"version":3,"count":0,"checksum":"` + checksumV2 + `","tasks":[],"views":[],"webhooks":[]}`,
+			wantErr: ErrIncompatibleVersion,
+		},
+		{
+			name:    "modified task",
+			archive: `{"version":1,"count":1,"checksum":"` + checksum + `","tasks":[{"id":"9bsv0s2hf8ng030mva9g","name":"Task 1","status":0}]}`,
+			wantErr: ErrChecksumMismatch,
+		},
+		{
+			name:    "wrong count",
+			archive: `{"version":1,"count":3,"checksum":"` + checksum + `","tasks":[]}`,
+			wantErr: ErrChecksumMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.archive))
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	tests := []struct {
+		name  string
+		tasks []models.Task
+	}{
+		{name: "no task", tasks: nil},
+		{name: "one task", tasks: []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task <1>"}}},
+		{name: "tasks", tasks: []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"}, {ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2", Status: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the checksum of the JSON array of the tasks
+			data, err := json.Marshal(append(make([]models.Task, 0), tt.tasks...))
+			assert.NoError(t, err)
+			sum := sha256.Sum256(data)
+
+			got, err := Checksum(tt.tasks)
+			assert.NoError(t, err)
+			assert.Equal(t, "sha256:"+hex.EncodeToString(sum[:]), got)
+		})
+	}
+}
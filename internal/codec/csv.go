@@ -0,0 +1,165 @@
+// Package codec converts tasks to and from the file formats used by other tools.
+package codec
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// csvHeader is the header of the exported CSV files, the import recognizes the same column names by default
+var csvHeader = []string{"id", "name", "status"}
+
+// csvImportFields are the task fields read by the CSV import
+var csvImportFields = []string{"name", "status"}
+
+var (
+	// ErrMissingColumn represents an error when a column mapped to a task field is missing from the CSV header
+	ErrMissingColumn = errors.New("missing column")
+	// ErrInvalidColumnMapping represents an error when a column mapping is malformed or maps an unknown task field
+	ErrInvalidColumnMapping = errors.New("invalid column mapping, expected field:column pairs of name and status")
+)
+
+// EncodeCSV writes the tasks as CSV rows of id, name and status after a header row.
+// The rows are written as they are encoded, so that large exports are streamed.
+func EncodeCSV(w io.Writer, tasks []models.Task) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if err := writer.Write([]string{task.ID, task.Name, strconv.Itoa(task.Status)}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// ParseCSVColumns parses a column mapping such as "name:Title,status:Done", mapping the task fields
+// to the CSV header names. The fields missing from the mapping are read from the column of the same name.
+func ParseCSVColumns(s string) (map[string]string, error) {
+	columns := make(map[string]string, len(csvImportFields))
+	for _, field := range csvImportFields {
+		columns[field] = field
+	}
+
+	if strings.TrimSpace(s) == "" {
+		return columns, nil
+	}
+
+	for _, pair := range strings.Split(s, ",") {
+		field, column, found := strings.Cut(pair, ":")
+		field, column = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(column)
+		if _, known := columns[field]; !found || !known || column == "" {
+			return nil, ErrInvalidColumnMapping
+		}
+
+		columns[field] = column
+	}
+
+	return columns, nil
+}
+
+// DecodeCSV reads new tasks from the CSV rows following a header row, the columns map the task fields
+// to the header names as returned by ParseCSVColumns. Header names are matched case-insensitively and
+// the other columns, such as the id of an export, are ignored. A missing status is an open task.
+//
+// Every row is validated like a created task, the errors of every invalid row are returned joined,
+// as field errors named rows[<line>] after the line of the row in the CSV.
+func DecodeCSV(r io.Reader, columns map[string]string) ([]models.NewTask, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, models.ErrNoTasksProvided
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]int, len(columns))
+	for field, column := range columns {
+		positions[field] = -1
+		for i, name := range header {
+			if i == 0 {
+				// spreadsheets may start their exports with a byte order mark
+				name = strings.TrimPrefix(name, "\ufeff")
+			}
+
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				positions[field] = i
+				break
+			}
+		}
+	}
+
+	// the status column is optional unless it was mapped explicitly
+	for _, field := range csvImportFields {
+		if positions[field] < 0 && (field == "name" || columns[field] != field) {
+			return nil, &models.FieldError{Field: "columns", Err: fmt.Errorf("%w: %s", ErrMissingColumn, columns[field])}
+		}
+	}
+
+	tasks := make([]models.NewTask, 0)
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		task, err := decodeCSVRecord(record, positions)
+		if err != nil {
+			errs = append(errs, &models.FieldError{Field: fmt.Sprintf("rows[%d]", line), Err: err})
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, models.ErrNoTasksProvided
+	}
+
+	return tasks, nil
+}
+
+// decodeCSVRecord reads a new task from the cells of a CSV row and validates it.
+func decodeCSVRecord(record []string, positions map[string]int) (models.NewTask, error) {
+	cell := func(field string) string {
+		if i := positions[field]; i >= 0 && i < len(record) {
+			return record[i]
+		}
+
+		return ""
+	}
+
+	// the name is kept as is for lossless round-trips
+	task := models.NewTask{Name: cell("name")}
+	if status := strings.TrimSpace(cell("status")); status != "" {
+		value, err := strconv.Atoi(status)
+		if err != nil {
+			return task, &models.FieldError{Field: "status", Err: models.ErrInvalidStatus}
+		}
+		task.Status = value
+	}
+
+	return task, task.Validate()
+}
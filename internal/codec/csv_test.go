@@ -0,0 +1,140 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeCSV(&buf, []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: `Deploy "api", then web`, Status: 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "id,name,status\n9bsv0s2hf8ng030mva9g,Task 1,0\n9bsv0s2hf8ng030mvaa0,\"Deploy \"\"api\"\", then web\",1\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, EncodeCSV(&buf, nil))
+	assert.Equal(t, "id,name,status\n", buf.String())
+}
+
+func TestParseCSVColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    map[string]string
+		wantErr error
+	}{
+		{name: "default columns", s: "", want: map[string]string{"name": "name", "status": "status"}},
+		{name: "mapped columns", s: "name:Title, Status:Done", want: map[string]string{"name": "Title", "status": "Done"}},
+		{name: "partially mapped columns", s: "name:Task", want: map[string]string{"name": "Task", "status": "status"}},
+		{name: "unknown field", s: "owner:Assignee", wantErr: ErrInvalidColumnMapping},
+		{name: "missing column", s: "name:", wantErr: ErrInvalidColumnMapping},
+		{name: "missing separator", s: "name", wantErr: ErrInvalidColumnMapping},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSVColumns(tt.s)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	defaultColumns := map[string]string{"name": "name", "status": "status"}
+
+	tests := []struct {
+		name           string
+		csv            string
+		columns        map[string]string
+		want           []models.NewTask
+		wantErr        error
+		wantViolations []models.FieldViolation
+	}{
+		{
+			name:    "exported tasks",
+			csv:     "id,name,status\n9bsv0s2hf8ng030mva9g,Task 1,0\n9bsv0s2hf8ng030mvaa0,\"Deploy \"\"api\"\", then web\",1\n",
+			columns: defaultColumns,
+			want:    []models.NewTask{{Name: "Task 1", Status: 0}, {Name: `Deploy "api", then web`, Status: 1}},
+		},
+		{
+			name:    "mapped columns in any case and order",
+			csv:     "\ufeffDone,Owner,TITLE\n1,alice,Task 1\n,bob,Task 2\n",
+			columns: map[string]string{"name": "Title", "status": "Done"},
+			want:    []models.NewTask{{Name: "Task 1", Status: 1}, {Name: "Task 2", Status: 0}},
+		},
+		{
+			name:    "without status column",
+			csv:     "name\nTask 1\n",
+			columns: defaultColumns,
+			want:    []models.NewTask{{Name: "Task 1", Status: 0}},
+		},
+		{
+			name:    "invalid rows",
+			csv:     "name,status\nTask 1,0\n,1\nTask 3,done\nTask 4,2\n",
+			columns: defaultColumns,
+			wantViolations: []models.FieldViolation{
+				{Field: "rows[3].name", Message: models.ErrTaskNameEmpty.Error()},
+				{Field: "rows[4].status", Message: models.ErrInvalidStatus.Error()},
+				{Field: "rows[5].status", Message: models.ErrInvalidStatus.Error()},
+			},
+		},
+		{
+			name:    "missing name column",
+			csv:     "title,status\nTask 1,0\n",
+			columns: defaultColumns,
+			wantErr: ErrMissingColumn,
+		},
+		{
+			name:    "missing mapped status column",
+			csv:     "name,status\nTask 1,0\n",
+			columns: map[string]string{"name": "name", "status": "Done"},
+			wantErr: ErrMissingColumn,
+		},
+		{
+			name:    "header only",
+			csv:     "name,status\n",
+			columns: defaultColumns,
+			wantErr: models.ErrNoTasksProvided,
+		},
+		{
+			name:    "empty file",
+			csv:     "",
+			columns: defaultColumns,
+			wantErr: models.ErrNoTasksProvided,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeCSV(strings.NewReader(tt.csv), tt.columns)
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantViolations != nil {
+				assert.Equal(t, tt.wantViolations, problem.Violations(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "  padded, with comma  ", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "multi\nline", Status: 1},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeCSV(&buf, tasks))
+
+	got, err := DecodeCSV(&buf, map[string]string{"name": "name", "status": "status"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.NewTask{{Name: tasks[0].Name, Status: 0}, {Name: tasks[1].Name, Status: 1}}, got)
+}
@@ -0,0 +1,248 @@
+package codec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+const (
+	// icalProductID identifies the application producing the calendars
+	icalProductID = "-//brionac626//taskManager//EN"
+	// icalDateTime is the layout of a UTC date-time value (RFC 5545 section 3.3.5)
+	icalDateTime = "20060102T150405Z"
+	// icalLineLength is the maximum length in octets of a content line before it is folded
+	icalLineLength = 75
+
+	icalStatusNeedsAction = "NEEDS-ACTION"
+	icalStatusCompleted   = "COMPLETED"
+)
+
+var (
+	// ErrInvalidCalendar represents an error when a file is not an iCalendar object
+	ErrInvalidCalendar = errors.New("invalid iCalendar object")
+	// ErrUnterminatedComponent represents an error when a component of a calendar has no END line
+	ErrUnterminatedComponent = errors.New("component is not terminated")
+)
+
+// EncodeICal writes the tasks as the VTODO components (RFC 5545) of a calendar.
+// The UID of a todo is the task id and its DTSTAMP the creation time of the id, so that exporting
+// the same tasks twice produces the same calendar, or the export time for a task without id since every todo
+// requires a DTSTAMP; the status maps to NEEDS-ACTION or COMPLETED.
+func EncodeICal(w io.Writer, tasks []models.Task) error {
+	exported := time.Now().UTC().Format(icalDateTime)
+
+	writer := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(writer, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", icalProductID)
+	for _, task := range tasks {
+		line("BEGIN", "VTODO")
+		line("UID", task.ID)
+		stamp := exported
+		if id, err := xid.FromString(task.ID); err == nil {
+			stamp = id.Time().UTC().Format(icalDateTime)
+		}
+		line("DTSTAMP", stamp)
+		line("SUMMARY", escapeICalText(task.Name))
+		if task.Status == 1 {
+			line("STATUS", icalStatusCompleted)
+		} else {
+			line("STATUS", icalStatusNeedsAction)
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+
+	return writer.Flush()
+}
+
+// DecodeICal reads the tasks of the VTODO components of a calendar, the other components are ignored.
+// The task id is the UID of a todo when it is a task id, and empty otherwise. A todo is done when its
+// status is COMPLETED or it has a completion date.
+//
+// Every task is validated like a created task, the errors of every invalid todo are returned joined,
+// as field errors named rows[<line>] after the line of the BEGIN:VTODO in the file.
+func DecodeICal(r io.Reader) ([]models.Task, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 || !strings.EqualFold(lines[0].text, "BEGIN:VCALENDAR") {
+		return nil, ErrInvalidCalendar
+	}
+
+	tasks := make([]models.Task, 0)
+	var errs []error
+	var components []string // names of the components enclosing the current line
+	var task *models.Task
+	var taskLine int
+	for _, l := range lines {
+		name, value, err := parseICalLine(l.text)
+		if err != nil {
+			return nil, &models.FieldError{Field: fmt.Sprintf("rows[%d]", l.number), Err: err}
+		}
+
+		switch {
+		case name == "BEGIN":
+			components = append(components, strings.ToUpper(value))
+			if len(components) == 2 && components[1] == "VTODO" {
+				task, taskLine = &models.Task{}, l.number
+			}
+		case name == "END":
+			if len(components) == 0 || components[len(components)-1] != strings.ToUpper(value) {
+				return nil, &models.FieldError{Field: fmt.Sprintf("rows[%d]", l.number), Err: ErrInvalidCalendar}
+			}
+
+			if len(components) == 2 && task != nil {
+				if err := task.Validate(); err != nil {
+					errs = append(errs, &models.FieldError{Field: fmt.Sprintf("rows[%d]", taskLine), Err: err})
+				} else {
+					tasks = append(tasks, *task)
+				}
+				task = nil
+			}
+			components = components[:len(components)-1]
+		case task != nil && len(components) == 2:
+			// properties of the nested components, such as alarms, are skipped
+			decodeICalProperty(task, name, value)
+		}
+	}
+
+	if len(components) != 0 {
+		return nil, ErrUnterminatedComponent
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, models.ErrNoTasksProvided
+	}
+
+	return tasks, nil
+}
+
+// decodeICalProperty sets the task field of a VTODO property.
+func decodeICalProperty(task *models.Task, name, value string) {
+	switch name {
+	case "UID":
+		if id, err := xid.FromString(value); err == nil {
+			task.ID = id.String()
+		}
+	case "SUMMARY":
+		task.Name = unescapeICalText(value)
+	case "STATUS":
+		if strings.EqualFold(value, icalStatusCompleted) {
+			task.Status = 1
+		}
+	case "COMPLETED":
+		task.Status = 1
+	}
+}
+
+// icalLine is an unfolded content line along with the number of its first physical line.
+type icalLine struct {
+	number int
+	text   string
+}
+
+// unfoldICalLines reads the content lines, joining the folded lines (RFC 5545 section 3.1) and skipping the empty ones.
+func unfoldICalLines(r io.Reader) ([]icalLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []icalLine
+	number := 0
+	for scanner.Scan() {
+		number++
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			if len(lines) == 0 {
+				return nil, ErrInvalidCalendar
+			}
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+
+		if text != "" {
+			lines = append(lines, icalLine{number: number, text: text})
+		}
+	}
+
+	return lines, scanner.Err()
+}
+
+// parseICalLine splits a content line into its upper-cased property name and its value, the parameters are ignored.
+func parseICalLine(text string) (string, string, error) {
+	// the value starts at the first colon outside of a quoted parameter value
+	quoted := false
+	for i, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ':' && !quoted:
+			name, _, _ := strings.Cut(text[:i], ";")
+			if name == "" {
+				return "", "", ErrInvalidCalendar
+			}
+
+			return strings.ToUpper(name), text[i+1:], nil
+		}
+	}
+
+	return "", "", ErrInvalidCalendar
+}
+
+// writeICalLine writes a content line terminated by CRLF, folded every icalLineLength octets
+// without splitting a UTF-8 character.
+func writeICalLine(w *bufio.Writer, text string) {
+	limit := icalLineLength
+	for len(text) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+
+		w.WriteString(text[:cut])
+		w.WriteString("\r\n ")
+		text = text[cut:]
+		// the leading space of a continuation line counts toward its length
+		limit = icalLineLength - 1
+	}
+
+	w.WriteString(text)
+	w.WriteString("\r\n")
+}
+
+var (
+	icalTextEscaper   = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	icalTextUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+)
+
+// escapeICalText escapes a TEXT value (RFC 5545 section 3.3.11).
+func escapeICalText(s string) string {
+	return icalTextEscaper.Replace(s)
+}
+
+// unescapeICalText unescapes a TEXT value (RFC 5545 section 3.3.11).
+func unescapeICalText(s string) string {
+	return icalTextUnescaper.Replace(s)
+}
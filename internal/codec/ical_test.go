@@ -0,0 +1,194 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeICal(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeICal(&buf, []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Deploy api, then web; notify\\ops", Status: 1},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//brionac626//taskManager//EN",
+		"BEGIN:VTODO",
+		"UID:9bsv0s2hf8ng030mva9g",
+		"DTSTAMP:20091110T230000Z",
+		"SUMMARY:Task 1",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+		"BEGIN:VTODO",
+		"UID:9bsv0s2hf8ng030mvaa0",
+		"DTSTAMP:20091110T230000Z",
+		`SUMMARY:Deploy api\, then web\; notify\\ops`,
+		"STATUS:COMPLETED",
+		"END:VTODO",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), buf.String())
+}
+
+func TestEncodeICal_WithoutID(t *testing.T) {
+	before := time.Now().UTC().Truncate(time.Second)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeICal(&buf, []models.Task{{Name: "Task 1"}}))
+
+	// every todo has a DTSTAMP, the export time of a task without id
+	var stamp string
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if value, ok := strings.CutPrefix(line, "DTSTAMP:"); ok {
+			stamp = value
+		}
+	}
+
+	exported, err := time.Parse(icalDateTime, stamp)
+	assert.NoError(t, err)
+	assert.False(t, exported.Before(before))
+	assert.False(t, exported.After(time.Now().UTC()))
+}
+
+func TestEncodeICal_Folding(t *testing.T) {
+	name := strings.Repeat("é", 60)
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeICal(&buf, []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: name}}))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), icalLineLength)
+		assert.True(t, strings.ToValidUTF8(line, "?") == line, "folded inside a character: %q", line)
+	}
+
+	tasks, err := DecodeICal(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: name}}, tasks)
+}
+
+func TestDecodeICal(t *testing.T) {
+	tests := []struct {
+		name           string
+		ics            string
+		want           []models.Task
+		wantErr        error
+		wantViolations []models.FieldViolation
+	}{
+		{
+			name: "todos of a calendar app",
+			ics: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"VERSION:2.0",
+				"PRODID:-//Example Corp.//CalDAV Client//EN",
+				"BEGIN:VEVENT",
+				"UID:event-1@example.com",
+				"SUMMARY:Standup",
+				"END:VEVENT",
+				"BEGIN:VTODO",
+				"UID:9bsv0s2hf8ng030mva9g",
+				"SUMMARY;LANGUAGE=en:Write the",
+				"  release notes",
+				"DUE;VALUE=DATE:20261020",
+				"BEGIN:VALARM",
+				"ACTION:DISPLAY",
+				"SUMMARY:Reminder",
+				"END:VALARM",
+				"END:VTODO",
+				"BEGIN:VTODO",
+				"UID:20261018T090000Z-42@example.com",
+				"SUMMARY:Book\\, the \\;room\\nfor Monday",
+				"STATUS:IN-PROCESS",
+				"END:VTODO",
+				"begin:vtodo",
+				"summary:Ship it",
+				"completed:20261018T090000Z",
+				"end:vtodo",
+				"END:VCALENDAR",
+			}, "\r\n"),
+			want: []models.Task{
+				{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the release notes", Status: 0},
+				{Name: "Book, the ;room\nfor Monday", Status: 0},
+				{Name: "Ship it", Status: 1},
+			},
+		},
+		{
+			name: "invalid todos",
+			ics: strings.Join([]string{
+				"BEGIN:VCALENDAR",
+				"BEGIN:VTODO",
+				"SUMMARY:Task 1",
+				"END:VTODO",
+				"BEGIN:VTODO",
+				"STATUS:COMPLETED",
+				"END:VTODO",
+				"END:VCALENDAR",
+			}, "\n"),
+			wantViolations: []models.FieldViolation{{Field: "rows[5].name", Message: models.ErrTaskNameEmpty.Error()}},
+		},
+		{
+			name:    "not a calendar",
+			ics:     "id,name,status\n",
+			wantErr: ErrInvalidCalendar,
+		},
+		{
+			name:    "line without value",
+			ics:     "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY\nEND:VTODO\nEND:VCALENDAR\n",
+			wantErr: ErrInvalidCalendar,
+		},
+		{
+			name:    "mismatched end",
+			ics:     "BEGIN:VCALENDAR\nBEGIN:VTODO\nEND:VEVENT\nEND:VCALENDAR\n",
+			wantErr: ErrInvalidCalendar,
+		},
+		{
+			name:    "unterminated calendar",
+			ics:     "BEGIN:VCALENDAR\nBEGIN:VTODO\nSUMMARY:Task 1\nEND:VTODO\n",
+			wantErr: ErrUnterminatedComponent,
+		},
+		{
+			name:    "calendar without todos",
+			ics:     "BEGIN:VCALENDAR\nVERSION:2.0\nEND:VCALENDAR\n",
+			wantErr: models.ErrNoTasksProvided,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeICal(strings.NewReader(tt.ics))
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantViolations != nil {
+				assert.Equal(t, tt.wantViolations, problem.Violations(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestICalRoundTrip(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: `a\n is not a newline, but` + "\nthis one is", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "  padded: with colon, comma; and semicolon  ", Status: 1},
+		{ID: "9bsv0s2hf8ng030mvab0", Name: strings.Repeat("long name ", 20), Status: 0},
+	}
+
+	var first bytes.Buffer
+	assert.NoError(t, EncodeICal(&first, tasks))
+
+	got, err := DecodeICal(bytes.NewReader(first.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	var second bytes.Buffer
+	assert.NoError(t, EncodeICal(&second, got))
+	assert.Equal(t, first.String(), second.String())
+}
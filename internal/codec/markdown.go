@@ -0,0 +1,194 @@
+package codec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// MarkdownGroup is the marker grouping the tasks of a Markdown checklist under headings.
+type MarkdownGroup string
+
+const (
+	// MarkdownGroupNone writes a single checklist
+	MarkdownGroupNone MarkdownGroup = ""
+	// MarkdownGroupProject groups the tasks by the first +project word of their name
+	MarkdownGroupProject MarkdownGroup = "project"
+	// MarkdownGroupTag groups the tasks by the first #tag word of their name
+	MarkdownGroupTag MarkdownGroup = "tag"
+)
+
+// ErrInvalidGroup represents an error when the tasks are grouped by an unknown marker
+var ErrInvalidGroup = errors.New("invalid group, expected project or tag")
+
+var (
+	// markdownChecklistItem matches a checklist item: an indented bullet or ordered list marker followed by
+	// a task list marker, the groups are the check mark and the text of the item
+	markdownChecklistItem = regexp.MustCompile(`^[ \t]*(?:[-*+]|[0-9]{1,9}[.)])[ \t]+\[([ xX])\](?: (.*))?$`)
+	// markdownFence matches the opening or closing line of a fenced code block
+	markdownFence = regexp.MustCompile("^[ \t]*(```|~~~)")
+	// markdownEscapes matches a backslash escape of an ASCII punctuation character (CommonMark section 2.4)
+	markdownEscapes = regexp.MustCompile("\\\\([!-/:-@\\[-`{-~])")
+
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", `\<`, ">", `\>`,
+		"\r\n", " ", "\n", " ", "\r", " ",
+	)
+)
+
+// ParseMarkdownGroup parses the marker grouping the tasks of a checklist, an empty string groups nothing.
+func ParseMarkdownGroup(s string) (MarkdownGroup, error) {
+	switch group := MarkdownGroup(strings.ToLower(strings.TrimSpace(s))); group {
+	case MarkdownGroupNone, MarkdownGroupProject, MarkdownGroupTag:
+		return group, nil
+	}
+
+	return MarkdownGroupNone, ErrInvalidGroup
+}
+
+// EncodeMarkdown writes the tasks as the items of a Markdown checklist, "- [ ]" for an incomplete task and
+// "- [x]" for a completed task. The Markdown characters of the names are escaped so that the rendered text
+// is the task name, and the line breaks are written as spaces.
+//
+// Tasks have no subtasks, the checklists are not nested. Grouped by project or tag, every group is a level 2
+// heading named after the first +project or #tag word of the task names, in alphabetical order, followed by
+// the tasks without such a word.
+func EncodeMarkdown(w io.Writer, tasks []models.Task, group MarkdownGroup) error {
+	writer := bufio.NewWriter(w)
+	if group == MarkdownGroupNone {
+		writeMarkdownChecklist(writer, tasks)
+		return writer.Flush()
+	}
+
+	prefix, heading := "+", "No project"
+	if group == MarkdownGroupTag {
+		prefix, heading = "#", "No tag"
+	}
+
+	groups := make(map[string][]models.Task)
+	for _, task := range tasks {
+		marker := firstMarker(task.Name, prefix)
+		groups[marker] = append(groups[marker], task)
+	}
+
+	markers := make([]string, 0, len(groups))
+	for marker := range groups {
+		if marker != "" {
+			markers = append(markers, marker)
+		}
+	}
+	sort.Strings(markers)
+	if _, found := groups[""]; found {
+		markers = append(markers, "")
+	}
+
+	for i, marker := range markers {
+		if i > 0 {
+			writer.WriteString("\n")
+		}
+
+		if marker == "" {
+			writer.WriteString("## " + heading + "\n\n")
+		} else {
+			writer.WriteString("## " + markdownEscaper.Replace(marker) + "\n\n")
+		}
+		writeMarkdownChecklist(writer, groups[marker])
+	}
+
+	return writer.Flush()
+}
+
+// writeMarkdownChecklist writes the tasks as checklist items.
+func writeMarkdownChecklist(w *bufio.Writer, tasks []models.Task) {
+	for _, task := range tasks {
+		if task.Status == 1 {
+			w.WriteString("- [x] ")
+		} else {
+			w.WriteString("- [ ] ")
+		}
+		w.WriteString(markdownEscaper.Replace(task.Name))
+		w.WriteString("\n")
+	}
+}
+
+// firstMarker returns the first word of the name starting with the prefix, or an empty string.
+func firstMarker(name, prefix string) string {
+	for _, word := range strings.Fields(name) {
+		if len(word) > len(prefix) && strings.HasPrefix(word, prefix) {
+			return word
+		}
+	}
+
+	return ""
+}
+
+// DecodeMarkdown reads new tasks from the checklist items of a Markdown document: "[ ]" is an incomplete task
+// and "[x]" a completed task, whatever the list marker and the indentation. The other lines, such as headings,
+// paragraphs and the lines of code blocks, are ignored. Tasks have no subtasks, the items of nested checklists
+// are read as tasks of their own.
+//
+// Every task is validated like a created task, the errors of every invalid item are returned joined,
+// as field errors named rows[<line>] after the line of the item in the document.
+func DecodeMarkdown(r io.Reader) ([]models.NewTask, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	tasks := make([]models.NewTask, 0)
+	var errs []error
+	var fence string // opening fence of the current code block
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if match := markdownFence.FindStringSubmatch(line); match != nil {
+			switch fence {
+			case "":
+				fence = match[1]
+			case match[1]:
+				fence = ""
+			}
+			continue
+		}
+
+		match := markdownChecklistItem.FindStringSubmatch(line)
+		if fence != "" || match == nil {
+			continue
+		}
+
+		task := models.NewTask{Name: markdownEscapes.ReplaceAllString(match[2], "$1")}
+		if match[1] != " " {
+			task.Status = 1
+		}
+
+		if err := task.Validate(); err != nil {
+			errs = append(errs, &models.FieldError{Field: fmt.Sprintf("rows[%d]", number), Err: err})
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, models.ErrNoTasksProvided
+	}
+
+	return tasks, nil
+}
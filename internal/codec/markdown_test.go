@@ -0,0 +1,160 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdownGroup(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    MarkdownGroup
+		wantErr error
+	}{
+		{name: "no group", s: "", want: MarkdownGroupNone},
+		{name: "project", s: "Project", want: MarkdownGroupProject},
+		{name: "tag", s: "tag", want: MarkdownGroupTag},
+		{name: "unknown group", s: "owner", want: MarkdownGroupNone, wantErr: ErrInvalidGroup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMarkdownGroup(tt.s)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEncodeMarkdown(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the *release* notes +Release #docs", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Fix [login]\nbug +Api #bug", Status: 1},
+		{ID: "9bsv0s2hf8ng030mvab0", Name: "Tag v2 +Release", Status: 1},
+		{ID: "9bsv0s2hf8ng030mvac0", Name: "Water the plants", Status: 0},
+	}
+
+	tests := []struct {
+		name  string
+		group MarkdownGroup
+		want  string
+	}{
+		{
+			name:  "single checklist",
+			group: MarkdownGroupNone,
+			want: "- [ ] Write the \\*release\\* notes +Release #docs\n" +
+				"- [x] Fix \\[login\\] bug +Api #bug\n" +
+				"- [x] Tag v2 +Release\n" +
+				"- [ ] Water the plants\n",
+		},
+		{
+			name:  "grouped by project",
+			group: MarkdownGroupProject,
+			want: "## +Api\n\n" +
+				"- [x] Fix \\[login\\] bug +Api #bug\n" +
+				"\n## +Release\n\n" +
+				"- [ ] Write the \\*release\\* notes +Release #docs\n" +
+				"- [x] Tag v2 +Release\n" +
+				"\n## No project\n\n" +
+				"- [ ] Water the plants\n",
+		},
+		{
+			name:  "grouped by tag",
+			group: MarkdownGroupTag,
+			want: "## #bug\n\n" +
+				"- [x] Fix \\[login\\] bug +Api #bug\n" +
+				"\n## #docs\n\n" +
+				"- [ ] Write the \\*release\\* notes +Release #docs\n" +
+				"\n## No tag\n\n" +
+				"- [x] Tag v2 +Release\n" +
+				"- [ ] Water the plants\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, EncodeMarkdown(&buf, tasks, tt.group))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestDecodeMarkdown(t *testing.T) {
+	tests := []struct {
+		name           string
+		markdown       string
+		want           []models.NewTask
+		wantErr        error
+		wantViolations []models.FieldViolation
+	}{
+		{
+			name: "checklists of a pull request",
+			markdown: "## Release\r\n" +
+				"\r\n" +
+				"Before merging:\r\n" +
+				"- [x] Write the release notes\r\n" +
+				"  - [ ] Review the \\*changelog\\*\r\n" +
+				"* [X] Tag v2\r\n" +
+				"1. [ ] Deploy\r\n" +
+				"- plain item\r\n" +
+				"- [ ]no space is not a task\r\n" +
+				"```markdown\r\n" +
+				"- [ ] example in a code block\r\n" +
+				"```\r\n" +
+				"- [ ] Announce it\r\n",
+			want: []models.NewTask{
+				{Name: "Write the release notes", Status: 1},
+				{Name: "Review the *changelog*", Status: 0},
+				{Name: "Tag v2", Status: 1},
+				{Name: "Deploy", Status: 0},
+				{Name: "Announce it", Status: 0},
+			},
+		},
+		{
+			name:           "invalid items",
+			markdown:       "- [ ] Task 1\n- [x]\n",
+			wantViolations: []models.FieldViolation{{Field: "rows[2].name", Message: models.ErrTaskNameEmpty.Error()}},
+		},
+		{
+			name:     "document without checklist",
+			markdown: "# Notes\n\n- plain item\n",
+			wantErr:  models.ErrNoTasksProvided,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeMarkdown(strings.NewReader(tt.markdown))
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantViolations != nil {
+				assert.Equal(t, tt.wantViolations, problem.Violations(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	tasks := []models.Task{
+		{Name: `escaped \*markdown\* and a back\slash`, Status: 0},
+		{Name: "*emphasis* _underscores_ `code` [link](url) <html>", Status: 1},
+		{Name: "  padded  +Release ", Status: 0},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, EncodeMarkdown(&buf, tasks, MarkdownGroupProject))
+
+	got, err := DecodeMarkdown(&buf)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []models.NewTask{
+		{Name: tasks[0].Name, Status: 0},
+		{Name: tasks[1].Name, Status: 1},
+		{Name: tasks[2].Name, Status: 0},
+	}, got)
+}
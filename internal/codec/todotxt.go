@@ -0,0 +1,155 @@
+package codec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+const (
+	// todoTxtDate is the layout of the completion and creation dates of a todo.txt line
+	todoTxtDate = "2006-01-02"
+	// todoTxtDone is the marker starting the line of a completed task
+	todoTxtDone = "x "
+	// todoTxtIDKey is the key of the key:value tag holding the task id
+	todoTxtIDKey = "id:"
+)
+
+var (
+	// todoTxtPriority matches the priority starting the line of an incomplete task, such as "(A) "
+	todoTxtPriority = regexp.MustCompile(`^\([A-Z]\) `)
+
+	// todoTxtLineBreaks replaces the line breaks of a task name with spaces
+	todoTxtLineBreaks = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+)
+
+// EncodeTodoTxt writes the tasks as the lines of a todo.txt file (https://github.com/todotxt/todo.txt).
+// A completed task starts with the "x " marker followed by its completion and creation dates, an incomplete
+// task with its creation date, and the task id is kept in an id:<id> tag at the end of the line. The projects
+// (+project) and contexts (@context) are part of the task name and are written as they are.
+//
+// The creation date is taken from the task id, or is today for a task without id. The completion date is
+// unknown and is written as the creation date. The dates are always written so that a name starting with
+// a date, a priority or the "x " marker is read back as it is.
+//
+// todo.txt has a line per task, the line breaks of the names are written as spaces.
+func EncodeTodoTxt(w io.Writer, tasks []models.Task) error {
+	today := time.Now().UTC().Format(todoTxtDate)
+
+	writer := bufio.NewWriter(w)
+	for _, task := range tasks {
+		created := today
+		if id, err := xid.FromString(task.ID); err == nil {
+			created = id.Time().UTC().Format(todoTxtDate)
+		}
+
+		if task.Status == 1 {
+			writer.WriteString(todoTxtDone + created + " ")
+		}
+		writer.WriteString(created + " ")
+
+		writer.WriteString(todoTxtLineBreaks.Replace(task.Name))
+		if task.ID != "" {
+			writer.WriteString(" " + todoTxtIDKey + task.ID)
+		}
+		writer.WriteString("\n")
+	}
+
+	return writer.Flush()
+}
+
+// DecodeTodoTxt reads the tasks of the lines of a todo.txt file, the empty lines are ignored.
+// A line starting with the "x " marker is a completed task, the priority and the dates are skipped since
+// tasks have neither, and the rest of the line is the task name, projects and contexts included.
+// The task id is the value of an id:<id> tag when it is a task id, the tag is then removed from the name.
+//
+// Every task is validated like a created task, the errors of every invalid line are returned joined,
+// as field errors named rows[<line>] after the line number in the file.
+func DecodeTodoTxt(r io.Reader) ([]models.Task, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	tasks := make([]models.Task, 0)
+	var errs []error
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		task := decodeTodoTxtLine(line)
+		if err := task.Validate(); err != nil {
+			errs = append(errs, &models.FieldError{Field: fmt.Sprintf("rows[%d]", number), Err: err})
+			continue
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, models.ErrNoTasksProvided
+	}
+
+	return tasks, nil
+}
+
+// decodeTodoTxtLine reads the task of a todo.txt line.
+func decodeTodoTxtLine(line string) models.Task {
+	var task models.Task
+
+	dates := 1 // creation date
+	if strings.HasPrefix(line, todoTxtDone) {
+		task.Status = 1
+		line = line[len(todoTxtDone):]
+		dates = 2 // completion date followed by the creation date
+	} else if priority := todoTxtPriority.FindString(line); priority != "" {
+		line = line[len(priority):]
+	}
+
+	for ; dates > 0; dates-- {
+		date, rest, _ := strings.Cut(line, " ")
+		if _, err := time.Parse(todoTxtDate, date); err != nil {
+			break
+		}
+		line = rest
+	}
+
+	// the words are split on single spaces so that the other spaces of the name are kept
+	words := strings.Split(line, " ")
+	for i := len(words) - 1; i >= 0; i-- {
+		value, found := strings.CutPrefix(words[i], todoTxtIDKey)
+		if !found {
+			continue
+		}
+
+		if id, err := xid.FromString(value); err == nil {
+			task.ID = id.String()
+			words = append(words[:i], words[i+1:]...)
+			break
+		}
+	}
+	task.Name = strings.Join(words, " ")
+
+	return task
+}
@@ -0,0 +1,104 @@
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeTodoTxt(t *testing.T) {
+	var buf bytes.Buffer
+	err := EncodeTodoTxt(&buf, []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Call the vendor +Release @phone", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Write the\nrelease notes +Release", Status: 1},
+		{Name: "Task without id"},
+	})
+	assert.NoError(t, err)
+	today := time.Now().UTC().Format("2006-01-02")
+	assert.Equal(t, "2009-11-10 Call the vendor +Release @phone id:9bsv0s2hf8ng030mva9g\n"+
+		"x 2009-11-10 2009-11-10 Write the release notes +Release id:9bsv0s2hf8ng030mvaa0\n"+
+		today+" Task without id\n", buf.String())
+}
+
+func TestDecodeTodoTxt(t *testing.T) {
+	tests := []struct {
+		name           string
+		todo           string
+		want           []models.Task
+		wantErr        error
+		wantViolations []models.FieldViolation
+	}{
+		{
+			name: "lines of todo.txt tools",
+			todo: "(A) 2026-10-17 Call Mom +Family @phone due:2026-10-20\r\n" +
+				"\r\n" +
+				"x 2026-10-18 2026-10-17 Review the PR +Release pri:B\r\n" +
+				"x Ship it id:9bsv0s2hf8ng030mva9g\r\n" +
+				"(b) Lower case is not a priority\r\n" +
+				"xylophone lesson @music id:1\r\n",
+			want: []models.Task{
+				{Name: "Call Mom +Family @phone due:2026-10-20", Status: 0},
+				{Name: "Review the PR +Release pri:B", Status: 1},
+				{ID: "9bsv0s2hf8ng030mva9g", Name: "Ship it", Status: 1},
+				{Name: "(b) Lower case is not a priority", Status: 0},
+				{Name: "xylophone lesson @music id:1", Status: 0},
+			},
+		},
+		{
+			name: "invalid lines",
+			todo: "Task 1\nx \n2026-10-17\n",
+			wantViolations: []models.FieldViolation{
+				{Field: "rows[2].name", Message: models.ErrTaskNameEmpty.Error()},
+				{Field: "rows[3].name", Message: models.ErrTaskNameEmpty.Error()},
+			},
+		},
+		{
+			name:    "empty file",
+			todo:    "\n\n",
+			wantErr: models.ErrNoTasksProvided,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeTodoTxt(strings.NewReader(tt.todo))
+			assert.Equal(t, tt.want, got)
+
+			if tt.wantViolations != nil {
+				assert.Equal(t, tt.wantViolations, problem.Violations(err))
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "(A) looks like a priority", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "2026-10-17 looks like a date", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvab0", Name: "x looks like a done marker", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvac0", Name: "  padded  with  spaces  +project @context key:value ", Status: 1},
+		{ID: "9bsv0s2hf8ng030mvad0", Name: "2025-01-02 2025-03-04 release", Status: 1},
+		{ID: "9bsv0s2hf8ng030mvae0", Name: "x (A) done twice", Status: 1},
+		{Name: "2025-01-02 release without id", Status: 0},
+		{Name: "(A) priority without id", Status: 0},
+		{Name: "x done marker without id", Status: 0},
+		{Name: "2025-01-02 2025-03-04 completed without id", Status: 1},
+	}
+
+	var first bytes.Buffer
+	assert.NoError(t, EncodeTodoTxt(&first, tasks))
+
+	got, err := DecodeTodoTxt(bytes.NewReader(first.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	var second bytes.Buffer
+	assert.NoError(t, EncodeTodoTxt(&second, got))
+	assert.Equal(t, first.String(), second.String())
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+const (
+	// DefaultBufferSize is the default number of events kept for replaying
+	DefaultBufferSize = 1024
+	// subscriberBufferSize is the number of events queued for a subscriber before it is dropped
+	subscriberBufferSize = 64
+)
+
+// Bus is an in-process event bus fanning out task events to the subscribers.
+// The most recent events are kept in a bounded buffer so that subscribers can resume after a disconnection.
+type Bus struct {
+	mu          sync.Mutex
+	lastID      uint64
+	buffer      []models.TaskEvent // ring buffer of the most recent events
+	start       int                // index of the oldest event in buffer
+	size        int                // number of events in buffer
+	subscribers map[*Subscription]struct{}
+	now         func() time.Time
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	bus    *Bus
+	events chan models.TaskEvent
+	once   sync.Once
+}
+
+// NewBus creates a new event bus keeping the given number of events for replaying.
+// A non-positive size falls back to DefaultBufferSize.
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+
+	return &Bus{
+		buffer:      make([]models.TaskEvent, bufferSize),
+		subscribers: make(map[*Subscription]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Publish assigns the next event id to a task event and delivers it to every subscriber.
+// A subscriber too slow to keep up is dropped, its channel is closed and it is expected to
+// subscribe again with the id of the last event it received.
+func (b *Bus) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := models.TaskEvent{ID: b.lastID + 1, Type: eventType, Task: task, Time: b.now().UTC()}
+	b.deliver(event)
+
+	return event
+}
+
+// Deliver delivers an event numbered by another publisher, such as the events shared by the replicas of the server,
+// keeping its id. The events must be delivered in the order of their ids, an event not following the last one
+// is ignored.
+func (b *Bus) Deliver(event models.TaskEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.ID > b.lastID {
+		b.deliver(event)
+	}
+}
+
+// deliver buffers the event and delivers it to every subscriber, b.mu must be held.
+func (b *Bus) deliver(event models.TaskEvent) {
+	b.lastID = event.ID
+	if b.size < len(b.buffer) {
+		b.buffer[(b.start+b.size)%len(b.buffer)] = event
+		b.size++
+	} else {
+		b.buffer[b.start] = event
+		b.start = (b.start + 1) % len(b.buffer)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			b.drop(sub)
+		}
+	}
+}
+
+// Subscribe subscribes to the events published after the event with the given id.
+// The buffered events after lastEventID are returned for replaying, and complete reports whether
+// every event after lastEventID was still buffered. A zero lastEventID only subscribes to new events.
+func (b *Bus) Subscribe(lastEventID uint64) (sub *Subscription, replay []models.TaskEvent, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{bus: b, events: make(chan models.TaskEvent, subscriberBufferSize)}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == 0 || lastEventID >= b.lastID {
+		return sub, nil, lastEventID <= b.lastID
+	}
+
+	// the ids of the delivered events may not start at 1
+	oldest := b.buffer[b.start].ID
+	complete = b.size > 0 && lastEventID+1 >= oldest
+
+	for i := 0; i < b.size; i++ {
+		event := b.buffer[(b.start+i)%len(b.buffer)]
+		if event.ID > lastEventID {
+			replay = append(replay, event)
+		}
+	}
+
+	return sub, replay, complete
+}
+
+// LastEventID returns the id of the last published event.
+func (b *Bus) LastEventID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.lastID
+}
+
+// drop removes the subscriber and closes its channel, b.mu must be held.
+func (b *Bus) drop(sub *Subscription) {
+	if _, exists := b.subscribers[sub]; !exists {
+		return
+	}
+
+	delete(b.subscribers, sub)
+	close(sub.events)
+}
+
+// Events returns the channel delivering the events, closed when the subscription is dropped or closed.
+func (s *Subscription) Events() <-chan models.TaskEvent {
+	return s.events
+}
+
+// Close cancels the subscription.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+
+		s.bus.drop(s)
+	})
+}
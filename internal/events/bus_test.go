@@ -0,0 +1,109 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func eventIDs(events []models.TaskEvent) []uint64 {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.ID)
+	}
+
+	return ids
+}
+
+func TestBus_PublishAndSubscribe(t *testing.T) {
+	bus := NewBus(3)
+
+	sub, replay, complete := bus.Subscribe(0)
+	defer sub.Close()
+	assert.Empty(t, replay)
+	assert.True(t, complete)
+
+	task := models.Task{ID: "task1", Name: "Task 1"}
+	event := bus.Publish(models.TaskCreated, task)
+	assert.Equal(t, uint64(1), event.ID)
+	assert.Equal(t, models.TaskCreated, event.Type)
+	assert.Equal(t, task, event.Task)
+
+	assert.Equal(t, event, <-sub.Events())
+	assert.Equal(t, uint64(1), bus.LastEventID())
+}
+
+func TestBus_Replay(t *testing.T) {
+	bus := NewBus(3)
+	for i := 0; i < 5; i++ {
+		bus.Publish(models.TaskUpdated, models.Task{ID: "task1"})
+	}
+
+	tests := []struct {
+		name         string
+		lastEventID  uint64
+		wantReplay   []uint64
+		wantComplete bool
+	}{
+		{name: "new subscriber", lastEventID: 0, wantReplay: []uint64{}, wantComplete: true},
+		{name: "resume within the buffer", lastEventID: 3, wantReplay: []uint64{4, 5}, wantComplete: true},
+		{name: "resume right before the buffer", lastEventID: 2, wantReplay: []uint64{3, 4, 5}, wantComplete: true},
+		{name: "resume after missed events", lastEventID: 1, wantReplay: []uint64{3, 4, 5}, wantComplete: false},
+		{name: "up to date", lastEventID: 5, wantReplay: []uint64{}, wantComplete: true},
+		{name: "unknown future event", lastEventID: 9, wantReplay: []uint64{}, wantComplete: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, replay, complete := bus.Subscribe(tt.lastEventID)
+			defer sub.Close()
+
+			assert.Equal(t, tt.wantReplay, eventIDs(replay))
+			assert.Equal(t, tt.wantComplete, complete)
+		})
+	}
+}
+
+func TestBus_Deliver(t *testing.T) {
+	bus := NewBus(3)
+	sub, _, _ := bus.Subscribe(0)
+	defer sub.Close()
+
+	// the events of another publisher keep their ids, the events delivered again are ignored
+	for _, id := range []uint64{7, 8, 8, 6} {
+		bus.Deliver(models.TaskEvent{ID: id, Type: models.TaskUpdated, Task: models.Task{ID: "task1"}})
+	}
+	assert.Equal(t, uint64(8), bus.LastEventID())
+	assert.Equal(t, uint64(7), (<-sub.Events()).ID)
+	assert.Equal(t, uint64(8), (<-sub.Events()).ID)
+	assert.Empty(t, sub.Events())
+
+	resumed, replay, complete := bus.Subscribe(7)
+	defer resumed.Close()
+	assert.Equal(t, []uint64{8}, eventIDs(replay))
+	assert.True(t, complete)
+
+	missed, replay, complete := bus.Subscribe(5)
+	defer missed.Close()
+	assert.Equal(t, []uint64{7, 8}, eventIDs(replay))
+	assert.False(t, complete)
+}
+
+func TestBus_DropSlowSubscriber(t *testing.T) {
+	bus := NewBus(DefaultBufferSize)
+	sub, _, _ := bus.Subscribe(0)
+
+	for i := 0; i <= subscriberBufferSize; i++ {
+		bus.Publish(models.TaskCreated, models.Task{})
+	}
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received)
+
+	// closing a dropped subscription is a no-op
+	sub.Close()
+	assert.Empty(t, bus.subscribers)
+}
@@ -0,0 +1,157 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// readBlock is how long a read of the stream waits for new events, before checking whether the relay is stopped
+const readBlock = time.Second
+
+// retryDelay is the wait before reading the stream again after a failed read
+const retryDelay = time.Second
+
+// publishScript numbers the event with the next id and appends it to the stream, it returns the id.
+// KEYS: stream, last event id.
+// ARGV: approximate length of the stream, JSON event.
+var publishScript = redis.NewScript(`
+local id = redis.call('INCR', KEYS[2])
+redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'id', id, 'event', ARGV[2])
+
+return id
+`)
+
+// RedisStream shares the task events of the replicas of the server through a Redis stream.
+// The events are numbered by Redis, so that an event has the same id on every replica, and every replica relays
+// the events of the stream to its own bus, see Start. The keys have the given prefix, which should be the hash tag
+// of the keys of the tasks.
+type RedisStream struct {
+	client redis.UniversalClient
+	stream string
+	seq    string
+	length int
+	now    func() time.Time
+	wg     sync.WaitGroup
+}
+
+// NewRedisStream creates a stream of the events keeping about length events for replaying.
+// A non-positive length falls back to DefaultBufferSize.
+func NewRedisStream(client redis.UniversalClient, prefix string, length int) *RedisStream {
+	if length <= 0 {
+		length = DefaultBufferSize
+	}
+
+	return &RedisStream{
+		client: client,
+		stream: prefix + ":events",
+		seq:    prefix + ":events:seq",
+		length: length,
+		now:    time.Now,
+	}
+}
+
+// Publish appends a task event to the stream with the next event id, and returns it. The event is delivered
+// to the subscribers of the buses relaying the stream. An event that cannot be appended is logged and returned
+// without id, the change of the task has already been written.
+func (s *RedisStream) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	event := models.TaskEvent{Type: eventType, Task: task, Time: s.now().UTC()}
+	data, err := json.Marshal(&event)
+	if err != nil {
+		log.Println("encode task event failed", err)
+		return event
+	}
+
+	id, err := publishScript.Run(context.Background(), s.client, []string{s.stream, s.seq}, s.length, data).Uint64()
+	if err != nil {
+		log.Println("publish task event failed", err)
+		return event
+	}
+	event.ID = id
+
+	return event
+}
+
+// Start delivers the events of the stream to the bus until the context is done. The events kept by the stream
+// are delivered before it returns, so that the subscribers of the bus can resume from the events published
+// by the other replicas, then the new events are delivered in the background.
+func (s *RedisStream) Start(ctx context.Context, bus *Bus) error {
+	// the newest events, oldest first
+	messages, err := s.client.XRevRangeN(ctx, s.stream, "+", "-", int64(s.length)).Result()
+	if err != nil {
+		return err
+	}
+
+	lastID := "0"
+	for i := len(messages) - 1; i >= 0; i-- {
+		s.deliver(bus, messages[i])
+		lastID = messages[i].ID
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		for ctx.Err() == nil {
+			streams, err := s.client.XRead(ctx, &redis.XReadArgs{
+				Streams: []string{s.stream, lastID},
+				Block:   readBlock,
+			}).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Println("read task events failed", err)
+				}
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(retryDelay):
+				}
+				continue
+			}
+
+			for _, stream := range streams {
+				for _, message := range stream.Messages {
+					s.deliver(bus, message)
+					lastID = message.ID
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Wait waits until the relay started by Start is stopped.
+func (s *RedisStream) Wait() {
+	s.wg.Wait()
+}
+
+// deliver delivers the event of a message of the stream to the bus.
+func (s *RedisStream) deliver(bus *Bus, message redis.XMessage) {
+	id, _ := message.Values["id"].(string)
+	data, _ := message.Values["event"].(string)
+
+	var event models.TaskEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		log.Println("decode task event failed", message.ID, err)
+		return
+	}
+
+	var err error
+	if event.ID, err = strconv.ParseUint(id, 10, 64); err != nil {
+		log.Println("decode task event failed", message.ID, err)
+		return
+	}
+
+	bus.Deliver(event)
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// receive returns the next event of the subscription, or fails the test after a second.
+func receive(t *testing.T, sub *Subscription) models.TaskEvent {
+	select {
+	case event := <-sub.Events():
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return models.TaskEvent{}
+	}
+}
+
+func TestRedisStream(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// two replicas relay the events of the stream to their own bus
+	replicas := []*RedisStream{NewRedisStream(client, "{tasks}", 3), NewRedisStream(client, "{tasks}", 3)}
+	buses := []*Bus{NewBus(3), NewBus(3)}
+	subs := make([]*Subscription, len(buses))
+	for i, replica := range replicas {
+		assert.NoError(t, replica.Start(ctx, buses[i]))
+		subs[i], _, _ = buses[i].Subscribe(0)
+		defer subs[i].Close()
+	}
+
+	task := models.Task{ID: "task1", Name: "Task 1"}
+	created := replicas[0].Publish(models.TaskCreated, task)
+	assert.Equal(t, uint64(1), created.ID)
+	updated := replicas[1].Publish(models.TaskUpdated, task)
+	assert.Equal(t, uint64(2), updated.ID)
+
+	// every replica receives every event with the same id
+	for _, sub := range subs {
+		assert.Equal(t, created, receive(t, sub))
+		assert.Equal(t, updated, receive(t, sub))
+	}
+
+	// a replica started later replays the events kept by the stream
+	for i := 0; i < 3; i++ {
+		replicas[0].Publish(models.TaskUpdated, task)
+	}
+	late := NewBus(3)
+	assert.NoError(t, NewRedisStream(client, "{tasks}", 3).Start(ctx, late))
+	assert.Equal(t, uint64(5), late.LastEventID())
+
+	sub, replay, complete := late.Subscribe(3)
+	defer sub.Close()
+	assert.Equal(t, []uint64{4, 5}, eventIDs(replay))
+	assert.True(t, complete)
+
+	// the relays stop with the context
+	cancel()
+	for _, replica := range replicas {
+		replica.Wait()
+	}
+}
@@ -0,0 +1,247 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/problem"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the client generated idempotency key
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed is the response header set when a response is replayed from the store
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	// DefaultTTL is the default window a response is kept for replaying
+	DefaultTTL = 24 * time.Hour
+	// maxKeyLength is the maximum length of an idempotency key
+	maxKeyLength = 255
+)
+
+var (
+	// ErrKeyInProgress represents an error when a request with the same key is still being processed
+	ErrKeyInProgress = errors.New("a request with the same idempotency key is in progress")
+	// ErrKeyReused represents an error when the same key is reused with a different request
+	ErrKeyReused = errors.New("idempotency key was already used with a different request")
+	// ErrKeyTooLong represents an error when the idempotency key is too long
+	ErrKeyTooLong = errors.New("idempotency key is too long")
+)
+
+// Response represents a response recorded for an idempotency key.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type entry struct {
+	fingerprint [sha256.Size]byte
+	response    *Response // nil while the first request is in progress
+	expiresAt   time.Time
+}
+
+// Keeper keeps the first response of every idempotency key for replaying, see Store and RedisStore.
+type Keeper interface {
+	// Begin reserves the key for a request with the given fingerprint.
+	// It returns the recorded response when the key was already completed with the same fingerprint,
+	// or nil when the caller should process the request and then call Complete or Abort.
+	Begin(ctx context.Context, key string, fingerprint [sha256.Size]byte) (*Response, error)
+	// Complete records the response of the request holding the key.
+	Complete(ctx context.Context, key string, response *Response) error
+	// Abort releases the key so that the request can be retried.
+	Abort(ctx context.Context, key string) error
+}
+
+// Store keeps the first response of every idempotency key for a configurable window in memory.
+type Store struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewStore creates a new idempotency store keeping responses for the given window.
+// A non-positive ttl falls back to DefaultTTL.
+func NewStore(ttl time.Duration) *Store {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &Store{
+		ttl:     ttl,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+}
+
+// Begin reserves the key for a request with the given fingerprint.
+// It returns the recorded response when the key was already completed with the same fingerprint,
+// or nil when the caller should process the request and then call Complete or Abort.
+func (s *Store) Begin(_ context.Context, key string, fingerprint [sha256.Size]byte) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if e, exists := s.entries[key]; exists && now.Before(e.expiresAt) {
+		if e.fingerprint != fingerprint {
+			return nil, ErrKeyReused
+		}
+
+		if e.response == nil {
+			return nil, ErrKeyInProgress
+		}
+
+		return e.response, nil
+	}
+
+	s.entries[key] = &entry{fingerprint: fingerprint, expiresAt: now.Add(s.ttl)}
+
+	return nil, nil
+}
+
+// Complete records the response of the request holding the key.
+func (s *Store) Complete(_ context.Context, key string, response *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, exists := s.entries[key]; exists {
+		e.response = response
+		e.expiresAt = s.now().Add(s.ttl)
+	}
+
+	return nil
+}
+
+// Abort releases the key so that the request can be retried.
+func (s *Store) Abort(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// sweep removes the expired entries, at most once per window.
+func (s *Store) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+
+	for key, e := range s.entries {
+		if !now.Before(e.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+
+	s.lastSweep = now
+}
+
+// Middleware honors the Idempotency-Key header: the first response for a key is recorded and replayed
+// for retries with the same request body. Requests without the header are passed through unchanged.
+// Server errors are not recorded so that the client can retry them.
+func Middleware(store Keeper) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+
+			if len(key) > maxKeyLength {
+				return problem.Respond(c, http.StatusBadRequest, ErrKeyTooLong)
+			}
+
+			body, err := io.ReadAll(c.Request().Body)
+			if err != nil {
+				return problem.Respond(c, http.StatusBadRequest, err)
+			}
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := c.Request().Context()
+			scopedKey := c.Request().Method + " " + c.Path() + " " + key
+			recorded, err := store.Begin(ctx, scopedKey, sha256.Sum256(body))
+			switch {
+			case errors.Is(err, ErrKeyReused):
+				return problem.Respond(c, http.StatusUnprocessableEntity, err)
+			case errors.Is(err, ErrKeyInProgress):
+				return problem.Respond(c, http.StatusConflict, err)
+			case err != nil:
+				return problem.Respond(c, http.StatusInternalServerError, err)
+			case recorded != nil:
+				return replay(c, recorded)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+
+			// the key is released or completed even when the client is gone
+			ctx = context.WithoutCancel(ctx)
+			if err := next(c); err != nil {
+				abort(ctx, store, scopedKey)
+				return err
+			}
+
+			status := c.Response().Status
+			if status >= http.StatusInternalServerError {
+				abort(ctx, store, scopedKey)
+				return nil
+			}
+
+			if err := store.Complete(ctx, scopedKey, &Response{
+				StatusCode: status,
+				Header:     c.Response().Header().Clone(),
+				Body:       recorder.body.Bytes(),
+			}); err != nil {
+				// the response is sent, a retry is processed again once the key expires
+				log.Println("record idempotent response failed", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// abort releases the key, a key that cannot be released is kept until it expires.
+func abort(ctx context.Context, store Keeper, key string) {
+	if err := store.Abort(ctx, key); err != nil {
+		log.Println("release idempotency key failed", err)
+	}
+}
+
+// replay writes a recorded response to the client.
+func replay(c echo.Context, recorded *Response) error {
+	for name, values := range recorded.Header {
+		c.Response().Header()[name] = values
+	}
+	c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	c.Response().WriteHeader(recorded.StatusCode)
+
+	_, err := c.Response().Write(recorded.Body)
+
+	return err
+}
+
+// responseRecorder copies the response body written by the handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,156 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Begin(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	fingerprint := sha256.Sum256([]byte("body"))
+	otherFingerprint := sha256.Sum256([]byte("other body"))
+	response := &Response{StatusCode: http.StatusCreated}
+
+	recorded, err := store.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+
+	_, err = store.Begin(ctx, "key", fingerprint)
+	assert.ErrorIs(t, err, ErrKeyInProgress)
+
+	assert.NoError(t, store.Complete(ctx, "key", response))
+
+	recorded, err = store.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Equal(t, response, recorded)
+
+	_, err = store.Begin(ctx, "key", otherFingerprint)
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	now = now.Add(2 * time.Minute)
+
+	recorded, err = store.Begin(ctx, "key", otherFingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+
+	assert.NoError(t, store.Abort(ctx, "key"))
+
+	recorded, err = store.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+}
+
+func TestMiddleware(t *testing.T) {
+	calls := 0
+	statusCode := http.StatusCreated
+
+	e := echo.New()
+	e.POST("/tasks", func(c echo.Context) error {
+		calls++
+		return c.JSON(statusCode, map[string]int{"call": calls})
+	}, Middleware(NewStore(time.Minute)))
+
+	type args struct {
+		key  string
+		body string
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		args               args
+		expectedStatusCode int
+		expectedCalls      int
+		expectedReplayed   bool
+	}{
+		{
+			name:               "request without key",
+			args:               args{body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusCreated,
+			expectedCalls:      1,
+		},
+		{
+			name:               "first request with key",
+			args:               args{key: "key-1", body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusCreated,
+			expectedCalls:      2,
+		},
+		{
+			name:               "retry with the same key and body",
+			args:               args{key: "key-1", body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusCreated,
+			expectedCalls:      2,
+			expectedReplayed:   true,
+		},
+		{
+			name:               "retry with the same key and a different body",
+			args:               args{key: "key-1", body: `{"tasks":[{"name":"Task 1"}]}`},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedCalls:      2,
+		},
+		{
+			name:               "key too long",
+			args:               args{key: string(bytes.Repeat([]byte("k"), maxKeyLength+1)), body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCalls:      2,
+		},
+		{
+			name: "server error is not recorded",
+			mockSetup: func() {
+				statusCode = http.StatusInternalServerError
+			},
+			args:               args{key: "key-2", body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCalls:      3,
+		},
+		{
+			name: "retry after server error",
+			mockSetup: func() {
+				statusCode = http.StatusCreated
+			},
+			args:               args{key: "key-2", body: `{"tasks":[]}`},
+			expectedStatusCode: http.StatusCreated,
+			expectedCalls:      4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(tt.args.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.args.key != "" {
+				req.Header.Set(HeaderIdempotencyKey, tt.args.key)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedReplayed, rec.Header().Get(HeaderIdempotentReplayed) == "true")
+
+			if rec.Code >= http.StatusBadRequest && rec.Code < http.StatusInternalServerError {
+				var resp models.ErrorResponse
+				err := json.Unmarshal(rec.Body.Bytes(), &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, rec.Code, resp.Code)
+			}
+		})
+	}
+}
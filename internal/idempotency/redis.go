@@ -0,0 +1,112 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// record represents the content of the key of an idempotency key in Redis.
+type record struct {
+	Fingerprint [sha256.Size]byte `json:"fingerprint"`
+	Response    *Response         `json:"response,omitempty"` // nil while the first request is in progress
+}
+
+// RedisStore keeps the first response of every idempotency key for a configurable window in Redis,
+// shared by the replicas of the server. Every idempotency key is kept in the key <prefix>:idempotency:<key>,
+// which expires with the window.
+type RedisStore struct {
+	client redis.UniversalClient
+	prefix string
+	ttl    time.Duration
+}
+
+var _ Keeper = (*RedisStore)(nil)
+
+// NewRedisStore creates a new idempotency store keeping responses in Redis for the given window.
+// A non-positive ttl falls back to DefaultTTL.
+func NewRedisStore(client redis.UniversalClient, prefix string, ttl time.Duration) *RedisStore {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	return &RedisStore{client: client, prefix: prefix + ":idempotency:", ttl: ttl}
+}
+
+// Begin reserves the key for a request with the given fingerprint.
+// It returns the recorded response when the key was already completed with the same fingerprint,
+// or nil when the caller should process the request and then call Complete or Abort.
+func (s *RedisStore) Begin(ctx context.Context, key string, fingerprint [sha256.Size]byte) (*Response, error) {
+	data, err := json.Marshal(record{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		reserved, err := s.client.SetNX(ctx, s.prefix+key, data, s.ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		if reserved {
+			return nil, nil
+		}
+
+		recorded, err := s.client.Get(ctx, s.prefix+key).Bytes()
+		if errors.Is(err, redis.Nil) {
+			// expired or aborted in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var r record
+		if err := json.Unmarshal(recorded, &r); err != nil {
+			return nil, err
+		}
+
+		if r.Fingerprint != fingerprint {
+			return nil, ErrKeyReused
+		}
+
+		if r.Response == nil {
+			return nil, ErrKeyInProgress
+		}
+
+		return r.Response, nil
+	}
+}
+
+// Complete records the response of the request holding the key.
+func (s *RedisStore) Complete(ctx context.Context, key string, response *Response) error {
+	recorded, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var r record
+	if err := json.Unmarshal(recorded, &r); err != nil {
+		return err
+	}
+	r.Response = response
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return s.client.SetXX(ctx, s.prefix+key, data, s.ttl).Err()
+}
+
+// Abort releases the key so that the request can be retried.
+func (s *RedisStore) Abort(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisStore_Begin(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// the replicas share the keys
+	store, replica := NewRedisStore(client, "{tasks}", time.Minute), NewRedisStore(client, "{tasks}", time.Minute)
+
+	fingerprint := sha256.Sum256([]byte("body"))
+	otherFingerprint := sha256.Sum256([]byte("other body"))
+	response := &Response{StatusCode: http.StatusCreated, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{}`)}
+
+	recorded, err := store.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+
+	_, err = replica.Begin(ctx, "key", fingerprint)
+	assert.ErrorIs(t, err, ErrKeyInProgress)
+
+	assert.NoError(t, store.Complete(ctx, "key", response))
+
+	recorded, err = replica.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Equal(t, response, recorded)
+
+	_, err = replica.Begin(ctx, "key", otherFingerprint)
+	assert.ErrorIs(t, err, ErrKeyReused)
+
+	server.FastForward(2 * time.Minute)
+
+	recorded, err = replica.Begin(ctx, "key", otherFingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+
+	assert.NoError(t, replica.Abort(ctx, "key"))
+
+	recorded, err = store.Begin(ctx, "key", fingerprint)
+	assert.NoError(t, err)
+	assert.Nil(t, recorded)
+
+	// a key released in the meantime is not completed
+	assert.NoError(t, store.Abort(ctx, "key"))
+	assert.NoError(t, store.Complete(ctx, "key", response))
+	assert.False(t, server.Exists("{tasks}:idempotency:key"))
+}
+
+func TestMiddleware_Redis(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// a retry sent to another replica is replayed
+	calls := 0
+	replicas := make([]*echo.Echo, 2)
+	for i := range replicas {
+		replicas[i] = echo.New()
+		replicas[i].POST("/tasks", func(c echo.Context) error {
+			calls++
+			return c.JSON(http.StatusCreated, map[string]int{"call": calls})
+		}, Middleware(NewRedisStore(client, "{tasks}", time.Minute)))
+	}
+
+	var bodies []string
+	for _, e := range replicas {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBufferString(`{"tasks":[]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(HeaderIdempotencyKey, "key-1")
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		bodies = append(bodies, rec.Body.String())
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, bodies[0], bodies[1])
+}
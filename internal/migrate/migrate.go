@@ -0,0 +1,211 @@
+// Package migrate copies the tasks of a task manager to another one, such as from a storage to another kind of storage.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/brionac626/taskManager/internal/backup"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+)
+
+// DefaultBatchSize is the default number of tasks copied at once
+const DefaultBatchSize = 500
+
+// ErrVerificationFailed represents an error when the tasks of the target differ from the tasks of the source
+var ErrVerificationFailed = errors.New("migration verification failed")
+
+// Report represents the result of a migration.
+type Report struct {
+	Total   int // number of tasks of the source
+	Copied  int // tasks missing from the target, restored with their id
+	Updated int // tasks of the target whose name or status differed from the source
+	Skipped int // tasks already in the target, copied by an interrupted migration
+}
+
+// Progress is called after every batch with the number of tasks migrated so far.
+type Progress func(done int)
+
+// options represents the optional settings of a migration.
+type options struct {
+	batchSize int
+	progress  Progress
+}
+
+// Option configures a migration.
+type Option func(*options)
+
+// WithBatchSize copies the tasks by batches of the size, a non-positive size falls back to DefaultBatchSize.
+func WithBatchSize(size int) Option {
+	return func(o *options) {
+		o.batchSize = size
+	}
+}
+
+// WithProgress reports the progress of the migration after every batch.
+func WithProgress(progress Progress) Option {
+	return func(o *options) {
+		o.progress = progress
+	}
+}
+
+// newOptions returns the options of a migration.
+func newOptions(opts []Option) options {
+	o := options{batchSize: DefaultBatchSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = DefaultBatchSize
+	}
+
+	return o
+}
+
+// Migrate copies every task of the source to the target with its id, by batches. The tasks are read page by page
+// in the order of their ids from both task managers, so that neither has to be loaded at once. The tasks already
+// in the target are skipped, or updated when they differ, so that an interrupted migration resumes where it
+// stopped when run again. The tasks of the target missing from the source are kept, Verify reports them.
+func Migrate(ctx context.Context, source, target repository.TaskManager, opts ...Option) (Report, error) {
+	o := newOptions(opts)
+
+	var report Report
+	after := ""
+	for {
+		batch, err := source.ListTasks(ctx, after, o.batchSize)
+		if err != nil {
+			return report, fmt.Errorf("get the tasks of the source: %w", err)
+		}
+		if len(batch) == 0 {
+			return report, nil
+		}
+
+		migrated, err := migratedTasks(ctx, target, after, batch)
+		if err != nil {
+			return report, fmt.Errorf("get the tasks of the target: %w", err)
+		}
+
+		missing := make([]models.Task, 0, len(batch))
+		for _, task := range batch {
+			current, found := migrated[task.ID]
+			switch {
+			case !found:
+				missing = append(missing, task)
+			case current != task:
+				if err := target.UpdateTask(ctx, task.ID, &task.Name, &task.Status); err != nil {
+					return report, fmt.Errorf("update task %s: %w", task.ID, err)
+				}
+				report.Updated++
+			default:
+				report.Skipped++
+			}
+		}
+
+		if len(missing) != 0 {
+			if err := target.RestoreTasks(ctx, missing); err != nil {
+				return report, fmt.Errorf("restore tasks: %w", err)
+			}
+			report.Copied += len(missing)
+		}
+
+		report.Total += len(batch)
+		if o.progress != nil {
+			o.progress(report.Total)
+		}
+
+		if len(batch) < o.batchSize {
+			return report, nil
+		}
+		after = batch[len(batch)-1].ID
+	}
+}
+
+// migratedTasks returns the tasks of the target with the ids of the batch of the source, which follows the task id after.
+// The page of the target following the same id holds them unless the target has tasks missing from the source,
+// the tasks of the batch beyond the page are then read one by one.
+func migratedTasks(ctx context.Context, target repository.TaskManager, after string, batch []models.Task) (map[string]models.Task, error) {
+	page, err := target.ListTasks(ctx, after, len(batch))
+	if err != nil {
+		return nil, err
+	}
+
+	migrated := make(map[string]models.Task, len(page))
+	for _, task := range page {
+		migrated[task.ID] = task
+	}
+
+	if len(page) < len(batch) {
+		// the page holds every following task of the target
+		return migrated, nil
+	}
+
+	last := page[len(page)-1].ID
+	for _, task := range batch {
+		if task.ID <= last {
+			continue
+		}
+
+		current, err := target.GetTask(ctx, task.ID)
+		if errors.Is(err, repository.ErrTaskNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		migrated[task.ID] = current
+	}
+
+	return migrated, nil
+}
+
+// Verify compares the tasks of the source and the target, which must have the same number of tasks
+// and the same checksum. The tasks are read page by page, of the size of the batches of the options.
+func Verify(ctx context.Context, source, target repository.TaskManager, opts ...Option) error {
+	o := newOptions(opts)
+
+	sourceCount, sourceChecksum, err := summarize(ctx, source, o.batchSize)
+	if err != nil {
+		return fmt.Errorf("get the tasks of the source: %w", err)
+	}
+
+	targetCount, targetChecksum, err := summarize(ctx, target, o.batchSize)
+	if err != nil {
+		return fmt.Errorf("get the tasks of the target: %w", err)
+	}
+
+	if sourceCount != targetCount {
+		return fmt.Errorf("%w: the source has %d tasks, the target %d", ErrVerificationFailed, sourceCount, targetCount)
+	}
+
+	if sourceChecksum != targetChecksum {
+		return fmt.Errorf("%w: checksum %s of the source, %s of the target", ErrVerificationFailed, sourceChecksum, targetChecksum)
+	}
+
+	return nil
+}
+
+// summarize returns the number of tasks of the task manager and their checksum in the order of their ids,
+// reading the tasks by pages of the size.
+func summarize(ctx context.Context, repo repository.TaskManager, size int) (int, string, error) {
+	checksum := backup.NewChecksum()
+	after := ""
+	for {
+		page, err := repo.ListTasks(ctx, after, size)
+		if err != nil {
+			return 0, "", err
+		}
+
+		for _, task := range page {
+			if err := checksum.Add(task); err != nil {
+				return 0, "", err
+			}
+		}
+
+		if len(page) < size {
+			return checksum.Count(), checksum.Sum(), nil
+		}
+		after = page[len(page)-1].ID
+	}
+}
@@ -0,0 +1,101 @@
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/eventsourced"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := eventsourced.Open(t.TempDir())
+	assert.NoError(t, err)
+	defer source.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2", Status: 1}, {Name: "Task 3"}, {Name: "Task 4"}, {Name: "Task 5", Status: 1}}
+	assert.NoError(t, source.CreateTasks(ctx, tasks))
+
+	target := repository.NewRepository()
+
+	// the migration is interrupted after its first batch
+	interruptedCtx, interrupt := context.WithCancel(ctx)
+	var progress []int
+	report, err := Migrate(interruptedCtx, source, target, WithBatchSize(2), WithProgress(func(done int) {
+		progress = append(progress, done)
+		interrupt()
+	}))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, Report{Total: 2, Copied: 2}, report)
+	assert.Equal(t, []int{2}, progress)
+	assert.ErrorIs(t, Verify(ctx, source, target), ErrVerificationFailed)
+
+	// running it again resumes after the tasks already copied
+	progress = nil
+	report, err = Migrate(ctx, source, target, WithBatchSize(2), WithProgress(func(done int) {
+		progress = append(progress, done)
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, Report{Total: 5, Copied: 3, Skipped: 2}, report)
+	assert.Equal(t, []int{2, 4, 5}, progress)
+	assert.NoError(t, Verify(ctx, source, target, WithBatchSize(2)))
+
+	got, err := target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+
+	// the tasks changed in the source since are updated
+	name := "Task 1 renamed"
+	assert.NoError(t, source.UpdateTask(ctx, tasks[0].ID, &name, nil))
+	assert.ErrorIs(t, Verify(ctx, source, target), ErrVerificationFailed)
+
+	report, err = Migrate(ctx, source, target)
+	assert.NoError(t, err)
+	assert.Equal(t, Report{Total: 5, Updated: 1, Skipped: 4}, report)
+	assert.NoError(t, Verify(ctx, source, target))
+
+	// the tasks only in the target fail the verification
+	assert.NoError(t, target.CreateTasks(ctx, []models.Task{{Name: "Task 6"}}))
+	assert.ErrorIs(t, Verify(ctx, source, target), ErrVerificationFailed)
+}
+
+func TestMigrate_TargetTasks(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := eventsourced.Open(t.TempDir())
+	assert.NoError(t, err)
+	defer source.Close()
+
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"},
+		{ID: "9bsv0s2hf8ng030mvab0", Name: "Task 3"},
+		{ID: "9bsv0s2hf8ng030mvad0", Name: "Task 5"},
+		{ID: "9bsv0s2hf8ng030mvaf0", Name: "Task 7"},
+	}
+	assert.NoError(t, source.RestoreTasks(ctx, tasks))
+
+	// the tasks only in the target come between the tasks of the source, the pages of both no longer match
+	target, err := eventsourced.Open(t.TempDir())
+	assert.NoError(t, err)
+	defer target.Close()
+
+	assert.NoError(t, target.RestoreTasks(ctx, []models.Task{
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2"},
+		{ID: "9bsv0s2hf8ng030mvac0", Name: "Task 4"},
+		{ID: "9bsv0s2hf8ng030mvad0", Name: "Task 5 renamed"},
+		{ID: "9bsv0s2hf8ng030mvae0", Name: "Task 6"},
+	}))
+
+	report, err := Migrate(ctx, source, target, WithBatchSize(2))
+	assert.NoError(t, err)
+	assert.Equal(t, Report{Total: 4, Copied: 3, Updated: 1}, report)
+
+	got, err := target.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 7)
+	assert.ErrorIs(t, Verify(ctx, source, target, WithBatchSize(2)), ErrVerificationFailed)
+}
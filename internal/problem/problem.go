@@ -0,0 +1,208 @@
+package problem
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// MIMEApplicationProblemJSON is the media type of an RFC 7807 problem details document
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+const (
+	// TypeAboutBlank is the problem type of errors without additional semantics beyond the status code
+	TypeAboutBlank = "about:blank"
+	// TypeValidationError is the problem type of requests with invalid field values
+	TypeValidationError = "urn:task-manager:problem:validation-error"
+	// TypeInvalidRequestBody is the problem type of requests whose body cannot be decoded
+	TypeInvalidRequestBody = "urn:task-manager:problem:invalid-request-body"
+)
+
+// Respond writes err as an error response with the given status code.
+// Clients accepting application/problem+json get RFC 7807 problem details,
+// while the other clients get the models.ErrorResponse shape.
+// The errors of the server errors are logged instead of being sent to the client.
+func Respond(c echo.Context, code int, err error) error {
+	if code >= http.StatusInternalServerError {
+		c.Logger().Error(err)
+	}
+
+	if !Accepts(c.Request()) {
+		return c.JSON(
+			code,
+			&models.ErrorResponse{
+				Code:    code,
+				Message: Message(code, err),
+			},
+		)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, MIMEApplicationProblemJSON)
+
+	return c.JSON(code, New(c.Request(), code, err))
+}
+
+// New builds the problem details of err for the request.
+func New(req *http.Request, code int, err error) *models.ProblemDetails {
+	details := &models.ProblemDetails{
+		Type:     TypeAboutBlank,
+		Title:    http.StatusText(code),
+		Status:   code,
+		Detail:   detail(code, err),
+		Instance: req.URL.Path,
+		Errors:   Violations(err),
+	}
+
+	var httpErr *echo.HTTPError
+	switch {
+	case len(details.Errors) != 0:
+		details.Type = TypeValidationError
+		details.Title = "Your request parameters didn't validate"
+	case code == http.StatusBadRequest && errors.As(err, &httpErr):
+		details.Type = TypeInvalidRequestBody
+	}
+
+	return details
+}
+
+// Message returns the message of err sent to the client on a single line. The errors of the server errors,
+// such as the errors of the storages, are replaced by the status text of the code.
+func Message(code int, err error) string {
+	if code >= http.StatusInternalServerError {
+		return http.StatusText(code)
+	}
+
+	return strings.ReplaceAll(err.Error(), "\n", "; ")
+}
+
+// detail returns a human readable explanation of err.
+// Errors raised while decoding a request are replaced by a generic explanation
+// instead of leaking the raw decoder message, and the errors of the server errors by the status text.
+func detail(code int, err error) string {
+	if code >= http.StatusInternalServerError {
+		return http.StatusText(code)
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		if code == http.StatusBadRequest {
+			return "The request body could not be decoded."
+		}
+
+		if message, ok := httpErr.Message.(string); ok {
+			return message
+		}
+
+		return http.StatusText(code)
+	}
+
+	violations := Violations(err)
+	if len(violations) == 1 {
+		return violations[0].Message
+	}
+
+	if len(violations) > 1 {
+		return strconv.Itoa(len(violations)) + " fields are invalid."
+	}
+
+	return err.Error()
+}
+
+// Violations collects the field level violations from err.
+// Nested field errors are reported with dotted paths, e.g. tasks[0].name.
+func Violations(err error) []models.FieldViolation {
+	return collect(err, "")
+}
+
+func collect(err error, prefix string) []models.FieldViolation {
+	switch e := err.(type) {
+	case nil:
+		return nil
+	case *models.FieldError:
+		field := e.Field
+		if prefix != "" {
+			field = joinField(prefix, e.Field)
+		}
+
+		if nested := collect(e.Err, field); len(nested) != 0 {
+			return nested
+		}
+
+		violation := models.FieldViolation{Field: field, Message: e.Err.Error()}
+
+		var positioned interface{ Position() int }
+		if errors.As(e.Err, &positioned) {
+			violation.Position = positioned.Position()
+		}
+
+		return []models.FieldViolation{violation}
+	case interface{ Unwrap() []error }:
+		var violations []models.FieldViolation
+		for _, err := range e.Unwrap() {
+			violations = append(violations, collect(err, prefix)...)
+		}
+
+		return violations
+	case interface{ Unwrap() error }:
+		return collect(e.Unwrap(), prefix)
+	}
+
+	return nil
+}
+
+// joinField joins a parent field path with a child field, keeping index suffixes attached.
+func joinField(parent, child string) string {
+	if strings.HasPrefix(child, "[") {
+		return parent + child
+	}
+
+	return parent + "." + child
+}
+
+// Accepts reports whether the client prefers problem details, i.e. application/problem+json
+// is listed in the Accept header with a non-zero quality.
+func Accepts(req *http.Request) bool {
+	for _, accept := range req.Header.Values(echo.HeaderAccept) {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || mediaType != MIMEApplicationProblemJSON {
+				continue
+			}
+
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPErrorHandler returns an echo.HTTPErrorHandler serving problem details to the clients that accept them,
+// and falling back to the given handler for the other clients.
+func HTTPErrorHandler(fallback echo.HTTPErrorHandler) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed || !Accepts(c.Request()) {
+			fallback(err, c)
+			return
+		}
+
+		code := http.StatusInternalServerError
+		var httpErr *echo.HTTPError
+		if errors.As(err, &httpErr) {
+			code = httpErr.Code
+		}
+
+		if err := Respond(c, code, err); err != nil {
+			c.Logger().Error(err)
+		}
+	}
+}
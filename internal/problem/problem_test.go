@@ -0,0 +1,213 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   bool
+	}{
+		{name: "no accept header", accept: "", want: false},
+		{name: "json only", accept: echo.MIMEApplicationJSON, want: false},
+		{name: "problem json", accept: MIMEApplicationProblemJSON, want: true},
+		{name: "problem json in a list", accept: "application/json;q=0.9, application/problem+json", want: true},
+		{name: "problem json not acceptable", accept: "application/problem+json;q=0", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/tasks", nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+
+			assert.Equal(t, tt.want, Accepts(req))
+		})
+	}
+}
+
+func TestViolations(t *testing.T) {
+	req := models.CreateNewTasksRequest{
+		Tasks: []models.NewTask{
+			{Name: "Task 1", Status: 0},
+			{Name: "", Status: 3},
+		},
+	}
+	_, queryErr := query.Parse("open AND tag:infra")
+
+	tests := []struct {
+		name string
+		err  error
+		want []models.FieldViolation
+	}{
+		{
+			name: "no violations",
+			err:  errors.New("task not found"),
+			want: nil,
+		},
+		{
+			name: "nested violations",
+			err:  req.Validate(),
+			want: []models.FieldViolation{
+				{Field: "tasks[1].name", Message: models.ErrTaskNameEmpty.Error()},
+				{Field: "tasks[1].status", Message: models.ErrInvalidStatus.Error()},
+			},
+		},
+		{
+			name: "filter violation",
+			err:  (&models.BatchTarget{Filter: &models.TaskFilter{Status: &req.Tasks[1].Status}}).Validate(),
+			want: []models.FieldViolation{
+				{Field: "filter.status", Message: models.ErrInvalidStatus.Error()},
+			},
+		},
+		{
+			name: "violation with position",
+			err:  &models.FieldError{Field: "q", Err: queryErr},
+			want: []models.FieldViolation{
+				{Field: "q", Message: queryErr.Error(), Position: 10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Violations(tt.err))
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	validationErr := (&models.NewTask{Name: "", Status: 0}).Validate()
+	bindErr := echo.NewHTTPError(http.StatusBadRequest, "Syntax error: offset=1, error=invalid character 'i' looking for beginning of value")
+
+	tests := []struct {
+		name            string
+		accept          string
+		code            int
+		err             error
+		wantContentType string
+		wantResponse    any
+	}{
+		{
+			name:            "legacy error response",
+			code:            http.StatusBadRequest,
+			err:             validationErr,
+			wantContentType: echo.MIMEApplicationJSON,
+			wantResponse: &models.ErrorResponse{
+				Code:    http.StatusBadRequest,
+				Message: models.ErrTaskNameEmpty.Error(),
+			},
+		},
+		{
+			name:            "problem details with field violations",
+			accept:          MIMEApplicationProblemJSON,
+			code:            http.StatusBadRequest,
+			err:             validationErr,
+			wantContentType: MIMEApplicationProblemJSON,
+			wantResponse: &models.ProblemDetails{
+				Type:     TypeValidationError,
+				Title:    "Your request parameters didn't validate",
+				Status:   http.StatusBadRequest,
+				Detail:   models.ErrTaskNameEmpty.Error(),
+				Instance: "/tasks",
+				Errors:   []models.FieldViolation{{Field: "name", Message: models.ErrTaskNameEmpty.Error()}},
+			},
+		},
+		{
+			name:            "problem details hiding the decoder error",
+			accept:          MIMEApplicationProblemJSON,
+			code:            http.StatusBadRequest,
+			err:             bindErr,
+			wantContentType: MIMEApplicationProblemJSON,
+			wantResponse: &models.ProblemDetails{
+				Type:     TypeInvalidRequestBody,
+				Title:    http.StatusText(http.StatusBadRequest),
+				Status:   http.StatusBadRequest,
+				Detail:   "The request body could not be decoded.",
+				Instance: "/tasks",
+			},
+		},
+		{
+			name:            "problem details of an internal error",
+			accept:          MIMEApplicationProblemJSON,
+			code:            http.StatusInternalServerError,
+			err:             errors.New("failed to get tasks"),
+			wantContentType: MIMEApplicationProblemJSON,
+			wantResponse: &models.ProblemDetails{
+				Type:     TypeAboutBlank,
+				Title:    http.StatusText(http.StatusInternalServerError),
+				Status:   http.StatusInternalServerError,
+				Detail:   http.StatusText(http.StatusInternalServerError),
+				Instance: "/tasks",
+			},
+		},
+		{
+			name:            "legacy error response of an internal error",
+			code:            http.StatusInternalServerError,
+			err:             errors.New("sql: database is closed"),
+			wantContentType: echo.MIMEApplicationJSON,
+			wantResponse: &models.ErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: http.StatusText(http.StatusInternalServerError),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/tasks", nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(req, rec)
+
+			err := Respond(c, tt.code, tt.err)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.code, rec.Code)
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), tt.wantContentType)
+
+			switch want := tt.wantResponse.(type) {
+			case *models.ErrorResponse:
+				var got models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Equal(t, want, &got)
+			case *models.ProblemDetails:
+				var got models.ProblemDetails
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+				assert.Equal(t, want, &got)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler(e.DefaultHTTPErrorHandler)
+	e.GET("/tasks", func(c echo.Context) error { return nil })
+
+	req := httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	req.Header.Set(echo.HeaderAccept, MIMEApplicationProblemJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	req = httptest.NewRequest(http.MethodGet, "/unknown", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+}
@@ -0,0 +1,148 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of query"
+	case tokenWord:
+		return "word"
+	case tokenString:
+		return "quoted string"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return `"("`
+	case tokenRParen:
+		return `")"`
+	case tokenAnd:
+		return "AND"
+	case tokenOr:
+		return "OR"
+	case tokenNot:
+		return "NOT"
+	}
+
+	return "unknown token"
+}
+
+// token represents a lexical token of a query, pos is the 1-based column of its first character.
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// operators ordered so that two-character operators are matched first
+var operators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+func isWordRune(r rune) bool {
+	return !unicode.IsSpace(r) && !strings.ContainsRune(`()":=!<>`, r)
+}
+
+// lex splits the query into tokens.
+func lex(input string) ([]token, error) {
+	tokens := make([]token, 0)
+	column := func(offset int) int { return utf8.RuneCountInString(input[:offset]) + 1 }
+
+	for i := 0; i < len(input); {
+		r, size := utf8.DecodeRuneInString(input[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: column(i)})
+			i += size
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: column(i)})
+			i += size
+		case r == '"':
+			start := i
+			var b strings.Builder
+			i += size
+			closed := false
+			for i < len(input) {
+				r, size := utf8.DecodeRuneInString(input[i:])
+				i += size
+				if r == '\\' && i < len(input) {
+					r, size = utf8.DecodeRuneInString(input[i:])
+					i += size
+					b.WriteRune(r)
+					continue
+				}
+				if r == '"' {
+					closed = true
+					break
+				}
+				b.WriteRune(r)
+			}
+			if !closed {
+				return nil, &ParseError{Pos: column(start), Msg: "unterminated quoted string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: column(start)})
+		default:
+			if op := matchOperator(input[i:]); op != "" {
+				tokens = append(tokens, token{kind: tokenOperator, text: op, pos: column(i)})
+				i += len(op)
+				continue
+			}
+
+			if !isWordRune(r) {
+				return nil, &ParseError{Pos: column(i), Msg: "unexpected character " + string(r)}
+			}
+
+			start := i
+			for i < len(input) {
+				r, size := utf8.DecodeRuneInString(input[i:])
+				if !isWordRune(r) {
+					break
+				}
+				i += size
+			}
+
+			word := input[start:i]
+			kind := tokenWord
+			switch word {
+			case "AND":
+				kind = tokenAnd
+			case "OR":
+				kind = tokenOr
+			case "NOT":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, text: word, pos: column(start)})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: column(len(input))}), nil
+}
+
+func matchOperator(s string) string {
+	for _, op := range operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+
+	return ""
+}
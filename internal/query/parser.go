@@ -0,0 +1,175 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse parses the query text into a syntax tree.
+//
+// The grammar, from the lowest to the highest precedence:
+//
+//	or      = and { "OR" and }
+//	and     = unary { [ "AND" ] unary }
+//	unary   = ( "NOT" | "-" ) unary | primary
+//	primary = "(" or ")" | word operator value | word | string
+func Parse(text string) (*Query, error) {
+	tokens, err := lex(text)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, &ParseError{Pos: 1, Msg: "empty query"}
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", describe(tok))}
+	}
+
+	return &Query{Root: root, text: text}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek().kind == tokenOr {
+		p.next()
+
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Or{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenWord, tokenString, tokenLParen, tokenNot:
+			// juxtaposed predicates are combined with AND
+		default:
+			return left, nil
+		}
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &And{Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	tok := p.peek()
+	if tok.kind == tokenNot || (tok.kind == tokenWord && tok.text == "-") {
+		p.next()
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Not{X: x}, nil
+	}
+
+	if tok.kind == tokenWord && strings.HasPrefix(tok.text, "-") {
+		// "-open" is read as the negation of "open"
+		p.tokens[p.pos].text = tok.text[1:]
+		p.tokens[p.pos].pos++
+
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &Not{X: x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &ParseError{Pos: closing.pos, Msg: fmt.Sprintf("expected \")\" to close \"(\" at position %d, found %s", tok.pos, describe(closing))}
+		}
+
+		return node, nil
+	case tokenString:
+		return newPredicate("name", ":", tok.text, tok.pos, tok.pos, tok.pos)
+	case tokenWord:
+		if op := p.peek(); op.kind == tokenOperator {
+			p.next()
+
+			value := p.next()
+			if value.kind != tokenWord && value.kind != tokenString {
+				return nil, &ParseError{Pos: value.pos, Msg: fmt.Sprintf("expected a value after %q, found %s", op.text, describe(value))}
+			}
+
+			return newPredicate(tok.text, op.text, value.text, tok.pos, op.pos, value.pos)
+		}
+
+		if isStatusKeyword(tok.text) {
+			return newPredicate("status", "=", tok.text, tok.pos, tok.pos, tok.pos)
+		}
+
+		return newPredicate("name", ":", tok.text, tok.pos, tok.pos, tok.pos)
+	}
+
+	return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", describe(tok))}
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEOF, tokenLParen, tokenRParen, tokenAnd, tokenOr, tokenNot:
+		return tok.kind.String()
+	}
+
+	return fmt.Sprintf("%s %q", tok.kind, tok.text)
+}
@@ -0,0 +1,297 @@
+// Package query implements the task query language used by saved filters.
+//
+// A query is made of predicates combined with AND, OR, NOT and parentheses, e.g.
+//
+//	open AND (name:deploy OR name:release) AND NOT id<=9bsv0s2hf8ng030mva9g
+//
+// Juxtaposed predicates are combined with AND, and a leading "-" negates a predicate.
+// A predicate is either a field comparison (field, operator, value), a status keyword
+// (open, done) or a bare word or quoted string matching the words of the task name.
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+)
+
+// ParseError represents a syntax or semantic error of a query at a given position.
+type ParseError struct {
+	Pos int // 1-based column of the offending token
+	Msg string
+}
+
+func (pe *ParseError) Error() string {
+	return fmt.Sprintf("%s at position %d", pe.Msg, pe.Pos)
+}
+
+// Position returns the 1-based column of the offending token.
+func (pe *ParseError) Position() int {
+	return pe.Pos
+}
+
+// Node represents a node of the query syntax tree.
+type Node interface {
+	// Eval reports whether the task matches the node.
+	Eval(task models.Task) bool
+	// String returns the canonical query text of the node.
+	String() string
+}
+
+// And matches the tasks matching both operands.
+type And struct {
+	Left, Right Node
+}
+
+// Or matches the tasks matching either operand.
+type Or struct {
+	Left, Right Node
+}
+
+// Not matches the tasks not matching the operand.
+type Not struct {
+	X Node
+}
+
+// Predicate compares a task field with a value.
+type Predicate struct {
+	Field string
+	Op    string
+	Value string
+	Pos   int
+
+	match func(task models.Task) bool
+}
+
+func (n *And) Eval(task models.Task) bool       { return n.Left.Eval(task) && n.Right.Eval(task) }
+func (n *Or) Eval(task models.Task) bool        { return n.Left.Eval(task) || n.Right.Eval(task) }
+func (n *Not) Eval(task models.Task) bool       { return !n.X.Eval(task) }
+func (n *Predicate) Eval(task models.Task) bool { return n.match(task) }
+
+func (n *And) String() string { return "(" + n.Left.String() + " AND " + n.Right.String() + ")" }
+func (n *Or) String() string  { return "(" + n.Left.String() + " OR " + n.Right.String() + ")" }
+func (n *Not) String() string { return "NOT " + n.X.String() }
+func (n *Predicate) String() string {
+	return n.Field + n.Op + strconv.Quote(n.Value)
+}
+
+// Query represents a parsed query.
+type Query struct {
+	Root Node
+	text string
+}
+
+// String returns the query text as it was parsed.
+func (q *Query) String() string {
+	return q.text
+}
+
+// Match reports whether the task matches the query.
+func (q *Query) Match(task models.Task) bool {
+	return q.Root.Eval(task)
+}
+
+// Filter returns the tasks matching the query, keeping their order.
+func (q *Query) Filter(tasks []models.Task) []models.Task {
+	result := make([]models.Task, 0)
+	for _, task := range tasks {
+		if q.Match(task) {
+			result = append(result, task)
+		}
+	}
+
+	return result
+}
+
+// Pushdown represents the predicates of a query that a repository can answer from its indexes.
+// Every task matching the query matches the pushdown, so a repository may use it to narrow the
+// candidate tasks before evaluating the full query.
+type Pushdown struct {
+	IDs       []string // the task id must be one of IDs, when not nil
+	NameTerms []string // every term must prefix a word of the task name
+	Status    *int     // the task must have the status, when not nil
+}
+
+// Pushdown returns the indexable predicates required by the query, i.e. the id equalities,
+// name predicates and status equalities found in the top-level conjunction.
+func (q *Query) Pushdown() Pushdown {
+	var pushdown Pushdown
+	for _, node := range conjuncts(q.Root) {
+		predicate, ok := node.(*Predicate)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case predicate.Field == "id" && (predicate.Op == "=" || predicate.Op == ":"):
+			if pushdown.IDs == nil || contains(pushdown.IDs, predicate.Value) {
+				pushdown.IDs = []string{predicate.Value}
+			} else {
+				pushdown.IDs = []string{}
+			}
+		case predicate.Field == "name" && predicate.Op == ":":
+			pushdown.NameTerms = append(pushdown.NameTerms, search.Tokenize(predicate.Value)...)
+		case predicate.Field == "status" && predicate.Op != "!=" && pushdown.Status == nil:
+			// a conflicting status equality matches no task, the first one is enough to narrow the candidates
+			if status, ok := ParseStatus(predicate.Value); ok {
+				pushdown.Status = &status
+			}
+		}
+	}
+
+	return pushdown
+}
+
+func conjuncts(node Node) []Node {
+	if and, ok := node.(*And); ok {
+		return append(conjuncts(and.Left), conjuncts(and.Right)...)
+	}
+
+	return []Node{node}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// field describes a task field usable in predicates.
+type field struct {
+	operators []string
+	// compile returns the matcher of the predicate, or an error message when the value is invalid
+	compile func(op, value string) (func(task models.Task) bool, string)
+}
+
+// fields is the registry of the task fields usable in predicates.
+var fields = map[string]field{
+	"id": {
+		operators: []string{":", "=", "!=", "<", "<=", ">", ">="},
+		compile: func(op, value string) (func(task models.Task) bool, string) {
+			return func(task models.Task) bool { return compare(op, strings.Compare(task.ID, value)) }, ""
+		},
+	},
+	"name": {
+		operators: []string{":", "=", "!="},
+		compile: func(op, value string) (func(task models.Task) bool, string) {
+			switch op {
+			case "=":
+				return func(task models.Task) bool { return strings.EqualFold(task.Name, value) }, ""
+			case "!=":
+				return func(task models.Task) bool { return !strings.EqualFold(task.Name, value) }, ""
+			}
+
+			terms := search.Tokenize(value)
+			if len(terms) == 0 {
+				return nil, "name value has no words"
+			}
+
+			return func(task models.Task) bool { return prefixesWords(terms, search.Tokenize(task.Name)) }, ""
+		},
+	},
+	"status": {
+		operators: []string{":", "=", "!="},
+		compile: func(op, value string) (func(task models.Task) bool, string) {
+			status, ok := ParseStatus(value)
+			if !ok {
+				return nil, fmt.Sprintf("invalid status %q, expected open, done, 0 or 1", value)
+			}
+
+			if op == "!=" {
+				return func(task models.Task) bool { return task.Status != status }, ""
+			}
+
+			return func(task models.Task) bool { return task.Status == status }, ""
+		},
+	},
+}
+
+// statusValues maps the status keywords and values to task statuses
+var statusValues = map[string]int{
+	"open":      0,
+	"0":         0,
+	"done":      1,
+	"completed": 1,
+	"1":         1,
+}
+
+// ParseStatus returns the task status of a status value of the queries, e.g. open, done or 1.
+func ParseStatus(value string) (int, bool) {
+	status, ok := statusValues[strings.ToLower(value)]
+
+	return status, ok
+}
+
+// isStatusKeyword reports whether a bare word is a status keyword, e.g. open or done.
+func isStatusKeyword(word string) bool {
+	switch strings.ToLower(word) {
+	case "open", "done", "completed":
+		return true
+	}
+
+	return false
+}
+
+func compare(op string, cmp int) bool {
+	switch op {
+	case ":", "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+
+	return false
+}
+
+// prefixesWords reports whether every term is a prefix of one of the words.
+func prefixesWords(terms, words []string) bool {
+	for _, term := range terms {
+		found := false
+		for _, word := range words {
+			if strings.HasPrefix(word, term) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newPredicate validates the field, operator and value and compiles the predicate.
+func newPredicate(fieldName, op, value string, fieldPos, opPos, valuePos int) (*Predicate, error) {
+	f, ok := fields[strings.ToLower(fieldName)]
+	if !ok {
+		return nil, &ParseError{Pos: fieldPos, Msg: fmt.Sprintf("unknown field %q", fieldName)}
+	}
+
+	if !contains(f.operators, op) {
+		return nil, &ParseError{Pos: opPos, Msg: fmt.Sprintf("operator %q is not supported by field %q", op, fieldName)}
+	}
+
+	match, msg := f.compile(op, value)
+	if msg != "" {
+		return nil, &ParseError{Pos: valuePos, Msg: msg}
+	}
+
+	return &Predicate{Field: strings.ToLower(fieldName), Op: op, Value: value, Pos: fieldPos, match: match}, nil
+}
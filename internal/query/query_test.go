@@ -0,0 +1,135 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var testTasks = []models.Task{
+	{ID: "a1", Name: "Deploy infra", Status: 0},
+	{ID: "a2", Name: "Write infrastructure docs", Status: 1},
+	{ID: "a3", Name: "Release v2", Status: 0},
+	{ID: "a4", Name: "Review release notes", Status: 1},
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantTree string
+		wantIDs  []string
+	}{
+		{
+			name:     "status keyword",
+			query:    "open",
+			wantTree: `status="open"`,
+			wantIDs:  []string{"a1", "a3"},
+		},
+		{
+			name:     "implicit and with bare words",
+			query:    "infra docs",
+			wantTree: `(name:"infra" AND name:"docs")`,
+			wantIDs:  []string{"a2"},
+		},
+		{
+			name:     "precedence of AND over OR",
+			query:    "done AND name:review OR name:deploy",
+			wantTree: `((status="done" AND name:"review") OR name:"deploy")`,
+			wantIDs:  []string{"a1", "a4"},
+		},
+		{
+			name:     "parentheses",
+			query:    "open AND (name:deploy OR name:release)",
+			wantTree: `(status="open" AND (name:"deploy" OR name:"release"))`,
+			wantIDs:  []string{"a1", "a3"},
+		},
+		{
+			name:     "negations",
+			query:    `NOT open -"release"`,
+			wantTree: `(NOT status="open" AND NOT name:"release")`,
+			wantIDs:  []string{"a2"},
+		},
+		{
+			name:     "id comparison",
+			query:    "id>=a2 status!=1",
+			wantTree: `(id>="a2" AND status!="1")`,
+			wantIDs:  []string{"a3"},
+		},
+		{
+			name:     "exact name",
+			query:    `name="release v2"`,
+			wantTree: `name="release v2"`,
+			wantIDs:  []string{"a3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTree, q.Root.String())
+			assert.Equal(t, tt.query, q.String())
+
+			ids := make([]string, 0)
+			for _, task := range q.Filter(testTasks) {
+				ids = append(ids, task.ID)
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantPos int
+		wantMsg string
+	}{
+		{name: "empty query", query: "  ", wantPos: 1, wantMsg: "empty query"},
+		{name: "unknown field", query: "open AND tag:infra", wantPos: 10, wantMsg: `unknown field "tag"`},
+		{name: "unsupported operator", query: "status>=1", wantPos: 7, wantMsg: `operator ">=" is not supported by field "status"`},
+		{name: "invalid status", query: "status:maybe", wantPos: 8, wantMsg: `invalid status "maybe", expected open, done, 0 or 1`},
+		{name: "missing value", query: "name: )", wantPos: 7, wantMsg: `expected a value after ":", found ")"`},
+		{name: "unclosed parenthesis", query: "(open OR done", wantPos: 14, wantMsg: `expected ")" to close "(" at position 1, found end of query`},
+		{name: "unexpected closing parenthesis", query: "open)", wantPos: 5, wantMsg: `unexpected ")"`},
+		{name: "dangling operator", query: "open AND", wantPos: 9, wantMsg: "unexpected end of query"},
+		{name: "unterminated string", query: `name:"deploy`, wantPos: 6, wantMsg: "unterminated quoted string"},
+		{name: "positions count characters", query: `"café" tag:x`, wantPos: 8, wantMsg: `unknown field "tag"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+
+			var parseErr *ParseError
+			assert.True(t, errors.As(err, &parseErr))
+			assert.Equal(t, tt.wantPos, parseErr.Position())
+			assert.Equal(t, tt.wantMsg, parseErr.Msg)
+		})
+	}
+}
+
+func TestQuery_Pushdown(t *testing.T) {
+	open, done := 0, 1
+	tests := []struct {
+		name  string
+		query string
+		want  Pushdown
+	}{
+		{name: "nothing indexable", query: "open OR name:deploy", want: Pushdown{}},
+		{name: "id equality", query: "id=a1 open", want: Pushdown{IDs: []string{"a1"}, Status: &open}},
+		{name: "conflicting id equalities", query: "id=a1 id=a2", want: Pushdown{IDs: []string{}}},
+		{name: "name terms", query: `open name:"infra docs" (deploy OR release)`, want: Pushdown{NameTerms: []string{"infra", "docs"}, Status: &open}},
+		{name: "status equality", query: "status:done NOT id=a1", want: Pushdown{Status: &done}},
+		{name: "status inequality", query: "status!=done", want: Pushdown{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, q.Pushdown())
+		})
+	}
+}
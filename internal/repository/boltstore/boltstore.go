@@ -0,0 +1,867 @@
+// Package boltstore implements a task manager on an embedded key-value store, a B+tree file opened by a single process.
+// The tasks are kept in a bucket ordered by id, along with buckets indexing them by status and by the words of their
+// names, and a bucket of their changes answering the sync and history queries. Every mutation is a single transaction,
+// durable once it returns, so a crash never leaves a partial write behind. The full-text index ranking the search
+// results is rebuilt in memory when the file is opened, and a meta bucket records the schema version of the file.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// lockTimeout is how long Open waits for another process to release the file
+const lockTimeout = time.Second
+
+var (
+	tasksBucket   = []byte("tasks")   // task id -> JSON task, iterated in the order of the ids
+	statusBucket  = []byte("status")  // status, NUL, task id -> nothing
+	wordsBucket   = []byte("words")   // word of the name, NUL, task id -> nothing
+	changesBucket = []byte("changes") // big-endian sequence number -> JSON change
+)
+
+// ErrLocked represents an error when the file is opened by another process
+var ErrLocked = errors.New("the task file is opened by another process")
+
+// change represents a version of a task appended to the changes bucket.
+type change struct {
+	TaskID  string    `json:"taskId"`
+	Name    string    `json:"name"`
+	Status  int       `json:"status"`
+	Deleted bool      `json:"deleted,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Repository is a task manager storing the tasks in a key-value file.
+type Repository struct {
+	db        *bolt.DB
+	index     *search.Index
+	now       func() time.Time
+	publisher repository.Publisher
+
+	// writeMu serializes the write transactions with their updates of the full-text index,
+	// bolt releasing its writer lock before running the commit handlers.
+	writeMu sync.Mutex
+}
+
+var _ repository.TaskManager = (*Repository)(nil)
+
+// Option configures the repository opened by Open.
+type Option func(*Repository)
+
+// WithPublisher publishes a task event to the publisher on every mutation of the tasks.
+func WithPublisher(publisher repository.Publisher) Option {
+	return func(r *Repository) {
+		r.publisher = publisher
+	}
+}
+
+// WithClock sets the clock recording the time of the changes.
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) {
+		r.now = now
+	}
+}
+
+// Open opens the key-value file, creating it and its buckets when missing.
+// The file must have the current schema version, see Migrations, and a new file records it.
+// The file is locked until the repository is closed.
+func Open(path string, options ...Option) (*Repository, error) {
+	r := &Repository{index: search.NewIndex(), now: time.Now}
+	for _, option := range options {
+		option(r)
+	}
+
+	db, err := OpenDB(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{tasksBucket, statusBucket, wordsBucket, changesBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+
+		return tx.Bucket(tasksBucket).ForEach(func(_, data []byte) error {
+			var task models.Task
+			if err := json.Unmarshal(data, &task); err != nil {
+				return err
+			}
+			r.index.Add(task.ID, task.Name)
+
+			return nil
+		})
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+	r.db = db.DB
+
+	return r, nil
+}
+
+// Close closes the key-value file.
+func (r *Repository) Close() error {
+	return r.db.Close()
+}
+
+// update runs fn in a write transaction, holding writeMu until the full-text index is updated by the commit
+// handlers so that the index is updated in the order of the commits.
+func (r *Repository) update(fn func(tx *bolt.Tx) error) error {
+	r.writeMu.Lock()
+	defer r.writeMu.Unlock()
+
+	return r.db.Update(fn)
+}
+
+// statusKey returns the key of the task in the status bucket.
+func statusKey(status int, taskID string) []byte {
+	return []byte(strconv.Itoa(status) + "\x00" + taskID)
+}
+
+// wordKeys returns the keys of the task in the words bucket, one for every distinct word of its name.
+func wordKeys(task models.Task) [][]byte {
+	words := search.Tokenize(task.Name)
+	slices.Sort(words)
+
+	keys := make([][]byte, 0, len(words))
+	for _, word := range slices.Compact(words) {
+		keys = append(keys, []byte(word+"\x00"+task.ID))
+	}
+
+	return keys
+}
+
+// keyTaskID returns the task id following the NUL separator of an index key.
+func keyTaskID(key []byte) string {
+	return string(key[bytes.IndexByte(key, 0)+1:])
+}
+
+// getTask returns the task with the id, and whether it exists.
+func getTask(tx *bolt.Tx, taskID string) (models.Task, bool, error) {
+	data := tx.Bucket(tasksBucket).Get([]byte(taskID))
+	if data == nil {
+		return models.Task{}, false, nil
+	}
+
+	var task models.Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return models.Task{}, false, err
+	}
+
+	return task, true, nil
+}
+
+// put writes the task and its index entries, replacing the index entries of its previous version when given,
+// and records the change. The full-text index is updated once the transaction is committed.
+func (r *Repository) put(tx *bolt.Tx, task models.Task, previous *models.Task) error {
+	if previous != nil {
+		if err := removeIndexes(tx, *previous); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(tasksBucket).Put([]byte(task.ID), data); err != nil {
+		return err
+	}
+
+	if err := tx.Bucket(statusBucket).Put(statusKey(task.Status, task.ID), nil); err != nil {
+		return err
+	}
+
+	words := tx.Bucket(wordsBucket)
+	for _, key := range wordKeys(task) {
+		if err := words.Put(key, nil); err != nil {
+			return err
+		}
+	}
+	tx.OnCommit(func() { r.index.Add(task.ID, task.Name) })
+
+	return r.record(tx, task, false)
+}
+
+// remove deletes the task and its index entries, and records the deletion.
+// The task is removed from the full-text index once the transaction is committed.
+func (r *Repository) remove(tx *bolt.Tx, task models.Task) error {
+	if err := tx.Bucket(tasksBucket).Delete([]byte(task.ID)); err != nil {
+		return err
+	}
+
+	if err := removeIndexes(tx, task); err != nil {
+		return err
+	}
+	tx.OnCommit(func() { r.index.Remove(task.ID) })
+
+	return r.record(tx, task, true)
+}
+
+// removeIndexes deletes the index entries of the task.
+func removeIndexes(tx *bolt.Tx, task models.Task) error {
+	if err := tx.Bucket(statusBucket).Delete(statusKey(task.Status, task.ID)); err != nil {
+		return err
+	}
+
+	words := tx.Bucket(wordsBucket)
+	for _, key := range wordKeys(task) {
+		if err := words.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// record appends a version of the task to the changes with the next sequence number, deleted records its deletion.
+func (r *Repository) record(tx *bolt.Tx, task models.Task, deleted bool) error {
+	changes := tx.Bucket(changesBucket)
+	seq, err := changes.NextSequence()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(change{TaskID: task.ID, Name: task.Name, Status: task.Status, Deleted: deleted, Time: r.now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	return changes.Put(binary.BigEndian.AppendUint64(nil, seq), data)
+}
+
+// GetTasks returns all tasks in the order of the ids, which is the order of the tasks bucket
+func (r *Repository) GetTasks(ctx context.Context) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	tasks := make([]models.Task, 0)
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(tasksBucket).ForEach(func(_, data []byte) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var task models.Task
+			if err := json.Unmarshal(data, &task); err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+
+			return nil
+		})
+	}); err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return tasks, nil
+}
+
+// ListTasks returns at most limit tasks sorted by ID following the task id after, a non-positive limit returns
+// every following task. An empty after starts from the first task.
+func (r *Repository) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	tasks := make([]models.Task, 0)
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(tasksBucket).Cursor()
+		key, data := cursor.Seek([]byte(after))
+		if key != nil && string(key) == after {
+			key, data = cursor.Next()
+		}
+
+		for ; key != nil && (limit <= 0 || len(tasks) < limit); key, data = cursor.Next() {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			var task models.Task
+			if err := json.Unmarshal(data, &task); err != nil {
+				return err
+			}
+			tasks = append(tasks, task)
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return tasks, nil
+}
+
+// GetTask returns a task by task id
+func (r *Repository) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return models.Task{}, ctx.Err()
+	default:
+	}
+
+	var task models.Task
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		var exists bool
+		var err error
+		task, exists, err = getTask(tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		return nil
+	}); err != nil {
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// QueryTasks returns the tasks matching the query, sorted by ID.
+// The candidate tasks are looked up by id, in the words index or in the status index before the query is evaluated.
+func (r *Repository) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	pushdown := q.Pushdown()
+	if pushdown.IDs == nil && len(pushdown.NameTerms) == 0 && pushdown.Status == nil {
+		tasks, err := r.GetTasks(ctx)
+		if err != nil {
+			return make([]models.Task, 0), err
+		}
+
+		return q.Filter(tasks), nil
+	}
+
+	result := make([]models.Task, 0)
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		var candidateIDs []string
+		switch {
+		case pushdown.IDs != nil:
+			candidateIDs = slices.Clone(pushdown.IDs)
+			slices.Sort(candidateIDs)
+		case len(pushdown.NameTerms) != 0:
+			candidateIDs = matchWords(tx, pushdown.NameTerms)
+		default:
+			candidateIDs = scanIndex(tx.Bucket(statusBucket), []byte(strconv.Itoa(*pushdown.Status)+"\x00"))
+		}
+
+		for _, taskID := range candidateIDs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			task, exists, err := getTask(tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if exists && q.Match(task) {
+				result = append(result, task)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return result, nil
+}
+
+// scanIndex returns the task ids of the index keys starting with the prefix, in the order of the keys.
+func scanIndex(bucket *bolt.Bucket, prefix []byte) []string {
+	taskIDs := make([]string, 0)
+	c := bucket.Cursor()
+	for key, _ := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = c.Next() {
+		taskIDs = append(taskIDs, keyTaskID(key))
+	}
+
+	return taskIDs
+}
+
+// matchWords returns the sorted ids of the tasks having a word prefixed by every term.
+func matchWords(tx *bolt.Tx, terms []string) []string {
+	var matched map[string]struct{}
+	for _, term := range terms {
+		ids := make(map[string]struct{})
+		for _, taskID := range scanIndex(tx.Bucket(wordsBucket), []byte(term)) {
+			if _, found := matched[taskID]; matched == nil || found {
+				ids[taskID] = struct{}{}
+			}
+		}
+		matched = ids
+	}
+
+	taskIDs := make([]string, 0, len(matched))
+	for taskID := range matched {
+		taskIDs = append(taskIDs, taskID)
+	}
+	slices.Sort(taskIDs)
+
+	return taskIDs
+}
+
+// CreateTasks writes the tasks with new task ids in a single transaction, the ids are assigned to the given tasks
+func (r *Repository) CreateTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	created := make([]models.Task, len(tasks))
+	for i := range tasks {
+		created[i] = tasks[i]
+		created[i].NewTaskID()
+	}
+
+	if err := r.update(func(tx *bolt.Tx) error {
+		for _, task := range created {
+			if err := r.put(tx, task, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	copy(tasks, created)
+	for _, task := range created {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// RestoreTasks writes the tasks with their own task ids in a single transaction.
+// No task is created when one of the ids is invalid, repeated or already exists.
+func (r *Repository) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	seen := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		if _, err := xid.FromString(task.ID); err != nil {
+			return repository.ErrTaskID
+		}
+
+		if _, duplicated := seen[task.ID]; duplicated {
+			return repository.ErrTaskExists
+		}
+		seen[task.ID] = struct{}{}
+	}
+
+	if err := r.update(func(tx *bolt.Tx) error {
+		for _, task := range tasks {
+			if tx.Bucket(tasksBucket).Get([]byte(task.ID)) != nil {
+				return repository.ErrTaskExists
+			}
+
+			if err := r.put(tx, task, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// UpdateTask updates the name and the status of a task by task id
+func (r *Repository) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var previous, task models.Task
+	if err := r.update(func(tx *bolt.Tx) error {
+		var exists bool
+		var err error
+		previous, exists, err = getTask(tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		task = repository.Apply(previous, name, status)
+
+		return r.put(tx, task, &previous)
+	}); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, previous, task)
+
+	return nil
+}
+
+// CompareAndSwapTask replaces a task by the task if it is still the previous task, keeping its id
+func (r *Repository) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	task.ID = previous.ID
+	if err := r.update(func(tx *bolt.Tx) error {
+		current, exists, err := getTask(tx, previous.ID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		if current != previous {
+			return repository.ErrTaskChanged
+		}
+
+		return r.put(tx, task, &previous)
+	}); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, previous, task)
+
+	return nil
+}
+
+// DeleteTask deletes a task by task id
+func (r *Repository) DeleteTask(ctx context.Context, taskID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var task models.Task
+	if err := r.update(func(tx *bolt.Tx) error {
+		var exists bool
+		var err error
+		task, exists, err = getTask(tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		return r.remove(tx, task)
+	}); err != nil {
+		return err
+	}
+	repository.Publish(r.publisher, models.TaskDeleted, task)
+
+	return nil
+}
+
+// BatchUpdateTasks updates the tasks with the given task ids in a single transaction,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	var previous, updated []models.Task
+	if err := r.update(func(tx *bolt.Tx) error {
+		results = make([]models.BatchResult, 0, len(taskIDs))
+		previous, updated = nil, nil
+		seen := make(map[string]struct{}, len(taskIDs))
+		for _, taskID := range taskIDs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			if _, duplicated := seen[taskID]; duplicated {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+				continue
+			}
+			seen[taskID] = struct{}{}
+
+			task, exists, err := getTask(tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+				continue
+			}
+
+			if err := r.put(tx, repository.Apply(task, name, status), &task); err != nil {
+				return err
+			}
+			previous = append(previous, task)
+			updated = append(updated, repository.Apply(task, name, status))
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeUpdated})
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for i := range updated {
+		repository.PublishUpdate(r.publisher, previous[i], updated[i])
+	}
+
+	return results, nil
+}
+
+// BatchDeleteTasks deletes the tasks with the given task ids in a single transaction,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	var deleted []models.Task
+	if err := r.update(func(tx *bolt.Tx) error {
+		results = make([]models.BatchResult, 0, len(taskIDs))
+		deleted = nil
+		seen := make(map[string]struct{}, len(taskIDs))
+		for _, taskID := range taskIDs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			if _, duplicated := seen[taskID]; duplicated {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+				continue
+			}
+			seen[taskID] = struct{}{}
+
+			task, exists, err := getTask(tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+				continue
+			}
+
+			if err := r.remove(tx, task); err != nil {
+				return err
+			}
+			deleted = append(deleted, task)
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeDeleted})
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for _, task := range deleted {
+		repository.Publish(r.publisher, models.TaskDeleted, task)
+	}
+
+	return results, nil
+}
+
+// SearchTasks returns at most limit tasks whose names match the full-text query, ordered by relevance.
+// A non-positive limit returns every matching task.
+func (r *Repository) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	results := make([]models.SearchResult, 0)
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		for _, hit := range r.index.Search(query) {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			if limit > 0 && len(results) == limit {
+				break
+			}
+
+			task, exists, err := getTask(tx, hit.ID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				// deleted after the index was searched
+				continue
+			}
+
+			results = append(results, models.SearchResult{
+				Task:      task,
+				Score:     hit.Score,
+				Highlight: search.Highlight(task.Name, hit.Terms),
+			})
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.SearchResult, 0), err
+	}
+
+	return results, nil
+}
+
+// SyncTasks returns the tasks created or updated and the ids of the tasks deleted after the sequence number,
+// which is the sequence number of the changes. Since 0 returns every task without tombstones.
+func (r *Repository) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	select {
+	case <-ctx.Done():
+		return models.TaskChanges{}, ctx.Err()
+	default:
+	}
+
+	var result models.TaskChanges
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		changes := tx.Bucket(changesBucket)
+		seq := changes.Sequence()
+		if since > seq {
+			// issued before the file was reset
+			return repository.ErrSyncTokenExpired
+		}
+
+		// the ids of the changed tasks ordered by their last change
+		var taskIDs []string
+		last := make(map[string]int)
+		c := changes.Cursor()
+		for key, data := c.Seek(binary.BigEndian.AppendUint64(nil, since+1)); key != nil; key, data = c.Next() {
+			var ch change
+			if err := json.Unmarshal(data, &ch); err != nil {
+				return err
+			}
+
+			if i, found := last[ch.TaskID]; found {
+				taskIDs[i] = ""
+			}
+			last[ch.TaskID] = len(taskIDs)
+			taskIDs = append(taskIDs, ch.TaskID)
+		}
+
+		result = models.TaskChanges{Tasks: make([]models.Task, 0), Deleted: make([]string, 0), Seq: seq}
+		for _, taskID := range taskIDs {
+			if taskID == "" {
+				continue
+			}
+
+			task, exists, err := getTask(tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				if since > 0 {
+					result.Deleted = append(result.Deleted, taskID)
+				}
+				continue
+			}
+			result.Tasks = append(result.Tasks, task)
+		}
+
+		return nil
+	}); err != nil {
+		return models.TaskChanges{}, err
+	}
+
+	return result, nil
+}
+
+// TasksAsOf rebuilds the tasks, sorted by ID, from the changes recorded up to the given time.
+// The changes are never pruned, so the whole history is available.
+func (r *Repository) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	state := make(map[string]models.Task)
+	if err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(changesBucket).Cursor()
+		for key, data := c.First(); key != nil; key, data = c.Next() {
+			var ch change
+			if err := json.Unmarshal(data, &ch); err != nil {
+				return err
+			}
+
+			if ch.Time.After(at) {
+				// the changes are recorded in time order
+				break
+			}
+
+			if ch.Deleted {
+				delete(state, ch.TaskID)
+			} else {
+				state[ch.TaskID] = models.Task{ID: ch.TaskID, Name: ch.Name, Status: ch.Status}
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	tasks := make([]models.Task, 0, len(state))
+	for _, task := range state {
+		tasks = append(tasks, task)
+	}
+	models.SortTasksByID(tasks)
+
+	return tasks, nil
+}
@@ -0,0 +1,324 @@
+package boltstore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock returns a clock advancing by a minute on every call.
+func testClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+type recordingPublisher struct {
+	events []models.TaskEventType
+}
+
+func (r *recordingPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	r.events = append(r.events, eventType)
+	return models.TaskEvent{ID: uint64(len(r.events)), Type: eventType, Task: task}
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.bolt")
+	publisher := &recordingPublisher{}
+	repo, err := Open(path, WithPublisher(publisher))
+	assert.NoError(t, err)
+
+	// the file is locked by the repository
+	_, err = Open(path)
+	assert.ErrorIs(t, err, ErrLocked)
+
+	tasks := []models.Task{{Name: "Write report"}, {Name: "Review report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NotEmpty(t, tasks[0].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+	write, review := tasks[0], tasks[1]
+
+	name, status := "Write the report", 1
+	assert.NoError(t, repo.UpdateTask(ctx, write.ID, &name, &status))
+	assert.ErrorIs(t, repo.UpdateTask(ctx, "unknown", &name, nil), repository.ErrTaskNotFound)
+
+	task, err := repo.GetTask(ctx, write.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Task{ID: write.ID, Name: "Write the report", Status: 1}, task)
+
+	assert.NoError(t, repo.DeleteTask(ctx, review.ID))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, review.ID), repository.ErrTaskNotFound)
+
+	_, err = repo.GetTask(ctx, review.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	assert.Equal(t, []models.TaskEventType{
+		models.TaskCreated, models.TaskCreated, models.TaskUpdated, models.TaskCompleted, models.TaskDeleted,
+	}, publisher.events)
+	assert.NoError(t, repo.Close())
+
+	// the tasks are kept in the file
+	reopened, err := Open(path)
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	got, err = reopened.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, got)
+	assert.NoError(t, checkIndexes(reopened))
+
+	results, err := reopened.SearchTasks(ctx, "report", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, task, results[0].Task)
+}
+
+func TestRepository_GetTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	// restored out of order, listed in the order of the ids
+	tasks := []models.Task{
+		{ID: "d3bkmd6hf8ng0305igb0", Name: "Task 3"},
+		{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"},
+		{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"},
+	}
+	assert.NoError(t, repo.RestoreTasks(ctx, tasks))
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{tasks[1], tasks[2], tasks[0]}, got)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.GetTasks(canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRepository_RestoreTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	existing := []models.Task{{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"}}
+	assert.NoError(t, repo.RestoreTasks(ctx, existing))
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{name: "invalid id", tasks: []models.Task{{ID: "1", Name: "Task 2"}}, wantErr: repository.ErrTaskID},
+		{name: "existing id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305ig90", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+		{name: "repeated id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305iga0", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repo.RestoreTasks(ctx, tt.tasks), tt.wantErr)
+
+			got, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, existing, got)
+			assert.NoError(t, checkIndexes(repo))
+		})
+	}
+}
+
+func TestRepository_BatchTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	status := 1
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, "unknown", tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+
+	results, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+	assert.NoError(t, checkIndexes(repo))
+}
+
+func TestRepository_QueryTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{
+		{Name: "Deploy the API"},
+		{Name: "Deploy the docs", Status: 1},
+		{Name: "Écrire la documentation"},
+		{Name: "deploy"},
+		{Name: "Review the deployment", Status: 1},
+		{Name: "Write report"},
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+
+	// the indexes return the tasks the query matches in memory
+	for _, text := range []string{
+		"open",
+		"status:done deploy",
+		"deploy AND NOT done",
+		"écrire",
+		`name="DEPLOY"`,
+		"NOT (deploy OR report)",
+		"id=" + all[2].ID,
+		"id=" + all[2].ID + " done",
+	} {
+		t.Run(text, func(t *testing.T) {
+			q, err := query.Parse(text)
+			assert.NoError(t, err)
+
+			got, err := repo.QueryTasks(ctx, q)
+			assert.NoError(t, err)
+			assert.Equal(t, q.Filter(all), got)
+		})
+	}
+
+	results, err := repo.SearchTasks(ctx, "deploy", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "<mark>deploy</mark>", results[0].Highlight)
+
+	results, err = repo.SearchTasks(ctx, "deploy", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestRepository_SyncTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: tasks, Deleted: []string{}, Seq: 3}, changes)
+
+	name := "Task 1 renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+
+	changes, err = repo.SyncTasks(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: tasks[0].ID, Name: name}},
+		Deleted: []string{tasks[1].ID},
+		Seq:     5,
+	}, changes)
+
+	_, err = repo.SyncTasks(ctx, 6)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+}
+
+func TestRepository_TasksAsOf(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"), WithClock(testClock(start)))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks)) // 09:01
+
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status)) // 09:02
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[0].ID))               // 09:03
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []models.Task
+	}{
+		{name: "before the creation", at: start, want: []models.Task{}},
+		{name: "after the creation", at: start.Add(time.Minute), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1"}}},
+		{name: "after the update", at: start.Add(150 * time.Second), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}},
+		{name: "after the deletion", at: start.Add(3 * time.Minute), want: []models.Task{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TasksAsOf(ctx, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_SearchTasks_ConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "word"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	const renames = 20
+	var wg sync.WaitGroup
+	for i := range renames {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("word%c", 'a'+i)
+			assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+		}()
+	}
+	wg.Wait()
+
+	task, err := repo.GetTask(ctx, tasks[0].ID)
+	assert.NoError(t, err)
+
+	// the full-text index holds the name of the last committed update only
+	for i := range renames {
+		name := fmt.Sprintf("word%c", 'a'+i)
+		results, err := repo.SearchTasks(ctx, name, 0)
+		assert.NoError(t, err)
+		if name == task.Name {
+			assert.Len(t, results, 1)
+		} else {
+			assert.Empty(t, results, name)
+		}
+	}
+}
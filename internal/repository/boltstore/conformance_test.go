@@ -0,0 +1,20 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.TaskManager {
+		repo, err := Open(filepath.Join(t.TempDir(), "tasks.bolt"))
+		assert.NoError(t, err)
+		t.Cleanup(func() { repo.Close() })
+
+		return repo
+	})
+}
@@ -0,0 +1,187 @@
+package boltstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// crashWriterEnv holds the file written by the test process re-executed as the writer TestRepository_Crash kills
+	crashWriterEnv = "BOLTSTORE_CRASH_FILE"
+	// crashBatchSize is the number of tasks the writer creates in a transaction
+	crashBatchSize = 20
+	// crashRounds is the number of times the writer is killed
+	crashRounds = 3
+)
+
+// checkIndexes returns an error unless the index buckets hold exactly the entries of the tasks.
+func checkIndexes(r *Repository) error {
+	return r.db.View(func(tx *bolt.Tx) error {
+		wantStatus, wantWords := 0, 0
+		if err := tx.Bucket(tasksBucket).ForEach(func(key, data []byte) error {
+			var task models.Task
+			if err := json.Unmarshal(data, &task); err != nil {
+				return err
+			}
+
+			if task.ID != string(key) {
+				return fmt.Errorf("task %s stored under %s", task.ID, key)
+			}
+
+			if tx.Bucket(statusBucket).Get(statusKey(task.Status, task.ID)) == nil {
+				return fmt.Errorf("task %s missing from the status index", task.ID)
+			}
+			wantStatus++
+
+			for _, key := range wordKeys(task) {
+				if tx.Bucket(wordsBucket).Get(key) == nil {
+					return fmt.Errorf("task %s missing from the words index", task.ID)
+				}
+				wantWords++
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if n := tx.Bucket(statusBucket).Stats().KeyN; n != wantStatus {
+			return fmt.Errorf("the status index has %d entries, expected %d", n, wantStatus)
+		}
+
+		if n := tx.Bucket(wordsBucket).Stats().KeyN; n != wantWords {
+			return fmt.Errorf("the words index has %d entries, expected %d", n, wantWords)
+		}
+
+		return nil
+	})
+}
+
+// TestCrashWriter writes tasks until it is killed, it only runs as the process started by TestRepository_Crash.
+// Every batch of tasks is created, then completed, and every third batch is deleted, each step in a transaction.
+// The number of the batch is printed once its last step is committed.
+func TestCrashWriter(t *testing.T) {
+	path := os.Getenv(crashWriterEnv)
+	if path == "" {
+		t.Skip("run by TestRepository_Crash")
+	}
+
+	ctx := context.Background()
+	repo, err := Open(path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	status := 1
+	for batch := 0; ; batch++ {
+		tasks := make([]models.Task, crashBatchSize)
+		for i := range tasks {
+			tasks[i].Name = fmt.Sprintf("batch %d-%d task %d", os.Getpid(), batch, i)
+		}
+
+		if !assert.NoError(t, repo.CreateTasks(ctx, tasks)) {
+			return
+		}
+
+		taskIDs := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+
+		if _, err := repo.BatchUpdateTasks(ctx, taskIDs, nil, &status); !assert.NoError(t, err) {
+			return
+		}
+
+		if batch%3 == 0 {
+			if _, err := repo.BatchDeleteTasks(ctx, taskIDs); !assert.NoError(t, err) {
+				return
+			}
+		}
+
+		fmt.Printf("committed %d-%d\n", os.Getpid(), batch)
+	}
+}
+
+func TestRepository_Crash(t *testing.T) {
+	if testing.Short() {
+		t.Skip("kills a writer process")
+	}
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.bolt")
+	var committed []string
+	for round := 0; round < crashRounds; round++ {
+		// the writer is killed after committing a few batches, most likely in the middle of a transaction
+		cmd := exec.Command(os.Args[0], "-test.run=^TestCrashWriter$")
+		cmd.Env = append(os.Environ(), crashWriterEnv+"="+path)
+		stdout, err := cmd.StdoutPipe()
+		assert.NoError(t, err)
+		assert.NoError(t, cmd.Start())
+
+		scanner := bufio.NewScanner(stdout)
+		for n := 0; n < 5+round*3 && scanner.Scan(); {
+			if batch, found := strings.CutPrefix(scanner.Text(), "committed "); found {
+				committed = append(committed, batch)
+				n++
+			}
+		}
+		assert.NoError(t, cmd.Process.Kill())
+		cmd.Wait()
+
+		repo, err := Open(path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, checkIndexes(repo))
+
+		tasks, err := repo.GetTasks(ctx)
+		assert.NoError(t, err)
+
+		// every batch is written as a whole: all of its tasks exist with the same status, or none
+		batches := make(map[string][]models.Task)
+		for _, task := range tasks {
+			batch, _, _ := strings.Cut(strings.TrimPrefix(task.Name, "batch "), " ")
+			batches[batch] = append(batches[batch], task)
+		}
+		for batch, tasks := range batches {
+			assert.Len(t, tasks, crashBatchSize, "batch %s", batch)
+			for _, task := range tasks {
+				assert.Equal(t, tasks[0].Status, task.Status, "batch %s", batch)
+			}
+		}
+
+		// the batches reported committed survived the kill
+		for _, batch := range committed {
+			_, number, _ := strings.Cut(batch, "-")
+			n, err := strconv.Atoi(number)
+			assert.NoError(t, err)
+
+			tasks, found := batches[batch]
+			assert.Equal(t, n%3 != 0, found, "batch %s", batch)
+			if found {
+				assert.Equal(t, 1, tasks[0].Status, "batch %s", batch)
+			}
+		}
+
+		// the changes account for every task
+		changes, err := repo.SyncTasks(ctx, 0)
+		assert.NoError(t, err)
+		models.SortTasksByID(changes.Tasks)
+		assert.True(t, slices.Equal(tasks, changes.Tasks))
+
+		assert.NoError(t, repo.Close())
+	}
+}
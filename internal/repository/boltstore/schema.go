@@ -0,0 +1,144 @@
+package boltstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+)
+
+// metaBucket holds the schema version of the file, under versionKey as a decimal number
+var (
+	metaBucket = []byte("meta")
+	versionKey = []byte("version")
+)
+
+// Migrations are the schema migrations of the key-value files, run by the server on startup.
+//
+// Version 1 is the file without meta bucket. Version 2 records the schema version in it.
+var Migrations = schema.NewRegistry(
+	schema.Migration[DB]{
+		Version:     2,
+		Description: "record the schema version in the meta bucket",
+		Apply:       createMetaBucket,
+	},
+)
+
+// DB is a store of the tasks of a key-value file, recording the schema version in its meta bucket.
+type DB struct {
+	*bolt.DB
+}
+
+// OpenDB opens the key-value file, creating it when missing. The file is locked until the database is closed.
+func OpenDB(path string) (DB, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: lockTimeout})
+	if errors.Is(err, berrors.ErrTimeout) {
+		return DB{}, fmt.Errorf("%w: %s", ErrLocked, path)
+	}
+	if err != nil {
+		return DB{}, err
+	}
+
+	return DB{db}, nil
+}
+
+// ReadVersion returns the schema version recorded in the file, or 0 when there is none.
+func (db DB) ReadVersion(context.Context) (int, error) {
+	var version int
+	err := db.View(func(tx *bolt.Tx) error {
+		meta := tx.Bucket(metaBucket)
+		if meta == nil {
+			return nil
+		}
+
+		data := meta.Get(versionKey)
+		if data == nil {
+			return nil
+		}
+
+		var err error
+		if version, err = strconv.Atoi(string(data)); err != nil {
+			return fmt.Errorf("read the schema version: %w", err)
+		}
+
+		return nil
+	})
+
+	return version, err
+}
+
+// WriteVersion records the schema version in the file, creating the meta bucket when missing.
+func (db DB) WriteVersion(_ context.Context, version int) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return err
+		}
+
+		return meta.Put(versionKey, []byte(strconv.Itoa(version)))
+	})
+}
+
+// checkSchema records the current schema version in a new file, and returns an error
+// unless an existing file has the current version.
+func checkSchema(db DB) error {
+	ctx := context.Background()
+	version, err := db.ReadVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		var exists bool
+		if err := db.View(func(tx *bolt.Tx) error {
+			exists = tx.Bucket(tasksBucket) != nil
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		if !exists {
+			return db.WriteVersion(ctx, Migrations.Current())
+		}
+	}
+
+	return Migrations.Check(ctx, db)
+}
+
+// createMetaBucket creates the meta bucket of the file and counts the tasks and their changes,
+// the migration to version 2 changes nothing else.
+func createMetaBucket(_ context.Context, db DB, dryRun bool) (string, error) {
+	var tasks, changes int
+	var exists bool
+	if err := db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket(tasksBucket); bucket != nil {
+			exists = true
+			tasks = bucket.Stats().KeyN
+		}
+		if bucket := tx.Bucket(changesBucket); bucket != nil {
+			changes = bucket.Stats().KeyN
+		}
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "no tasks bucket", nil
+	}
+
+	if !dryRun {
+		if err := db.Update(func(tx *bolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(metaBucket)
+			return err
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("found %d tasks and %d changes", tasks, changes), nil
+}
@@ -0,0 +1,116 @@
+package boltstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// copyFixture copies the key-value file of a fixture to a temporary directory.
+func copyFixture(t *testing.T, name string) string {
+	data, err := os.ReadFile(filepath.Join("testdata", "schema", name+".bolt"))
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tasks.bolt")
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+	return path
+}
+
+// migrate runs the migrations of the file.
+func migrate(t *testing.T, path string, dryRun bool) ([]schema.Step, error) {
+	db, err := OpenDB(path)
+	assert.NoError(t, err)
+	defer db.Close()
+
+	return Migrations.Migrate(context.Background(), db, dryRun)
+}
+
+func TestMigrations(t *testing.T) {
+	// every fixture is a file written by the release of its schema version
+	ctx := context.Background()
+	expected := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the release notes +Release", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Tag v1", Status: 1},
+	}
+
+	tests := []struct {
+		fixture   string
+		wantSteps []schema.Step
+	}{
+		{
+			fixture: "v1",
+			wantSteps: []schema.Step{
+				{Version: 2, Description: "record the schema version in the meta bucket", Changes: "found 2 tasks and 6 changes"},
+			},
+		},
+		{
+			fixture: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			path := copyFixture(t, tt.fixture)
+
+			steps, err := migrate(t, path, true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			// the dry run leaves the file as it was
+			if tt.wantSteps != nil {
+				_, err = Open(path)
+				assert.ErrorIs(t, err, schema.ErrOutdated)
+			}
+
+			steps, err = migrate(t, path, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			repo, err := Open(path)
+			assert.NoError(t, err)
+
+			tasks, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, tasks)
+
+			changes, err := repo.SyncTasks(ctx, 3)
+			assert.NoError(t, err)
+			assert.Equal(t, models.TaskChanges{Tasks: expected[:1], Deleted: []string{"9bsv0s2hf8ng030mvab0"}, Seq: 6}, changes)
+
+			results, err := repo.SearchTasks(ctx, "release", 0)
+			assert.NoError(t, err)
+			assert.Len(t, results, 1)
+			assert.NoError(t, repo.Close())
+
+			// migrating again changes nothing
+			steps, err = migrate(t, path, false)
+			assert.NoError(t, err)
+			assert.Empty(t, steps)
+		})
+	}
+}
+
+func TestOpen_Schema(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.bolt")
+	repo, err := Open(path)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	db, err := OpenDB(path)
+	assert.NoError(t, err)
+	version, err := db.ReadVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Migrations.Current(), version)
+
+	assert.NoError(t, db.WriteVersion(ctx, Migrations.Current()+1))
+	assert.NoError(t, db.Close())
+
+	_, err = Open(path)
+	assert.ErrorIs(t, err, schema.ErrNewer)
+}
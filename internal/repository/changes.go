@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+)
+
+// changeLog records the sequence number of the last change of every task, including the deleted ones.
+// Sequence numbers increase monotonically, so the tasks changed since a sequence number can be listed.
+type changeLog struct {
+	mu      sync.Mutex
+	seq     uint64
+	changes map[string]uint64 // task id -> sequence number of its last change
+}
+
+func newChangeLog() *changeLog {
+	return &changeLog{changes: make(map[string]uint64)}
+}
+
+// record assigns the next sequence number to the change of the task.
+func (cl *changeLog) record(taskID string) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	cl.seq++
+	cl.changes[taskID] = cl.seq
+}
+
+// since returns the ids of the tasks changed after the sequence number ordered by their last change,
+// along with the current sequence number.
+func (cl *changeLog) since(seq uint64) ([]string, uint64) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	taskIDs := make([]string, 0)
+	for taskID, changed := range cl.changes {
+		if changed > seq {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+
+	sort.Slice(taskIDs, func(i, j int) bool { return cl.changes[taskIDs[i]] < cl.changes[taskIDs[j]] })
+
+	return taskIDs, cl.seq
+}
@@ -0,0 +1,17 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/repositorytest"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.TaskManager {
+		repository.ResetTasks()
+		t.Cleanup(repository.ResetTasks)
+
+		return repository.NewRepository()
+	})
+}
@@ -0,0 +1,105 @@
+package eventsourced
+
+import (
+	"sort"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+)
+
+// EventType represents the kind of change recorded by an event.
+type EventType string
+
+const (
+	// TaskCreated records the creation of a task with its name and status
+	TaskCreated EventType = "TaskCreated"
+	// TaskRenamed records the new name of a task
+	TaskRenamed EventType = "TaskRenamed"
+	// TaskStatusChanged records the new status of a task
+	TaskStatusChanged EventType = "TaskStatusChanged"
+	// TaskDeleted records the deletion of a task
+	TaskDeleted EventType = "TaskDeleted"
+)
+
+// Event represents an immutable change of a task, events are never rewritten once appended to the log.
+type Event struct {
+	Seq    uint64    `json:"seq"` // position in the log, starting at 1
+	Type   EventType `json:"type"`
+	TaskID string    `json:"taskId"`
+	Name   string    `json:"name,omitempty"` // set by TaskCreated and TaskRenamed
+	Status int       `json:"status"`         // set by TaskCreated and TaskStatusChanged
+	Time   time.Time `json:"time"`           // when the change was recorded
+}
+
+// projection is the state of the tasks derived from the events applied in order.
+type projection struct {
+	Seq     uint64                 `json:"seq"`     // sequence number of the last applied event
+	Tasks   map[string]models.Task `json:"tasks"`   // current tasks by id
+	Changed map[string]uint64      `json:"changed"` // task id -> sequence number of its last event, deleted tasks included
+
+	index *search.Index // full-text index of the task names, nil when not maintained
+}
+
+func newProjection(index *search.Index) *projection {
+	return &projection{
+		Tasks:   make(map[string]models.Task),
+		Changed: make(map[string]uint64),
+		index:   index,
+	}
+}
+
+// apply applies the event to the state.
+func (p *projection) apply(e Event) {
+	switch e.Type {
+	case TaskCreated:
+		p.Tasks[e.TaskID] = models.Task{ID: e.TaskID, Name: e.Name, Status: e.Status}
+	case TaskRenamed:
+		task := p.Tasks[e.TaskID]
+		task.Name = e.Name
+		p.Tasks[e.TaskID] = task
+	case TaskStatusChanged:
+		task := p.Tasks[e.TaskID]
+		task.Status = e.Status
+		p.Tasks[e.TaskID] = task
+	case TaskDeleted:
+		delete(p.Tasks, e.TaskID)
+	}
+
+	if p.index != nil {
+		if task, exists := p.Tasks[e.TaskID]; exists {
+			p.index.Add(task.ID, task.Name)
+		} else {
+			p.index.Remove(e.TaskID)
+		}
+	}
+
+	p.Seq = e.Seq
+	p.Changed[e.TaskID] = e.Seq
+}
+
+// tasks returns the current tasks sorted by ID.
+func (p *projection) tasks() []models.Task {
+	result := make([]models.Task, 0, len(p.Tasks))
+	for _, task := range p.Tasks {
+		result = append(result, task)
+	}
+
+	models.SortTasksByID(result)
+
+	return result
+}
+
+// changedSince returns the ids of the tasks changed after the sequence number ordered by their last change.
+func (p *projection) changedSince(seq uint64) []string {
+	taskIDs := make([]string, 0)
+	for taskID, changed := range p.Changed {
+		if changed > seq {
+			taskIDs = append(taskIDs, taskID)
+		}
+	}
+
+	sort.Slice(taskIDs, func(i, j int) bool { return p.Changed[taskIDs[i]] < p.Changed[taskIDs[j]] })
+
+	return taskIDs
+}
@@ -0,0 +1,697 @@
+// Package eventsourced implements a task manager storing the history of the tasks as an append-only log of events.
+// The current tasks are a projection of the events, snapshotted regularly so that opening the log only replays
+// the events appended since the last snapshot.
+package eventsourced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+// DefaultSnapshotInterval is the default number of events appended between two snapshots
+const DefaultSnapshotInterval = 1000
+
+// ErrClosed represents an error when the repository is used after being closed
+var ErrClosed = errors.New("event log closed")
+
+// Repository is a task manager deriving the tasks from an append-only log of events stored in a directory.
+type Repository struct {
+	mu     sync.RWMutex
+	dir    string
+	log    *os.File
+	offset int64 // byte offset following the last event of the log
+
+	state            *projection
+	index            *search.Index
+	snapshotSeq      uint64
+	snapshotInterval uint64
+
+	now       func() time.Time
+	publisher repository.Publisher
+}
+
+var _ repository.TaskManager = (*Repository)(nil)
+
+// Option configures the repository opened by Open.
+type Option func(*Repository)
+
+// WithSnapshotInterval sets the number of events appended between two snapshots, 0 disables the snapshots.
+func WithSnapshotInterval(interval uint64) Option {
+	return func(r *Repository) {
+		r.snapshotInterval = interval
+	}
+}
+
+// WithPublisher publishes a task event to the publisher on every mutation of the tasks.
+func WithPublisher(publisher repository.Publisher) Option {
+	return func(r *Repository) {
+		r.publisher = publisher
+	}
+}
+
+// WithClock sets the clock recording the time of the events.
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) {
+		r.now = now
+	}
+}
+
+// Open opens the event log of the directory, creating it when missing, and projects the current tasks
+// from the last snapshot and the events appended after it. The data directory must have the current
+// schema version, see Migrations.
+func Open(dir string, options ...Option) (*Repository, error) {
+	r := &Repository{
+		dir:              dir,
+		index:            search.NewIndex(),
+		snapshotInterval: DefaultSnapshotInterval,
+		now:              time.Now,
+	}
+	for _, option := range options {
+		option(r)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(dir); err != nil {
+		return nil, err
+	}
+
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	r.state = newProjection(r.index)
+	if snap != nil {
+		r.state.Seq, r.state.Tasks, r.state.Changed = snap.Seq, snap.Tasks, snap.Changed
+		for _, task := range r.state.Tasks {
+			r.index.Add(task.ID, task.Name)
+		}
+		r.offset = snap.Offset
+		r.snapshotSeq = snap.Seq
+	}
+
+	r.log, err = os.OpenFile(filepath.Join(dir, logFileName), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := r.log.Stat()
+	if err != nil {
+		r.log.Close()
+		return nil, err
+	}
+
+	if info.Size() < r.offset {
+		r.log.Close()
+		return nil, fmt.Errorf("%w: log ends before the snapshot", ErrCorruptLog)
+	}
+
+	offset, err := replayLog(r.log, r.offset, func(e Event) error {
+		if e.Seq != r.state.Seq+1 {
+			return fmt.Errorf("%w: event %d follows event %d", ErrCorruptLog, e.Seq, r.state.Seq)
+		}
+		r.state.apply(e)
+
+		return nil
+	})
+	if err != nil {
+		r.log.Close()
+		return nil, err
+	}
+
+	// drop the torn event left by a crash during an append
+	if err := r.log.Truncate(offset); err != nil {
+		r.log.Close()
+		return nil, err
+	}
+	r.offset = offset
+
+	return r, nil
+}
+
+// Close closes the event log.
+func (r *Repository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.log == nil {
+		return nil
+	}
+
+	err := r.log.Close()
+	r.log = nil
+
+	return err
+}
+
+// Snapshot writes the current state, so that the next Open only replays the events appended after it.
+func (r *Repository) Snapshot() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.snapshot()
+}
+
+func (r *Repository) snapshot() error {
+	if err := writeSnapshot(r.dir, &snapshot{Version: snapshotVersion, Offset: r.offset, Time: r.now().UTC(), projection: r.state}); err != nil {
+		return err
+	}
+	r.snapshotSeq = r.state.Seq
+
+	return nil
+}
+
+// append appends the events to the log and applies them to the state.
+// The events are numbered and timestamped in order, they are durable once append returns.
+func (r *Repository) append(events []Event) error {
+	if r.log == nil {
+		return ErrClosed
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	now := r.now().UTC()
+	for i := range events {
+		events[i].Seq = r.state.Seq + uint64(i) + 1
+		events[i].Time = now
+	}
+
+	data, err := encodeEvents(events)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.log.Write(data); err != nil {
+		// the events are not applied, drop what was written of them
+		r.log.Truncate(r.offset)
+		return err
+	}
+
+	if err := r.log.Sync(); err != nil {
+		r.log.Truncate(r.offset)
+		return err
+	}
+	r.offset += int64(len(data))
+
+	for _, e := range events {
+		r.state.apply(e)
+	}
+
+	if r.snapshotInterval > 0 && r.state.Seq-r.snapshotSeq >= r.snapshotInterval {
+		// the events are durable, a failed snapshot only slows down the next Open
+		r.snapshot()
+	}
+
+	return nil
+}
+
+// updateEvents returns the events changing the name and the status of the task, skipping the unchanged fields.
+func updateEvents(task models.Task, name *string, status *int) []Event {
+	var events []Event
+	if name != nil && *name != task.Name {
+		events = append(events, Event{Type: TaskRenamed, TaskID: task.ID, Name: *name})
+	}
+
+	if status != nil && *status != task.Status {
+		events = append(events, Event{Type: TaskStatusChanged, TaskID: task.ID, Status: *status})
+	}
+
+	return events
+}
+
+// GetTasks returns all tasks sorted by ID
+func (r *Repository) GetTasks(ctx context.Context) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.state.tasks(), nil
+}
+
+// ListTasks returns at most limit tasks sorted by ID following the task id after, a non-positive limit returns
+// every following task. An empty after starts from the first task.
+func (r *Repository) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	tasks, err := r.GetTasks(ctx)
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return repository.PageTasks(tasks, after, limit), nil
+}
+
+// GetTask returns a task by task id
+func (r *Repository) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return models.Task{}, ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, exists := r.state.Tasks[taskID]
+	if !exists {
+		return models.Task{}, repository.ErrTaskNotFound
+	}
+
+	return task, nil
+}
+
+// CreateTasks records the creation of the tasks with new task ids, the ids are assigned to the given tasks
+func (r *Repository) CreateTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	events := make([]Event, 0, len(tasks))
+	for i := range tasks {
+		tasks[i].NewTaskID()
+		events = append(events, Event{Type: TaskCreated, TaskID: tasks[i].ID, Name: tasks[i].Name, Status: tasks[i].Status})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.append(events); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		repository.Publish(r.publisher, models.TaskCreated, r.state.Tasks[e.TaskID])
+	}
+
+	return nil
+}
+
+// RestoreTasks records the creation of the tasks with their own task ids.
+// No task is created when one of the ids is invalid, repeated or already exists.
+func (r *Repository) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]Event, 0, len(tasks))
+	seen := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		if _, err := xid.FromString(task.ID); err != nil {
+			return repository.ErrTaskID
+		}
+
+		_, exists := r.state.Tasks[task.ID]
+		_, duplicated := seen[task.ID]
+		if exists || duplicated {
+			return repository.ErrTaskExists
+		}
+		seen[task.ID] = struct{}{}
+
+		events = append(events, Event{Type: TaskCreated, TaskID: task.ID, Name: task.Name, Status: task.Status})
+	}
+
+	if err := r.append(events); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		repository.Publish(r.publisher, models.TaskCreated, r.state.Tasks[e.TaskID])
+	}
+
+	return nil
+}
+
+// UpdateTask records the changes of the name and the status of a task
+func (r *Repository) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, exists := r.state.Tasks[taskID]
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+
+	if err := r.append(updateEvents(task, name, status)); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, task, r.state.Tasks[taskID])
+
+	return nil
+}
+
+// CompareAndSwapTask records the changes of a task to the task if it is still the previous task, keeping its id
+func (r *Repository) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.state.Tasks[previous.ID]
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+
+	if current != previous {
+		return repository.ErrTaskChanged
+	}
+
+	if err := r.append(updateEvents(current, &task.Name, &task.Status)); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, previous, r.state.Tasks[previous.ID])
+
+	return nil
+}
+
+// DeleteTask records the deletion of a task
+func (r *Repository) DeleteTask(ctx context.Context, taskID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task, exists := r.state.Tasks[taskID]
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+
+	if err := r.append([]Event{{Type: TaskDeleted, TaskID: taskID}}); err != nil {
+		return err
+	}
+	repository.Publish(r.publisher, models.TaskDeleted, task)
+
+	return nil
+}
+
+// BatchUpdateTasks records the changes of the name and the status of every task in a single append,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	seen := make(map[string]struct{}, len(taskIDs))
+	var events []Event
+	var updated []models.Task
+	for _, taskID := range taskIDs {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		if _, duplicated := seen[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		seen[taskID] = struct{}{}
+
+		task, exists := r.state.Tasks[taskID]
+		if !exists {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+			continue
+		}
+
+		events = append(events, updateEvents(task, name, status)...)
+		updated = append(updated, task)
+		results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeUpdated})
+	}
+
+	if err := r.append(events); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for _, previous := range updated {
+		repository.PublishUpdate(r.publisher, previous, r.state.Tasks[previous.ID])
+	}
+
+	return results, nil
+}
+
+// BatchDeleteTasks records the deletion of every task in a single append,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	seen := make(map[string]struct{}, len(taskIDs))
+	var events []Event
+	var deleted []models.Task
+	for _, taskID := range taskIDs {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		if _, duplicated := seen[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		seen[taskID] = struct{}{}
+
+		task, exists := r.state.Tasks[taskID]
+		if !exists {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+			continue
+		}
+
+		events = append(events, Event{Type: TaskDeleted, TaskID: taskID})
+		deleted = append(deleted, task)
+		results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeDeleted})
+	}
+
+	if err := r.append(events); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for _, task := range deleted {
+		repository.Publish(r.publisher, models.TaskDeleted, task)
+	}
+
+	return results, nil
+}
+
+// SearchTasks returns at most limit tasks whose names match the full-text query, ordered by relevance.
+// A non-positive limit returns every matching task.
+func (r *Repository) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]models.SearchResult, 0)
+	for _, hit := range r.index.Search(query) {
+		select {
+		case <-ctx.Done():
+			return make([]models.SearchResult, 0), ctx.Err()
+		default:
+		}
+
+		if limit > 0 && len(results) == limit {
+			break
+		}
+
+		task := r.state.Tasks[hit.ID]
+		results = append(results, models.SearchResult{
+			Task:      task,
+			Score:     hit.Score,
+			Highlight: search.Highlight(task.Name, hit.Terms),
+		})
+	}
+
+	return results, nil
+}
+
+// QueryTasks returns the tasks matching the query, sorted by ID.
+// Name predicates are looked up in the full-text index before the query is evaluated.
+func (r *Repository) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	pushdown := q.Pushdown()
+	switch {
+	case pushdown.IDs != nil:
+		candidates := make([]models.Task, 0, len(pushdown.IDs))
+		for _, taskID := range pushdown.IDs {
+			if task, exists := r.state.Tasks[taskID]; exists {
+				candidates = append(candidates, task)
+			}
+		}
+		models.SortTasksByID(candidates)
+
+		return q.Filter(candidates), nil
+	case len(pushdown.NameTerms) != 0:
+		candidates := make([]models.Task, 0)
+		for _, hit := range r.index.Search(strings.Join(pushdown.NameTerms, " ")) {
+			candidates = append(candidates, r.state.Tasks[hit.ID])
+		}
+		models.SortTasksByID(candidates)
+
+		return q.Filter(candidates), nil
+	}
+
+	return q.Filter(r.state.tasks()), nil
+}
+
+// SyncTasks returns the tasks created or updated and the ids of the tasks deleted after the sequence number,
+// which is the sequence number of the events. Since 0 returns every task without tombstones.
+func (r *Repository) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	select {
+	case <-ctx.Done():
+		return models.TaskChanges{}, ctx.Err()
+	default:
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if since > r.state.Seq {
+		return models.TaskChanges{}, repository.ErrSyncTokenExpired
+	}
+
+	result := models.TaskChanges{Tasks: make([]models.Task, 0), Deleted: make([]string, 0), Seq: r.state.Seq}
+	for _, taskID := range r.state.changedSince(since) {
+		task, exists := r.state.Tasks[taskID]
+		if !exists {
+			if since > 0 {
+				result.Deleted = append(result.Deleted, taskID)
+			}
+			continue
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
+
+// Events returns the events of the log in order.
+func (r *Repository) Events(ctx context.Context) ([]Event, error) {
+	events := make([]Event, 0)
+	err := r.replay(ctx, func(e Event) bool {
+		events = append(events, e)
+		return true
+	})
+
+	return events, err
+}
+
+// TasksAt rebuilds the tasks, sorted by ID, as they were right after the event with the sequence number.
+func (r *Repository) TasksAt(ctx context.Context, seq uint64) ([]models.Task, error) {
+	state := newProjection(nil)
+	err := r.replay(ctx, func(e Event) bool {
+		if e.Seq > seq {
+			return false
+		}
+		state.apply(e)
+
+		return true
+	})
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return state.tasks(), nil
+}
+
+// TasksAsOf rebuilds the tasks, sorted by ID, as they were at the given time.
+func (r *Repository) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	state := newProjection(nil)
+	err := r.replay(ctx, func(e Event) bool {
+		if e.Time.After(at) {
+			return false
+		}
+		state.apply(e)
+
+		return true
+	})
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return state.tasks(), nil
+}
+
+// replay calls fn for the events of the log in order, until fn returns false.
+func (r *Repository) replay(ctx context.Context, fn func(Event) bool) error {
+	r.mu.RLock()
+	closed, last := r.log == nil, r.state.Seq
+	r.mu.RUnlock()
+
+	if closed {
+		return ErrClosed
+	}
+
+	// read with a separate handle, the events already appended are immutable
+	f, err := os.Open(filepath.Join(r.dir, logFileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	errStop := errors.New("stop replay")
+	_, err = replayLog(f, 0, func(e Event) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		if e.Seq > last || !fn(e) {
+			return errStop
+		}
+
+		return nil
+	})
+	if errors.Is(err, errStop) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,385 @@
+package eventsourced
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock returns a clock advancing by a minute on every call.
+func testClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+type recordingPublisher struct {
+	events []models.TaskEventType
+}
+
+func (r *recordingPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	r.events = append(r.events, eventType)
+	return models.TaskEvent{ID: uint64(len(r.events)), Type: eventType, Task: task}
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.Type)
+	}
+
+	return types
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	publisher := &recordingPublisher{}
+	repo, err := Open(t.TempDir(), WithPublisher(publisher))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Write report"}, {Name: "Review report"}}))
+
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+	write, review := tasks[0], tasks[1]
+	assert.Equal(t, "Write report", write.Name)
+
+	name, status := "Write the report", 1
+	assert.NoError(t, repo.UpdateTask(ctx, write.ID, &name, &status))
+	assert.NoError(t, repo.UpdateTask(ctx, write.ID, &name, &status))
+	assert.ErrorIs(t, repo.UpdateTask(ctx, "unknown", &name, nil), repository.ErrTaskNotFound)
+
+	got, err := repo.GetTask(ctx, write.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Task{ID: write.ID, Name: name, Status: status}, got)
+
+	assert.NoError(t, repo.DeleteTask(ctx, review.ID))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, review.ID), repository.ErrTaskNotFound)
+	_, err = repo.GetTask(ctx, review.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	events, err := repo.Events(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{TaskCreated, TaskCreated, TaskRenamed, TaskStatusChanged, TaskDeleted}, eventTypes(events))
+	for i, e := range events {
+		assert.Equal(t, uint64(i+1), e.Seq)
+	}
+
+	assert.Equal(t, []models.TaskEventType{
+		models.TaskCreated, models.TaskCreated,
+		models.TaskUpdated, models.TaskCompleted,
+		models.TaskUpdated,
+		models.TaskDeleted,
+	}, publisher.events)
+}
+
+func TestRepository_Batch(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(t.TempDir())
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}))
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+
+	status := 1
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, "unknown", tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+
+	results, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+}
+
+func TestRepository_RestoreTasks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+
+	created := []models.Task{{Name: "Task 1"}, {Name: "Task 2", Status: 1}}
+	assert.NoError(t, repo.CreateTasks(ctx, created))
+	assert.NotEmpty(t, created[0].ID)
+	assert.NoError(t, repo.DeleteTask(ctx, created[1].ID))
+
+	assert.ErrorIs(t, repo.RestoreTasks(ctx, []models.Task{created[1], created[0]}), repository.ErrTaskExists)
+	assert.ErrorIs(t, repo.RestoreTasks(ctx, []models.Task{{ID: "1", Name: "Task 3"}}), repository.ErrTaskID)
+	assert.NoError(t, repo.RestoreTasks(ctx, []models.Task{created[1]}))
+	assert.NoError(t, repo.Close())
+
+	repo, err = Open(dir)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, created, tasks)
+}
+
+func TestRepository_SearchAndQuery(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(t.TempDir())
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Deploy api"}, {Name: "Deploy web", Status: 1}, {Name: "Write docs"}}))
+
+	results, err := repo.SearchTasks(ctx, "deploy", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = repo.SearchTasks(ctx, "deploy", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	q, err := query.Parse("open AND name:deploy")
+	assert.NoError(t, err)
+	tasks, err := repo.QueryTasks(ctx, q)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "Deploy api", tasks[0].Name)
+
+	name := "Release api"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+
+	tasks, err = repo.QueryTasks(ctx, q)
+	assert.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	q, err = query.Parse("open")
+	assert.NoError(t, err)
+	tasks, err = repo.QueryTasks(ctx, q)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 2)
+}
+
+func TestRepository_SyncTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(t.TempDir())
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}))
+	full, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, full.Tasks, 2)
+	assert.Equal(t, uint64(2), full.Seq)
+
+	assert.NoError(t, repo.DeleteTask(ctx, full.Tasks[0].ID))
+	name := "Renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, full.Tasks[1].ID, &name, nil))
+
+	delta, err := repo.SyncTasks(ctx, full.Seq)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: full.Tasks[1].ID, Name: name}},
+		Deleted: []string{full.Tasks[0].ID},
+		Seq:     4,
+	}, delta)
+
+	_, err = repo.SyncTasks(ctx, 5)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+}
+
+func TestRepository_Reopen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name             string
+		snapshotInterval uint64
+		wantSnapshot     bool
+	}{
+		{name: "replay every event", snapshotInterval: 0, wantSnapshot: false},
+		{name: "replay the events after the snapshot", snapshotInterval: 3, wantSnapshot: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(dir, tt.name)
+			repo, err := Open(dir, WithSnapshotInterval(tt.snapshotInterval))
+			assert.NoError(t, err)
+
+			assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}))
+			tasks, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			status := 1
+			assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status))
+			assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+
+			want, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			wantChanges, err := repo.SyncTasks(ctx, 2)
+			assert.NoError(t, err)
+			assert.NoError(t, repo.Close())
+
+			_, err = os.Stat(filepath.Join(dir, snapshotFileName))
+			assert.Equal(t, tt.wantSnapshot, err == nil)
+
+			reopened, err := Open(dir, WithSnapshotInterval(tt.snapshotInterval))
+			assert.NoError(t, err)
+			defer reopened.Close()
+
+			got, err := reopened.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			gotChanges, err := reopened.SyncTasks(ctx, 2)
+			assert.NoError(t, err)
+			assert.Equal(t, wantChanges, gotChanges)
+
+			results, err := reopened.SearchTasks(ctx, "task", 0)
+			assert.NoError(t, err)
+			assert.Len(t, results, 1)
+
+			// the sequence continues after the replayed events
+			assert.NoError(t, reopened.CreateTasks(ctx, []models.Task{{Name: "Task 3"}}))
+			events, err := reopened.Events(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(5), events[len(events)-1].Seq)
+		})
+	}
+}
+
+func TestRepository_TornAppend(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}}))
+	assert.NoError(t, repo.Close())
+
+	logPath := filepath.Join(dir, logFileName)
+	info, err := os.Stat(logPath)
+	assert.NoError(t, err)
+
+	// a crash in the middle of an append leaves a partial line
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0o644)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"seq":2,"type":"TaskRen`)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	repo, err = Open(dir)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	truncated, err := os.Stat(logPath)
+	assert.NoError(t, err)
+	assert.Equal(t, info.Size(), truncated.Size())
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 2"}}))
+	events, err := repo.Events(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{TaskCreated, TaskCreated}, eventTypes(events))
+}
+
+func TestRepository_CorruptLog(t *testing.T) {
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{
+			name: "undecodable event",
+			log:  "{\"seq\":1,\"type\":\"TaskCreated\",\"taskId\":\"a\",\"name\":\"Task\",\"status\":0}\nnot json\n",
+		},
+		{
+			name: "event out of sequence",
+			log:  "{\"seq\":1,\"type\":\"TaskCreated\",\"taskId\":\"a\",\"name\":\"Task\",\"status\":0}\n{\"seq\":3,\"type\":\"TaskDeleted\",\"taskId\":\"a\",\"status\":0}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assert.NoError(t, schema.WriteVersion(dir, Migrations.Current()))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, logFileName), []byte(tt.log), 0o644))
+
+			_, err := Open(dir)
+			assert.ErrorIs(t, err, ErrCorruptLog)
+		})
+	}
+}
+
+func TestRepository_History(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	repo, err := Open(t.TempDir(), WithClock(testClock(start)))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	// every append is recorded a minute after the previous one
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}}))
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	taskID := tasks[0].ID
+
+	name := "Renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, taskID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, taskID))
+
+	tests := []struct {
+		name string
+		at   time.Time
+		seq  uint64
+		want []models.Task
+	}{
+		{name: "before the creation", at: start, seq: 0, want: []models.Task{}},
+		{name: "after the creation", at: start.Add(time.Minute), seq: 1, want: []models.Task{{ID: taskID, Name: "Task 1"}}},
+		{name: "after the rename", at: start.Add(2*time.Minute + time.Second), seq: 2, want: []models.Task{{ID: taskID, Name: name}}},
+		{name: "after the deletion", at: start.Add(time.Hour), seq: 3, want: []models.Task{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TasksAsOf(ctx, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			got, err = repo.TasksAt(ctx, tt.seq)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_Closed(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+	assert.NoError(t, repo.Close())
+
+	assert.ErrorIs(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}}), ErrClosed)
+	_, err = repo.Events(ctx)
+	assert.ErrorIs(t, err, ErrClosed)
+}
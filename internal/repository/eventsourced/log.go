@@ -0,0 +1,147 @@
+package eventsourced
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	logFileName      = "events.log"
+	snapshotFileName = "snapshot.json"
+
+	// snapshotVersion is the version of the snapshot format
+	snapshotVersion = 1
+)
+
+var (
+	// ErrCorruptLog represents an error when an event of the log cannot be decoded or is out of sequence
+	ErrCorruptLog = errors.New("corrupt event log")
+	// ErrUnsupportedSnapshot represents an error when the snapshot was written by an unknown version
+	ErrUnsupportedSnapshot = errors.New("unsupported snapshot version")
+)
+
+// snapshot represents the state of the tasks after an event, along with the position of the next event in the log.
+type snapshot struct {
+	Version int       `json:"version"`
+	Offset  int64     `json:"offset"` // byte offset of the event following the snapshot in the log
+	Time    time.Time `json:"time"`   // when the snapshot was taken
+	*projection
+}
+
+// replayLog decodes the events of the log starting at the byte offset and calls fn for each of them.
+// It returns the offset following the last complete event: a torn last line, left by a crash during an append,
+// is ignored so that the caller can truncate it.
+func replayLog(f *os.File, offset int64, fn func(Event) error) (int64, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// an empty or a torn last line
+			return offset, nil
+		}
+		if err != nil {
+			return offset, err
+		}
+
+		var event Event
+		if err := json.Unmarshal(line, &event); err != nil {
+			return offset, fmt.Errorf("%w: offset %d: %v", ErrCorruptLog, offset, err)
+		}
+
+		if err := fn(event); err != nil {
+			return offset, err
+		}
+		offset += int64(len(line))
+	}
+}
+
+// encodeEvents encodes the events as lines of the log.
+func encodeEvents(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for i := range events {
+		if err := encoder.Encode(&events[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// readSnapshot reads the snapshot of the directory, it returns nil when there is none.
+func readSnapshot(dir string) (*snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &snapshot{projection: newProjection(nil)}
+	if err := json.Unmarshal(data, snap); err != nil {
+		return nil, err
+	}
+
+	if snap.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSnapshot, snap.Version)
+	}
+
+	return snap, nil
+}
+
+// writeSnapshot atomically replaces the snapshot of the directory.
+func writeSnapshot(dir string, snap *snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, snapshotFileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(dir, snapshotFileName)); err != nil {
+		return err
+	}
+
+	return syncDir(dir)
+}
+
+// syncDir flushes the entries of the directory, so that a created or renamed file survives a crash.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
@@ -0,0 +1,66 @@
+package eventsourced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brionac626/taskManager/internal/schema"
+)
+
+// Migrations are the schema migrations of the data directories of the event log, run by the server on startup.
+//
+// Version 1 is the data directory without schema file. Version 2 records the schema version.
+var Migrations = schema.NewRegistry(
+	schema.Migration[schema.Dir]{
+		Version:     2,
+		Description: "record the schema version of the data directory",
+		Apply:       verifyEvents,
+	},
+)
+
+// checkSchema records the current schema version in a new data directory, and returns an error
+// unless an existing data directory has the current version.
+func checkSchema(dir string) error {
+	version, err := schema.ReadVersion(dir)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		if _, err := os.Stat(filepath.Join(dir, logFileName)); errors.Is(err, os.ErrNotExist) {
+			return schema.WriteVersion(dir, Migrations.Current())
+		}
+	}
+
+	return Migrations.Check(context.Background(), schema.Dir(dir))
+}
+
+// verifyEvents verifies that every event of the log decodes in sequence, the migration to version 2
+// changes nothing else.
+func verifyEvents(_ context.Context, dir schema.Dir, _ bool) (string, error) {
+	f, err := os.Open(filepath.Join(string(dir), logFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return "no event log", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var seq uint64
+	if _, err := replayLog(f, 0, func(e Event) error {
+		if e.Seq != seq+1 {
+			return fmt.Errorf("%w: event %d follows event %d", ErrCorruptLog, e.Seq, seq)
+		}
+		seq = e.Seq
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("verified %d events", seq), nil
+}
@@ -0,0 +1,235 @@
+package eventsourced
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// copyFixture copies the data directory of a fixture to a temporary directory.
+func copyFixture(t *testing.T, name string) string {
+	dir := t.TempDir()
+	entries, err := os.ReadDir(filepath.Join("testdata", "schema", name))
+	assert.NoError(t, err)
+
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "schema", name, entry.Name()))
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, entry.Name()), data, 0o644))
+	}
+
+	return dir
+}
+
+func TestMigrations(t *testing.T) {
+	// every fixture is a data directory written by the release of its schema version
+	expected := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the release notes +Release", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Tag v1", Status: 1},
+	}
+
+	tests := []struct {
+		fixture   string
+		wantSteps []schema.Step
+	}{
+		{
+			fixture: "v1",
+			wantSteps: []schema.Step{
+				{Version: 2, Description: "record the schema version of the data directory", Changes: "verified 6 events"},
+			},
+		},
+		{
+			fixture: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			dir := copyFixture(t, tt.fixture)
+
+			steps, err := Migrations.Migrate(context.Background(), schema.Dir(dir), true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			// the dry run leaves the data directory as it was
+			if tt.wantSteps != nil {
+				_, err = Open(dir)
+				assert.ErrorIs(t, err, schema.ErrOutdated)
+			}
+
+			steps, err = Migrations.Migrate(context.Background(), schema.Dir(dir), false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			repo, err := Open(dir)
+			assert.NoError(t, err)
+			defer repo.Close()
+
+			tasks, err := repo.GetTasks(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, tasks)
+
+			// migrating again changes nothing
+			steps, err = Migrations.Migrate(context.Background(), schema.Dir(dir), false)
+			assert.NoError(t, err)
+			assert.Empty(t, steps)
+		})
+	}
+}
+
+// tagNames is a migration rewriting the data of the directory for the tests: it tags the name of every
+// event with +Legacy and drops the snapshot, which holds the former names.
+func tagNames(_ context.Context, store schema.Dir, dryRun bool) (string, error) {
+	dir := string(store)
+	f, err := os.Open(filepath.Join(dir, logFileName))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	var events []Event
+	var tagged int
+	if _, err := replayLog(f, 0, func(e Event) error {
+		if e.Name != "" {
+			e.Name += " +Legacy"
+			tagged++
+		}
+		events = append(events, e)
+
+		return nil
+	}); err != nil {
+		return "", err
+	}
+
+	changes := fmt.Sprintf("tagged %d events", tagged)
+	if _, err := os.Stat(filepath.Join(dir, snapshotFileName)); err == nil {
+		changes += ", dropped the snapshot"
+	}
+	if dryRun {
+		return changes, nil
+	}
+
+	data, err := encodeEvents(events)
+	if err != nil {
+		return "", err
+	}
+
+	tmp := filepath.Join(dir, logFileName+".tmp")
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, logFileName)); err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(filepath.Join(dir, snapshotFileName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	return changes, nil
+}
+
+func TestMigrations_Rewrite(t *testing.T) {
+	// the registry of the release, followed by a migration rewriting the events
+	registered := Migrations
+	Migrations = schema.NewRegistry(
+		schema.Migration[schema.Dir]{Version: 2, Description: "record the schema version of the data directory", Apply: verifyEvents},
+		schema.Migration[schema.Dir]{Version: 3, Description: "tag the task names", Apply: tagNames},
+	)
+	t.Cleanup(func() { Migrations = registered })
+
+	expected := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the release notes +Release +Legacy", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Tag v1 +Legacy", Status: 1},
+	}
+
+	tests := []struct {
+		fixture   string
+		wantSteps []schema.Step
+	}{
+		{
+			fixture: "v1",
+			wantSteps: []schema.Step{
+				{Version: 2, Description: "record the schema version of the data directory", Changes: "verified 6 events"},
+				{Version: 3, Description: "tag the task names", Changes: "tagged 4 events, dropped the snapshot"},
+			},
+		},
+		{
+			fixture: "v2",
+			wantSteps: []schema.Step{
+				{Version: 3, Description: "tag the task names", Changes: "tagged 4 events, dropped the snapshot"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			dir := copyFixture(t, tt.fixture)
+			before := readDir(t, dir)
+
+			steps, err := Migrations.Migrate(context.Background(), schema.Dir(dir), true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			// the dry run leaves every file of the data directory as it was
+			assert.Equal(t, before, readDir(t, dir))
+
+			steps, err = Migrations.Migrate(context.Background(), schema.Dir(dir), false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			version, err := schema.ReadVersion(dir)
+			assert.NoError(t, err)
+			assert.Equal(t, 3, version)
+			assert.NoFileExists(t, filepath.Join(dir, snapshotFileName))
+
+			// the tasks are projected from the rewritten events
+			repo, err := Open(dir)
+			assert.NoError(t, err)
+			defer repo.Close()
+
+			tasks, err := repo.GetTasks(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, expected, tasks)
+
+			steps, err = Migrations.Migrate(context.Background(), schema.Dir(dir), false)
+			assert.NoError(t, err)
+			assert.Empty(t, steps)
+		})
+	}
+}
+
+// readDir returns the content of the files of the directory by name.
+func readDir(t *testing.T, dir string) map[string]string {
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	files := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		assert.NoError(t, err)
+		files[entry.Name()] = string(data)
+	}
+
+	return files
+}
+
+func TestOpen_Schema(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := Open(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	version, err := schema.ReadVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, Migrations.Current(), version)
+
+	assert.NoError(t, schema.WriteVersion(dir, Migrations.Current()+1))
+	_, err = Open(dir)
+	assert.ErrorIs(t, err, schema.ErrNewer)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "sync"
+
+// ResetTasks empties the in-memory storage shared by the task repositories, the history is kept by every repository.
+func ResetTasks() {
+	manager = sync.Map{}
+	index.Reset()
+	changes = newChangeLog()
+}
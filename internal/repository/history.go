@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// historyPruneInterval is the minimum time between two prunings of the whole history
+const historyPruneInterval = time.Minute
+
+// taskVersion is the state of a task from the time it was recorded until the next version of the task.
+type taskVersion struct {
+	task    models.Task
+	deleted bool
+	time    time.Time
+}
+
+// taskHistory records the versions of every task, so that the tasks can be rebuilt as they were at a point in time.
+// Versions superseded more than the retention ago are pruned, the tasks before the horizon are unknown.
+type taskHistory struct {
+	retention time.Duration    // how long the superseded versions are kept, 0 keeps them forever
+	now       func() time.Time // clock recording the time of the versions
+
+	mu       sync.Mutex
+	versions map[string][]taskVersion // task id -> versions ordered by time
+	horizon  time.Time                // earliest time the tasks can be rebuilt at
+	pruned   time.Time                // when the whole history was last pruned
+}
+
+func newTaskHistory(retention time.Duration, now func() time.Time) *taskHistory {
+	return &taskHistory{retention: retention, now: now, versions: make(map[string][]taskVersion)}
+}
+
+// record appends a version of the task at the current time, deleted records its deletion, and prunes the versions
+// superseded before the retention period. The time is read while the history is locked, so that the versions
+// are appended in time order.
+func (h *taskHistory) record(task models.Task, deleted bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	at := h.now()
+	h.versions[task.ID] = append(h.versions[task.ID], taskVersion{task: task, deleted: deleted, time: at})
+	if h.retention > 0 {
+		h.prune(at.Add(-h.retention), at)
+	}
+}
+
+// prune drops the versions superseded before the cutoff, and the tasks deleted before it.
+// The whole history is pruned at most once per historyPruneInterval. The history must be locked.
+func (h *taskHistory) prune(cutoff, now time.Time) {
+	if now.Sub(h.pruned) < historyPruneInterval || !cutoff.After(h.horizon) {
+		return
+	}
+	h.pruned = now
+	h.horizon = cutoff
+
+	for taskID, versions := range h.versions {
+		// the last version recorded at or before the cutoff is still the state at the cutoff
+		first := 0
+		for i := range versions {
+			if versions[i].time.After(cutoff) {
+				break
+			}
+			first = i
+		}
+
+		versions = versions[first:]
+		if len(versions) == 1 && versions[0].deleted && !versions[0].time.After(cutoff) {
+			delete(h.versions, taskID)
+			continue
+		}
+		h.versions[taskID] = append([]taskVersion(nil), versions...)
+	}
+}
+
+// asOf returns the tasks, sorted by ID, as they were at the given time.
+func (h *taskHistory) asOf(at time.Time) ([]models.Task, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if at.Before(h.horizon) {
+		return make([]models.Task, 0), ErrHistoryExpired
+	}
+
+	result := make([]models.Task, 0)
+	for _, versions := range h.versions {
+		var current *taskVersion
+		for i := range versions {
+			if versions[i].time.After(at) {
+				break
+			}
+			current = &versions[i]
+		}
+
+		if current != nil && !current.deleted {
+			result = append(result, current.task)
+		}
+	}
+
+	models.SortTasksByID(result)
+
+	return result, nil
+}
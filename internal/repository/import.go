@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+// ImportTasks imports the tasks of a file into the task manager: the tasks without id are created, the tasks
+// with the id of an existing task update the task, and the other tasks are restored with their id, so that an
+// export can be edited and imported back. The ids of the created tasks are assigned to the given tasks.
+//
+// Every task is classified before anything is written: it returns ErrTaskID when an id is not valid and
+// ErrTaskExists when an id is repeated. When a write fails, the tasks already written are reverted.
+func ImportTasks(ctx context.Context, repo TaskManager, tasks []models.Task) error {
+	var created, restored []models.Task
+	var createdIndexes []int
+	previous := make(map[string]models.Task) // existing tasks updated by the import
+	var updated []models.Task
+	seen := make(map[string]bool)
+	for i, task := range tasks {
+		if task.ID == "" {
+			created = append(created, task)
+			createdIndexes = append(createdIndexes, i)
+			continue
+		}
+
+		if _, err := xid.FromString(task.ID); err != nil {
+			return ErrTaskID
+		}
+		if seen[task.ID] {
+			return ErrTaskExists
+		}
+		seen[task.ID] = true
+
+		current, err := repo.GetTask(ctx, task.ID)
+		if errors.Is(err, ErrTaskNotFound) {
+			restored = append(restored, task)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		previous[task.ID] = current
+		updated = append(updated, task)
+	}
+
+	// the writes most likely to fail come first, each of them writes all of its tasks or none
+	var written importWrites
+	if len(restored) != 0 {
+		if err := repo.RestoreTasks(ctx, restored); err != nil {
+			return err
+		}
+		written.restored = restored
+	}
+
+	if len(created) != 0 {
+		if err := repo.CreateTasks(ctx, created); err != nil {
+			return errors.Join(err, written.revert(ctx, repo))
+		}
+		written.created = created
+	}
+
+	for _, task := range updated {
+		if err := repo.UpdateTask(ctx, task.ID, &task.Name, &task.Status); err != nil {
+			return errors.Join(err, written.revert(ctx, repo))
+		}
+		written.updated = append(written.updated, previous[task.ID])
+	}
+
+	for j, i := range createdIndexes {
+		tasks[i].ID = created[j].ID
+	}
+
+	return nil
+}
+
+// importWrites are the tasks written by an import so far.
+type importWrites struct {
+	restored []models.Task
+	created  []models.Task
+	updated  []models.Task // the tasks as they were before the import
+}
+
+// revert deletes the restored and created tasks and updates the updated tasks back, even when the context is done.
+func (w importWrites) revert(ctx context.Context, repo TaskManager) error {
+	ctx = context.WithoutCancel(ctx)
+
+	var errs []error
+	for _, task := range w.updated {
+		errs = append(errs, repo.UpdateTask(ctx, task.ID, &task.Name, &task.Status))
+	}
+
+	for _, tasks := range [][]models.Task{w.created, w.restored} {
+		taskIDs := make([]string, 0, len(tasks))
+		for _, task := range tasks {
+			taskIDs = append(taskIDs, task.ID)
+		}
+		if len(taskIDs) == 0 {
+			continue
+		}
+
+		if _, err := repo.BatchDeleteTasks(ctx, taskIDs); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportTasks(t *testing.T) {
+	ResetTasks()
+	t.Cleanup(ResetTasks)
+
+	repo := NewRepository()
+	ctx := context.Background()
+
+	existing := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, existing))
+
+	tasks := []models.Task{
+		{ID: existing[0].ID, Name: "Task 1 renamed", Status: 1},
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 2"},
+		{Name: "Task 3"},
+	}
+	assert.NoError(t, ImportTasks(ctx, repo, tasks))
+	assert.NotEmpty(t, tasks[2].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, tasks, got)
+
+	// the tasks imported twice update the same tasks
+	assert.NoError(t, ImportTasks(ctx, repo, tasks))
+	got, err = repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, 3)
+
+	before, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+
+	// rejected before the existing task is updated
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{
+			name:    "repeated id",
+			tasks:   []models.Task{{ID: tasks[0].ID, Name: "Task 1 updated"}, {ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 4"}, {ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 4"}},
+			wantErr: ErrTaskExists,
+		},
+		{
+			name:    "repeated existing id",
+			tasks:   []models.Task{{ID: tasks[0].ID, Name: "Task 1 updated"}, {ID: tasks[0].ID, Name: "Task 1 updated again"}},
+			wantErr: ErrTaskExists,
+		},
+		{
+			name:    "invalid id",
+			tasks:   []models.Task{{ID: tasks[0].ID, Name: "Task 1 updated"}, {ID: "1", Name: "Task 4"}},
+			wantErr: ErrTaskID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, ImportTasks(ctx, repo, tt.tasks), tt.wantErr)
+
+			got, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, before, got)
+		})
+	}
+}
+
+// failingUpdates fails every update of the task manager
+type failingUpdates struct {
+	TaskManager
+}
+
+var errUpdateFailed = errors.New("update failed")
+
+func (f failingUpdates) UpdateTask(context.Context, string, *string, *int) error {
+	return errUpdateFailed
+}
+
+func TestImportTasks_Revert(t *testing.T) {
+	ResetTasks()
+	t.Cleanup(ResetTasks)
+
+	repo := NewRepository()
+	ctx := context.Background()
+
+	existing := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, existing))
+
+	// the update comes last, the restored and created tasks are deleted when it fails
+	tasks := []models.Task{
+		{ID: existing[0].ID, Name: "Task 1 renamed"},
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 2"},
+		{Name: "Task 3"},
+	}
+	assert.ErrorIs(t, ImportTasks(ctx, failingUpdates{repo}, tasks), errUpdateFailed)
+	assert.Empty(t, tasks[2].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, existing, got)
+}
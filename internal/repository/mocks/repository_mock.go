@@ -12,7 +12,9 @@ package mock_repository
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
+	query "github.com/brionac626/taskManager/internal/query"
 	models "github.com/brionac626/taskManager/models"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +43,50 @@ func (m *MockTaskManager) EXPECT() *MockTaskManagerMockRecorder {
 	return m.recorder
 }
 
+// BatchDeleteTasks mocks base method.
+func (m *MockTaskManager) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchDeleteTasks", ctx, taskIDs)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchDeleteTasks indicates an expected call of BatchDeleteTasks.
+func (mr *MockTaskManagerMockRecorder) BatchDeleteTasks(ctx, taskIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchDeleteTasks", reflect.TypeOf((*MockTaskManager)(nil).BatchDeleteTasks), ctx, taskIDs)
+}
+
+// BatchUpdateTasks mocks base method.
+func (m *MockTaskManager) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdateTasks", ctx, taskIDs, name, status)
+	ret0, _ := ret[0].([]models.BatchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchUpdateTasks indicates an expected call of BatchUpdateTasks.
+func (mr *MockTaskManagerMockRecorder) BatchUpdateTasks(ctx, taskIDs, name, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdateTasks", reflect.TypeOf((*MockTaskManager)(nil).BatchUpdateTasks), ctx, taskIDs, name, status)
+}
+
+// CompareAndSwapTask mocks base method.
+func (m *MockTaskManager) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompareAndSwapTask", ctx, previous, task)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompareAndSwapTask indicates an expected call of CompareAndSwapTask.
+func (mr *MockTaskManagerMockRecorder) CompareAndSwapTask(ctx, previous, task any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompareAndSwapTask", reflect.TypeOf((*MockTaskManager)(nil).CompareAndSwapTask), ctx, previous, task)
+}
+
 // CreateTasks mocks base method.
 func (m *MockTaskManager) CreateTasks(ctx context.Context, tasks []models.Task) error {
 	m.ctrl.T.Helper()
@@ -69,6 +115,21 @@ func (mr *MockTaskManagerMockRecorder) DeleteTask(ctx, taskID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockTaskManager)(nil).DeleteTask), ctx, taskID)
 }
 
+// GetTask mocks base method.
+func (m *MockTaskManager) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTask", ctx, taskID)
+	ret0, _ := ret[0].(models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTask indicates an expected call of GetTask.
+func (mr *MockTaskManagerMockRecorder) GetTask(ctx, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockTaskManager)(nil).GetTask), ctx, taskID)
+}
+
 // GetTasks mocks base method.
 func (m *MockTaskManager) GetTasks(ctx context.Context) ([]models.Task, error) {
 	m.ctrl.T.Helper()
@@ -84,6 +145,95 @@ func (mr *MockTaskManagerMockRecorder) GetTasks(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockTaskManager)(nil).GetTasks), ctx)
 }
 
+// ListTasks mocks base method.
+func (m *MockTaskManager) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTasks", ctx, after, limit)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTasks indicates an expected call of ListTasks.
+func (mr *MockTaskManagerMockRecorder) ListTasks(ctx, after, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTasks", reflect.TypeOf((*MockTaskManager)(nil).ListTasks), ctx, after, limit)
+}
+
+// QueryTasks mocks base method.
+func (m *MockTaskManager) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryTasks", ctx, q)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryTasks indicates an expected call of QueryTasks.
+func (mr *MockTaskManagerMockRecorder) QueryTasks(ctx, q any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryTasks", reflect.TypeOf((*MockTaskManager)(nil).QueryTasks), ctx, q)
+}
+
+// RestoreTasks mocks base method.
+func (m *MockTaskManager) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreTasks", ctx, tasks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreTasks indicates an expected call of RestoreTasks.
+func (mr *MockTaskManagerMockRecorder) RestoreTasks(ctx, tasks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreTasks", reflect.TypeOf((*MockTaskManager)(nil).RestoreTasks), ctx, tasks)
+}
+
+// SearchTasks mocks base method.
+func (m *MockTaskManager) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchTasks", ctx, query, limit)
+	ret0, _ := ret[0].([]models.SearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTasks indicates an expected call of SearchTasks.
+func (mr *MockTaskManagerMockRecorder) SearchTasks(ctx, query, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTasks", reflect.TypeOf((*MockTaskManager)(nil).SearchTasks), ctx, query, limit)
+}
+
+// SyncTasks mocks base method.
+func (m *MockTaskManager) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SyncTasks", ctx, since)
+	ret0, _ := ret[0].(models.TaskChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SyncTasks indicates an expected call of SyncTasks.
+func (mr *MockTaskManagerMockRecorder) SyncTasks(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncTasks", reflect.TypeOf((*MockTaskManager)(nil).SyncTasks), ctx, since)
+}
+
+// TasksAsOf mocks base method.
+func (m *MockTaskManager) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TasksAsOf", ctx, at)
+	ret0, _ := ret[0].([]models.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TasksAsOf indicates an expected call of TasksAsOf.
+func (mr *MockTaskManagerMockRecorder) TasksAsOf(ctx, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TasksAsOf", reflect.TypeOf((*MockTaskManager)(nil).TasksAsOf), ctx, at)
+}
+
 // UpdateTask mocks base method.
 func (m *MockTaskManager) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
 	m.ctrl.T.Helper()
@@ -97,3 +247,307 @@ func (mr *MockTaskManagerMockRecorder) UpdateTask(ctx, taskID, name, status any)
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockTaskManager)(nil).UpdateTask), ctx, taskID, name, status)
 }
+
+// MockPublisher is a mock of Publisher interface.
+type MockPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPublisherMockRecorder
+	isgomock struct{}
+}
+
+// MockPublisherMockRecorder is the mock recorder for MockPublisher.
+type MockPublisherMockRecorder struct {
+	mock *MockPublisher
+}
+
+// NewMockPublisher creates a new mock instance.
+func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
+	mock := &MockPublisher{ctrl: ctrl}
+	mock.recorder = &MockPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", eventType, task)
+	ret0, _ := ret[0].(models.TaskEvent)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockPublisherMockRecorder) Publish(eventType, task any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), eventType, task)
+}
+
+// MockViewManager is a mock of ViewManager interface.
+type MockViewManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockViewManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockViewManagerMockRecorder is the mock recorder for MockViewManager.
+type MockViewManagerMockRecorder struct {
+	mock *MockViewManager
+}
+
+// NewMockViewManager creates a new mock instance.
+func NewMockViewManager(ctrl *gomock.Controller) *MockViewManager {
+	mock := &MockViewManager{ctrl: ctrl}
+	mock.recorder = &MockViewManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockViewManager) EXPECT() *MockViewManagerMockRecorder {
+	return m.recorder
+}
+
+// CreateView mocks base method.
+func (m *MockViewManager) CreateView(ctx context.Context, view models.View) (models.View, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateView", ctx, view)
+	ret0, _ := ret[0].(models.View)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateView indicates an expected call of CreateView.
+func (mr *MockViewManagerMockRecorder) CreateView(ctx, view any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateView", reflect.TypeOf((*MockViewManager)(nil).CreateView), ctx, view)
+}
+
+// DeleteView mocks base method.
+func (m *MockViewManager) DeleteView(ctx context.Context, viewID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteView", ctx, viewID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteView indicates an expected call of DeleteView.
+func (mr *MockViewManagerMockRecorder) DeleteView(ctx, viewID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteView", reflect.TypeOf((*MockViewManager)(nil).DeleteView), ctx, viewID)
+}
+
+// GetView mocks base method.
+func (m *MockViewManager) GetView(ctx context.Context, viewID string) (models.View, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetView", ctx, viewID)
+	ret0, _ := ret[0].(models.View)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetView indicates an expected call of GetView.
+func (mr *MockViewManagerMockRecorder) GetView(ctx, viewID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetView", reflect.TypeOf((*MockViewManager)(nil).GetView), ctx, viewID)
+}
+
+// GetViews mocks base method.
+func (m *MockViewManager) GetViews(ctx context.Context, owner string) ([]models.View, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetViews", ctx, owner)
+	ret0, _ := ret[0].([]models.View)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetViews indicates an expected call of GetViews.
+func (mr *MockViewManagerMockRecorder) GetViews(ctx, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetViews", reflect.TypeOf((*MockViewManager)(nil).GetViews), ctx, owner)
+}
+
+// ListViews mocks base method.
+func (m *MockViewManager) ListViews(ctx context.Context) ([]models.View, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListViews", ctx)
+	ret0, _ := ret[0].([]models.View)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListViews indicates an expected call of ListViews.
+func (mr *MockViewManagerMockRecorder) ListViews(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListViews", reflect.TypeOf((*MockViewManager)(nil).ListViews), ctx)
+}
+
+// RestoreViews mocks base method.
+func (m *MockViewManager) RestoreViews(ctx context.Context, views []models.View) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreViews", ctx, views)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreViews indicates an expected call of RestoreViews.
+func (mr *MockViewManagerMockRecorder) RestoreViews(ctx, views any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreViews", reflect.TypeOf((*MockViewManager)(nil).RestoreViews), ctx, views)
+}
+
+// UpdateView mocks base method.
+func (m *MockViewManager) UpdateView(ctx context.Context, view models.View) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateView", ctx, view)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateView indicates an expected call of UpdateView.
+func (mr *MockViewManagerMockRecorder) UpdateView(ctx, view any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateView", reflect.TypeOf((*MockViewManager)(nil).UpdateView), ctx, view)
+}
+
+// MockWebhookManager is a mock of WebhookManager interface.
+type MockWebhookManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookManagerMockRecorder
+	isgomock struct{}
+}
+
+// MockWebhookManagerMockRecorder is the mock recorder for MockWebhookManager.
+type MockWebhookManagerMockRecorder struct {
+	mock *MockWebhookManager
+}
+
+// NewMockWebhookManager creates a new mock instance.
+func NewMockWebhookManager(ctrl *gomock.Controller) *MockWebhookManager {
+	mock := &MockWebhookManager{ctrl: ctrl}
+	mock.recorder = &MockWebhookManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookManager) EXPECT() *MockWebhookManagerMockRecorder {
+	return m.recorder
+}
+
+// AddDelivery mocks base method.
+func (m *MockWebhookManager) AddDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDelivery indicates an expected call of AddDelivery.
+func (mr *MockWebhookManagerMockRecorder) AddDelivery(ctx, delivery any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDelivery", reflect.TypeOf((*MockWebhookManager)(nil).AddDelivery), ctx, delivery)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookManager) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookManagerMockRecorder) CreateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookManager)(nil).CreateWebhook), ctx, webhook)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookManager) DeleteWebhook(ctx context.Context, webhookID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookManagerMockRecorder) DeleteWebhook(ctx, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookManager)(nil).DeleteWebhook), ctx, webhookID)
+}
+
+// GetDeliveries mocks base method.
+func (m *MockWebhookManager) GetDeliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeliveries", ctx, webhookID)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeliveries indicates an expected call of GetDeliveries.
+func (mr *MockWebhookManagerMockRecorder) GetDeliveries(ctx, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeliveries", reflect.TypeOf((*MockWebhookManager)(nil).GetDeliveries), ctx, webhookID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookManager) GetWebhook(ctx context.Context, webhookID string) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, webhookID)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookManagerMockRecorder) GetWebhook(ctx, webhookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhook), ctx, webhookID)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockWebhookManager) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockWebhookManagerMockRecorder) GetWebhooks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockWebhookManager)(nil).GetWebhooks), ctx)
+}
+
+// RestoreWebhooks mocks base method.
+func (m *MockWebhookManager) RestoreWebhooks(ctx context.Context, webhooks []models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreWebhooks", ctx, webhooks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreWebhooks indicates an expected call of RestoreWebhooks.
+func (mr *MockWebhookManagerMockRecorder) RestoreWebhooks(ctx, webhooks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreWebhooks", reflect.TypeOf((*MockWebhookManager)(nil).RestoreWebhooks), ctx, webhooks)
+}
+
+// UpdateWebhook mocks base method.
+func (m *MockWebhookManager) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", ctx, webhook)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockWebhookManagerMockRecorder) UpdateWebhook(ctx, webhook any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockWebhookManager)(nil).UpdateWebhook), ctx, webhook)
+}
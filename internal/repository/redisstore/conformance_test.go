@@ -0,0 +1,19 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/repository/repositorytest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.TaskManager {
+		repo, err := Open(context.Background(), newClient(t))
+		assert.NoError(t, err)
+
+		return repo
+	})
+}
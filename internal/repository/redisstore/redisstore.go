@@ -0,0 +1,1115 @@
+// Package redisstore implements a task manager on Redis, shared by every replica of the server.
+// The tasks are kept in hashes, listed in the order of their ids from sorted sets, and changed by Lua scripts
+// so that every change of a task, its indexes and its history is atomic. The updates are optimistic: a task is
+// read, then written by a script only if its version did not change in the meantime.
+//
+// The views and the webhooks of the replicas are shared in the same Redis by ViewRepository and WebhookRepository,
+// and so are their task events, idempotency keys and undo history, see events.RedisStream, idempotency.RedisStore
+// and undo.RedisStacks.
+package redisstore
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/xid"
+)
+
+// DefaultPrefix is the default prefix of the keys, its hash tag keeps the keys on a single node of a cluster
+const DefaultPrefix = "{tasks}"
+
+const (
+	// maxUpdateAttempts is the number of times an update or a deletion reads and writes tasks changed concurrently
+	// before giving up
+	maxUpdateAttempts = 16
+	// conflictBackoff is the longest random wait before the second attempt of a write, doubled for every next
+	// attempt up to maxConflictBackoff
+	conflictBackoff    = time.Millisecond
+	maxConflictBackoff = 256 * time.Millisecond
+	// historyPruneInterval is the minimum time between two trims of the history by a repository
+	historyPruneInterval = time.Minute
+)
+
+var (
+	// ErrConflict represents an error when a task keeps being changed concurrently during an update
+	ErrConflict = errors.New("the task keeps being changed concurrently, try again")
+	// ErrContention represents an error when the views or the webhooks keep being changed concurrently during an update
+	ErrContention = errors.New("the views or the webhooks keep being changed concurrently, try again")
+)
+
+// change represents a version of a task in the history sorted set, the scripts add its sequence number.
+type change struct {
+	Seq     uint64    `json:"seq,omitempty"`
+	TaskID  string    `json:"taskId"`
+	Name    string    `json:"name,omitempty"`
+	Status  int       `json:"status,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+	Time    time.Time `json:"time"`
+}
+
+// Repository is a task manager storing the tasks in Redis.
+type Repository struct {
+	client    redis.UniversalClient
+	prefix    string
+	now       func() time.Time
+	publisher repository.Publisher
+	retention time.Duration // how long the superseded history entries are kept, 0 keeps them forever
+
+	pruneMu sync.Mutex
+	pruned  time.Time // when the history was last trimmed by this repository
+}
+
+var _ repository.TaskManager = (*Repository)(nil)
+
+// Option configures the repository opened by Open.
+type Option func(*Repository)
+
+// WithPrefix sets the prefix of the keys, so that several repositories share a Redis database.
+// The prefix should be a hash tag, such as {tasks}, when Redis is a cluster.
+func WithPrefix(prefix string) Option {
+	return func(r *Repository) {
+		r.prefix = prefix
+	}
+}
+
+// WithPublisher publishes a task event to the publisher on every mutation of the tasks.
+func WithPublisher(publisher repository.Publisher) Option {
+	return func(r *Repository) {
+		r.publisher = publisher
+	}
+}
+
+// WithClock sets the clock recording the time of the changes.
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) {
+		r.now = now
+	}
+}
+
+// WithHistoryRetention keeps the superseded history entries of the tasks, and the deletions of the changes,
+// for the retention, 0 keeps them forever.
+func WithHistoryRetention(retention time.Duration) Option {
+	return func(r *Repository) {
+		r.retention = retention
+	}
+}
+
+// Open checks the connection of the client and loads the scripts of the repository. The keys of the repository
+// must have the current schema version, see Migrations, and new keys record it.
+// The client stays owned by the caller.
+func Open(ctx context.Context, client redis.UniversalClient, options ...Option) (*Repository, error) {
+	r := &Repository{client: client, prefix: DefaultPrefix, now: time.Now}
+	for _, option := range options {
+		option(r)
+	}
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, err
+	}
+
+	if err := checkSchema(ctx, Keyspace{Client: client, Prefix: r.prefix}); err != nil {
+		return nil, err
+	}
+
+	for _, script := range scripts {
+		if err := script.Load(ctx, client).Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+// entry returns the arguments giving the history entry of the task at the current time to the scripts: the time
+// in milliseconds and the entry, deleted records its deletion.
+func (r *Repository) entry(task models.Task, deleted bool) ([]any, error) {
+	c := change{TaskID: task.ID, Deleted: deleted, Time: r.now().UTC()}
+	if !deleted {
+		c.Name, c.Status = task.Name, task.Status
+	}
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{c.Time.UnixMilli(), string(data)}, nil
+}
+
+// prune trims the history entries superseded before the retention period, at most once per historyPruneInterval.
+// The history is trimmed after the writes, a failed trim is retried by the next write.
+func (r *Repository) prune(ctx context.Context) {
+	if r.retention <= 0 {
+		return
+	}
+
+	r.pruneMu.Lock()
+	defer r.pruneMu.Unlock()
+
+	now := r.now()
+	if now.Sub(r.pruned) < historyPruneInterval {
+		return
+	}
+
+	keys := []string{r.prefix + ":history", r.prefix + ":changes", r.prefix + ":horizon", r.prefix + ":trimmed"}
+	if err := trimScript.Run(ctx, r.client, keys, now.Add(-r.retention).UnixMilli()).Err(); err == nil {
+		r.pruned = now
+	}
+}
+
+// taskKey returns the key of the hash of the task.
+func (r *Repository) taskKey(taskID string) string {
+	return r.prefix + ":task:" + taskID
+}
+
+// statusKey returns the key of the sorted set of the ids of the tasks with the status.
+func (r *Repository) statusKey(status string) string {
+	return r.prefix + ":status:" + status
+}
+
+// wordKey returns the key of the set of the ids of the tasks having the word.
+func (r *Repository) wordKey(word string) string {
+	return r.prefix + ":word:" + word
+}
+
+// changeKeys returns the keys of the ids, the changes, the sequence number, the history, the words and the length,
+// with room for n more keys.
+func (r *Repository) changeKeys(n int) []string {
+	keys := make([]string, 0, 6+n)
+	return append(keys, r.prefix+":ids", r.prefix+":changes", r.prefix+":seq", r.prefix+":history",
+		r.prefix+":words", r.prefix+":length")
+}
+
+// words returns the keys of the sets of the distinct words of the name, and the arguments giving the words
+// to the scripts: the number of words, the number of distinct words and the distinct words.
+func (r *Repository) words(name string) ([]string, []any) {
+	tokens := search.Tokenize(name)
+	distinct := slices.Compact(slices.Sorted(slices.Values(tokens)))
+
+	keys := make([]string, len(distinct))
+	args := make([]any, 0, 2+len(distinct))
+	args = append(args, len(tokens), len(distinct))
+	for i, word := range distinct {
+		keys[i] = r.wordKey(word)
+		args = append(args, word)
+	}
+
+	return keys, args
+}
+
+// backoff waits before an attempt of an optimistic write, a random time doubling with every attempt after the first.
+func backoff(ctx context.Context, attempt int) error {
+	if attempt > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(rand.N(min(conflictBackoff<<attempt, maxConflictBackoff))):
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	return nil
+}
+
+// load returns the task with the id along with its version, and whether it exists.
+func (r *Repository) load(ctx context.Context, taskID string) (models.Task, string, bool, error) {
+	values, err := r.client.HMGet(ctx, r.taskKey(taskID), "name", "status", "version").Result()
+	if err != nil {
+		return models.Task{}, "", false, err
+	}
+
+	name, _ := values[0].(string)
+	status, _ := values[1].(string)
+	version, exists := values[2].(string)
+	if !exists {
+		return models.Task{}, "", false, nil
+	}
+
+	task, err := parseTask(taskID, name, status)
+	if err != nil {
+		return models.Task{}, "", false, err
+	}
+
+	return task, version, true, nil
+}
+
+// parseTask returns the task of the fields of its hash.
+func parseTask(taskID, name, status string) (models.Task, error) {
+	s, err := strconv.Atoi(status)
+	if err != nil {
+		return models.Task{}, fmt.Errorf("task %s: invalid status %q", taskID, status)
+	}
+
+	return models.Task{ID: taskID, Name: name, Status: s}, nil
+}
+
+// create runs the create script for the tasks, it returns false when one of the tasks exists.
+func (r *Repository) create(ctx context.Context, tasks []models.Task) (bool, error) {
+	keys := r.changeKeys(2 * len(tasks))
+	args := make([]any, 0, 6*len(tasks))
+	for _, task := range tasks {
+		entry, err := r.entry(task, false)
+		if err != nil {
+			return false, err
+		}
+
+		wordKeys, wordArgs := r.words(task.Name)
+		keys = append(keys, r.taskKey(task.ID), r.statusKey(strconv.Itoa(task.Status)))
+		keys = append(keys, wordKeys...)
+		args = append(args, task.ID, task.Name, task.Status)
+		args = append(args, entry...)
+		args = append(args, wordArgs...)
+	}
+
+	created, err := createScript.Run(ctx, r.client, keys, args...).Int()
+	if err != nil {
+		return false, err
+	}
+
+	if created == 1 {
+		r.prune(ctx)
+	}
+
+	return created == 1, nil
+}
+
+// versionedUpdate is the new state of a task and its previous state, read with its version.
+type versionedUpdate struct {
+	previous models.Task
+	task     models.Task
+	version  string
+}
+
+// updateIfVersion writes the task if the stored version of its previous state is still the given one.
+// It returns 1 when the task is updated, 0 when the task has changed and -1 when the task does not exist.
+func (r *Repository) updateIfVersion(ctx context.Context, previous, task models.Task, version string) (int, error) {
+	updated, err := r.updateIfVersions(ctx, []versionedUpdate{{previous: previous, task: task, version: version}})
+	if err != nil {
+		return 0, err
+	}
+
+	return updated[0], nil
+}
+
+// updateIfVersions runs the update script for the tasks, so that every task is written in a single script if the
+// stored version of its previous state is still the given one. The tasks must be distinct. It returns in order 1 for
+// every updated task, 0 for a task that has changed and -1 for a task that does not exist.
+func (r *Repository) updateIfVersions(ctx context.Context, updates []versionedUpdate) ([]int, error) {
+	// the ids are unchanged
+	keys := r.changeKeys(3 * len(updates))[1:]
+	args := make([]any, 0, 11*len(updates))
+	for _, u := range updates {
+		entry, err := r.entry(u.task, false)
+		if err != nil {
+			return nil, err
+		}
+
+		status := strconv.Itoa(u.previous.Status)
+		keys = append(keys, r.taskKey(u.task.ID), r.statusKey(status), r.statusKey(strconv.Itoa(u.task.Status)))
+		args = append(args, u.task.ID, u.version, status, u.task.Name, u.task.Status)
+		args = append(args, entry...)
+
+		if u.previous.Name == u.task.Name {
+			// the words are unchanged
+			args = append(args, 0, 0, 0, 0)
+			continue
+		}
+
+		previousKeys, previousArgs := r.words(u.previous.Name)
+		wordKeys, wordArgs := r.words(u.task.Name)
+		keys = append(append(keys, previousKeys...), wordKeys...)
+		args = append(append(args, previousArgs...), wordArgs...)
+	}
+
+	replies, err := updateScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := make([]int, len(replies))
+	for i, reply := range replies {
+		updated[i] = int(reply)
+	}
+
+	if slices.Contains(updated, 1) {
+		r.prune(ctx)
+	}
+
+	return updated, nil
+}
+
+// remove deletes the tasks with the ids, which must be distinct. The tasks are read and deleted if their versions
+// did not change meanwhile, again after a random backoff until they are deleted,
+// or ErrConflict after maxUpdateAttempts. It returns the deleted tasks, and whether every task existed.
+func (r *Repository) remove(ctx context.Context, taskIDs []string) ([]models.Task, []bool, error) {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		if err := backoff(ctx, attempt); err != nil {
+			return nil, nil, err
+		}
+
+		deleted, existed, removed, err := r.removeIfVersion(ctx, taskIDs)
+		if err != nil || removed {
+			return deleted, existed, err
+		}
+	}
+
+	return nil, nil, ErrConflict
+}
+
+// removeIfVersion runs the delete script for the task ids with the versions of the tasks, which gives the status sets
+// and the word sets of the tasks to the script. It returns false when one of the tasks changed in the meantime.
+func (r *Repository) removeIfVersion(ctx context.Context, taskIDs []string) ([]models.Task, []bool, bool, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(taskIDs))
+	for i, taskID := range taskIDs {
+		cmds[i] = pipe.HMGet(ctx, r.taskKey(taskID), "name", "status", "version")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, nil, false, err
+	}
+
+	keys := r.changeKeys(2 * len(taskIDs))
+	args := make([]any, 0, 5*len(taskIDs))
+	for i, taskID := range taskIDs {
+		entry, err := r.entry(models.Task{ID: taskID}, true)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		// a missing task has no status set nor words, the script only reads the sets of an existing task
+		values := cmds[i].Val()
+		name, _ := values[0].(string)
+		status, _ := values[1].(string)
+		version, _ := values[2].(string)
+		wordKeys, wordArgs := r.words(name)
+		keys = append(keys, r.taskKey(taskID), r.statusKey(cmp.Or(status, "0")))
+		keys = append(keys, wordKeys...)
+		args = append(args, taskID, version)
+		args = append(args, entry...)
+		args = append(args, wordArgs...)
+	}
+
+	reply, err := deleteScript.Run(ctx, r.client, keys, args...).Result()
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	replies, ok := reply.([]any)
+	if !ok {
+		return nil, nil, false, nil
+	}
+
+	deleted := make([]models.Task, 0, len(replies))
+	existed := make([]bool, len(replies))
+	for i, reply := range replies {
+		fields, ok := reply.([]any)
+		if !ok {
+			continue
+		}
+
+		name, _ := fields[0].(string)
+		status, _ := fields[1].(string)
+		task, err := parseTask(taskIDs[i], name, status)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		deleted = append(deleted, task)
+		existed[i] = true
+	}
+
+	if len(deleted) > 0 {
+		r.prune(ctx)
+	}
+
+	return deleted, existed, true, nil
+}
+
+// list returns the tasks of the sorted set of task ids with the key following the prefix, in the order of the ids.
+func (r *Repository) list(ctx context.Context, key string) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	taskIDs, err := r.client.ZRange(ctx, r.prefix+":"+key, 0, -1).Result()
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return r.loadTasks(ctx, taskIDs)
+}
+
+// loadTasks returns the tasks with the ids in the same order, skipping the tasks deleted since their ids were read.
+func (r *Repository) loadTasks(ctx context.Context, taskIDs []string) ([]models.Task, error) {
+	tasks := make([]models.Task, 0, len(taskIDs))
+	fields, err := r.loadFields(ctx, taskIDs)
+	if err != nil {
+		return tasks, err
+	}
+
+	for i, taskID := range taskIDs {
+		if fields[i] == nil {
+			continue
+		}
+
+		task, err := parseTask(taskID, fields[i][0], fields[i][1])
+		if err != nil {
+			return make([]models.Task, 0), err
+		}
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// loadFields returns the name and the status of the tasks with the ids in the same order, read in a single pipeline.
+// The fields of a missing task are nil.
+func (r *Repository) loadFields(ctx context.Context, taskIDs []string) ([][]string, error) {
+	if len(taskIDs) == 0 {
+		return nil, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(taskIDs))
+	for i, taskID := range taskIDs {
+		cmds[i] = pipe.HMGet(ctx, r.taskKey(taskID), "name", "status")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	fields := make([][]string, len(taskIDs))
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		name, _ := values[0].(string)
+		status, exists := values[1].(string)
+		if exists {
+			fields[i] = []string{name, status}
+		}
+	}
+
+	return fields, nil
+}
+
+// GetTasks returns all tasks in the order of the ids, which is the order of the sorted set of the ids
+func (r *Repository) GetTasks(ctx context.Context) ([]models.Task, error) {
+	return r.list(ctx, "ids")
+}
+
+// ListTasks returns at most limit tasks sorted by ID following the task id after, a non-positive limit returns
+// every following task. An empty after starts from the first task.
+func (r *Repository) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	start := "-"
+	if after != "" {
+		start = "(" + after
+	}
+
+	// a zero count returns every following id
+	taskIDs, err := r.client.ZRangeByLex(ctx, r.prefix+":ids", &redis.ZRangeBy{Min: start, Max: "+", Count: int64(max(limit, 0))}).Result()
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return r.loadTasks(ctx, taskIDs)
+}
+
+// GetTask returns a task by task id
+func (r *Repository) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return models.Task{}, ctx.Err()
+	default:
+	}
+
+	task, _, exists, err := r.load(ctx, taskID)
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	if !exists {
+		return models.Task{}, repository.ErrTaskNotFound
+	}
+
+	return task, nil
+}
+
+// QueryTasks returns the tasks matching the query, sorted by ID.
+// The candidate tasks are loaded by id or listed from the sorted set of their status before the query is evaluated.
+func (r *Repository) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	pushdown := q.Pushdown()
+	switch {
+	case pushdown.IDs != nil:
+		candidates := make([]models.Task, 0, len(pushdown.IDs))
+		for _, taskID := range pushdown.IDs {
+			task, err := r.GetTask(ctx, taskID)
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				continue
+			}
+			if err != nil {
+				return make([]models.Task, 0), err
+			}
+			candidates = append(candidates, task)
+		}
+		models.SortTasksByID(candidates)
+
+		return q.Filter(candidates), nil
+	case pushdown.Status != nil:
+		candidates, err := r.list(ctx, "status:"+strconv.Itoa(*pushdown.Status))
+		if err != nil {
+			return make([]models.Task, 0), err
+		}
+
+		return q.Filter(candidates), nil
+	}
+
+	tasks, err := r.GetTasks(ctx)
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return q.Filter(tasks), nil
+}
+
+// CreateTasks creates the tasks with new task ids in a single script, the ids are assigned to the given tasks
+func (r *Repository) CreateTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	created := make([]models.Task, len(tasks))
+	for i := range tasks {
+		created[i] = tasks[i]
+		created[i].NewTaskID()
+	}
+
+	ok, err := r.create(ctx, created)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		// the new ids are unique
+		return repository.ErrTaskExists
+	}
+
+	copy(tasks, created)
+	for _, task := range created {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// RestoreTasks creates the tasks with their own task ids in a single script.
+// No task is created when one of the ids is invalid, repeated or already exists.
+func (r *Repository) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	seen := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		if _, err := xid.FromString(task.ID); err != nil {
+			return repository.ErrTaskID
+		}
+
+		if _, duplicated := seen[task.ID]; duplicated {
+			return repository.ErrTaskExists
+		}
+		seen[task.ID] = struct{}{}
+	}
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	ok, err := r.create(ctx, tasks)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return repository.ErrTaskExists
+	}
+
+	for _, task := range tasks {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// UpdateTask updates the name and the status of a task by task id. The task is read and written
+// if its version did not change meanwhile, again after a random backoff until it is written,
+// or ErrConflict after maxUpdateAttempts.
+func (r *Repository) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		if err := backoff(ctx, attempt); err != nil {
+			return err
+		}
+
+		previous, version, exists, err := r.load(ctx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		task := repository.Apply(previous, name, status)
+		updated, err := r.updateIfVersion(ctx, previous, task, version)
+		if err != nil {
+			return err
+		}
+
+		switch updated {
+		case 1:
+			repository.PublishUpdate(r.publisher, previous, task)
+			return nil
+		case -1:
+			return repository.ErrTaskNotFound
+		}
+	}
+
+	return ErrConflict
+}
+
+// CompareAndSwapTask replaces a task by the task if it is still the previous task, keeping its id.
+// The task is written if its version did not change since it was compared to the previous task.
+func (r *Repository) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	current, version, exists, err := r.load(ctx, previous.ID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+
+	if current != previous {
+		return repository.ErrTaskChanged
+	}
+
+	task.ID = previous.ID
+	updated, err := r.updateIfVersion(ctx, previous, task, version)
+	if err != nil {
+		return err
+	}
+
+	switch updated {
+	case 0:
+		return repository.ErrTaskChanged
+	case -1:
+		return repository.ErrTaskNotFound
+	}
+	repository.PublishUpdate(r.publisher, previous, task)
+
+	return nil
+}
+
+// DeleteTask deletes a task by task id
+func (r *Repository) DeleteTask(ctx context.Context, taskID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	deleted, _, err := r.remove(ctx, []string{taskID})
+	if err != nil {
+		return err
+	}
+
+	if len(deleted) == 0 {
+		return repository.ErrTaskNotFound
+	}
+	repository.Publish(r.publisher, models.TaskDeleted, deleted[0])
+
+	return nil
+}
+
+// BatchUpdateTasks updates the tasks with the given task ids in a single script, and reports the outcome for every id.
+// A task that is changed by someone else between its read and its write, or that appears more than once
+// in the id list, is reported as a conflict and left untouched by this batch. An error leaves every task untouched.
+func (r *Repository) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.BatchResult, 0), ctx.Err()
+	default:
+	}
+
+	seen := make(map[string]struct{}, len(taskIDs))
+	unique := make([]string, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if _, duplicated := seen[taskID]; !duplicated {
+			seen[taskID] = struct{}{}
+			unique = append(unique, taskID)
+		}
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.SliceCmd, len(unique))
+	for i, taskID := range unique {
+		cmds[i] = pipe.HMGet(ctx, r.taskKey(taskID), "name", "status", "version")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	// a missing task is not found, the script only updates the tasks read with their versions
+	updates := make([]versionedUpdate, 0, len(unique))
+	for i, taskID := range unique {
+		values := cmds[i].Val()
+		version, exists := values[2].(string)
+		if !exists {
+			continue
+		}
+
+		storedName, _ := values[0].(string)
+		storedStatus, _ := values[1].(string)
+		previous, err := parseTask(taskID, storedName, storedStatus)
+		if err != nil {
+			return make([]models.BatchResult, 0), err
+		}
+		updates = append(updates, versionedUpdate{previous: previous, task: repository.Apply(previous, name, status), version: version})
+	}
+
+	outcomes := make(map[string]models.BatchOutcome, len(unique))
+	if len(updates) > 0 {
+		updated, err := r.updateIfVersions(ctx, updates)
+		if err != nil {
+			return make([]models.BatchResult, 0), err
+		}
+
+		for i, u := range updates {
+			switch updated[i] {
+			case 1:
+				repository.PublishUpdate(r.publisher, u.previous, u.task)
+				outcomes[u.task.ID] = models.BatchOutcomeUpdated
+			case 0:
+				outcomes[u.task.ID] = models.BatchOutcomeConflict
+			}
+		}
+	}
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	reported := make(map[string]struct{}, len(unique))
+	for _, taskID := range taskIDs {
+		if _, duplicated := reported[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		reported[taskID] = struct{}{}
+		results = append(results, models.BatchResult{ID: taskID, Outcome: cmp.Or(outcomes[taskID], models.BatchOutcomeNotFound)})
+	}
+
+	return results, nil
+}
+
+// BatchDeleteTasks deletes the tasks with the given task ids in a single script,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.BatchResult, 0), ctx.Err()
+	default:
+	}
+
+	seen := make(map[string]struct{}, len(taskIDs))
+	unique := make([]string, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if _, duplicated := seen[taskID]; !duplicated {
+			seen[taskID] = struct{}{}
+			unique = append(unique, taskID)
+		}
+	}
+
+	if len(unique) == 0 {
+		return make([]models.BatchResult, 0), nil
+	}
+
+	deleted, existed, err := r.remove(ctx, unique)
+	if err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	outcomes := make(map[string]models.BatchOutcome, len(unique))
+	for i, taskID := range unique {
+		outcomes[taskID] = models.BatchOutcomeNotFound
+		if existed[i] {
+			outcomes[taskID] = models.BatchOutcomeDeleted
+		}
+	}
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	reported := make(map[string]struct{}, len(unique))
+	for _, taskID := range taskIDs {
+		if _, duplicated := reported[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		reported[taskID] = struct{}{}
+		results = append(results, models.BatchResult{ID: taskID, Outcome: outcomes[taskID]})
+	}
+
+	for _, task := range deleted {
+		repository.Publish(r.publisher, models.TaskDeleted, task)
+	}
+
+	return results, nil
+}
+
+// SearchTasks returns at most limit tasks whose names match the full-text query, ordered by relevance.
+// A non-positive limit returns every matching task. The words prefixed by the query terms are looked up in the
+// words sorted set, then only the tasks having a word prefixed by every query term are read, and ranked against
+// the number of tasks having each of their matched words.
+func (r *Repository) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	queryTerms := search.Tokenize(query)
+	if len(queryTerms) == 0 {
+		return make([]models.SearchResult, 0), nil
+	}
+
+	pipe := r.client.Pipeline()
+	prefixed := make([]*redis.StringSliceCmd, len(queryTerms))
+	for i, term := range queryTerms {
+		// no word holds the byte 0xff, which is invalid in UTF-8
+		prefixed[i] = pipe.ZRangeByLex(ctx, r.prefix+":words", &redis.ZRangeBy{Min: "[" + term, Max: "[" + term + "\xff"})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return make([]models.SearchResult, 0), err
+	}
+
+	// the sets of the words and the statistics of every task are read at once
+	pipe = r.client.TxPipeline()
+	members := make(map[string]*redis.StringSliceCmd)
+	for _, cmd := range prefixed {
+		if len(cmd.Val()) == 0 {
+			return make([]models.SearchResult, 0), nil
+		}
+
+		for _, word := range cmd.Val() {
+			members[word] = pipe.SMembers(ctx, r.wordKey(word))
+		}
+	}
+	count := pipe.ZCard(ctx, r.prefix+":ids")
+	length := pipe.Get(ctx, r.prefix+":length")
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return make([]models.SearchResult, 0), err
+	}
+
+	stats := search.Stats{DocCount: int(count.Val()), DocFreq: make(map[string]int, len(members))}
+	if value := length.Val(); value != "" {
+		var err error
+		if stats.TotalLen, err = strconv.Atoi(value); err != nil {
+			return make([]models.SearchResult, 0), fmt.Errorf("invalid length %q", value)
+		}
+	}
+	for word, cmd := range members {
+		stats.DocFreq[word] = len(cmd.Val())
+	}
+
+	// the candidates have a word prefixed by every query term
+	var candidates map[string]struct{}
+	for _, cmd := range prefixed {
+		matched := make(map[string]struct{})
+		for _, word := range cmd.Val() {
+			for _, taskID := range members[word].Val() {
+				if _, ok := candidates[taskID]; ok || candidates == nil {
+					matched[taskID] = struct{}{}
+				}
+			}
+		}
+		candidates = matched
+	}
+
+	taskIDs := slices.Sorted(maps.Keys(candidates))
+	tasks, err := r.loadTasks(ctx, taskIDs)
+	if err != nil {
+		return make([]models.SearchResult, 0), err
+	}
+
+	byID := make(map[string]models.Task, len(tasks))
+	names := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+		names[task.ID] = task.Name
+	}
+
+	results := make([]models.SearchResult, 0)
+	for _, hit := range search.Rank(query, names, stats) {
+		if limit > 0 && len(results) == limit {
+			break
+		}
+
+		task := byID[hit.ID]
+		results = append(results, models.SearchResult{
+			Task:      task,
+			Score:     hit.Score,
+			Highlight: search.Highlight(task.Name, hit.Terms),
+		})
+	}
+
+	return results, nil
+}
+
+// SyncTasks returns the tasks created or updated and the ids of the tasks deleted after the sequence number,
+// which is the sequence number of the changes. Since 0 returns every task without tombstones.
+// A sequence number before a deletion trimmed with the history returns ErrSyncTokenExpired.
+func (r *Repository) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	select {
+	case <-ctx.Done():
+		return models.TaskChanges{}, ctx.Err()
+	default:
+	}
+
+	keys := []string{r.prefix + ":seq", r.prefix + ":changes", r.prefix + ":trimmed"}
+	replies, err := syncScript.Run(ctx, r.client, keys, since).Slice()
+	if err != nil {
+		return models.TaskChanges{}, err
+	}
+
+	seq, ok := replies[0].(int64)
+	if !ok {
+		return models.TaskChanges{}, fmt.Errorf("invalid sequence number %v", replies[0])
+	}
+
+	trimmed, ok := replies[1].(int64)
+	if !ok {
+		return models.TaskChanges{}, fmt.Errorf("invalid sequence number %v", replies[1])
+	}
+
+	if since > uint64(seq) {
+		// issued before the database was flushed
+		return models.TaskChanges{}, repository.ErrSyncTokenExpired
+	}
+
+	if since > 0 && since < uint64(trimmed) {
+		// the deletions after since may have been trimmed
+		return models.TaskChanges{}, repository.ErrSyncTokenExpired
+	}
+
+	taskIDs := make([]string, 0, len(replies)-2)
+	for _, reply := range replies[2:] {
+		taskID, _ := reply.(string)
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	// the current state is returned, it may already include changes after seq
+	fields, err := r.loadFields(ctx, taskIDs)
+	if err != nil {
+		return models.TaskChanges{}, err
+	}
+
+	result := models.TaskChanges{Tasks: make([]models.Task, 0), Deleted: make([]string, 0), Seq: uint64(seq)}
+	for i, taskID := range taskIDs {
+		if fields[i] == nil {
+			if since > 0 {
+				result.Deleted = append(result.Deleted, taskID)
+			}
+			continue
+		}
+
+		task, err := parseTask(taskID, fields[i][0], fields[i][1])
+		if err != nil {
+			return models.TaskChanges{}, err
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
+
+// TasksAsOf rebuilds the tasks, sorted by ID, from the history recorded up to the given time.
+// Times before the retention period of the history return ErrHistoryExpired.
+func (r *Repository) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	// the horizon and the entries are read in a transaction, so that no trim runs in between
+	pipe := r.client.TxPipeline()
+	horizon := pipe.Get(ctx, r.prefix+":horizon")
+	entries := pipe.ZRangeByScore(ctx, r.prefix+":history", &redis.ZRangeBy{Min: "-inf", Max: strconv.FormatInt(at.UnixMilli(), 10)})
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return make([]models.Task, 0), err
+	}
+
+	if cutoff, err := horizon.Int64(); err == nil && at.UnixMilli() < cutoff {
+		return make([]models.Task, 0), repository.ErrHistoryExpired
+	}
+
+	changes := make([]change, 0, len(entries.Val()))
+	for _, entry := range entries.Val() {
+		var c change
+		if err := json.Unmarshal([]byte(entry), &c); err != nil {
+			return make([]models.Task, 0), err
+		}
+
+		if !c.Time.After(at) {
+			changes = append(changes, c)
+		}
+	}
+
+	// the clocks of the replicas may differ, the changes are applied in the order of their sequence numbers
+	slices.SortFunc(changes, func(a, b change) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	state := make(map[string]models.Task)
+	for _, c := range changes {
+		if c.Deleted {
+			delete(state, c.TaskID)
+		} else {
+			state[c.TaskID] = models.Task{ID: c.TaskID, Name: c.Name, Status: c.Status}
+		}
+	}
+
+	tasks := make([]models.Task, 0, len(state))
+	for _, task := range state {
+		tasks = append(tasks, task)
+	}
+	models.SortTasksByID(tasks)
+
+	return tasks, nil
+}
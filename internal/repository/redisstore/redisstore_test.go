@@ -0,0 +1,601 @@
+package redisstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock returns a clock advancing by a minute on every call.
+func testClock(start time.Time) func() time.Time {
+	var mu sync.Mutex
+	now := start
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []models.TaskEventType
+}
+
+func (r *recordingPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, eventType)
+	return models.TaskEvent{ID: uint64(len(r.events)), Type: eventType, Task: task}
+}
+
+// newClient starts an in-process Redis server and returns a client connected to it.
+func newClient(t *testing.T) redis.UniversalClient {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	publisher := &recordingPublisher{}
+	repo, err := Open(ctx, client, WithPublisher(publisher))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Write report"}, {Name: "Review report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NotEmpty(t, tasks[0].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+	write, review := tasks[0], tasks[1]
+
+	name, status := "Write the report", 1
+	assert.NoError(t, repo.UpdateTask(ctx, write.ID, &name, &status))
+	assert.ErrorIs(t, repo.UpdateTask(ctx, "unknown", &name, nil), repository.ErrTaskNotFound)
+
+	task, err := repo.GetTask(ctx, write.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Task{ID: write.ID, Name: "Write the report", Status: 1}, task)
+
+	assert.NoError(t, repo.DeleteTask(ctx, review.ID))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, review.ID), repository.ErrTaskNotFound)
+
+	_, err = repo.GetTask(ctx, review.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	assert.Equal(t, []models.TaskEventType{
+		models.TaskCreated, models.TaskCreated, models.TaskUpdated, models.TaskCompleted, models.TaskDeleted,
+	}, publisher.events)
+
+	// another replica shares the tasks, another prefix does not
+	replica, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	got, err = replica.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, got)
+
+	other, err := Open(ctx, client, WithPrefix("{other}"))
+	assert.NoError(t, err)
+
+	got, err = other.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = repo.GetTasks(canceled)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRepository_GetTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	// restored out of order, listed in the order of the ids
+	tasks := []models.Task{
+		{ID: "d3bkmd6hf8ng0305igb0", Name: "Task 3", Status: 1},
+		{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"},
+		{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"},
+	}
+	assert.NoError(t, repo.RestoreTasks(ctx, tasks))
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{tasks[1], tasks[2], tasks[0]}, got)
+}
+
+func TestRepository_RestoreTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	existing := []models.Task{{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"}}
+	assert.NoError(t, repo.RestoreTasks(ctx, existing))
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{name: "invalid id", tasks: []models.Task{{ID: "1", Name: "Task 2"}}, wantErr: repository.ErrTaskID},
+		{name: "existing id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305ig90", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+		{name: "repeated id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305iga0", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repo.RestoreTasks(ctx, tt.tasks), tt.wantErr)
+
+			got, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, existing, got)
+		})
+	}
+}
+
+func TestRepository_updateIfVersion(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	tests := []struct {
+		name     string
+		previous models.Task
+		task     models.Task
+		version  string
+		want     int
+	}{
+		{name: "current version", previous: tasks[0], task: models.Task{ID: tasks[0].ID, Name: "Task 1", Status: 1}, version: "1", want: 1},
+		{name: "stale version", previous: tasks[0], task: models.Task{ID: tasks[0].ID, Name: "Task 1 renamed"}, version: "1", want: 0},
+		{name: "stale status", previous: tasks[0], task: models.Task{ID: tasks[0].ID, Name: "Task 1 renamed"}, version: "2", want: 0},
+		{name: "missing task", previous: models.Task{ID: "d3bkmd6hf8ng0305ig90"}, task: models.Task{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 2"}, version: "1", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.updateIfVersion(ctx, tt.previous, tt.task, tt.version)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// only the update of the current version is written, with the status index
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+
+	q, err := query.Parse("open")
+	assert.NoError(t, err)
+
+	got, err = repo.QueryTasks(ctx, q)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRepository_UpdateTask_concurrent(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+
+	tasks := []models.Task{{Name: "Task"}}
+	repo, err := Open(ctx, client)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	// replicas rename the task concurrently, every update is written once
+	const replicas, updates = 4, 10
+	var wg sync.WaitGroup
+	for i := 0; i < replicas; i++ {
+		replica, err := Open(ctx, client)
+		assert.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < updates; j++ {
+				name := fmt.Sprintf("Task %d-%d", i, j)
+				assert.NoError(t, replica.UpdateTask(ctx, tasks[0].ID, &name, nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	version, err := client.HGet(ctx, DefaultPrefix+":task:"+tasks[0].ID, "version").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 1+replicas*updates, version)
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1+replicas*updates), changes.Seq)
+}
+
+// scriptKeys records the KEYS of the scripts run by a client, and runs before the first run of a script.
+type scriptKeys struct {
+	mu     sync.Mutex
+	keys   map[string]struct{}
+	runs   int
+	before func()
+}
+
+func (s *scriptKeys) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (s *scriptKeys) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		args := cmd.Args()
+		if name := cmd.Name(); name == "evalsha" || name == "eval" {
+			s.mu.Lock()
+			s.runs++
+			n, _ := args[2].(int)
+			for _, key := range args[3 : 3+n] {
+				s.keys[key.(string)] = struct{}{}
+			}
+			before := s.before
+			s.before = nil
+			s.mu.Unlock()
+
+			if before != nil {
+				before()
+			}
+		}
+
+		return next(ctx, cmd)
+	}
+}
+
+func (s *scriptKeys) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestRepository_scriptKeys(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	hook := &scriptKeys{keys: make(map[string]struct{})}
+	client.AddHook(hook)
+
+	repo, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	status, name := 1, "Renamed task"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, &status))
+
+	// another replica completes the task between the read of its status and its deletion
+	hook.before = func() {
+		server.HSet(DefaultPrefix+":task:"+tasks[1].ID, "status", "1", "version", "2")
+		server.ZRem(DefaultPrefix+":status:0", tasks[1].ID)
+		server.ZAdd(DefaultPrefix+":status:1", 0, tasks[1].ID)
+	}
+	results, err := repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	_, err = repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+
+	// every key written by the scripts is given in KEYS, so that a cluster routes the scripts,
+	// the schema key is written by Open
+	for _, key := range server.Keys() {
+		if key != DefaultPrefix+":schema" {
+			assert.Contains(t, hook.keys, key)
+		}
+	}
+
+	// the deleted task is removed from the status set of its current status
+	for _, status := range []string{"0", "1"} {
+		members, err := client.ZRange(ctx, DefaultPrefix+":status:"+status, 0, -1).Result()
+		assert.NoError(t, err)
+		assert.NotContains(t, members, tasks[1].ID)
+	}
+}
+
+func TestRepository_BatchUpdateTasks_singleScript(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	hook := &scriptKeys{keys: make(map[string]struct{})}
+	client.AddHook(hook)
+
+	repo, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	// another replica renames the second task between the read of the tasks and their update
+	hook.runs = 0
+	hook.before = func() {
+		server.HSet(DefaultPrefix+":task:"+tasks[1].ID, "name", "Task 2 renamed", "version", "2")
+	}
+	name := "Renamed task"
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, tasks[1].ID, "unknown", tasks[2].ID}, &name, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[2].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+	assert.Equal(t, 1, hook.runs)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{
+		{ID: tasks[0].ID, Name: name},
+		{ID: tasks[1].ID, Name: "Task 2 renamed"},
+		{ID: tasks[2].ID, Name: name},
+	}, got)
+
+	// the words of the updated tasks are indexed
+	hits, err := repo.SearchTasks(ctx, "renamed", 0)
+	assert.NoError(t, err)
+	assert.Len(t, hits, 2)
+}
+
+func TestRepository_BatchTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	status := 1
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, "unknown", tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+
+	results, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+}
+
+func TestRepository_QueryTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{
+		{Name: "Deploy the API"},
+		{Name: "Deploy the docs", Status: 1},
+		{Name: "deploy"},
+		{Name: "Review the deployment", Status: 1},
+		{Name: "Write report"},
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+
+	for _, text := range []string{
+		"open",
+		"status:done deploy",
+		"deploy AND NOT done",
+		"NOT (deploy OR report)",
+		"id=" + all[2].ID,
+	} {
+		t.Run(text, func(t *testing.T) {
+			q, err := query.Parse(text)
+			assert.NoError(t, err)
+
+			got, err := repo.QueryTasks(ctx, q)
+			assert.NoError(t, err)
+			assert.Equal(t, q.Filter(all), got)
+		})
+	}
+
+	results, err := repo.SearchTasks(ctx, "deploy", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "<mark>deploy</mark>", results[0].Highlight)
+
+	results, err = repo.SearchTasks(ctx, "deploy", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+}
+
+func TestRepository_SearchTasks(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	repo, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Document the deployment"}, {Name: "Deploy the API"}, {Name: "Deploy the docs"}, {Name: "Write report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	name := "Deploy the report"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[3].ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[2].ID))
+
+	// the tasks score as in an index of every task
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	index := search.NewIndex()
+	for _, task := range all {
+		index.Add(task.ID, task.Name)
+	}
+
+	for _, text := range []string{"deploy", "dep the", "report", "docs", "write", "deploy docs"} {
+		t.Run(text, func(t *testing.T) {
+			results, err := repo.SearchTasks(ctx, text, 0)
+			assert.NoError(t, err)
+
+			hits := index.Search(text)
+			assert.Len(t, results, len(hits))
+			for i, hit := range hits {
+				assert.Equal(t, hit.ID, results[i].Task.ID)
+				assert.Equal(t, hit.Score, results[i].Score)
+			}
+		})
+	}
+
+	// the words of the renamed and the deleted tasks are removed from the index
+	words, err := client.ZRange(ctx, DefaultPrefix+":words", 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "deploy", "deployment", "document", "report", "the"}, words)
+
+	length, err := client.Get(ctx, DefaultPrefix+":length").Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 9, length)
+
+	exists, err := client.Exists(ctx, DefaultPrefix+":word:docs", DefaultPrefix+":word:write").Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestRepository_SyncTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, newClient(t))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: tasks, Deleted: []string{}, Seq: 3}, changes)
+
+	name := "Task 1 renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+
+	changes, err = repo.SyncTasks(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: tasks[0].ID, Name: name}},
+		Deleted: []string{tasks[1].ID},
+		Seq:     5,
+	}, changes)
+
+	_, err = repo.SyncTasks(ctx, 6)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+}
+
+func TestRepository_TasksAsOf(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	repo, err := Open(ctx, newClient(t), WithClock(testClock(start)))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks)) // 09:01
+
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status)) // 09:02
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[0].ID))               // 09:03
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []models.Task
+	}{
+		{name: "before the creation", at: start, want: []models.Task{}},
+		{name: "after the creation", at: start.Add(time.Minute), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1"}}},
+		{name: "after the update", at: start.Add(150 * time.Second), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}},
+		{name: "after the deletion", at: start.Add(3 * time.Minute), want: []models.Task{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TasksAsOf(ctx, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_HistoryRetention(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	now := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	repo, err := Open(ctx, client, WithClock(func() time.Time { return now }), WithHistoryRetention(time.Hour))
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks)) // 09:00
+
+	now = now.Add(10 * time.Minute)
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status)) // 09:10
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))               // 09:10
+
+	// the history superseded before 09:30 is trimmed
+	now = now.Add(80 * time.Minute)
+	name := "Task 1 renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil)) // 10:30
+
+	entries, err := client.ZCard(ctx, DefaultPrefix+":history").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), entries)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		want    []models.Task
+		wantErr error
+	}{
+		{name: "before the horizon", at: now.Add(-61 * time.Minute), want: []models.Task{}, wantErr: repository.ErrHistoryExpired},
+		{name: "at the horizon", at: now.Add(-time.Hour), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}},
+		{name: "after the rename", at: now, want: []models.Task{{ID: tasks[0].ID, Name: name, Status: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TasksAsOf(ctx, tt.at)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// the deletion of the second task is trimmed from the changes
+	_, err = repo.SyncTasks(ctx, 2)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+
+	changes, err := repo.SyncTasks(ctx, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: tasks[0].ID, Name: name, Status: 1}},
+		Deleted: []string{},
+		Seq:     5,
+	}, changes)
+}
@@ -0,0 +1,169 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/redis/go-redis/v9"
+)
+
+// Migrations are the schema migrations of the keys of the repositories, run by the server on startup.
+//
+// Version 1 is the keys without schema key. Version 2 records the schema version in it.
+// Version 3 indexes the words of the task names for the searches. Version 4 keeps the history in a sorted set
+// scored with the time of the changes, so that it is read from a time and trimmed.
+var Migrations = schema.NewRegistry(
+	schema.Migration[Keyspace]{
+		Version:     2,
+		Description: "record the schema version in the schema key",
+		Apply:       countKeys,
+	},
+	schema.Migration[Keyspace]{
+		Version:     3,
+		Description: "index the words of the task names",
+		Apply:       indexWords,
+	},
+	schema.Migration[Keyspace]{
+		Version:     4,
+		Description: "keep the history in a sorted set scored with the time of the changes",
+		Apply:       scoreHistory,
+	},
+)
+
+// Keyspace is a store of the tasks of the keys with the prefix, recording the schema version in the key
+// <prefix>:schema.
+type Keyspace struct {
+	Client redis.UniversalClient
+	Prefix string
+}
+
+// ReadVersion returns the schema version recorded in the schema key, or 0 when there is none.
+func (k Keyspace) ReadVersion(ctx context.Context) (int, error) {
+	version, err := k.Client.Get(ctx, k.Prefix+":schema").Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// WriteVersion records the schema version in the schema key.
+func (k Keyspace) WriteVersion(ctx context.Context, version int) error {
+	return k.Client.Set(ctx, k.Prefix+":schema", version, 0).Err()
+}
+
+// checkSchema records the current schema version in a new keyspace, and returns an error
+// unless an existing keyspace has the current version.
+func checkSchema(ctx context.Context, k Keyspace) error {
+	version, err := k.ReadVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		// the sequence number is kept once the tasks are deleted
+		exists, err := k.Client.Exists(ctx, k.Prefix+":ids", k.Prefix+":seq").Result()
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			return k.WriteVersion(ctx, Migrations.Current())
+		}
+	}
+
+	return Migrations.Check(ctx, k)
+}
+
+// countKeys counts the tasks and their changes, the migration to version 2 changes nothing else.
+func countKeys(ctx context.Context, k Keyspace, _ bool) (string, error) {
+	pipe := k.Client.Pipeline()
+	tasks := pipe.ZCard(ctx, k.Prefix+":ids")
+	changes := pipe.LLen(ctx, k.Prefix+":history")
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("found %d tasks and %d changes", tasks.Val(), changes.Val()), nil
+}
+
+// indexWords adds every task to the sets of the words of its name, which the migration to version 3 creates along with
+// the words sorted set and the length. The index is written in a single transaction.
+func indexWords(ctx context.Context, k Keyspace, dryRun bool) (string, error) {
+	r := &Repository{client: k.Client, prefix: k.Prefix}
+	taskIDs, err := k.Client.ZRange(ctx, k.Prefix+":ids", 0, -1).Result()
+	if err != nil {
+		return "", err
+	}
+
+	tasks, err := r.loadTasks(ctx, taskIDs)
+	if err != nil {
+		return "", err
+	}
+
+	pipe := k.Client.TxPipeline()
+	words := make(map[string]struct{})
+	length := 0
+	for _, task := range tasks {
+		tokens := search.Tokenize(task.Name)
+		length += len(tokens)
+		for _, word := range tokens {
+			words[word] = struct{}{}
+			pipe.SAdd(ctx, r.wordKey(word), task.ID)
+			pipe.ZAdd(ctx, k.Prefix+":words", redis.Z{Member: word})
+		}
+	}
+	pipe.Set(ctx, k.Prefix+":length", length, 0)
+
+	if !dryRun {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("indexed %d words of %d tasks", len(words), len(tasks)), nil
+}
+
+// scoreHistory moves the entries of the history list to the history sorted set, scored with the time of their changes
+// in milliseconds, which the migration to version 4 creates. The entries are given sequence numbers in the order of
+// the list. The history is replaced in a single transaction.
+func scoreHistory(ctx context.Context, k Keyspace, dryRun bool) (string, error) {
+	key := k.Prefix + ":history"
+	entries, err := k.Client.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return "", err
+	}
+
+	members := make([]redis.Z, 0, len(entries))
+	for i, entry := range entries {
+		var c change
+		if err := json.Unmarshal([]byte(entry), &c); err != nil {
+			return "", err
+		}
+
+		c.Seq = uint64(i + 1)
+		data, err := json.Marshal(c)
+		if err != nil {
+			return "", err
+		}
+		members = append(members, redis.Z{Score: float64(c.Time.UnixMilli()), Member: string(data)})
+	}
+
+	pipe := k.Client.TxPipeline()
+	pipe.Del(ctx, key)
+	if len(members) > 0 {
+		pipe.ZAdd(ctx, key, members...)
+	}
+
+	if !dryRun {
+		if _, err := pipe.Exec(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("scored %d changes", len(members)), nil
+}
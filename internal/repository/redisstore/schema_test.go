@@ -0,0 +1,120 @@
+package redisstore
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	repo, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "Write the release notes"}, {Name: "Tag v1"}, {Name: "Deleted task"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[2].ID))
+
+	// the keys written by the release of schema version 1 have no schema key nor word index,
+	// and their history is a list of entries without sequence numbers
+	index, err := client.Keys(ctx, DefaultPrefix+":word*").Result()
+	assert.NoError(t, err)
+	assert.NoError(t, client.Del(ctx, append(index, DefaultPrefix+":schema", DefaultPrefix+":length")...).Err())
+	history := listHistory(t, client)
+	assert.NoError(t, client.Del(ctx, DefaultPrefix+":history").Err())
+	assert.NoError(t, client.RPush(ctx, DefaultPrefix+":history", history...).Err())
+	keyspace := Keyspace{Client: client, Prefix: DefaultPrefix}
+	wantSteps := []schema.Step{
+		{Version: 2, Description: "record the schema version in the schema key", Changes: "found 2 tasks and 4 changes"},
+		{Version: 3, Description: "index the words of the task names", Changes: "indexed 6 words of 2 tasks"},
+		{Version: 4, Description: "keep the history in a sorted set scored with the time of the changes", Changes: "scored 4 changes"},
+	}
+
+	steps, err := Migrations.Migrate(ctx, keyspace, true)
+	assert.NoError(t, err)
+	assert.Equal(t, wantSteps, steps)
+
+	// the dry run leaves the keys as they were
+	_, err = Open(ctx, client)
+	assert.ErrorIs(t, err, schema.ErrOutdated)
+
+	steps, err = Migrations.Migrate(ctx, keyspace, false)
+	assert.NoError(t, err)
+	assert.Equal(t, wantSteps, steps)
+
+	repo, err = Open(ctx, client)
+	assert.NoError(t, err)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks[:2], got)
+
+	results, err := repo.SearchTasks(ctx, "release", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, tasks[0], results[0].Task)
+
+	got, err = repo.TasksAsOf(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, tasks[:2], got)
+
+	// migrating again changes nothing
+	steps, err = Migrations.Migrate(ctx, keyspace, false)
+	assert.NoError(t, err)
+	assert.Empty(t, steps)
+}
+
+// listHistory returns the entries of the history sorted set without their sequence numbers,
+// in the order of the sequence numbers.
+func listHistory(t *testing.T, client redis.UniversalClient) []any {
+	members, err := client.ZRange(context.Background(), DefaultPrefix+":history", 0, -1).Result()
+	assert.NoError(t, err)
+
+	changes := make([]change, len(members))
+	for i, member := range members {
+		assert.NoError(t, json.Unmarshal([]byte(member), &changes[i]))
+	}
+	slices.SortFunc(changes, func(a, b change) int { return cmp.Compare(a.Seq, b.Seq) })
+
+	entries := make([]any, len(changes))
+	for i, c := range changes {
+		c.Seq = 0
+		data, err := json.Marshal(c)
+		assert.NoError(t, err)
+		entries[i] = string(data)
+	}
+
+	return entries
+}
+
+func TestOpen_Schema(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	_, err := Open(ctx, client)
+	assert.NoError(t, err)
+
+	keyspace := Keyspace{Client: client, Prefix: DefaultPrefix}
+	version, err := keyspace.ReadVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Migrations.Current(), version)
+
+	// the keys of another prefix have their own version
+	_, err = Open(ctx, client, WithPrefix("{other}"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, keyspace.WriteVersion(ctx, Migrations.Current()+1))
+	_, err = Open(ctx, client)
+	assert.ErrorIs(t, err, schema.ErrNewer)
+
+	_, err = Open(ctx, client, WithPrefix("{other}"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,216 @@
+package redisstore
+
+import "github.com/redis/go-redis/v9"
+
+// The scripts only touch the keys given in KEYS, so that Redis Cluster routes them, and every key of
+// a repository has the same hash tag so that the scripts run on a single node of a cluster.
+//
+// Every change of a task increments the sequence number, scores the task id with it in the changes
+// sorted set and adds the change with the sequence number to the history sorted set, scored with the time of
+// the change in milliseconds, in the same script as the change itself. The time and the history entry of a change
+// are given as two arguments in ARGV.
+//
+// The words of the task names are indexed for the searches: the set of every word holds the ids of the tasks
+// having it, the words sorted set holds every word having tasks for the prefix lookups, and the length key
+// counts the words of every task. The words of a task are given as the number of its words, then the number
+// of its distinct words followed by them in ARGV, and the keys of their sets in KEYS.
+
+// indexFunctions add the task id to the sets of the words and remove it from them.
+// words and length are the keys of the words sorted set and the length, k the index of the first word set in KEYS
+// and a the index of the number of words in ARGV.
+const indexFunctions = `
+local function index(words, length, id, k, a)
+	local n = tonumber(ARGV[a + 1])
+	for i = 0, n - 1 do
+		redis.call('SADD', KEYS[k + i], id)
+		redis.call('ZADD', words, 0, ARGV[a + 2 + i])
+	end
+	redis.call('INCRBY', length, ARGV[a])
+
+	return n
+end
+
+local function unindex(words, length, id, k, a)
+	local n = tonumber(ARGV[a + 1])
+	for i = 0, n - 1 do
+		redis.call('SREM', KEYS[k + i], id)
+		if redis.call('SCARD', KEYS[k + i]) == 0 then
+			redis.call('ZREM', words, ARGV[a + 2 + i])
+		end
+	end
+	redis.call('DECRBY', length, ARGV[a])
+
+	return n
+end
+`
+
+// recordFunction records a change of the task.
+// changes, seq and history are the keys of the changes sorted set, the sequence number and the history sorted set.
+const recordFunction = `
+local function record(changes, seq, history, id, time, entry)
+	local n = redis.call('INCR', seq)
+	redis.call('ZADD', changes, n, id)
+	redis.call('ZADD', history, time, '{"seq":' .. n .. ',' .. string.sub(entry, 2))
+end
+`
+
+// createScript creates the tasks unless one of them exists, it returns 1 when the tasks are created and 0 otherwise.
+// KEYS: ids, changes, seq, history, words, length, then the hash, the status set and the word sets of every task.
+// ARGV: the id, name, status, time, history entry and words of every task.
+var createScript = redis.NewScript(indexFunctions + recordFunction + `
+local tasks, k, a = {}, 7, 1
+while a <= #ARGV do
+	if redis.call('EXISTS', KEYS[k]) == 1 then
+		return 0
+	end
+
+	local n = tonumber(ARGV[a + 6])
+	tasks[#tasks + 1] = {k, a}
+	k, a = k + 2 + n, a + 7 + n
+end
+
+for _, task in ipairs(tasks) do
+	local k, a = task[1], task[2]
+	local id = ARGV[a]
+	redis.call('HSET', KEYS[k], 'name', ARGV[a + 1], 'status', ARGV[a + 2], 'version', 1)
+	redis.call('ZADD', KEYS[1], 0, id)
+	redis.call('ZADD', KEYS[k + 1], 0, id)
+	record(KEYS[2], KEYS[3], KEYS[4], id, ARGV[a + 3], ARGV[a + 4])
+	index(KEYS[5], KEYS[6], id, k + 2, a + 5)
+end
+
+return 1
+`)
+
+// updateScript updates the names and the statuses of the tasks whose versions and statuses are still the expected ones.
+// It returns 1 for every updated task, 0 for a task that has changed and -1 for a task that does not exist.
+// KEYS: changes, seq, history, words, length, then the hash, the status set of the expected status, the status set
+// of the new status and the word sets of the previous name and of the new name of every task.
+// ARGV: the id, expected version, expected status, name, status, time, history entry, words of the previous name
+// and of the new name of every task, none when the name is unchanged.
+var updateScript = redis.NewScript(indexFunctions + recordFunction + `
+local updated, k, a = {}, 6, 1
+while a <= #ARGV do
+	local id = ARGV[a]
+	local previous = tonumber(ARGV[a + 8])
+	local n = tonumber(ARGV[a + 10 + previous])
+	local current = redis.call('HMGET', KEYS[k], 'status', 'version')
+	if not current[2] then
+		updated[#updated + 1] = -1
+	elseif current[2] ~= ARGV[a + 1] or current[1] ~= ARGV[a + 2] then
+		updated[#updated + 1] = 0
+	else
+		redis.call('HSET', KEYS[k], 'name', ARGV[a + 3], 'status', ARGV[a + 4], 'version', tonumber(current[2]) + 1)
+		redis.call('ZREM', KEYS[k + 1], id)
+		redis.call('ZADD', KEYS[k + 2], 0, id)
+		record(KEYS[1], KEYS[2], KEYS[3], id, ARGV[a + 5], ARGV[a + 6])
+		unindex(KEYS[4], KEYS[5], id, k + 3, a + 7)
+		index(KEYS[4], KEYS[5], id, k + 3 + previous, a + 9 + previous)
+		updated[#updated + 1] = 1
+	end
+
+	k, a = k + 3 + previous + n, a + 11 + previous + n
+end
+
+return updated
+`)
+
+// deleteScript deletes the tasks if their versions are still the expected ones, it returns -1 when one of them has
+// changed, and otherwise the name and the status of every deleted task and 0 for every missing one.
+// KEYS: ids, changes, seq, history, words, length, then the hash, the status set of the expected status
+// and the word sets of every task.
+// ARGV: the id, expected version, empty for a missing task, time, history entry and words of every task.
+var deleteScript = redis.NewScript(indexFunctions + recordFunction + `
+local tasks, k, a = {}, 7, 1
+while a <= #ARGV do
+	local version = redis.call('HGET', KEYS[k], 'version')
+	if version and version ~= ARGV[a + 1] then
+		return -1
+	end
+
+	local n = tonumber(ARGV[a + 5])
+	tasks[#tasks + 1] = {k, a}
+	k, a = k + 2 + n, a + 6 + n
+end
+
+local deleted = {}
+for _, task in ipairs(tasks) do
+	local k, a = task[1], task[2]
+	local id = ARGV[a]
+	local fields = redis.call('HMGET', KEYS[k], 'name', 'status')
+	if fields[2] then
+		redis.call('DEL', KEYS[k])
+		redis.call('ZREM', KEYS[1], id)
+		redis.call('ZREM', KEYS[k + 1], id)
+		record(KEYS[2], KEYS[3], KEYS[4], id, ARGV[a + 2], ARGV[a + 3])
+		unindex(KEYS[5], KEYS[6], id, k + 2, a + 4)
+		deleted[#deleted + 1] = fields
+	else
+		deleted[#deleted + 1] = 0
+	end
+end
+
+return deleted
+`)
+
+// syncScript returns the current sequence number and the highest sequence number of the deletions trimmed from
+// the changes, followed by the ids of the tasks changed after the given sequence number ordered by their last change.
+// KEYS: seq, changes, trimmed.
+// ARGV: sequence number.
+var syncScript = redis.NewScript(`
+local changes = {tonumber(redis.call('GET', KEYS[1]) or '0'), tonumber(redis.call('GET', KEYS[3]) or '0')}
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '(' .. ARGV[1], '+inf')) do
+	changes[#changes + 1] = id
+end
+
+return changes
+`)
+
+// trimScript moves the horizon of the history to the cutoff time in milliseconds unless it is already later, and
+// removes the history entries superseded at the cutoff: only the last entry recorded up to the cutoff is kept for
+// every task, and none for a task deleted by then, whose deletion is also removed from the changes sorted set
+// unless the task changed again. The trimmed key records the highest sequence number of the removed deletions.
+// It returns the number of removed history entries.
+// KEYS: history, changes, horizon, trimmed.
+// ARGV: cutoff time.
+var trimScript = redis.NewScript(`
+local cutoff = tonumber(ARGV[1])
+if cutoff <= tonumber(redis.call('GET', KEYS[3]) or '0') then
+	return 0
+end
+redis.call('SET', KEYS[3], ARGV[1])
+
+local last, removed = {}, 0
+for _, member in ipairs(redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1])) do
+	local change = cjson.decode(member)
+	local previous = last[change.taskId]
+	if previous and previous.seq > change.seq then
+		redis.call('ZREM', KEYS[1], member)
+		removed = removed + 1
+	else
+		if previous then
+			redis.call('ZREM', KEYS[1], previous.member)
+			removed = removed + 1
+		end
+		last[change.taskId] = {seq = change.seq, deleted = change.deleted, member = member}
+	end
+end
+
+local trimmed = tonumber(redis.call('GET', KEYS[4]) or '0')
+for id, change in pairs(last) do
+	if change.deleted then
+		redis.call('ZREM', KEYS[1], change.member)
+		removed = removed + 1
+		if tonumber(redis.call('ZSCORE', KEYS[2], id)) == change.seq then
+			redis.call('ZREM', KEYS[2], id)
+			trimmed = math.max(trimmed, change.seq)
+		end
+	end
+end
+redis.call('SET', KEYS[4], trimmed)
+
+return removed
+`)
+
+// scripts are loaded by Open
+var scripts = []*redis.Script{createScript, updateScript, deleteScript, syncScript, trimScript}
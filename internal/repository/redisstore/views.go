@@ -0,0 +1,191 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// ViewRepository is a view manager keeping the saved views in Redis, shared by every replica of the server.
+// The views are encoded in the hash <prefix>:views by view id, and changed in transactions watching the hash.
+type ViewRepository struct {
+	client redis.UniversalClient
+	key    string
+}
+
+var _ repository.ViewManager = (*ViewRepository)(nil)
+
+// NewViewRepository creates a view manager keeping the views under the keys with the prefix, such as DefaultPrefix.
+// The client stays owned by the caller.
+func NewViewRepository(client redis.UniversalClient, prefix string) *ViewRepository {
+	return &ViewRepository{client: client, key: prefix + ":views"}
+}
+
+// GetViews returns the views of the owner sorted by ID
+func (v *ViewRepository) GetViews(ctx context.Context, owner string) ([]models.View, error) {
+	views, err := v.ListViews(ctx)
+	if err != nil {
+		return make([]models.View, 0), err
+	}
+
+	return slices.DeleteFunc(views, func(view models.View) bool { return view.Owner != owner }), nil
+}
+
+// ListViews returns the views of every owner sorted by ID
+func (v *ViewRepository) ListViews(ctx context.Context) ([]models.View, error) {
+	values, err := v.client.HVals(ctx, v.key).Result()
+	if err != nil {
+		return make([]models.View, 0), err
+	}
+
+	views := make([]models.View, len(values))
+	for i, value := range values {
+		if err := json.Unmarshal([]byte(value), &views[i]); err != nil {
+			return make([]models.View, 0), err
+		}
+	}
+	slices.SortFunc(views, func(a, b models.View) int { return strings.Compare(a.ID, b.ID) })
+
+	return views, nil
+}
+
+// GetView returns a view by view id
+func (v *ViewRepository) GetView(ctx context.Context, viewID string) (models.View, error) {
+	return getView(ctx, v.client, v.key, viewID)
+}
+
+// getView reads the view with the id from the hash of the views.
+func getView(ctx context.Context, client redis.Cmdable, key, viewID string) (models.View, error) {
+	data, err := client.HGet(ctx, key, viewID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.View{}, repository.ErrViewNotFound
+	}
+	if err != nil {
+		return models.View{}, err
+	}
+
+	var view models.View
+	if err := json.Unmarshal(data, &view); err != nil {
+		return models.View{}, err
+	}
+
+	return view, nil
+}
+
+// CreateView stores a new view with a new view id and returns it
+func (v *ViewRepository) CreateView(ctx context.Context, view models.View) (models.View, error) {
+	view.NewViewID()
+	view.CreatedAt = time.Now().UTC()
+	view.UpdatedAt = view.CreatedAt
+
+	data, err := json.Marshal(view)
+	if err != nil {
+		return models.View{}, err
+	}
+
+	if err := v.client.HSet(ctx, v.key, view.ID, data).Err(); err != nil {
+		return models.View{}, err
+	}
+
+	return view, nil
+}
+
+// UpdateView replaces the name, query, sort and columns of an existing view. The view is written only if the views
+// did not change since it was read, and read again otherwise, so that a concurrent update is not lost
+// and a concurrently deleted view is not stored again.
+func (v *ViewRepository) UpdateView(ctx context.Context, view models.View) error {
+	return watch(ctx, v.client, v.key, func(tx *redis.Tx) error {
+		updated, err := getView(ctx, tx, v.key, view.ID)
+		if err != nil {
+			return err
+		}
+
+		updated.Name = view.Name
+		updated.Query = view.Query
+		updated.Sort = view.Sort
+		updated.Columns = view.Columns
+		updated.UpdatedAt = time.Now().UTC()
+		data, err := json.Marshal(updated)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, v.key, view.ID, data).Err()
+		})
+
+		return err
+	})
+}
+
+// DeleteView deletes a view by view id
+func (v *ViewRepository) DeleteView(ctx context.Context, viewID string) error {
+	deleted, err := v.client.HDel(ctx, v.key, viewID).Result()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return repository.ErrViewNotFound
+	}
+
+	return nil
+}
+
+// RestoreViews stores the views with their ids and times, no view is stored when one of them already exists
+func (v *ViewRepository) RestoreViews(ctx context.Context, views []models.View) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(views))
+	values := make([]any, 0, 2*len(views))
+	for i, view := range views {
+		data, err := json.Marshal(view)
+		if err != nil {
+			return err
+		}
+		fields[i] = view.ID
+		values = append(values, view.ID, data)
+	}
+
+	return watch(ctx, v.client, v.key, func(tx *redis.Tx) error {
+		existing, err := tx.HMGet(ctx, v.key, fields...).Result()
+		if err != nil {
+			return err
+		}
+
+		if slices.ContainsFunc(existing, func(value any) bool { return value != nil }) {
+			return repository.ErrViewExists
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, v.key, values...).Err()
+		})
+
+		return err
+	})
+}
+
+// watch runs fn in a transaction watching the key, again after a random backoff while the key changes
+// before the transaction is executed, or ErrContention after maxUpdateAttempts.
+func watch(ctx context.Context, client redis.UniversalClient, key string, fn func(tx *redis.Tx) error) error {
+	for attempt := 0; attempt < maxUpdateAttempts; attempt++ {
+		if err := backoff(ctx, attempt); err != nil {
+			return err
+		}
+
+		if err := client.Watch(ctx, fn, key); !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+
+	return ErrContention
+}
@@ -0,0 +1,76 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	// the replicas share the views
+	repo, replica := NewViewRepository(client, DefaultPrefix), NewViewRepository(client, DefaultPrefix)
+
+	aliceView, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, aliceView.ID)
+	assert.False(t, aliceView.CreatedAt.IsZero())
+
+	bobView, err := replica.CreateView(ctx, models.View{Owner: "bob", Name: "Done tasks", Query: "done", Sort: "-name"})
+	assert.NoError(t, err)
+
+	views, err := replica.GetViews(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.View{aliceView}, views)
+
+	views, err = repo.ListViews(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.View{aliceView, bobView}, views)
+
+	got, err := repo.GetView(ctx, bobView.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, bobView, got)
+
+	bobView.Name = "Recently done"
+	bobView.Columns = []string{"name"}
+	bobView.Owner = "mallory"
+	assert.NoError(t, repo.UpdateView(ctx, bobView))
+
+	got, err = replica.GetView(ctx, bobView.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Recently done", got.Name)
+	assert.Equal(t, []string{"name"}, got.Columns)
+	assert.Equal(t, "bob", got.Owner)
+
+	assert.NoError(t, replica.DeleteView(ctx, bobView.ID))
+
+	_, err = repo.GetView(ctx, bobView.ID)
+	assert.ErrorIs(t, err, repository.ErrViewNotFound)
+	assert.ErrorIs(t, repo.UpdateView(ctx, bobView), repository.ErrViewNotFound)
+	assert.ErrorIs(t, repo.DeleteView(ctx, bobView.ID), repository.ErrViewNotFound)
+}
+
+func TestViewRepository_RestoreViews(t *testing.T) {
+	ctx := context.Background()
+	repo := NewViewRepository(newClient(t), DefaultPrefix)
+
+	created, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+
+	restored := []models.View{
+		{ID: "cv1h8ms2hf8ng030mva0", Owner: "bob", Name: "Done tasks", Query: "done", CreatedAt: created.CreatedAt, UpdatedAt: created.CreatedAt},
+	}
+	assert.NoError(t, repo.RestoreViews(ctx, restored))
+
+	// no view is restored when one of them exists
+	err = repo.RestoreViews(ctx, []models.View{{ID: "cv1h8ms2hf8ng030mvc0"}, restored[0]})
+	assert.ErrorIs(t, err, repository.ErrViewExists)
+
+	views, err := repo.ListViews(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, append(restored, created), views)
+}
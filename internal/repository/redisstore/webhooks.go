@@ -0,0 +1,264 @@
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// maxDeliveries is the number of deliveries kept in the log of every webhook
+	maxDeliveries = 100
+	// claimTTL is how long the claim of the delivery of an event to a webhook is kept
+	claimTTL = 24 * time.Hour
+)
+
+// storedWebhook is the encoding of a webhook in the hash of the webhooks, with its secret.
+type storedWebhook struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookRepository is a webhook manager keeping the webhooks and their delivery logs in Redis, shared by every
+// replica of the server. The webhooks are encoded with their secrets in the hash <prefix>:webhooks by webhook id,
+// and changed in transactions watching the hash. The delivery log of a webhook is the list
+// <prefix>:deliveries:<id>, latest delivery first.
+type WebhookRepository struct {
+	client redis.UniversalClient
+	prefix string
+	key    string
+}
+
+var _ repository.WebhookManager = (*WebhookRepository)(nil)
+
+// NewWebhookRepository creates a webhook manager keeping the webhooks under the keys with the prefix,
+// such as DefaultPrefix. The client stays owned by the caller.
+func NewWebhookRepository(client redis.UniversalClient, prefix string) *WebhookRepository {
+	return &WebhookRepository{client: client, prefix: prefix, key: prefix + ":webhooks"}
+}
+
+// deliveriesKey returns the key of the delivery log of the webhook.
+func (w *WebhookRepository) deliveriesKey(webhookID string) string {
+	return w.prefix + ":deliveries:" + webhookID
+}
+
+// encodeWebhook returns the encoding of the webhook with its secret.
+func encodeWebhook(webhook models.Webhook) ([]byte, error) {
+	return json.Marshal(storedWebhook{Webhook: webhook, Secret: webhook.Secret})
+}
+
+// decodeWebhook returns the webhook of its encoding with its secret.
+func decodeWebhook(data []byte) (models.Webhook, error) {
+	var stored storedWebhook
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return models.Webhook{}, err
+	}
+	stored.Webhook.Secret = stored.Secret
+
+	return stored.Webhook, nil
+}
+
+// GetWebhooks returns every webhook sorted by ID
+func (w *WebhookRepository) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	values, err := w.client.HVals(ctx, w.key).Result()
+	if err != nil {
+		return make([]models.Webhook, 0), err
+	}
+
+	webhooks := make([]models.Webhook, len(values))
+	for i, value := range values {
+		if webhooks[i], err = decodeWebhook([]byte(value)); err != nil {
+			return make([]models.Webhook, 0), err
+		}
+	}
+	slices.SortFunc(webhooks, func(a, b models.Webhook) int { return strings.Compare(a.ID, b.ID) })
+
+	return webhooks, nil
+}
+
+// GetWebhook returns a webhook by webhook id
+func (w *WebhookRepository) GetWebhook(ctx context.Context, webhookID string) (models.Webhook, error) {
+	return getWebhook(ctx, w.client, w.key, webhookID)
+}
+
+// getWebhook reads the webhook with the id from the hash of the webhooks.
+func getWebhook(ctx context.Context, client redis.Cmdable, key, webhookID string) (models.Webhook, error) {
+	data, err := client.HGet(ctx, key, webhookID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.Webhook{}, repository.ErrWebhookNotFound
+	}
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	return decodeWebhook(data)
+}
+
+// CreateWebhook stores a new webhook with a new webhook id and returns it
+func (w *WebhookRepository) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	webhook.NewWebhookID()
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	data, err := encodeWebhook(webhook)
+	if err != nil {
+		return models.Webhook{}, err
+	}
+
+	if err := w.client.HSet(ctx, w.key, webhook.ID, data).Err(); err != nil {
+		return models.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+// UpdateWebhook replaces the URL, secret and event types of an existing webhook
+func (w *WebhookRepository) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	return watch(ctx, w.client, w.key, func(tx *redis.Tx) error {
+		stored, err := getWebhook(ctx, tx, w.key, webhook.ID)
+		if err != nil {
+			return err
+		}
+
+		stored.URL = webhook.URL
+		stored.Secret = webhook.Secret
+		stored.Events = webhook.Events
+		stored.UpdatedAt = time.Now().UTC()
+		data, err := encodeWebhook(stored)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, w.key, webhook.ID, data).Err()
+		})
+
+		return err
+	})
+}
+
+// DeleteWebhook deletes a webhook and its delivery log by webhook id
+func (w *WebhookRepository) DeleteWebhook(ctx context.Context, webhookID string) error {
+	pipe := w.client.TxPipeline()
+	deleted := pipe.HDel(ctx, w.key, webhookID)
+	pipe.Del(ctx, w.deliveriesKey(webhookID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if deleted.Val() == 0 {
+		return repository.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// RestoreWebhooks stores the webhooks with their ids and times and an empty delivery log,
+// no webhook is stored when one of them already exists
+func (w *WebhookRepository) RestoreWebhooks(ctx context.Context, webhooks []models.Webhook) error {
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	fields := make([]string, len(webhooks))
+	values := make([]any, 0, 2*len(webhooks))
+	for i, webhook := range webhooks {
+		data, err := encodeWebhook(webhook)
+		if err != nil {
+			return err
+		}
+		fields[i] = webhook.ID
+		values = append(values, webhook.ID, data)
+	}
+
+	return watch(ctx, w.client, w.key, func(tx *redis.Tx) error {
+		existing, err := tx.HMGet(ctx, w.key, fields...).Result()
+		if err != nil {
+			return err
+		}
+
+		if slices.ContainsFunc(existing, func(value any) bool { return value != nil }) {
+			return repository.ErrWebhookExists
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, w.key, values...).Err()
+		})
+
+		return err
+	})
+}
+
+// addDeliveryScript prepends the delivery to the log of the webhook if it exists, keeping the latest deliveries.
+// It returns 1 when the delivery is added and 0 when the webhook does not exist.
+// KEYS: webhooks, deliveries of the webhook.
+// ARGV: webhook id, delivery, number of deliveries kept.
+var addDeliveryScript = redis.NewScript(`
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+
+redis.call('LPUSH', KEYS[2], ARGV[2])
+redis.call('LTRIM', KEYS[2], 0, tonumber(ARGV[3]) - 1)
+
+return 1
+`)
+
+// AddDelivery appends a delivery to the log of its webhook, only the latest deliveries are kept
+func (w *WebhookRepository) AddDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	delivery.NewWebhookDeliveryID()
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{w.key, w.deliveriesKey(delivery.WebhookID)}
+	added, err := addDeliveryScript.Run(ctx, w.client, keys, delivery.WebhookID, data, maxDeliveries).Int()
+	if err != nil {
+		return err
+	}
+
+	if added == 0 {
+		return repository.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ClaimDelivery claims the delivery of the event to the webhook, it returns false when the delivery was already
+// claimed, such as by another replica. The claim is kept in the key <prefix>:claim:<webhook id>:<event id>,
+// which expires after claimTTL.
+func (w *WebhookRepository) ClaimDelivery(ctx context.Context, webhookID string, eventID uint64) (bool, error) {
+	return w.client.SetNX(ctx, w.prefix+":claim:"+webhookID+":"+strconv.FormatUint(eventID, 10), 1, claimTTL).Result()
+}
+
+// GetDeliveries returns the delivery log of a webhook, the latest delivery first
+func (w *WebhookRepository) GetDeliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	pipe := w.client.TxPipeline()
+	exists := pipe.HExists(ctx, w.key, webhookID)
+	values := pipe.LRange(ctx, w.deliveriesKey(webhookID), 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return make([]models.WebhookDelivery, 0), err
+	}
+
+	if !exists.Val() {
+		return make([]models.WebhookDelivery, 0), repository.ErrWebhookNotFound
+	}
+
+	deliveries := make([]models.WebhookDelivery, len(values.Val()))
+	for i, value := range values.Val() {
+		if err := json.Unmarshal([]byte(value), &deliveries[i]); err != nil {
+			return make([]models.WebhookDelivery, 0), err
+		}
+	}
+
+	return deliveries, nil
+}
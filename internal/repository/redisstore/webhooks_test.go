@@ -0,0 +1,128 @@
+package redisstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	// the replicas share the webhooks
+	repo, replica := NewWebhookRepository(client, DefaultPrefix), NewWebhookRepository(client, DefaultPrefix)
+
+	ciHook, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ciHook.ID)
+
+	chatHook, err := replica.CreateWebhook(ctx, models.Webhook{URL: "https://chat.example.com/hooks", Events: []models.TaskEventType{models.TaskCompleted}})
+	assert.NoError(t, err)
+
+	// the secrets are kept along with the webhooks
+	webhooks, err := replica.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Webhook{ciHook, chatHook}, webhooks)
+
+	ciHook.Secret = "rotated"
+	ciHook.Events = []models.TaskEventType{models.TaskDeleted}
+	assert.NoError(t, replica.UpdateWebhook(ctx, ciHook))
+
+	got, err := repo.GetWebhook(ctx, ciHook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "rotated", got.Secret)
+	assert.Equal(t, []models.TaskEventType{models.TaskDeleted}, got.Events)
+
+	assert.NoError(t, repo.DeleteWebhook(ctx, chatHook.ID))
+
+	_, err = replica.GetWebhook(ctx, chatHook.ID)
+	assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+	assert.ErrorIs(t, replica.UpdateWebhook(ctx, chatHook), repository.ErrWebhookNotFound)
+	assert.ErrorIs(t, replica.DeleteWebhook(ctx, chatHook.ID), repository.ErrWebhookNotFound)
+}
+
+func TestWebhookRepository_Deliveries(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	repo := NewWebhookRepository(client, DefaultPrefix)
+
+	webhook, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci"})
+	assert.NoError(t, err)
+
+	for attempt := 1; attempt <= maxDeliveries+2; attempt++ {
+		assert.NoError(t, repo.AddDelivery(ctx, models.WebhookDelivery{WebhookID: webhook.ID, EventID: 1, Attempt: attempt}))
+	}
+
+	deliveries, err := repo.GetDeliveries(ctx, webhook.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, maxDeliveries)
+	assert.Equal(t, maxDeliveries+2, deliveries[0].Attempt)
+	assert.Equal(t, 3, deliveries[len(deliveries)-1].Attempt)
+	assert.NotEmpty(t, deliveries[0].ID)
+
+	assert.ErrorIs(t, repo.AddDelivery(ctx, models.WebhookDelivery{WebhookID: "unknown"}), repository.ErrWebhookNotFound)
+	_, err = repo.GetDeliveries(ctx, "unknown")
+	assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+
+	// the delivery log is deleted along with the webhook
+	assert.NoError(t, repo.DeleteWebhook(ctx, webhook.ID))
+	_, err = repo.GetDeliveries(ctx, webhook.ID)
+	assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+
+	exists, err := client.Exists(ctx, DefaultPrefix+":deliveries:"+webhook.ID).Result()
+	assert.NoError(t, err)
+	assert.Zero(t, exists)
+}
+
+func TestWebhookRepository_ClaimDelivery(t *testing.T) {
+	ctx := context.Background()
+	client := newClient(t)
+	repo, replica := NewWebhookRepository(client, DefaultPrefix), NewWebhookRepository(client, DefaultPrefix)
+
+	claimed, err := repo.ClaimDelivery(ctx, "webhook1", 1)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// every delivery is claimed once across the replicas
+	claimed, err = replica.ClaimDelivery(ctx, "webhook1", 1)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	claimed, err = replica.ClaimDelivery(ctx, "webhook1", 2)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = replica.ClaimDelivery(ctx, "webhook2", 1)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	ttl, err := client.TTL(ctx, DefaultPrefix+":claim:webhook1:1").Result()
+	assert.NoError(t, err)
+	assert.Equal(t, claimTTL, ttl)
+}
+
+func TestWebhookRepository_RestoreWebhooks(t *testing.T) {
+	ctx := context.Background()
+	repo := NewWebhookRepository(newClient(t), DefaultPrefix)
+
+	created, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci"})
+	assert.NoError(t, err)
+
+	restored := []models.Webhook{
+		{ID: "cv2k1ts2hf8ng030mvc0", URL: "https://chat.example.com/hooks", Secret: "chat", CreatedAt: created.CreatedAt.Add(-time.Hour)},
+		{ID: "cv2k1ts2hf8ng030mvd0", URL: "https://ops.example.com/hooks", Secret: "ops", Events: []models.TaskEventType{models.TaskDeleted}},
+	}
+	assert.NoError(t, repo.RestoreWebhooks(ctx, restored))
+
+	// no webhook is restored when one of them exists
+	err = repo.RestoreWebhooks(ctx, []models.Webhook{{ID: "cv2k1ts2hf8ng030mve0"}, restored[0]})
+	assert.ErrorIs(t, err, repository.ErrWebhookExists)
+
+	webhooks, err := repo.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, append(restored, created), webhooks)
+}
@@ -2,14 +2,82 @@ package repository
 
 import (
 	"context"
+	"time"
 
+	"github.com/brionac626/taskManager/internal/query"
 	"github.com/brionac626/taskManager/models"
 )
 
 // TaskManager represents a task manager to manage tasks in the memory
 type TaskManager interface {
 	GetTasks(ctx context.Context) ([]models.Task, error)
+	ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error)
+	GetTask(ctx context.Context, taskID string) (models.Task, error)
 	CreateTasks(ctx context.Context, tasks []models.Task) error
+	RestoreTasks(ctx context.Context, tasks []models.Task) error
 	UpdateTask(ctx context.Context, taskID string, name *string, status *int) error
+	CompareAndSwapTask(ctx context.Context, previous, task models.Task) error
 	DeleteTask(ctx context.Context, taskID string) error
+	BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error)
+	BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error)
+	SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error)
+	QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error)
+	SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error)
+	TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error)
+}
+
+// Publisher represents a receiver of the task events published on every mutation of a repository
+type Publisher interface {
+	Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent
+}
+
+// Publish publishes a task event to the publisher of a repository, a repository without publisher passes nil
+func Publish(publisher Publisher, eventType models.TaskEventType, task models.Task) {
+	if publisher != nil {
+		publisher.Publish(eventType, task)
+	}
+}
+
+// PublishUpdate publishes the update of a task, followed by its completion when the update marks it as done
+func PublishUpdate(publisher Publisher, previous, task models.Task) {
+	Publish(publisher, models.TaskUpdated, task)
+	if previous.Status != 1 && task.Status == 1 {
+		Publish(publisher, models.TaskCompleted, task)
+	}
+}
+
+// Apply returns the task with the name and the status changed when given
+func Apply(task models.Task, name *string, status *int) models.Task {
+	if name != nil {
+		task.Name = *name
+	}
+
+	if status != nil {
+		task.Status = *status
+	}
+
+	return task
+}
+
+// ViewManager represents a view manager to manage the saved views of the users
+type ViewManager interface {
+	GetViews(ctx context.Context, owner string) ([]models.View, error)
+	ListViews(ctx context.Context) ([]models.View, error)
+	GetView(ctx context.Context, viewID string) (models.View, error)
+	CreateView(ctx context.Context, view models.View) (models.View, error)
+	UpdateView(ctx context.Context, view models.View) error
+	DeleteView(ctx context.Context, viewID string) error
+	RestoreViews(ctx context.Context, views []models.View) error
+}
+
+// WebhookManager represents a webhook manager to manage the webhook subscriptions and their delivery log
+type WebhookManager interface {
+	GetWebhooks(ctx context.Context) ([]models.Webhook, error)
+	GetWebhook(ctx context.Context, webhookID string) (models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook models.Webhook) error
+	DeleteWebhook(ctx context.Context, webhookID string) error
+	RestoreWebhooks(ctx context.Context, webhooks []models.Webhook) error
+	AddDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error)
 }
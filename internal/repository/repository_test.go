@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApply(t *testing.T) {
+	name := "Updated Task"
+	status := 1
+	task := models.Task{ID: "cv1p7ba0f4g3tpdg5r0g", Name: "Task", Status: 0}
+
+	tests := []struct {
+		name   string
+		tName  *string
+		status *int
+		want   models.Task
+	}{
+		{
+			name: "nothing changed",
+			want: task,
+		},
+		{
+			name:  "name changed",
+			tName: &name,
+			want:  models.Task{ID: task.ID, Name: name, Status: 0},
+		},
+		{
+			name:   "status changed",
+			status: &status,
+			want:   models.Task{ID: task.ID, Name: task.Name, Status: status},
+		},
+		{
+			name:   "name and status changed",
+			tName:  &name,
+			status: &status,
+			want:   models.Task{ID: task.ID, Name: name, Status: status},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Apply(task, tt.tName, tt.status))
+		})
+	}
+}
+
+func TestPublishUpdate(t *testing.T) {
+	todo := models.Task{ID: "cv1p7ba0f4g3tpdg5r0g", Name: "Task", Status: 0}
+	done := models.Task{ID: "cv1p7ba0f4g3tpdg5r0g", Name: "Task", Status: 1}
+
+	tests := []struct {
+		name     string
+		previous models.Task
+		task     models.Task
+		want     []models.TaskEventType
+	}{
+		{
+			name:     "task renamed",
+			previous: todo,
+			task:     models.Task{ID: todo.ID, Name: "Updated Task", Status: 0},
+			want:     []models.TaskEventType{models.TaskUpdated},
+		},
+		{
+			name:     "task completed",
+			previous: todo,
+			task:     done,
+			want:     []models.TaskEventType{models.TaskUpdated, models.TaskCompleted},
+		},
+		{
+			name:     "completed task updated",
+			previous: done,
+			task:     done,
+			want:     []models.TaskEventType{models.TaskUpdated},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{}
+			PublishUpdate(publisher, tt.previous, tt.task)
+
+			got := make([]models.TaskEventType, 0, len(publisher.events))
+			for _, e := range publisher.events {
+				got = append(got, e.Type)
+				assert.Equal(t, tt.task, e.Task)
+			}
+			assert.Equal(t, tt.want, got)
+
+			assert.NotPanics(t, func() { PublishUpdate(nil, tt.previous, tt.task) })
+		})
+	}
+}
@@ -0,0 +1,634 @@
+// Package repositorytest provides a conformance suite verifying that an implementation of
+// repository.TaskManager honours the contracts of the interface, so that every backend is
+// verified identically.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// Factory returns an empty repository, the resources of the repository are released with t.Cleanup.
+type Factory func(t *testing.T) repository.TaskManager
+
+// concurrency is the number of goroutines, and of mutations of every goroutine, of the concurrent tests
+const concurrency = 8
+
+// Run runs the conformance suite against the repositories returned by factory, which is called once per subtest.
+// The subtests are not run in parallel so that the factory may reset a storage shared by its repositories.
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo repository.TaskManager)
+	}{
+		{name: "ordering", test: testOrdering},
+		{name: "pages", test: testPages},
+		{name: "not found", test: testNotFound},
+		{name: "create and update", test: testCreateAndUpdate},
+		{name: "compare and swap", test: testCompareAndSwap},
+		{name: "restore", test: testRestore},
+		{name: "batch", test: testBatch},
+		{name: "search", test: testSearch},
+		{name: "query", test: testQuery},
+		{name: "sync", test: testSync},
+		{name: "as of", test: testAsOf},
+		{name: "context cancellation", test: testContextCancellation},
+		{name: "concurrent creations", test: testConcurrentCreations},
+		{name: "concurrent updates", test: testConcurrentUpdates},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, factory(t))
+		})
+	}
+}
+
+// testOrdering verifies that the tasks are listed in the order of their ids, whatever the order they are stored in.
+func testOrdering(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	// restored out of order
+	restored := []models.Task{
+		{ID: "d3bkmd6hf8ng0305igb0", Name: "Task 3"},
+		{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1", Status: 1},
+		{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"},
+	}
+	assert.NoError(t, repo.RestoreTasks(ctx, restored))
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{restored[1], restored[2], restored[0]}, got)
+
+	// the ids of the created tasks follow the ids of the existing tasks
+	created := []models.Task{{Name: "Task 4"}, {Name: "Task 5"}}
+	assert.NoError(t, repo.CreateTasks(ctx, created))
+
+	got, err = repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{restored[1], restored[2], restored[0], created[0], created[1]}, got)
+
+	q, err := query.Parse("open")
+	assert.NoError(t, err)
+	got, err = repo.QueryTasks(ctx, q)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{restored[2], restored[0], created[0], created[1]}, got)
+}
+
+// testPages verifies that the pages of tasks follow the given task id in the order of the ids.
+func testPages(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{
+		{ID: "d3bkmd6hf8ng0305igb0", Name: "Task 3"},
+		{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"},
+		{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2", Status: 1},
+		{ID: "d3bkmd6hf8ng0305igc0", Name: "Task 4"},
+		{ID: "d3bkmd6hf8ng0305igd0", Name: "Task 5"},
+	}
+	assert.NoError(t, repo.RestoreTasks(ctx, tasks))
+	sorted := []models.Task{tasks[1], tasks[2], tasks[0], tasks[3], tasks[4]}
+
+	tests := []struct {
+		name  string
+		after string
+		limit int
+		want  []models.Task
+	}{
+		{name: "first page", limit: 2, want: sorted[:2]},
+		{name: "next page", after: sorted[1].ID, limit: 2, want: sorted[2:4]},
+		{name: "last page", after: sorted[3].ID, limit: 2, want: sorted[4:]},
+		{name: "after the last task", after: sorted[4].ID, limit: 2, want: []models.Task{}},
+		{name: "after a missing id", after: "d3bkmd6hf8ng0305iga5", limit: 2, want: sorted[2:4]},
+		{name: "without limit", after: sorted[0].ID, want: sorted[1:]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.ListTasks(ctx, tt.after, tt.limit)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// testNotFound verifies that the operations on a missing task return repository.ErrTaskNotFound.
+func testNotFound(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[0].ID))
+
+	name, status := "Task", 1
+	for _, taskID := range []string{"unknown", "d3bkmd6hf8ng0305ig90", tasks[0].ID} {
+		t.Run(taskID, func(t *testing.T) {
+			_, err := repo.GetTask(ctx, taskID)
+			assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+			assert.ErrorIs(t, repo.UpdateTask(ctx, taskID, &name, &status), repository.ErrTaskNotFound)
+			err = repo.CompareAndSwapTask(ctx, models.Task{ID: taskID, Name: "Task 1"}, models.Task{ID: taskID, Name: name, Status: status})
+			assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+			assert.ErrorIs(t, repo.DeleteTask(ctx, taskID), repository.ErrTaskNotFound)
+		})
+	}
+
+	// the failed operations do not create the tasks
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+// testCreateAndUpdate verifies that the ids of the created tasks are assigned and that an update only changes the given fields.
+func testCreateAndUpdate(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Write report"}, {Name: "Review report", Status: 1}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NotEmpty(t, tasks[0].ID)
+	assert.NotEmpty(t, tasks[1].ID)
+	assert.NotEqual(t, tasks[0].ID, tasks[1].ID)
+
+	name, status := "Write the report", 1
+	tests := []struct {
+		name   string
+		update func() error
+		want   models.Task
+	}{
+		{
+			name:   "name",
+			update: func() error { return repo.UpdateTask(ctx, tasks[0].ID, &name, nil) },
+			want:   models.Task{ID: tasks[0].ID, Name: "Write the report"},
+		},
+		{
+			name:   "status",
+			update: func() error { return repo.UpdateTask(ctx, tasks[0].ID, nil, &status) },
+			want:   models.Task{ID: tasks[0].ID, Name: "Write the report", Status: 1},
+		},
+		{
+			name:   "nothing",
+			update: func() error { return repo.UpdateTask(ctx, tasks[0].ID, nil, nil) },
+			want:   models.Task{ID: tasks[0].ID, Name: "Write the report", Status: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.update())
+
+			got, err := repo.GetTask(ctx, tt.want.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	// the other task is left unchanged
+	got, err := repo.GetTask(ctx, tasks[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks[1], got)
+
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Write the report", Status: 1}}, all)
+}
+
+// testCompareAndSwap verifies that a task is only replaced while it is the previous task, and that a single
+// one of the replacements of the same previous task made concurrently is applied.
+func testCompareAndSwap(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Write report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	task := tasks[0]
+
+	tests := []struct {
+		name     string
+		previous models.Task
+		task     models.Task
+		wantErr  error
+		want     models.Task
+	}{
+		{
+			name:     "stale name",
+			previous: models.Task{ID: task.ID, Name: "Write the report"},
+			task:     models.Task{ID: task.ID, Name: "Write the final report"},
+			wantErr:  repository.ErrTaskChanged,
+			want:     task,
+		},
+		{
+			name:     "stale status",
+			previous: models.Task{ID: task.ID, Name: task.Name, Status: 1},
+			task:     models.Task{ID: task.ID, Name: "Write the final report"},
+			wantErr:  repository.ErrTaskChanged,
+			want:     task,
+		},
+		{
+			name:     "current task",
+			previous: task,
+			task:     models.Task{ID: task.ID, Name: "Write the report", Status: 1},
+			want:     models.Task{ID: task.ID, Name: "Write the report", Status: 1},
+		},
+		{
+			name:     "replaced task",
+			previous: task,
+			task:     models.Task{ID: task.ID, Name: "Write the final report"},
+			wantErr:  repository.ErrTaskChanged,
+			want:     models.Task{ID: task.ID, Name: "Write the report", Status: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repo.CompareAndSwapTask(ctx, tt.previous, tt.task), tt.wantErr)
+
+			got, err := repo.GetTask(ctx, task.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	previous, err := repo.GetTask(ctx, task.ID)
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	swapped := make([]bool, concurrency)
+	for i := range swapped {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repo.CompareAndSwapTask(ctx, previous, models.Task{ID: task.ID, Name: fmt.Sprintf("Report %d", i)})
+			if !errors.Is(err, repository.ErrTaskChanged) {
+				assert.NoError(t, err)
+				swapped[i] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetTask(ctx, task.ID)
+	assert.NoError(t, err)
+	winners := make([]models.Task, 0, 1)
+	for i, ok := range swapped {
+		if ok {
+			winners = append(winners, models.Task{ID: task.ID, Name: fmt.Sprintf("Report %d", i)})
+		}
+	}
+	assert.Equal(t, []models.Task{got}, winners)
+}
+
+// testRestore verifies that the restored tasks keep their ids and that no task is restored when one of them is invalid.
+func testRestore(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	existing := []models.Task{{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1", Status: 1}}
+	assert.NoError(t, repo.RestoreTasks(ctx, existing))
+
+	got, err := repo.GetTask(ctx, existing[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, existing[0], got)
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{name: "invalid id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "1", Name: "Task 3"}}, wantErr: repository.ErrTaskID},
+		{name: "existing id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305ig90", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+		{name: "repeated id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305iga0", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repo.RestoreTasks(ctx, tt.tasks), tt.wantErr)
+
+			got, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, existing, got)
+		})
+	}
+}
+
+// testBatch verifies the outcome of every task of a batch, in the order of the given ids.
+func testBatch(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	status := 1
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, "unknown", tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+
+	results, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+}
+
+// testSearch verifies that the search ranks the exact matches first, highlights the matches and honours the limit.
+func testSearch(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{
+		{Name: "Deploy the API"},
+		{Name: "Review the deployment"},
+		{Name: "deploy"},
+		{Name: "Write report"},
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	results, err := repo.SearchTasks(ctx, "deploy", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	if assert.NotEmpty(t, results) {
+		assert.Equal(t, tasks[2], results[0].Task)
+		assert.Equal(t, "<mark>deploy</mark>", results[0].Highlight)
+	}
+	for _, result := range results {
+		assert.NotEqual(t, tasks[3].ID, result.Task.ID)
+	}
+
+	results, err = repo.SearchTasks(ctx, "deploy", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	// the deleted tasks are no longer found
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[3].ID))
+	results, err = repo.SearchTasks(ctx, "report", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+// testQuery verifies that a query returns the tasks it matches, in the order of their ids.
+func testQuery(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{
+		{Name: "Deploy the API"},
+		{Name: "Deploy the docs", Status: 1},
+		{Name: "Écrire la documentation"},
+		{Name: "deploy"},
+		{Name: "Review the deployment", Status: 1},
+		{Name: "Write report"},
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+
+	for _, text := range []string{
+		"open",
+		"status:done deploy",
+		"deploy AND NOT done",
+		"écrire",
+		`name="DEPLOY"`,
+		"NOT (deploy OR report)",
+		"id=" + tasks[2].ID,
+		"id=" + tasks[2].ID + " done",
+		"id>" + tasks[3].ID,
+	} {
+		t.Run(text, func(t *testing.T) {
+			q, err := query.Parse(text)
+			assert.NoError(t, err)
+
+			got, err := repo.QueryTasks(ctx, q)
+			assert.NoError(t, err)
+			assert.Equal(t, q.Filter(all), got)
+		})
+	}
+}
+
+// testSync verifies that the changes since a sequence number return the changed tasks and the ids of the deleted tasks.
+func testSync(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: []models.Task{}, Deleted: []string{}, Seq: 0}, changes)
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	// every task, without the deleted ones
+	changes, err = repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: tasks, Deleted: []string{}, Seq: 3}, changes)
+
+	name := "Task 3 renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[2].ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+
+	// ordered by their last change
+	changes, err = repo.SyncTasks(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: tasks[2].ID, Name: name}, {ID: tasks[0].ID, Name: name}},
+		Deleted: []string{tasks[1].ID},
+		Seq:     6,
+	}, changes)
+
+	changes, err = repo.SyncTasks(ctx, 6)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: []models.Task{}, Deleted: []string{}, Seq: 6}, changes)
+
+	_, err = repo.SyncTasks(ctx, 7)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+}
+
+// testAsOf verifies that the tasks are returned as they were at a time, using the clock of the repository.
+func testAsOf(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	created, err := repo.TasksAsOf(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, created)
+
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+
+	got, err := repo.TasksAsOf(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+
+	got, err = repo.TasksAsOf(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+// testContextCancellation verifies that every operation returns the error of a canceled context without changing the tasks.
+func testContextCancellation(t *testing.T, repo repository.TaskManager) {
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(context.Background(), tasks))
+	taskID := tasks[0].ID
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	q, err := query.Parse("open")
+	assert.NoError(t, err)
+	name, status := "Task 1 renamed", 1
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "GetTasks", call: func() error { _, err := repo.GetTasks(ctx); return err }},
+		{name: "ListTasks", call: func() error { _, err := repo.ListTasks(ctx, "", 1); return err }},
+		{name: "GetTask", call: func() error { _, err := repo.GetTask(ctx, taskID); return err }},
+		{name: "CreateTasks", call: func() error { return repo.CreateTasks(ctx, []models.Task{{Name: "Task 2"}}) }},
+		{name: "RestoreTasks", call: func() error {
+			return repo.RestoreTasks(ctx, []models.Task{{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 2"}})
+		}},
+		{name: "UpdateTask", call: func() error { return repo.UpdateTask(ctx, taskID, &name, &status) }},
+		{name: "CompareAndSwapTask", call: func() error {
+			return repo.CompareAndSwapTask(ctx, tasks[0], models.Task{ID: taskID, Name: name, Status: status})
+		}},
+		{name: "DeleteTask", call: func() error { return repo.DeleteTask(ctx, taskID) }},
+		{name: "BatchUpdateTasks", call: func() error {
+			_, err := repo.BatchUpdateTasks(ctx, []string{taskID}, &name, &status)
+			return err
+		}},
+		{name: "BatchDeleteTasks", call: func() error { _, err := repo.BatchDeleteTasks(ctx, []string{taskID}); return err }},
+		{name: "SearchTasks", call: func() error { _, err := repo.SearchTasks(ctx, "task", 0); return err }},
+		{name: "QueryTasks", call: func() error { _, err := repo.QueryTasks(ctx, q); return err }},
+		{name: "SyncTasks", call: func() error { _, err := repo.SyncTasks(ctx, 0); return err }},
+		{name: "TasksAsOf", call: func() error { _, err := repo.TasksAsOf(ctx, time.Now()); return err }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.call(), context.Canceled)
+		})
+	}
+
+	got, err := repo.GetTasks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+}
+
+// testConcurrentCreations verifies that the tasks created concurrently are all kept with distinct ids.
+func testConcurrentCreations(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	created := make([][]models.Task, concurrency)
+	for i := range created {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range concurrency {
+				tasks := []models.Task{{Name: fmt.Sprintf("Task %d-%d", i, j)}}
+				assert.NoError(t, repo.CreateTasks(ctx, tasks))
+				created[i] = append(created[i], tasks...)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var want []models.Task
+	for _, tasks := range created {
+		want = append(want, tasks...)
+	}
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, got, concurrency*concurrency)
+	assert.ElementsMatch(t, want, got)
+	assert.IsIncreasing(t, ids(got))
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(concurrency*concurrency), changes.Seq)
+}
+
+// testConcurrentUpdates verifies that no update is lost when the same task and distinct tasks are updated concurrently.
+func testConcurrentUpdates(t *testing.T, repo repository.TaskManager) {
+	ctx := context.Background()
+
+	shared := []models.Task{{Name: "Shared"}}
+	assert.NoError(t, repo.CreateTasks(ctx, shared))
+	own := make([]models.Task, concurrency)
+	for i := range own {
+		own[i].Name = fmt.Sprintf("Task %d", i)
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, own))
+
+	before, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+
+	statuses := make(map[string]int) // status of every name of the shared task
+	for i := range concurrency {
+		for j := range concurrency {
+			statuses[fmt.Sprintf("Shared %d-%d", i, j)] = j % 2
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := range concurrency {
+				name := fmt.Sprintf("Shared %d-%d", i, j)
+				status := statuses[name]
+				assert.NoError(t, repo.UpdateTask(ctx, shared[0].ID, &name, &status))
+
+				// the updates of a task of its own are applied in order
+				name = fmt.Sprintf("Task %d-%d", i, j)
+				assert.NoError(t, repo.UpdateTask(ctx, own[i].ID, &name, nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetTask(ctx, shared[0].ID)
+	assert.NoError(t, err)
+	// the name and the status of the shared task come from the same update
+	status, ok := statuses[got.Name]
+	assert.True(t, ok, "unexpected name %q", got.Name)
+	assert.Equal(t, status, got.Status)
+
+	for i, task := range own {
+		got, err := repo.GetTask(ctx, task.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, models.Task{ID: task.ID, Name: fmt.Sprintf("Task %d-%d", i, concurrency-1)}, got)
+	}
+
+	// every update records at least one change, a backend may record a change per updated field
+	after, err := repo.SyncTasks(ctx, before.Seq)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, after.Seq, before.Seq+2*concurrency*concurrency)
+	assert.Len(t, after.Tasks, concurrency+1)
+}
+
+// ids returns the ids of the tasks
+func ids(tasks []models.Task) []string {
+	taskIDs := make([]string, len(tasks))
+	for i, task := range tasks {
+		taskIDs[i] = task.ID
+	}
+
+	return taskIDs
+}
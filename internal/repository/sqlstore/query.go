@@ -0,0 +1,187 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+)
+
+// comparisons maps the operators of the id predicates to the SQL operators
+var comparisons = map[string]string{":": "=", "=": "=", "!=": "<>", "<": "<", "<=": "<=", ">": ">", ">=": ">="}
+
+// QueryTasksPage returns at most limit tasks matching the query, sorted by ID and following the task id after.
+// A nil query matches every task, an empty after starts from the first task and a non-positive limit
+// returns every task. The predicates the database can evaluate are pushed into the statement along with
+// the limit, the rest of the query is evaluated on the rows as they are read.
+func (r *Repository) QueryTasksPage(ctx context.Context, q *query.Query, after string, limit int) ([]models.Task, error) {
+	var conditions []string
+	var args []any
+	var match func(models.Task) bool
+	if q != nil {
+		where, whereArgs, exact := condition(q.Root)
+		if where != "" {
+			conditions = append(conditions, where)
+			args = append(args, whereArgs...)
+		}
+
+		if !exact {
+			match = q.Match
+		}
+	}
+
+	if after != "" {
+		conditions = append(conditions, "id > ?")
+		args = append(args, after)
+	}
+
+	return r.selectTasks(ctx, strings.Join(conditions, " AND "), args, limit, match)
+}
+
+// selectTasks returns at most limit tasks, sorted by ID, satisfying the SQL condition and matching match when given.
+// A non-positive limit returns every task.
+func (r *Repository) selectTasks(ctx context.Context, where string, args []any, limit int, match func(models.Task) bool) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	if r.prepared == nil {
+		return make([]models.Task, 0), ErrClosed
+	}
+
+	statement := "SELECT id, name, status FROM tasks"
+	if where != "" {
+		statement += " WHERE " + where
+	}
+	statement += " ORDER BY id"
+	if limit > 0 && match == nil {
+		statement += " LIMIT " + strconv.Itoa(limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return scanTasks(rows, limit, match)
+}
+
+// scanTasks reads at most limit tasks matching match when given, and closes the rows.
+// A non-positive limit reads every row.
+func scanTasks(rows *sql.Rows, limit int, match func(models.Task) bool) ([]models.Task, error) {
+	defer rows.Close()
+
+	tasks := make([]models.Task, 0)
+	for rows.Next() {
+		var task models.Task
+		if err := rows.Scan(&task.ID, &task.Name, &task.Status); err != nil {
+			return make([]models.Task, 0), err
+		}
+
+		if match != nil && !match(task) {
+			continue
+		}
+
+		tasks = append(tasks, task)
+		if limit > 0 && len(tasks) == limit {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return tasks, nil
+}
+
+// condition translates the query node to a SQL condition on the tasks table and its arguments.
+// The condition holds for every task matching the node, exact reports whether it holds for these tasks only.
+// An empty condition holds for every task, e.g. for the name equalities that compare the names without case.
+func condition(node query.Node) (where string, args []any, exact bool) {
+	switch n := node.(type) {
+	case *query.And:
+		left, leftArgs, leftExact := condition(n.Left)
+		right, rightArgs, rightExact := condition(n.Right)
+		switch {
+		case left == "":
+			return right, rightArgs, false
+		case right == "":
+			return left, leftArgs, false
+		}
+
+		return "(" + left + " AND " + right + ")", append(leftArgs, rightArgs...), leftExact && rightExact
+	case *query.Or:
+		left, leftArgs, leftExact := condition(n.Left)
+		right, rightArgs, rightExact := condition(n.Right)
+		if left == "" || right == "" {
+			return "", nil, false
+		}
+
+		return "(" + left + " OR " + right + ")", append(leftArgs, rightArgs...), leftExact && rightExact
+	case *query.Not:
+		// only an exact condition can be negated, the negation of a wider condition misses tasks
+		x, xArgs, xExact := condition(n.X)
+		if !xExact {
+			return "", nil, false
+		}
+
+		return "NOT " + x, xArgs, true
+	case *query.Predicate:
+		return predicateCondition(n)
+	}
+
+	return "", nil, false
+}
+
+// predicateCondition translates a predicate to a SQL condition, see condition.
+func predicateCondition(p *query.Predicate) (string, []any, bool) {
+	switch p.Field {
+	case "id":
+		return "id " + comparisons[p.Op] + " ?", []any{p.Value}, true
+	case "status":
+		status, ok := query.ParseStatus(p.Value)
+		if !ok {
+			return "", nil, false
+		}
+
+		if p.Op == "!=" {
+			return "status <> ?", []any{status}, true
+		}
+
+		return "status = ?", []any{status}, true
+	case "name":
+		if p.Op != ":" {
+			return "", nil, false
+		}
+
+		where, args := termsCondition(search.Tokenize(p.Value))
+
+		return where, args, where != ""
+	}
+
+	return "", nil, false
+}
+
+// termsCondition returns the SQL condition of the tasks having a word prefixed by every term.
+// The terms are made of letters and digits only, they never hold a LIKE wildcard.
+func termsCondition(queryTerms []string) (string, []any) {
+	if len(queryTerms) == 0 {
+		return "", nil
+	}
+
+	conditions := make([]string, 0, len(queryTerms))
+	args := make([]any, 0, len(queryTerms))
+	for _, term := range queryTerms {
+		conditions = append(conditions, "terms LIKE ?")
+		args = append(args, "% "+term+"%")
+	}
+
+	return "(" + strings.Join(conditions, " AND ") + ")", args
+}
@@ -0,0 +1,103 @@
+package sqlstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_condition(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantWhere string
+		wantArgs  []any
+		wantExact bool
+	}{
+		{name: "status keyword", query: "open", wantWhere: "status = ?", wantArgs: []any{0}, wantExact: true},
+		{name: "id comparison", query: "id>=a1 status!=done", wantWhere: "(id >= ? AND status <> ?)", wantArgs: []any{"a1", 1}, wantExact: true},
+		{name: "name words", query: `name:"infra docs"`, wantWhere: "(terms LIKE ? AND terms LIKE ?)", wantArgs: []any{"% infra%", "% docs%"}, wantExact: true},
+		{name: "negation", query: "NOT (deploy OR done)", wantWhere: "NOT ((terms LIKE ?) OR status = ?)", wantArgs: []any{"% deploy%", 1}, wantExact: true},
+		{name: "name equality", query: `open name="Deploy"`, wantWhere: "status = ?", wantArgs: []any{0}, wantExact: false},
+		{name: "disjunction with a name equality", query: `open OR name="Deploy"`, wantExact: false},
+		{name: "negated name equality", query: `NOT name="Deploy"`, wantExact: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.query)
+			assert.NoError(t, err)
+
+			where, args, exact := condition(q.Root)
+			assert.Equal(t, tt.wantWhere, where)
+			assert.Equal(t, tt.wantArgs, args)
+			assert.Equal(t, tt.wantExact, exact)
+		})
+	}
+}
+
+func TestRepository_QueryTasksPage(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{
+		{Name: "Deploy the API"},
+		{Name: "Deploy the docs", Status: 1},
+		{Name: "Écrire la documentation"},
+		{Name: "deploy"},
+		{Name: "Review the deployment", Status: 1},
+		{Name: "Write report"},
+	}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	all, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, all, len(tasks))
+
+	// the database returns the tasks the query matches in memory
+	for _, text := range []string{
+		"open",
+		"done deploy",
+		"deploy AND NOT done",
+		"écrire",
+		`name="DEPLOY"`,
+		`open OR name="deploy"`,
+		"NOT (deploy OR report)",
+		"id>" + all[2].ID,
+	} {
+		t.Run(text, func(t *testing.T) {
+			q, err := query.Parse(text)
+			assert.NoError(t, err)
+
+			got, err := repo.QueryTasks(ctx, q)
+			assert.NoError(t, err)
+			assert.Equal(t, q.Filter(all), got)
+		})
+	}
+
+	// the pages follow each other in the order of the ids
+	q, err := query.Parse("open")
+	assert.NoError(t, err)
+	open := q.Filter(all)
+
+	page, err := repo.QueryTasksPage(ctx, q, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, open[:2], page)
+
+	page, err = repo.QueryTasksPage(ctx, q, page[1].ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, open[2:], page)
+
+	// the limit applies to the tasks matching the query evaluated in memory
+	q, err = query.Parse(`open name!="deploy"`)
+	assert.NoError(t, err)
+
+	page, err = repo.QueryTasksPage(ctx, q, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, q.Filter(all)[:2], page)
+}
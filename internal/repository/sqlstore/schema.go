@@ -0,0 +1,122 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/brionac626/taskManager/internal/schema"
+)
+
+// versionTableStatement creates the table holding the schema version of the database, in a single row
+const versionTableStatement = `CREATE TABLE IF NOT EXISTS schema_version (version INTEGER NOT NULL)`
+
+// Migrations are the schema migrations of the databases, run by the server on startup.
+//
+// Version 1 is the database without schema_version table. Version 2 records the schema version in it.
+var Migrations = schema.NewRegistry(
+	schema.Migration[DB]{
+		Version:     2,
+		Description: "record the schema version in the schema_version table",
+		Apply:       createVersionTable,
+	},
+)
+
+// DB is a store of the tasks of a database, recording the schema version in its schema_version table.
+type DB struct {
+	*sql.DB
+}
+
+// ReadVersion returns the schema version recorded in the database, or 0 when there is none.
+func (db DB) ReadVersion(ctx context.Context) (int, error) {
+	exists, err := hasTable(ctx, db.DB, "schema_version")
+	if err != nil || !exists {
+		return 0, err
+	}
+
+	var version int
+	err = db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// WriteVersion records the schema version in the database, creating the schema_version table when missing.
+func (db DB) WriteVersion(ctx context.Context, version int) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, statement := range []string{versionTableStatement, `DELETE FROM schema_version`} {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_version (version) VALUES (?)`, version); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// hasTable returns whether the database has the table.
+func hasTable(ctx context.Context, db *sql.DB, name string) (bool, error) {
+	var count int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+
+	return count > 0, err
+}
+
+// checkSchema records the current schema version in a new database, and returns an error
+// unless an existing database has the current version.
+func checkSchema(ctx context.Context, db *sql.DB) error {
+	version, err := DB{db}.ReadVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	if version == 0 {
+		exists, err := hasTable(ctx, db, "tasks")
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return DB{db}.WriteVersion(ctx, Migrations.Current())
+		}
+	}
+
+	return Migrations.Check(ctx, DB{db})
+}
+
+// createVersionTable creates the schema_version table of the database and counts the rows of the tables
+// of the tasks, the migration to version 2 changes nothing else.
+func createVersionTable(ctx context.Context, db DB, dryRun bool) (string, error) {
+	exists, err := hasTable(ctx, db.DB, "tasks")
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "no tasks table", nil
+	}
+
+	var tasks, changes int
+	if err := db.QueryRowContext(ctx, `SELECT (SELECT COUNT(*) FROM tasks), (SELECT COUNT(*) FROM task_changes)`).Scan(&tasks, &changes); err != nil {
+		return "", err
+	}
+
+	if !dryRun {
+		if _, err := db.ExecContext(ctx, versionTableStatement); err != nil {
+			return "", err
+		}
+	}
+
+	return fmt.Sprintf("found %d tasks and %d changes", tasks, changes), nil
+}
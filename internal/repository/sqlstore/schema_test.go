@@ -0,0 +1,105 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/schema"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadFixture creates a database in a temporary directory from the statements of a fixture.
+func loadFixture(t *testing.T, name string) *sql.DB {
+	statements, err := os.ReadFile(filepath.Join("testdata", "schema", name+".sql"))
+	assert.NoError(t, err)
+
+	db := openDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+	_, err = db.Exec(string(statements))
+	assert.NoError(t, err)
+
+	return db
+}
+
+func TestMigrations(t *testing.T) {
+	// every fixture is a database written by the release of its schema version
+	ctx := context.Background()
+	expected := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the release notes +Release", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Tag v1", Status: 1},
+	}
+
+	tests := []struct {
+		fixture   string
+		wantSteps []schema.Step
+	}{
+		{
+			fixture: "v1",
+			wantSteps: []schema.Step{
+				{Version: 2, Description: "record the schema version in the schema_version table", Changes: "found 2 tasks and 6 changes"},
+			},
+		},
+		{
+			fixture: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			db := loadFixture(t, tt.fixture)
+
+			steps, err := Migrations.Migrate(ctx, DB{db}, true)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			// the dry run leaves the database as it was
+			if tt.wantSteps != nil {
+				_, err = Open(ctx, db)
+				assert.ErrorIs(t, err, schema.ErrOutdated)
+
+				exists, err := hasTable(ctx, db, "schema_version")
+				assert.NoError(t, err)
+				assert.False(t, exists)
+			}
+
+			steps, err = Migrations.Migrate(ctx, DB{db}, false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantSteps, steps)
+
+			repo, err := Open(ctx, db)
+			assert.NoError(t, err)
+			defer repo.Close()
+
+			tasks, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, tasks)
+
+			changes, err := repo.SyncTasks(ctx, 3)
+			assert.NoError(t, err)
+			assert.Equal(t, models.TaskChanges{Tasks: expected[:1], Deleted: []string{"9bsv0s2hf8ng030mvab0"}, Seq: 6}, changes)
+
+			// migrating again changes nothing
+			steps, err = Migrations.Migrate(ctx, DB{db}, false)
+			assert.NoError(t, err)
+			assert.Empty(t, steps)
+		})
+	}
+}
+
+func TestOpen_Schema(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+	repo, err := Open(ctx, db)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Close())
+
+	version, err := DB{db}.ReadVersion(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Migrations.Current(), version)
+
+	assert.NoError(t, DB{db}.WriteVersion(ctx, Migrations.Current()+1))
+	_, err = Open(ctx, db)
+	assert.ErrorIs(t, err, schema.ErrNewer)
+}
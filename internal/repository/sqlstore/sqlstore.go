@@ -0,0 +1,672 @@
+// Package sqlstore implements a task manager on a relational database through database/sql.
+// The statements use the SQL dialect of SQLite, the tasks are kept in a table indexed by id and status
+// and every change of a task is appended to a change table, which answers the sync and history queries.
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
+)
+
+// schemaStatements create the tables and indexes of the tasks when missing
+var schemaStatements = []string{
+	`CREATE TABLE IF NOT EXISTS tasks (
+		id     TEXT PRIMARY KEY,
+		name   TEXT NOT NULL,
+		status INTEGER NOT NULL,
+		terms  TEXT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS tasks_status ON tasks (status, id)`,
+	`CREATE TABLE IF NOT EXISTS task_changes (
+		seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id    TEXT NOT NULL,
+		name       TEXT NOT NULL,
+		status     INTEGER NOT NULL,
+		deleted    INTEGER NOT NULL,
+		changed_at INTEGER NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS task_changes_task ON task_changes (task_id, changed_at)`,
+	versionTableStatement,
+}
+
+// ErrClosed represents an error when the repository is used after being closed
+var ErrClosed = errors.New("sql repository closed")
+
+// Repository is a task manager storing the tasks in a relational database.
+type Repository struct {
+	db *sql.DB
+
+	selectTask   *sql.Stmt
+	insertTask   *sql.Stmt
+	updateTask   *sql.Stmt
+	deleteTask   *sql.Stmt
+	insertChange *sql.Stmt
+	lastSeq      *sql.Stmt
+	changedSince *sql.Stmt
+	tasksAsOf    *sql.Stmt
+	prepared     []*sql.Stmt
+
+	now       func() time.Time
+	publisher repository.Publisher
+}
+
+var _ repository.TaskManager = (*Repository)(nil)
+
+// Option configures the repository opened by Open.
+type Option func(*Repository)
+
+// WithPublisher publishes a task event to the publisher on every mutation of the tasks.
+func WithPublisher(publisher repository.Publisher) Option {
+	return func(r *Repository) {
+		r.publisher = publisher
+	}
+}
+
+// WithClock sets the clock recording the time of the changes.
+func WithClock(now func() time.Time) Option {
+	return func(r *Repository) {
+		r.now = now
+	}
+}
+
+// Open creates the tables of the tasks in the database when missing and prepares the statements of the repository.
+// The database must have the current schema version, see Migrations, and a new database records it.
+// The database stays owned by the caller, Close only releases the prepared statements.
+func Open(ctx context.Context, db *sql.DB, options ...Option) (*Repository, error) {
+	r := &Repository{db: db, now: time.Now}
+	for _, option := range options {
+		option(r)
+	}
+
+	if err := checkSchema(ctx, db); err != nil {
+		return nil, err
+	}
+
+	for _, statement := range schemaStatements {
+		if _, err := db.ExecContext(ctx, statement); err != nil {
+			return nil, err
+		}
+	}
+
+	statements := []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&r.selectTask, `SELECT id, name, status FROM tasks WHERE id = ?`},
+		{&r.insertTask, `INSERT INTO tasks (id, name, status, terms) VALUES (?, ?, ?, ?)`},
+		{&r.updateTask, `UPDATE tasks SET name = ?, status = ?, terms = ? WHERE id = ?`},
+		{&r.deleteTask, `DELETE FROM tasks WHERE id = ?`},
+		{&r.insertChange, `INSERT INTO task_changes (task_id, name, status, deleted, changed_at) VALUES (?, ?, ?, ?, ?)`},
+		{&r.lastSeq, `SELECT COALESCE(MAX(seq), 0) FROM task_changes`},
+		{&r.changedSince, `SELECT c.task_id, t.name, t.status
+			FROM (SELECT task_id, MAX(seq) AS last FROM task_changes WHERE seq > ? AND seq <= ? GROUP BY task_id) AS c
+			LEFT JOIN tasks AS t ON t.id = c.task_id
+			ORDER BY c.last`},
+		{&r.tasksAsOf, `SELECT c.task_id, c.name, c.status FROM task_changes AS c
+			WHERE c.seq = (SELECT MAX(v.seq) FROM task_changes AS v WHERE v.task_id = c.task_id AND v.changed_at <= ?)
+			AND c.deleted = 0
+			ORDER BY c.task_id`},
+	}
+	for _, s := range statements {
+		stmt, err := db.PrepareContext(ctx, s.query)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		*s.stmt = stmt
+		r.prepared = append(r.prepared, stmt)
+	}
+
+	return r, nil
+}
+
+// Close releases the prepared statements of the repository.
+func (r *Repository) Close() error {
+	var errs []error
+	for _, stmt := range r.prepared {
+		errs = append(errs, stmt.Close())
+	}
+	r.prepared = nil
+
+	return errors.Join(errs...)
+}
+
+// terms returns the words of the task name matched by the name predicates, each preceded by a space
+// so that a word prefix matches the pattern "% prefix%".
+func terms(name string) string {
+	words := search.Tokenize(name)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(words, " ")
+}
+
+// inTx runs fn in a transaction, which is committed when fn succeeds and rolled back otherwise.
+func (r *Repository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.prepared == nil {
+		return ErrClosed
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// load returns the task with the id in the transaction, and whether it exists.
+func (r *Repository) load(ctx context.Context, tx *sql.Tx, taskID string) (models.Task, bool, error) {
+	var task models.Task
+	err := tx.StmtContext(ctx, r.selectTask).QueryRowContext(ctx, taskID).Scan(&task.ID, &task.Name, &task.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, false, nil
+	}
+	if err != nil {
+		return models.Task{}, false, err
+	}
+
+	return task, true, nil
+}
+
+// insert inserts the task and records its creation in the transaction.
+func (r *Repository) insert(ctx context.Context, tx *sql.Tx, task models.Task) error {
+	if _, err := tx.StmtContext(ctx, r.insertTask).ExecContext(ctx, task.ID, task.Name, task.Status, terms(task.Name)); err != nil {
+		return err
+	}
+
+	return r.record(ctx, tx, task, false)
+}
+
+// update writes the name and the status of the task and records the change in the transaction.
+func (r *Repository) update(ctx context.Context, tx *sql.Tx, task models.Task) error {
+	if _, err := tx.StmtContext(ctx, r.updateTask).ExecContext(ctx, task.Name, task.Status, terms(task.Name), task.ID); err != nil {
+		return err
+	}
+
+	return r.record(ctx, tx, task, false)
+}
+
+// delete deletes the task and records its deletion in the transaction.
+func (r *Repository) delete(ctx context.Context, tx *sql.Tx, task models.Task) error {
+	if _, err := tx.StmtContext(ctx, r.deleteTask).ExecContext(ctx, task.ID); err != nil {
+		return err
+	}
+
+	return r.record(ctx, tx, task, true)
+}
+
+// record appends a version of the task to the changes, deleted records its deletion.
+func (r *Repository) record(ctx context.Context, tx *sql.Tx, task models.Task, deleted bool) error {
+	_, err := tx.StmtContext(ctx, r.insertChange).ExecContext(ctx, task.ID, task.Name, task.Status, deleted, r.now().UTC().UnixNano())
+
+	return err
+}
+
+// GetTasks returns all tasks sorted by ID
+func (r *Repository) GetTasks(ctx context.Context) ([]models.Task, error) {
+	return r.QueryTasksPage(ctx, nil, "", 0)
+}
+
+// ListTasks returns at most limit tasks sorted by ID following the task id after, a non-positive limit returns
+// every following task. An empty after starts from the first task.
+func (r *Repository) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	return r.QueryTasksPage(ctx, nil, after, limit)
+}
+
+// GetTask returns a task by task id
+func (r *Repository) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return models.Task{}, ctx.Err()
+	default:
+	}
+
+	if r.prepared == nil {
+		return models.Task{}, ErrClosed
+	}
+
+	var task models.Task
+	err := r.selectTask.QueryRowContext(ctx, taskID).Scan(&task.ID, &task.Name, &task.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Task{}, repository.ErrTaskNotFound
+	}
+	if err != nil {
+		return models.Task{}, err
+	}
+
+	return task, nil
+}
+
+// QueryTasks returns the tasks matching the query, sorted by ID.
+// The predicates on the id, the status and the words of the name are evaluated by the database.
+func (r *Repository) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	return r.QueryTasksPage(ctx, q, "", 0)
+}
+
+// CreateTasks inserts the tasks with new task ids in a single transaction, the ids are assigned to the given tasks
+func (r *Repository) CreateTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	created := make([]models.Task, len(tasks))
+	for i := range tasks {
+		created[i] = tasks[i]
+		created[i].NewTaskID()
+	}
+
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, task := range created {
+			if err := r.insert(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	copy(tasks, created)
+	for _, task := range created {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// RestoreTasks inserts the tasks with their own task ids in a single transaction.
+// No task is created when one of the ids is invalid, repeated or already exists.
+func (r *Repository) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	seen := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		if _, err := xid.FromString(task.ID); err != nil {
+			return repository.ErrTaskID
+		}
+
+		if _, duplicated := seen[task.ID]; duplicated {
+			return repository.ErrTaskExists
+		}
+		seen[task.ID] = struct{}{}
+	}
+
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, task := range tasks {
+			_, exists, err := r.load(ctx, tx, task.ID)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				return repository.ErrTaskExists
+			}
+
+			if err := r.insert(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		repository.Publish(r.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// UpdateTask updates the name and the status of a task by task id
+func (r *Repository) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var previous, task models.Task
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		var err error
+		previous, exists, err = r.load(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		task = repository.Apply(previous, name, status)
+
+		return r.update(ctx, tx, task)
+	}); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, previous, task)
+
+	return nil
+}
+
+// CompareAndSwapTask replaces a task by the task if it is still the previous task, keeping its id
+func (r *Repository) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	task.ID = previous.ID
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		current, exists, err := r.load(ctx, tx, previous.ID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		if current != previous {
+			return repository.ErrTaskChanged
+		}
+
+		return r.update(ctx, tx, task)
+	}); err != nil {
+		return err
+	}
+	repository.PublishUpdate(r.publisher, previous, task)
+
+	return nil
+}
+
+// DeleteTask deletes a task by task id
+func (r *Repository) DeleteTask(ctx context.Context, taskID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	var task models.Task
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var exists bool
+		var err error
+		task, exists, err = r.load(ctx, tx, taskID)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return repository.ErrTaskNotFound
+		}
+
+		return r.delete(ctx, tx, task)
+	}); err != nil {
+		return err
+	}
+	repository.Publish(r.publisher, models.TaskDeleted, task)
+
+	return nil
+}
+
+// BatchUpdateTasks updates the tasks with the given task ids in a single transaction,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	var previous, updated []models.Task
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		results = make([]models.BatchResult, 0, len(taskIDs))
+		seen := make(map[string]struct{}, len(taskIDs))
+		for _, taskID := range taskIDs {
+			if _, duplicated := seen[taskID]; duplicated {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+				continue
+			}
+			seen[taskID] = struct{}{}
+
+			task, exists, err := r.load(ctx, tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+				continue
+			}
+
+			if err := r.update(ctx, tx, repository.Apply(task, name, status)); err != nil {
+				return err
+			}
+			previous = append(previous, task)
+			updated = append(updated, repository.Apply(task, name, status))
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeUpdated})
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for i := range updated {
+		repository.PublishUpdate(r.publisher, previous[i], updated[i])
+	}
+
+	return results, nil
+}
+
+// BatchDeleteTasks deletes the tasks with the given task ids in a single transaction,
+// and returns the outcome of every task id in order. A task id given more than once is a conflict.
+func (r *Repository) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	var results []models.BatchResult
+	var deleted []models.Task
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		results = make([]models.BatchResult, 0, len(taskIDs))
+		seen := make(map[string]struct{}, len(taskIDs))
+		for _, taskID := range taskIDs {
+			if _, duplicated := seen[taskID]; duplicated {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+				continue
+			}
+			seen[taskID] = struct{}{}
+
+			task, exists, err := r.load(ctx, tx, taskID)
+			if err != nil {
+				return err
+			}
+
+			if !exists {
+				results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+				continue
+			}
+
+			if err := r.delete(ctx, tx, task); err != nil {
+				return err
+			}
+			deleted = append(deleted, task)
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeDeleted})
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.BatchResult, 0), err
+	}
+
+	for _, task := range deleted {
+		repository.Publish(r.publisher, models.TaskDeleted, task)
+	}
+
+	return results, nil
+}
+
+// SearchTasks returns at most limit tasks whose names match the full-text query, ordered by relevance.
+// A non-positive limit returns every matching task. Only the tasks having a word prefixed by every query term
+// are read, they are ranked against the number of tasks holding each of their matched words, counted by
+// the database.
+func (r *Repository) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	where, args := termsCondition(search.Tokenize(query))
+	if where == "" {
+		return make([]models.SearchResult, 0), nil
+	}
+
+	var tasks []models.Task
+	stats := search.Stats{DocFreq: make(map[string]int)}
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, "SELECT id, name, status FROM tasks WHERE "+where+" ORDER BY id", args...)
+		if err != nil {
+			return err
+		}
+
+		if tasks, err = scanTasks(rows, 0, nil); err != nil || len(tasks) == 0 {
+			return err
+		}
+
+		// every word of the terms is preceded by a space
+		if err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(*), COALESCE(SUM(LENGTH(terms) - LENGTH(REPLACE(terms, ' ', ''))), 0) FROM tasks",
+		).Scan(&stats.DocCount, &stats.TotalLen); err != nil {
+			return err
+		}
+
+		texts := make([]string, len(tasks))
+		for i, task := range tasks {
+			texts[i] = task.Name
+		}
+
+		for _, term := range search.MatchedTerms(query, texts...) {
+			var count int
+			if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM tasks WHERE terms || ' ' LIKE ?", "% "+term+" %").Scan(&count); err != nil {
+				return err
+			}
+			stats.DocFreq[term] = count
+		}
+
+		return nil
+	}); err != nil {
+		return make([]models.SearchResult, 0), err
+	}
+
+	byID := make(map[string]models.Task, len(tasks))
+	names := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+		names[task.ID] = task.Name
+	}
+
+	results := make([]models.SearchResult, 0)
+	for _, hit := range search.Rank(query, names, stats) {
+		if limit > 0 && len(results) == limit {
+			break
+		}
+
+		task := byID[hit.ID]
+		results = append(results, models.SearchResult{
+			Task:      task,
+			Score:     hit.Score,
+			Highlight: search.Highlight(task.Name, hit.Terms),
+		})
+	}
+
+	return results, nil
+}
+
+// SyncTasks returns the tasks created or updated and the ids of the tasks deleted after the sequence number,
+// which is the sequence number of the changes. Since 0 returns every task without tombstones.
+func (r *Repository) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	select {
+	case <-ctx.Done():
+		return models.TaskChanges{}, ctx.Err()
+	default:
+	}
+
+	var result models.TaskChanges
+	if err := r.inTx(ctx, func(tx *sql.Tx) error {
+		var seq uint64
+		if err := tx.StmtContext(ctx, r.lastSeq).QueryRowContext(ctx).Scan(&seq); err != nil {
+			return err
+		}
+
+		if since > seq {
+			// issued before the database was reset
+			return repository.ErrSyncTokenExpired
+		}
+
+		rows, err := tx.StmtContext(ctx, r.changedSince).QueryContext(ctx, since, seq)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		result = models.TaskChanges{Tasks: make([]models.Task, 0), Deleted: make([]string, 0), Seq: seq}
+		for rows.Next() {
+			var taskID string
+			var name sql.NullString
+			var status sql.NullInt64
+			if err := rows.Scan(&taskID, &name, &status); err != nil {
+				return err
+			}
+
+			// the current state is returned, it may already include changes after seq
+			if !name.Valid {
+				if since > 0 {
+					result.Deleted = append(result.Deleted, taskID)
+				}
+				continue
+			}
+			result.Tasks = append(result.Tasks, models.Task{ID: taskID, Name: name.String, Status: int(status.Int64)})
+		}
+
+		return rows.Err()
+	}); err != nil {
+		return models.TaskChanges{}, err
+	}
+
+	return result, nil
+}
+
+// TasksAsOf returns the tasks, sorted by ID, as they were at the given time.
+// The changes are never pruned, so the whole history is available.
+func (r *Repository) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	if r.prepared == nil {
+		return make([]models.Task, 0), ErrClosed
+	}
+
+	rows, err := r.tasksAsOf.QueryContext(ctx, at.UTC().UnixNano())
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return scanTasks(rows, 0, nil)
+}
@@ -0,0 +1,283 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/search"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+
+	_ "modernc.org/sqlite"
+)
+
+// testClock returns a clock advancing by a minute on every call.
+func testClock(start time.Time) func() time.Time {
+	now := start
+	return func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+}
+
+type recordingPublisher struct {
+	events []models.TaskEventType
+}
+
+func (r *recordingPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	r.events = append(r.events, eventType)
+	return models.TaskEvent{ID: uint64(len(r.events)), Type: eventType, Task: task}
+}
+
+// openDB opens a SQLite database in a file of a temporary directory.
+func openDB(t *testing.T, path string) *sql.DB {
+	db, err := sql.Open("sqlite", path)
+	assert.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestRepository(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tasks.db")
+	publisher := &recordingPublisher{}
+	repo, err := Open(ctx, openDB(t, path), WithPublisher(publisher))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Write report"}, {Name: "Review report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	assert.NotEmpty(t, tasks[0].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, got)
+	write, review := tasks[0], tasks[1]
+
+	name, status := "Write the report", 1
+	assert.NoError(t, repo.UpdateTask(ctx, write.ID, &name, &status))
+	assert.ErrorIs(t, repo.UpdateTask(ctx, "unknown", &name, nil), repository.ErrTaskNotFound)
+
+	task, err := repo.GetTask(ctx, write.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.Task{ID: write.ID, Name: "Write the report", Status: 1}, task)
+
+	assert.NoError(t, repo.DeleteTask(ctx, review.ID))
+	assert.ErrorIs(t, repo.DeleteTask(ctx, review.ID), repository.ErrTaskNotFound)
+
+	_, err = repo.GetTask(ctx, review.ID)
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+
+	assert.Equal(t, []models.TaskEventType{
+		models.TaskCreated, models.TaskCreated, models.TaskUpdated, models.TaskCompleted, models.TaskDeleted,
+	}, publisher.events)
+	assert.NoError(t, repo.Close())
+
+	// the tasks are kept in the database
+	reopened, err := Open(ctx, openDB(t, path))
+	assert.NoError(t, err)
+	defer reopened.Close()
+
+	got, err = reopened.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, got)
+}
+
+func TestRepository_CreateTasks(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t, filepath.Join(t.TempDir(), "tasks.db"))
+	repo, err := Open(ctx, db)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	// the database refuses the second task, the first one is rolled back with it
+	_, err = db.Exec(`CREATE TRIGGER refuse_task BEFORE INSERT ON tasks WHEN NEW.name = 'refused'
+		BEGIN SELECT RAISE(ABORT, 'task refused'); END`)
+	assert.NoError(t, err)
+
+	tasks := []models.Task{{Name: "accepted"}, {Name: "refused"}}
+	assert.ErrorContains(t, repo.CreateTasks(ctx, tasks), "task refused")
+	assert.Empty(t, tasks[0].ID)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), changes.Seq)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, repo.CreateTasks(canceled, []models.Task{{Name: "accepted"}}), context.Canceled)
+}
+
+func TestRepository_RestoreTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	existing := []models.Task{{ID: "d3bkmd6hf8ng0305ig90", Name: "Task 1"}}
+	assert.NoError(t, repo.RestoreTasks(ctx, existing))
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{name: "invalid id", tasks: []models.Task{{ID: "1", Name: "Task 2"}}, wantErr: repository.ErrTaskID},
+		{name: "existing id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305ig90", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+		{name: "repeated id", tasks: []models.Task{{ID: "d3bkmd6hf8ng0305iga0", Name: "Task 2"}, {ID: "d3bkmd6hf8ng0305iga0", Name: "Task 3"}}, wantErr: repository.ErrTaskExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repo.RestoreTasks(ctx, tt.tasks), tt.wantErr)
+
+			got, err := repo.GetTasks(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, existing, got)
+		})
+	}
+}
+
+func TestRepository_BatchTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	status := 1
+	results, err := repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, "unknown", tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeUpdated},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+		{ID: tasks[0].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeUpdated},
+	}, results)
+
+	got, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}, {ID: tasks[1].ID, Name: "Task 2", Status: 1}}, got)
+
+	results, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, tasks[1].ID, "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BatchResult{
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeDeleted},
+		{ID: tasks[1].ID, Outcome: models.BatchOutcomeConflict},
+		{ID: "unknown", Outcome: models.BatchOutcomeNotFound},
+	}, results)
+
+	got, err = repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}, got)
+}
+
+func TestRepository_SearchTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Document the deployment"}, {Name: "Deploy the API"}, {Name: "Deploy the docs"}, {Name: "Write report"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	// the exact words rank above the prefixed ones, though most matching tasks have them
+	results, err := repo.SearchTasks(ctx, "deploy", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.Equal(t, tasks[1], results[0].Task)
+	assert.Equal(t, "<mark>Deploy</mark> the API", results[0].Highlight)
+	assert.Equal(t, tasks[2], results[1].Task)
+	assert.Equal(t, tasks[0], results[2].Task)
+
+	// the tasks score as in an index of every task
+	index := search.NewIndex()
+	for _, task := range tasks {
+		index.Add(task.ID, task.Name)
+	}
+	for i, hit := range index.Search("deploy") {
+		assert.Equal(t, hit.ID, results[i].Task.ID)
+		assert.Equal(t, hit.Score, results[i].Score)
+	}
+
+	results, err = repo.SearchTasks(ctx, "deploy", 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	results, err = repo.SearchTasks(ctx, "deploy report", 0)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRepository_SyncTasks(t *testing.T) {
+	ctx := context.Background()
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: tasks, Deleted: []string{}, Seq: 3}, changes)
+
+	name := "Task 1 renamed"
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[1].ID))
+
+	changes, err = repo.SyncTasks(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks:   []models.Task{{ID: tasks[0].ID, Name: name}},
+		Deleted: []string{tasks[1].ID},
+		Seq:     5,
+	}, changes)
+
+	_, err = repo.SyncTasks(ctx, 6)
+	assert.ErrorIs(t, err, repository.ErrSyncTokenExpired)
+}
+
+func TestRepository_TasksAsOf(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC)
+	repo, err := Open(ctx, openDB(t, filepath.Join(t.TempDir(), "tasks.db")), WithClock(testClock(start)))
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks)) // 09:01
+
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, nil, &status)) // 09:02
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[0].ID))               // 09:03
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []models.Task
+	}{
+		{name: "before the creation", at: start, want: []models.Task{}},
+		{name: "after the creation", at: start.Add(time.Minute), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1"}}},
+		{name: "after the update", at: start.Add(150 * time.Second), want: []models.Task{{ID: tasks[0].ID, Name: "Task 1", Status: 1}}},
+		{name: "after the deletion", at: start.Add(3 * time.Minute), want: []models.Task{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.TasksAsOf(ctx, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
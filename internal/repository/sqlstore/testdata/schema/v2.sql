@@ -0,0 +1,28 @@
+CREATE TABLE tasks (
+	id     TEXT PRIMARY KEY,
+	name   TEXT NOT NULL,
+	status INTEGER NOT NULL,
+	terms  TEXT NOT NULL
+);
+CREATE INDEX tasks_status ON tasks (status, id);
+CREATE TABLE task_changes (
+	seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id    TEXT NOT NULL,
+	name       TEXT NOT NULL,
+	status     INTEGER NOT NULL,
+	deleted    INTEGER NOT NULL,
+	changed_at INTEGER NOT NULL
+);
+CREATE INDEX task_changes_task ON task_changes (task_id, changed_at);
+INSERT INTO tasks (id, name, status, terms) VALUES
+	('9bsv0s2hf8ng030mva9g', 'Write the release notes +Release', 0, ' write the release notes release'),
+	('9bsv0s2hf8ng030mvaa0', 'Tag v1', 1, ' tag v1');
+INSERT INTO task_changes (task_id, name, status, deleted, changed_at) VALUES
+	('9bsv0s2hf8ng030mva9g', 'Write the release notes', 0, 0, 1772442060000000000),
+	('9bsv0s2hf8ng030mvaa0', 'Tag v1', 0, 0, 1772442060000000000),
+	('9bsv0s2hf8ng030mvaa0', 'Tag v1', 1, 0, 1772442120000000000),
+	('9bsv0s2hf8ng030mvab0', 'Deleted task', 0, 0, 1772442240000000000),
+	('9bsv0s2hf8ng030mvab0', 'Deleted task', 0, 1, 1772442300000000000),
+	('9bsv0s2hf8ng030mva9g', 'Write the release notes +Release', 0, 0, 1772442360000000000);
+CREATE TABLE schema_version (version INTEGER NOT NULL);
+INSERT INTO schema_version (version) VALUES (2);
@@ -3,18 +3,39 @@ package repository
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/search"
 	"github.com/brionac626/taskManager/models"
+	"github.com/rs/xid"
 )
 
-type taskRepo struct{}
+type taskRepo struct {
+	publisher Publisher
+	retention time.Duration    // how long the superseded versions of the tasks are kept, 0 keeps them forever
+	now       func() time.Time // clock recording the time of the versions
+	history   *taskHistory     // versions of every task changed by the repository
+}
 
 var _ TaskManager = (*taskRepo)(nil)
 
 // in-memory storage for tasks
 var manager sync.Map
 
+// writeMu serializes the mutations of the storage along with their updates of the full-text index, the change log
+// and the history and their events, so that they are applied in the order of the storage
+var writeMu sync.Mutex
+
+// full-text index of the task names, maintained on every mutation of the storage
+var index = search.NewIndex()
+
+// sequence numbers of the last change of every task, maintained on every mutation of the storage
+var changes = newChangeLog()
+
 var (
 	// ErrGetTasksFailed represents an error when getting tasks failed
 	ErrGetTasksFailed = errors.New("failed to get tasks")
@@ -24,11 +45,56 @@ var (
 	ErrTaskType = errors.New("task type error")
 	// ErrTaskID represents an error when the task id is invalid (not xid)
 	ErrTaskID = errors.New("invalid task id")
+	// ErrTaskChanged represents an error when a task is replaced after it was changed since it was read
+	ErrTaskChanged = errors.New("task changed since it was read")
+	// ErrTaskExists represents an error when a task is restored with the id of an existing task
+	ErrTaskExists = errors.New("task already exists")
+	// ErrSyncTokenExpired represents an error when the changes since a sequence number are unknown
+	ErrSyncTokenExpired = errors.New("sync token expired, fetch every task again")
+	// ErrHistoryExpired represents an error when the tasks are requested as of a time before the retained history
+	ErrHistoryExpired = errors.New("history before the retention period is no longer available")
 )
 
+// Option configures the repository created by NewRepository.
+type Option func(*taskRepo)
+
+// WithPublisher publishes a task event to the publisher on every mutation of the tasks.
+func WithPublisher(publisher Publisher) Option {
+	return func(t *taskRepo) {
+		t.publisher = publisher
+	}
+}
+
+// WithHistoryRetention keeps the superseded versions of the tasks for the retention, 0 keeps them forever.
+func WithHistoryRetention(retention time.Duration) Option {
+	return func(t *taskRepo) {
+		t.retention = retention
+	}
+}
+
+// WithClock sets the clock recording the time of the task versions.
+func WithClock(now func() time.Time) Option {
+	return func(t *taskRepo) {
+		t.now = now
+	}
+}
+
 // NewRepository creates a new task manager for managing tasks in the memory
-func NewRepository() TaskManager {
-	return &taskRepo{}
+func NewRepository(options ...Option) TaskManager {
+	repo := &taskRepo{now: time.Now}
+	for _, option := range options {
+		option(repo)
+	}
+	repo.history = newTaskHistory(repo.retention, repo.now)
+
+	return repo
+}
+
+// record records the change of a task in the change log and its new version in the history,
+// deleted records the deletion of the task.
+func (t *taskRepo) record(task models.Task, deleted bool) {
+	changes.record(task.ID)
+	t.history.record(task, deleted)
 }
 
 // GetTasks returns all tasks from the memory
@@ -64,7 +130,107 @@ func (t *taskRepo) GetTasks(ctx context.Context) ([]models.Task, error) {
 
 }
 
-// CreateTasks creates tasks from request
+// ListTasks returns at most limit tasks sorted by ID following the task id after, a non-positive limit returns
+// every following task. An empty after starts from the first task.
+func (t *taskRepo) ListTasks(ctx context.Context, after string, limit int) ([]models.Task, error) {
+	tasks, err := t.GetTasks(ctx)
+	if err != nil {
+		return make([]models.Task, 0), err
+	}
+
+	return PageTasks(tasks, after, limit), nil
+}
+
+// PageTasks returns at most limit of the tasks sorted by ID following the task id after, a non-positive limit
+// returns every following task. An empty after starts from the first task.
+func PageTasks(tasks []models.Task, after string, limit int) []models.Task {
+	start, _ := slices.BinarySearchFunc(tasks, after, func(task models.Task, after string) int {
+		if task.ID <= after {
+			return -1
+		}
+
+		return 1
+	})
+	tasks = tasks[start:]
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return tasks
+}
+
+// GetTask returns a task by task id from the memory
+func (t *taskRepo) GetTask(ctx context.Context, taskID string) (models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return models.Task{}, ctx.Err()
+	default:
+	}
+
+	v, exists := manager.Load(taskID)
+	if !exists {
+		return models.Task{}, ErrTaskNotFound
+	}
+
+	task, ok := v.(models.Task)
+	if !ok {
+		return models.Task{}, ErrTaskType
+	}
+
+	return task, nil
+}
+
+// QueryTasks returns the tasks matching the query, sorted by ID.
+// The indexable predicates of the query narrow the candidate tasks before the query is evaluated:
+// id equalities are loaded directly and name predicates are looked up in the full-text index.
+func (t *taskRepo) QueryTasks(ctx context.Context, q *query.Query) ([]models.Task, error) {
+	var candidateIDs []string
+	pushdown := q.Pushdown()
+	switch {
+	case pushdown.IDs != nil:
+		candidateIDs = pushdown.IDs
+	case len(pushdown.NameTerms) != 0:
+		for _, hit := range index.Search(strings.Join(pushdown.NameTerms, " ")) {
+			candidateIDs = append(candidateIDs, hit.ID)
+		}
+	default:
+		tasks, err := t.GetTasks(ctx)
+		if err != nil {
+			return make([]models.Task, 0), err
+		}
+
+		return q.Filter(tasks), nil
+	}
+
+	result := make([]models.Task, 0)
+	for _, taskID := range candidateIDs {
+		select {
+		case <-ctx.Done():
+			return make([]models.Task, 0), ctx.Err()
+		default:
+		}
+
+		v, exists := manager.Load(taskID)
+		if !exists {
+			continue
+		}
+
+		task, ok := v.(models.Task)
+		if !ok {
+			return make([]models.Task, 0), ErrGetTasksFailed
+		}
+
+		if q.Match(task) {
+			result = append(result, task)
+		}
+	}
+
+	models.SortTasksByID(result)
+
+	return result, nil
+}
+
+// CreateTasks creates tasks from request, the new task ids are assigned to the given tasks
 func (t *taskRepo) CreateTasks(ctx context.Context, tasks []models.Task) error {
 	select {
 	case <-ctx.Done():
@@ -72,9 +238,57 @@ func (t *taskRepo) CreateTasks(ctx context.Context, tasks []models.Task) error {
 	default:
 	}
 
-	for _, task := range tasks {
-		task.NewTaskID()
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	for i := range tasks {
+		tasks[i].NewTaskID()
+		task := tasks[i]
 		manager.Store(task.ID, task)
+		index.Add(task.ID, task.Name)
+		t.record(task, false)
+		Publish(t.publisher, models.TaskCreated, task)
+	}
+
+	return nil
+}
+
+// RestoreTasks creates the tasks with their own task ids, e.g. to bring back deleted tasks.
+// No task is created when one of the ids is invalid, repeated or already exists.
+func (t *taskRepo) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	seen := make(map[string]struct{}, len(tasks))
+	for _, task := range tasks {
+		if _, err := xid.FromString(task.ID); err != nil {
+			return ErrTaskID
+		}
+
+		if _, exists := manager.Load(task.ID); exists {
+			return ErrTaskExists
+		}
+
+		if _, duplicated := seen[task.ID]; duplicated {
+			return ErrTaskExists
+		}
+		seen[task.ID] = struct{}{}
+	}
+
+	for _, task := range tasks {
+		if _, loaded := manager.LoadOrStore(task.ID, task); loaded {
+			// created since the ids were checked
+			return ErrTaskExists
+		}
+		index.Add(task.ID, task.Name)
+		t.record(task, false)
+		Publish(t.publisher, models.TaskCreated, task)
 	}
 
 	return nil
@@ -82,6 +296,9 @@ func (t *taskRepo) CreateTasks(ctx context.Context, tasks []models.Task) error {
 
 // UpdateTask updates a task by task id
 func (t *taskRepo) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
 	v, exists := manager.Load(taskID)
 	if !exists {
 		return ErrTaskNotFound
@@ -97,22 +314,45 @@ func (t *taskRepo) UpdateTask(ctx context.Context, taskID string, name *string,
 	if !ok {
 		return ErrTaskType
 	}
+	previous := task
+	task = Apply(task, name, status)
 
-	if name != nil {
-		task.Name = *name
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
 	}
 
-	if status != nil {
-		task.Status = *status
-	}
+	manager.Swap(taskID, task)
+	index.Add(taskID, task.Name)
+	t.record(task, false)
+	PublishUpdate(t.publisher, previous, task)
+
+	return nil
+}
 
+// CompareAndSwapTask replaces a task by the task if it is still the previous task, keeping its id
+func (t *taskRepo) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	default:
 	}
 
-	manager.Swap(taskID, task)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	if _, exists := manager.Load(previous.ID); !exists {
+		return ErrTaskNotFound
+	}
+
+	task.ID = previous.ID
+	if !manager.CompareAndSwap(previous.ID, previous, task) {
+		return ErrTaskChanged
+	}
+	index.Add(task.ID, task.Name)
+	t.record(task, false)
+	PublishUpdate(t.publisher, previous, task)
 
 	return nil
 }
@@ -125,10 +365,196 @@ func (t *taskRepo) DeleteTask(ctx context.Context, taskID string) error {
 	default:
 	}
 
-	_, exists := manager.LoadAndDelete(taskID)
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	v, exists := manager.LoadAndDelete(taskID)
 	if !exists {
 		return ErrTaskNotFound
 	}
+	index.Remove(taskID)
+	if task, ok := v.(models.Task); ok {
+		t.record(task, true)
+		Publish(t.publisher, models.TaskDeleted, task)
+	} else {
+		t.record(models.Task{ID: taskID}, true)
+	}
 
 	return nil
 }
+
+// BatchUpdateTasks updates the tasks with the given task ids and reports the outcome for every id.
+// A task that is changed by someone else while the batch is running, or that appears more than once
+// in the id list, is reported as a conflict and left untouched by this batch.
+func (t *taskRepo) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	seen := make(map[string]struct{}, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		if _, duplicated := seen[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		seen[taskID] = struct{}{}
+
+		v, exists := manager.Load(taskID)
+		if !exists {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+			continue
+		}
+
+		task, ok := v.(models.Task)
+		if !ok {
+			return results, ErrTaskType
+		}
+		previous := task
+		task = Apply(task, name, status)
+
+		if !manager.CompareAndSwap(taskID, v, task) {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		index.Add(taskID, task.Name)
+		t.record(task, false)
+		PublishUpdate(t.publisher, previous, task)
+
+		results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeUpdated})
+	}
+
+	return results, nil
+}
+
+// BatchDeleteTasks deletes the tasks with the given task ids and reports the outcome for every id.
+// A task that appears more than once in the id list is reported as a conflict after its first deletion.
+func (t *taskRepo) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	results := make([]models.BatchResult, 0, len(taskIDs))
+	seen := make(map[string]struct{}, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		select {
+		case <-ctx.Done():
+			return results, ctx.Err()
+		default:
+		}
+
+		if _, duplicated := seen[taskID]; duplicated {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeConflict})
+			continue
+		}
+		seen[taskID] = struct{}{}
+
+		v, exists := manager.LoadAndDelete(taskID)
+		if !exists {
+			results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeNotFound})
+			continue
+		}
+		index.Remove(taskID)
+		if task, ok := v.(models.Task); ok {
+			t.record(task, true)
+			Publish(t.publisher, models.TaskDeleted, task)
+		} else {
+			t.record(models.Task{ID: taskID}, true)
+		}
+
+		results = append(results, models.BatchResult{ID: taskID, Outcome: models.BatchOutcomeDeleted})
+	}
+
+	return results, nil
+}
+
+// SearchTasks returns at most limit tasks whose names match the full-text query, ordered by relevance.
+// A non-positive limit returns every matching task.
+func (t *taskRepo) SearchTasks(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+	results := make([]models.SearchResult, 0)
+
+	for _, hit := range index.Search(query) {
+		select {
+		case <-ctx.Done():
+			return make([]models.SearchResult, 0), ctx.Err()
+		default:
+		}
+
+		if limit > 0 && len(results) == limit {
+			break
+		}
+
+		v, exists := manager.Load(hit.ID)
+		if !exists {
+			// deleted after the index was searched
+			continue
+		}
+
+		task, ok := v.(models.Task)
+		if !ok {
+			return make([]models.SearchResult, 0), ErrTaskType
+		}
+
+		results = append(results, models.SearchResult{
+			Task:      task,
+			Score:     hit.Score,
+			Highlight: search.Highlight(task.Name, hit.Terms),
+		})
+	}
+
+	return results, nil
+}
+
+// SyncTasks returns the tasks created or updated and the ids of the tasks deleted after the sequence number.
+// Since 0 returns every task without tombstones.
+func (t *taskRepo) SyncTasks(ctx context.Context, since uint64) (models.TaskChanges, error) {
+	taskIDs, seq := changes.since(since)
+	if since > seq {
+		// issued before the storage was reset
+		return models.TaskChanges{}, ErrSyncTokenExpired
+	}
+
+	result := models.TaskChanges{Tasks: make([]models.Task, 0), Deleted: make([]string, 0), Seq: seq}
+	for _, taskID := range taskIDs {
+		select {
+		case <-ctx.Done():
+			return models.TaskChanges{}, ctx.Err()
+		default:
+		}
+
+		// the current state is returned, it may already include changes after seq
+		v, exists := manager.Load(taskID)
+		if !exists {
+			if since > 0 {
+				result.Deleted = append(result.Deleted, taskID)
+			}
+			continue
+		}
+
+		task, ok := v.(models.Task)
+		if !ok {
+			return models.TaskChanges{}, ErrTaskType
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	return result, nil
+}
+
+// TasksAsOf returns the tasks, sorted by ID, as they were at the given time.
+// Times before the retention period of the history return ErrHistoryExpired.
+func (t *taskRepo) TasksAsOf(ctx context.Context, at time.Time) ([]models.Task, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Task, 0), ctx.Err()
+	default:
+	}
+
+	return t.history.asOf(at)
+}
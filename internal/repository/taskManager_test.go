@@ -3,14 +3,18 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/search"
 	"github.com/brionac626/taskManager/models"
 	"github.com/stretchr/testify/assert"
 )
 
-var testRepo = &taskRepo{}
+var testRepo = NewRepository().(*taskRepo)
 
 func Test_taskRepo_GetTasks(t *testing.T) {
 	type args struct {
@@ -91,6 +95,79 @@ func Test_taskRepo_GetTasks(t *testing.T) {
 	}
 }
 
+func Test_taskRepo_GetTask(t *testing.T) {
+	type args struct {
+		ctx    context.Context
+		taskID string
+	}
+
+	task := models.Task{ID: "task1", Name: "Task 1", Status: 0}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		args           args
+		want           models.Task
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name: "get task",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task.ID, task)
+			},
+			args:    args{ctx: context.Background(), taskID: task.ID},
+			want:    task,
+			wantErr: false,
+		},
+		{
+			name: "get task with non-existing ID",
+			mockSetup: func() {
+				manager = sync.Map{}
+			},
+			args:           args{ctx: context.Background(), taskID: "non-existing-task-id"},
+			wantErr:        true,
+			wantErrContent: ErrTaskNotFound,
+		},
+		{
+			name: "get task with wrong type",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task.ID, nil)
+			},
+			args:           args{ctx: context.Background(), taskID: task.ID},
+			wantErr:        true,
+			wantErrContent: ErrTaskType,
+		},
+		{
+			name: "get task with context cancellation",
+			mockSetup: func() {
+				cancel()
+			},
+			args:           args{ctx: canceledCtx, taskID: task.ID},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			got, err := testRepo.GetTask(tt.args.ctx, tt.args.taskID)
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("taskRepo.GetTask() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func Test_taskRepo_CreateTasks(t *testing.T) {
 	type args struct {
 		ctx   context.Context
@@ -340,3 +417,683 @@ func Test_taskRepo_UpdateTask(t *testing.T) {
 		})
 	}
 }
+
+func Test_taskRepo_BatchUpdateTasks(t *testing.T) {
+	type args struct {
+		ctx     context.Context
+		taskIDs []string
+		name    *string
+		status  *int
+	}
+
+	task1 := models.Task{ID: "task1", Name: "Task 1", Status: 0}
+	task2 := models.Task{ID: "task2", Name: "Task 2", Status: 0}
+	updatedName := "Updated Task"
+	updatedStatus := 1
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		args           args
+		want           []models.BatchResult
+		wantTasks      []models.Task
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name: "batch update tasks",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task1.ID, task1)
+				manager.Store(task2.ID, task2)
+			},
+			args: args{
+				ctx:     context.Background(),
+				taskIDs: []string{task1.ID, task2.ID},
+				status:  &updatedStatus,
+			},
+			want: []models.BatchResult{
+				{ID: task1.ID, Outcome: models.BatchOutcomeUpdated},
+				{ID: task2.ID, Outcome: models.BatchOutcomeUpdated},
+			},
+			wantTasks: []models.Task{
+				{ID: task1.ID, Name: task1.Name, Status: updatedStatus},
+				{ID: task2.ID, Name: task2.Name, Status: updatedStatus},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch update tasks with non-existing and duplicated IDs",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task1.ID, task1)
+			},
+			args: args{
+				ctx:     context.Background(),
+				taskIDs: []string{task1.ID, "non-existing-task-id", task1.ID},
+				name:    &updatedName,
+			},
+			want: []models.BatchResult{
+				{ID: task1.ID, Outcome: models.BatchOutcomeUpdated},
+				{ID: "non-existing-task-id", Outcome: models.BatchOutcomeNotFound},
+				{ID: task1.ID, Outcome: models.BatchOutcomeConflict},
+			},
+			wantTasks: []models.Task{
+				{ID: task1.ID, Name: updatedName, Status: task1.Status},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch update tasks with wrong type",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task1.ID, nil)
+			},
+			args: args{
+				ctx:     context.Background(),
+				taskIDs: []string{task1.ID},
+				name:    &updatedName,
+			},
+			want:           []models.BatchResult{},
+			wantErr:        true,
+			wantErrContent: ErrTaskType,
+		},
+		{
+			name: "batch update tasks with context cancellation",
+			mockSetup: func() {
+				manager = sync.Map{}
+				cancel()
+			},
+			args: args{
+				ctx:     canceledCtx,
+				taskIDs: []string{task1.ID},
+				name:    &updatedName,
+			},
+			want:           []models.BatchResult{},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			got, err := testRepo.BatchUpdateTasks(tt.args.ctx, tt.args.taskIDs, tt.args.name, tt.args.status)
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("taskRepo.BatchUpdateTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			for _, want := range tt.wantTasks {
+				task, _ := manager.Load(want.ID)
+				assert.Equal(t, want, task.(models.Task))
+			}
+		})
+	}
+}
+
+func Test_taskRepo_BatchDeleteTasks(t *testing.T) {
+	type args struct {
+		ctx     context.Context
+		taskIDs []string
+	}
+
+	task1 := models.Task{ID: "task1", Name: "Task 1", Status: 0}
+	task2 := models.Task{ID: "task2", Name: "Task 2", Status: 1}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		args           args
+		want           []models.BatchResult
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name: "batch delete tasks",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task1.ID, task1)
+				manager.Store(task2.ID, task2)
+			},
+			args: args{
+				ctx:     context.Background(),
+				taskIDs: []string{task1.ID, task2.ID},
+			},
+			want: []models.BatchResult{
+				{ID: task1.ID, Outcome: models.BatchOutcomeDeleted},
+				{ID: task2.ID, Outcome: models.BatchOutcomeDeleted},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch delete tasks with non-existing and duplicated IDs",
+			mockSetup: func() {
+				manager = sync.Map{}
+				manager.Store(task1.ID, task1)
+			},
+			args: args{
+				ctx:     context.Background(),
+				taskIDs: []string{task1.ID, "non-existing-task-id", task1.ID},
+			},
+			want: []models.BatchResult{
+				{ID: task1.ID, Outcome: models.BatchOutcomeDeleted},
+				{ID: "non-existing-task-id", Outcome: models.BatchOutcomeNotFound},
+				{ID: task1.ID, Outcome: models.BatchOutcomeConflict},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch delete tasks with context cancellation",
+			mockSetup: func() {
+				cancel()
+			},
+			args: args{
+				ctx:     canceledCtx,
+				taskIDs: []string{task1.ID},
+			},
+			want:           []models.BatchResult{},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			got, err := testRepo.BatchDeleteTasks(tt.args.ctx, tt.args.taskIDs)
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("taskRepo.BatchDeleteTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+
+			for _, result := range got {
+				_, exists := manager.Load(result.ID)
+				assert.False(t, exists)
+			}
+		})
+	}
+}
+
+func Test_taskRepo_SearchTasks(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+
+	ctx := context.Background()
+	err := testRepo.CreateTasks(ctx, []models.Task{
+		{Name: "Deploy infra", Status: 0},
+		{Name: "Write infrastructure docs", Status: 0},
+		{Name: "Review docs", Status: 1},
+	})
+	assert.NoError(t, err)
+
+	tasks, err := testRepo.GetTasks(ctx)
+	assert.NoError(t, err)
+	taskIDs := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		taskIDs[task.Name] = task.ID
+	}
+
+	renamed := "Review release notes"
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	type args struct {
+		ctx   context.Context
+		query string
+		limit int
+	}
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		args           args
+		wantNames      []string
+		wantHighlights []string
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name:           "search tasks by prefix",
+			args:           args{ctx: ctx, query: "infra"},
+			wantNames:      []string{"Deploy infra", "Write infrastructure docs"},
+			wantHighlights: []string{"Deploy <mark>infra</mark>", "Write <mark>infrastructure</mark> docs"},
+		},
+		{
+			name:           "search tasks with limit",
+			args:           args{ctx: ctx, query: "docs", limit: 1},
+			wantNames:      []string{"Review docs"},
+			wantHighlights: []string{"Review <mark>docs</mark>"},
+		},
+		{
+			name: "search tasks after update",
+			mockSetup: func() {
+				assert.NoError(t, testRepo.UpdateTask(ctx, taskIDs["Review docs"], &renamed, nil))
+			},
+			args:           args{ctx: ctx, query: "docs"},
+			wantNames:      []string{"Write infrastructure docs"},
+			wantHighlights: []string{"Write infrastructure <mark>docs</mark>"},
+		},
+		{
+			name: "search tasks after delete",
+			mockSetup: func() {
+				assert.NoError(t, testRepo.DeleteTask(ctx, taskIDs["Deploy infra"]))
+			},
+			args:           args{ctx: ctx, query: "infra"},
+			wantNames:      []string{"Write infrastructure docs"},
+			wantHighlights: []string{"Write <mark>infrastructure</mark> docs"},
+		},
+		{
+			name: "search tasks with context cancellation",
+			mockSetup: func() {
+				cancel()
+			},
+			args:           args{ctx: canceledCtx, query: "docs"},
+			wantNames:      []string{},
+			wantHighlights: []string{},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			got, err := testRepo.SearchTasks(tt.args.ctx, tt.args.query, tt.args.limit)
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("taskRepo.SearchTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			names := make([]string, 0, len(got))
+			highlights := make([]string, 0, len(got))
+			for _, result := range got {
+				names = append(names, result.Task.Name)
+				highlights = append(highlights, result.Highlight)
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+			assert.Equal(t, tt.wantHighlights, highlights)
+		})
+	}
+}
+
+func Test_taskRepo_QueryTasks(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+
+	ctx := context.Background()
+	err := testRepo.CreateTasks(ctx, []models.Task{
+		{Name: "Deploy infra", Status: 0},
+		{Name: "Write infrastructure docs", Status: 1},
+		{Name: "Release v2", Status: 0},
+	})
+	assert.NoError(t, err)
+
+	tasks, err := testRepo.GetTasks(ctx)
+	assert.NoError(t, err)
+	taskIDs := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		taskIDs[task.Name] = task.ID
+	}
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	type args struct {
+		ctx   context.Context
+		query string
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantNames      []string
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name:      "query tasks by full scan",
+			args:      args{ctx: ctx, query: "open OR name:docs"},
+			wantNames: []string{"Deploy infra", "Write infrastructure docs", "Release v2"},
+		},
+		{
+			name:      "query tasks through the full-text index",
+			args:      args{ctx: ctx, query: "name:infra open"},
+			wantNames: []string{"Deploy infra"},
+		},
+		{
+			name:      "query tasks by id",
+			args:      args{ctx: ctx, query: "id=" + taskIDs["Release v2"]},
+			wantNames: []string{"Release v2"},
+		},
+		{
+			name:      "query tasks by non-existing id",
+			args:      args{ctx: ctx, query: "id=non-existing-task-id"},
+			wantNames: []string{},
+		},
+		{
+			name:           "query tasks with context cancellation",
+			args:           args{ctx: canceledCtx, query: "id=" + taskIDs["Release v2"]},
+			wantNames:      []string{},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := query.Parse(tt.args.query)
+			assert.NoError(t, err)
+
+			got, err := testRepo.QueryTasks(tt.args.ctx, q)
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("taskRepo.QueryTasks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			names := make([]string, 0, len(got))
+			for _, task := range got {
+				names = append(names, task.Name)
+			}
+
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
+type recordingPublisher struct {
+	events []models.TaskEvent
+}
+
+func (r *recordingPublisher) Publish(eventType models.TaskEventType, task models.Task) models.TaskEvent {
+	event := models.TaskEvent{ID: uint64(len(r.events) + 1), Type: eventType, Task: task}
+	r.events = append(r.events, event)
+	return event
+}
+
+func Test_taskRepo_PublishEvents(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+
+	publisher := &recordingPublisher{}
+	repo := NewRepository(WithPublisher(publisher))
+	ctx := context.Background()
+
+	err := repo.CreateTasks(ctx, []models.Task{{Name: "Task 1", Status: 0}, {Name: "Task 2", Status: 0}})
+	assert.NoError(t, err)
+
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	models.SortTasksByID(tasks)
+
+	name := "Updated Task 1"
+	status := 1
+	assert.NoError(t, repo.UpdateTask(ctx, tasks[0].ID, &name, nil))
+	_, err = repo.BatchUpdateTasks(ctx, []string{tasks[0].ID, tasks[1].ID}, nil, &status)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.DeleteTask(ctx, tasks[0].ID))
+	_, err = repo.BatchDeleteTasks(ctx, []string{tasks[1].ID, "non-existing-task-id"})
+	assert.NoError(t, err)
+
+	type event struct {
+		Type models.TaskEventType
+		Task models.Task
+	}
+	got := make([]event, 0, len(publisher.events))
+	for _, e := range publisher.events {
+		got = append(got, event{Type: e.Type, Task: e.Task})
+	}
+
+	assert.Equal(t, []event{
+		{Type: models.TaskCreated, Task: tasks[0]},
+		{Type: models.TaskCreated, Task: tasks[1]},
+		{Type: models.TaskUpdated, Task: models.Task{ID: tasks[0].ID, Name: name, Status: 0}},
+		{Type: models.TaskUpdated, Task: models.Task{ID: tasks[0].ID, Name: name, Status: status}},
+		{Type: models.TaskCompleted, Task: models.Task{ID: tasks[0].ID, Name: name, Status: status}},
+		{Type: models.TaskUpdated, Task: models.Task{ID: tasks[1].ID, Name: tasks[1].Name, Status: status}},
+		{Type: models.TaskCompleted, Task: models.Task{ID: tasks[1].ID, Name: tasks[1].Name, Status: status}},
+		{Type: models.TaskDeleted, Task: models.Task{ID: tasks[0].ID, Name: name, Status: status}},
+		{Type: models.TaskDeleted, Task: models.Task{ID: tasks[1].ID, Name: tasks[1].Name, Status: status}},
+	}, got)
+}
+
+func Test_taskRepo_SyncTasks(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+	changes = newChangeLog()
+
+	repo := NewRepository()
+	ctx := context.Background()
+
+	err := repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}, {Name: "Task 2"}, {Name: "Task 3"}})
+	assert.NoError(t, err)
+
+	full, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Len(t, full.Tasks, 3)
+	assert.Empty(t, full.Deleted)
+	assert.Equal(t, uint64(3), full.Seq)
+	task1, task2, task3 := full.Tasks[0], full.Tasks[1], full.Tasks[2]
+
+	unchanged, err := repo.SyncTasks(ctx, full.Seq)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: []models.Task{}, Deleted: []string{}, Seq: full.Seq}, unchanged)
+
+	name := "Updated Task 3"
+	assert.NoError(t, repo.UpdateTask(ctx, task3.ID, &name, nil))
+	assert.NoError(t, repo.DeleteTask(ctx, task1.ID))
+	status := 1
+	_, err = repo.BatchUpdateTasks(ctx, []string{task2.ID}, nil, &status)
+	assert.NoError(t, err)
+
+	delta, err := repo.SyncTasks(ctx, full.Seq)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{
+		Tasks: []models.Task{
+			{ID: task3.ID, Name: name, Status: task3.Status},
+			{ID: task2.ID, Name: task2.Name, Status: status},
+		},
+		Deleted: []string{task1.ID},
+		Seq:     full.Seq + 3,
+	}, delta)
+
+	_, err = repo.BatchDeleteTasks(ctx, []string{task2.ID})
+	assert.NoError(t, err)
+
+	delta, err = repo.SyncTasks(ctx, delta.Seq)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TaskChanges{Tasks: []models.Task{}, Deleted: []string{task2.ID}, Seq: full.Seq + 4}, delta)
+
+	full, err = repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: task3.ID, Name: name, Status: task3.Status}}, full.Tasks)
+	assert.Empty(t, full.Deleted)
+
+	_, err = repo.SyncTasks(ctx, full.Seq+1)
+	assert.ErrorIs(t, err, ErrSyncTokenExpired)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.SyncTasks(canceledCtx, 0)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_taskRepo_TasksAsOf(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+	changes = newChangeLog()
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	now := start
+	repo := NewRepository(WithClock(func() time.Time { return now }), WithHistoryRetention(7*24*time.Hour))
+	ctx := context.Background()
+
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}))
+	created, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	task1, task2 := created[0], created[1]
+
+	now = start.Add(time.Hour)
+	name, status := "Renamed Task 1", 1
+	assert.NoError(t, repo.UpdateTask(ctx, task1.ID, &name, &status))
+
+	now = start.Add(2 * time.Hour)
+	assert.NoError(t, repo.DeleteTask(ctx, task2.ID))
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		expected []models.Task
+	}{
+		{name: "before the first task", at: start.Add(-time.Second), expected: []models.Task{}},
+		{name: "at the creation", at: start, expected: []models.Task{task1, task2}},
+		{name: "after the update", at: start.Add(90 * time.Minute), expected: []models.Task{{ID: task1.ID, Name: name, Status: status}, task2}},
+		{name: "after the deletion", at: start.Add(3 * time.Hour), expected: []models.Task{{ID: task1.ID, Name: name, Status: status}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.TasksAsOf(ctx, tt.at)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tasks)
+		})
+	}
+
+	// the next change prunes the versions superseded before the retention period
+	now = start.Add(8 * 24 * time.Hour)
+	assert.NoError(t, repo.CreateTasks(ctx, []models.Task{{Name: "Task 3"}}))
+
+	_, err = repo.TasksAsOf(ctx, start.Add(90*time.Minute))
+	assert.ErrorIs(t, err, ErrHistoryExpired)
+
+	tasks, err := repo.TasksAsOf(ctx, now.Add(-7*24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{{ID: task1.ID, Name: name, Status: status}}, tasks)
+	assert.Len(t, repo.(*taskRepo).history.versions, 2)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = repo.TasksAsOf(canceledCtx, now)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_taskRepo_RestoreTasks(t *testing.T) {
+	manager = sync.Map{}
+	index.Reset()
+	changes = newChangeLog()
+
+	repo := NewRepository()
+	ctx := context.Background()
+
+	created := []models.Task{{Name: "Task 1"}, {Name: "Task 2", Status: 1}}
+	assert.NoError(t, repo.CreateTasks(ctx, created))
+	assert.NotEmpty(t, created[0].ID)
+	assert.NotEmpty(t, created[1].ID)
+	assert.NoError(t, repo.DeleteTask(ctx, created[1].ID))
+
+	tests := []struct {
+		name    string
+		tasks   []models.Task
+		wantErr error
+	}{
+		{name: "restore a deleted task", tasks: []models.Task{created[1]}},
+		{name: "restore an existing task", tasks: []models.Task{created[0]}, wantErr: ErrTaskExists},
+		{name: "restore a task with an invalid id", tasks: []models.Task{{ID: "1", Name: "Task 3"}}, wantErr: ErrTaskID},
+		{
+			name:    "restore the same task twice",
+			tasks:   []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 3"}, {ID: "9bsv0s2hf8ng030mva9g", Name: "Task 3"}},
+			wantErr: ErrTaskExists,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.RestoreTasks(ctx, tt.tasks)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	tasks, err := repo.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, created, tasks)
+
+	results, err := repo.SearchTasks(ctx, "task", 0)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+}
+
+func Test_taskRepo_ConcurrentUpdates(t *testing.T) {
+	ResetTasks()
+	t.Cleanup(ResetTasks)
+
+	ctx := context.Background()
+	repo := NewRepository()
+	tasks := []models.Task{{Name: "Task 0"}}
+	assert.NoError(t, repo.CreateTasks(ctx, tasks))
+	taskID := tasks[0].ID
+
+	// the index, the change log and the history follow the storage in the same order
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				name := fmt.Sprintf("Writer%d %d", i, j)
+				assert.NoError(t, repo.UpdateTask(ctx, taskID, &name, nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	task, err := repo.GetTask(ctx, taskID)
+	assert.NoError(t, err)
+
+	results, err := repo.SearchTasks(ctx, search.Tokenize(task.Name)[0], 0)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, task, results[0].Task)
+	}
+
+	changes, err := repo.SyncTasks(ctx, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, changes.Tasks)
+	assert.Equal(t, uint64(1+8*50), changes.Seq)
+
+	asOf, err := repo.TasksAsOf(ctx, time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, asOf)
+}
+
+func Test_taskRepo_TasksAsOf_PerRepository(t *testing.T) {
+	ResetTasks()
+	t.Cleanup(ResetTasks)
+
+	start := time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)
+	now := start
+	clock := func() time.Time { return now }
+	kept := NewRepository(WithClock(clock))
+	pruned := NewRepository(WithClock(clock), WithHistoryRetention(time.Hour))
+	ctx := context.Background()
+
+	tasks := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, kept.CreateTasks(ctx, tasks))
+
+	// the retention of a repository does not prune the history of another one
+	now = start.Add(2 * time.Hour)
+	assert.NoError(t, pruned.CreateTasks(ctx, []models.Task{{Name: "Task 2"}}))
+
+	asOf, err := kept.TasksAsOf(ctx, start)
+	assert.NoError(t, err)
+	assert.Equal(t, tasks, asOf)
+
+	_, err = pruned.TasksAsOf(ctx, start)
+	assert.ErrorIs(t, err, ErrHistoryExpired)
+}
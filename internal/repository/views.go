@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// viewRepo keeps the views in the memory, as *models.View so that they can be compared and swapped.
+type viewRepo struct {
+	views sync.Map
+}
+
+var _ ViewManager = (*viewRepo)(nil)
+
+var (
+	// ErrViewNotFound represents an error when a view is not found
+	ErrViewNotFound = errors.New("view not found")
+	// ErrViewType represents an error when the view type is invalid
+	ErrViewType = errors.New("view type error")
+	// ErrViewExists represents an error when a view is restored with the id of an existing view
+	ErrViewExists = errors.New("view already exists")
+)
+
+// NewViewRepository creates a new view manager for managing saved views in the memory
+func NewViewRepository() ViewManager {
+	return &viewRepo{}
+}
+
+// GetViews returns the views of the owner sorted by ID
+func (v *viewRepo) GetViews(ctx context.Context, owner string) ([]models.View, error) {
+	return v.filter(ctx, func(view *models.View) bool { return view.Owner == owner })
+}
+
+// ListViews returns the views of every owner sorted by ID
+func (v *viewRepo) ListViews(ctx context.Context) ([]models.View, error) {
+	return v.filter(ctx, func(*models.View) bool { return true })
+}
+
+// filter returns the views matched by match sorted by ID
+func (v *viewRepo) filter(ctx context.Context, match func(view *models.View) bool) ([]models.View, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.View, 0), ctx.Err()
+	default:
+	}
+
+	var err error
+	result := make([]models.View, 0)
+
+	v.views.Range(func(key, value interface{}) bool {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return false
+		default:
+		}
+
+		view, ok := value.(*models.View)
+		if !ok {
+			err = ErrViewType
+			return false
+		}
+
+		if match(view) {
+			result = append(result, *view)
+		}
+		return true
+	})
+
+	if err != nil {
+		return make([]models.View, 0), err
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+// GetView returns a view by view id
+func (v *viewRepo) GetView(ctx context.Context, viewID string) (models.View, error) {
+	select {
+	case <-ctx.Done():
+		return models.View{}, ctx.Err()
+	default:
+	}
+
+	value, exists := v.views.Load(viewID)
+	if !exists {
+		return models.View{}, ErrViewNotFound
+	}
+
+	view, ok := value.(*models.View)
+	if !ok {
+		return models.View{}, ErrViewType
+	}
+
+	return *view, nil
+}
+
+// CreateView stores a new view with a new view id and returns it
+func (v *viewRepo) CreateView(ctx context.Context, view models.View) (models.View, error) {
+	select {
+	case <-ctx.Done():
+		return models.View{}, ctx.Err()
+	default:
+	}
+
+	view.NewViewID()
+	view.CreatedAt = time.Now().UTC()
+	view.UpdatedAt = view.CreatedAt
+	v.views.Store(view.ID, &view)
+
+	return view, nil
+}
+
+// UpdateView replaces the name, query, sort and columns of an existing view. The view is swapped only
+// if it did not change since it was read, and read again otherwise, so that a concurrent update is not lost
+// and a concurrently deleted view is not stored again.
+func (v *viewRepo) UpdateView(ctx context.Context, view models.View) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		value, exists := v.views.Load(view.ID)
+		if !exists {
+			return ErrViewNotFound
+		}
+
+		stored, ok := value.(*models.View)
+		if !ok {
+			return ErrViewType
+		}
+
+		updated := *stored
+		updated.Name = view.Name
+		updated.Query = view.Query
+		updated.Sort = view.Sort
+		updated.Columns = view.Columns
+		updated.UpdatedAt = time.Now().UTC()
+		if v.views.CompareAndSwap(view.ID, value, &updated) {
+			return nil
+		}
+	}
+}
+
+// DeleteView deletes a view by view id
+func (v *viewRepo) DeleteView(ctx context.Context, viewID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if _, exists := v.views.LoadAndDelete(viewID); !exists {
+		return ErrViewNotFound
+	}
+
+	return nil
+}
+
+// RestoreViews stores the views with their ids and times, no view is stored when one of them already exists
+func (v *viewRepo) RestoreViews(ctx context.Context, views []models.View) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	for _, view := range views {
+		if _, exists := v.views.Load(view.ID); exists {
+			return ErrViewExists
+		}
+	}
+
+	for i, view := range views {
+		if _, loaded := v.views.LoadOrStore(view.ID, &view); loaded {
+			// created meanwhile, the views stored so far are deleted again
+			for _, stored := range views[:i] {
+				v.views.Delete(stored.ID)
+			}
+
+			return ErrViewExists
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_viewRepo(t *testing.T) {
+	repo := NewViewRepository()
+	ctx := context.Background()
+
+	aliceView, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, aliceView.ID)
+	assert.False(t, aliceView.CreatedAt.IsZero())
+
+	bobView, err := repo.CreateView(ctx, models.View{Owner: "bob", Name: "Done tasks", Query: "done", Sort: "-name"})
+	assert.NoError(t, err)
+
+	views, err := repo.GetViews(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.View{aliceView}, views)
+
+	got, err := repo.GetView(ctx, bobView.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, bobView, got)
+
+	bobView.Name = "Recently done"
+	bobView.Columns = []string{"name"}
+	bobView.Owner = "mallory"
+	assert.NoError(t, repo.UpdateView(ctx, bobView))
+
+	got, err = repo.GetView(ctx, bobView.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Recently done", got.Name)
+	assert.Equal(t, []string{"name"}, got.Columns)
+	assert.Equal(t, "bob", got.Owner)
+
+	assert.NoError(t, repo.DeleteView(ctx, bobView.ID))
+
+	_, err = repo.GetView(ctx, bobView.ID)
+	assert.ErrorIs(t, err, ErrViewNotFound)
+	assert.ErrorIs(t, repo.UpdateView(ctx, bobView), ErrViewNotFound)
+	assert.ErrorIs(t, repo.DeleteView(ctx, bobView.ID), ErrViewNotFound)
+}
+
+func Test_viewRepo_ConcurrentUpdateAndDelete(t *testing.T) {
+	repo := NewViewRepository()
+	ctx := context.Background()
+
+	view, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+
+	// the updates racing the deletion never store the view again
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				update := view
+				update.Name = fmt.Sprintf("Open tasks %d", j)
+				if err := repo.UpdateView(ctx, update); err != nil {
+					assert.ErrorIs(t, err, ErrViewNotFound)
+					return
+				}
+			}
+		}()
+	}
+	assert.NoError(t, repo.DeleteView(ctx, view.ID))
+	wg.Wait()
+
+	_, err = repo.GetView(ctx, view.ID)
+	assert.ErrorIs(t, err, ErrViewNotFound)
+}
+
+func Test_viewRepo_ContextCancellation(t *testing.T) {
+	repo := NewViewRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks"})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = repo.GetView(ctx, "view")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	assert.True(t, errors.Is(repo.UpdateView(ctx, models.View{ID: "view"}), context.Canceled))
+	assert.True(t, errors.Is(repo.DeleteView(ctx, "view"), context.Canceled))
+	assert.True(t, errors.Is(repo.RestoreViews(ctx, []models.View{{ID: "view"}}), context.Canceled))
+
+	_, err = repo.ListViews(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func Test_viewRepo_RestoreViews(t *testing.T) {
+	repo := NewViewRepository()
+	ctx := context.Background()
+
+	created, err := repo.CreateView(ctx, models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+
+	restored := []models.View{
+		{ID: "cv1h8ms2hf8ng030mvb0", Owner: "bob", Name: "Done tasks", Query: "done", CreatedAt: created.CreatedAt.Add(-time.Hour)},
+		{ID: "cv1h8ms2hf8ng030mvc0", Owner: "alice", Name: "Deploys", Query: "name:deploy"},
+	}
+	assert.NoError(t, repo.RestoreViews(ctx, restored))
+
+	// no view is restored when one of them exists
+	err = repo.RestoreViews(ctx, []models.View{{ID: "cv1h8ms2hf8ng030mvd0", Owner: "bob"}, restored[1]})
+	assert.ErrorIs(t, err, ErrViewExists)
+
+	views, err := repo.ListViews(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, append(restored, created), views)
+}
+
+func Test_viewRepo_GetViews(t *testing.T) {
+	repo := &viewRepo{}
+	canceledCtx, cancel := context.WithCancel(context.Background())
+
+	tests := []struct {
+		name           string
+		mockSetup      func()
+		ctx            context.Context
+		want           []models.View
+		wantErr        bool
+		wantErrContent error
+	}{
+		{
+			name: "get views of the owner",
+			mockSetup: func() {
+				repo.views.Store("v2", &models.View{ID: "v2", Owner: "alice"})
+				repo.views.Store("v1", &models.View{ID: "v1", Owner: "alice"})
+				repo.views.Store("v3", &models.View{ID: "v3", Owner: "bob"})
+			},
+			ctx:  context.Background(),
+			want: []models.View{{ID: "v1", Owner: "alice"}, {ID: "v2", Owner: "alice"}},
+		},
+		{
+			name: "get views failed (type error)",
+			mockSetup: func() {
+				repo.views.Store("v4", nil)
+			},
+			ctx:            context.Background(),
+			want:           []models.View{},
+			wantErr:        true,
+			wantErrContent: ErrViewType,
+		},
+		{
+			name: "get views with context cancellation",
+			mockSetup: func() {
+				cancel()
+			},
+			ctx:            canceledCtx,
+			want:           []models.View{},
+			wantErr:        true,
+			wantErrContent: context.Canceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			got, err := repo.GetViews(tt.ctx, "alice")
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, tt.wantErrContent)) {
+				t.Errorf("viewRepo.GetViews() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
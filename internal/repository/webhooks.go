@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// maxDeliveries is the number of deliveries kept in the log of every webhook
+const maxDeliveries = 100
+
+type webhookRepo struct {
+	mu         sync.RWMutex
+	webhooks   map[string]models.Webhook
+	deliveries map[string][]models.WebhookDelivery
+}
+
+var _ WebhookManager = (*webhookRepo)(nil)
+
+var (
+	// ErrWebhookNotFound represents an error when a webhook is not found
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookExists represents an error when a webhook is restored with the id of an existing webhook
+	ErrWebhookExists = errors.New("webhook already exists")
+)
+
+// NewWebhookRepository creates a new webhook manager for managing webhooks in the memory
+func NewWebhookRepository() WebhookManager {
+	return &webhookRepo{
+		webhooks:   make(map[string]models.Webhook),
+		deliveries: make(map[string][]models.WebhookDelivery),
+	}
+}
+
+// GetWebhooks returns every webhook sorted by ID
+func (w *webhookRepo) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.Webhook, 0), ctx.Err()
+	default:
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	result := make([]models.Webhook, 0, len(w.webhooks))
+	for _, webhook := range w.webhooks {
+		result = append(result, webhook)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, nil
+}
+
+// GetWebhook returns a webhook by webhook id
+func (w *webhookRepo) GetWebhook(ctx context.Context, webhookID string) (models.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		return models.Webhook{}, ctx.Err()
+	default:
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	webhook, exists := w.webhooks[webhookID]
+	if !exists {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
+
+// CreateWebhook stores a new webhook with a new webhook id and returns it
+func (w *webhookRepo) CreateWebhook(ctx context.Context, webhook models.Webhook) (models.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		return models.Webhook{}, ctx.Err()
+	default:
+	}
+
+	webhook.NewWebhookID()
+	webhook.CreatedAt = time.Now().UTC()
+	webhook.UpdatedAt = webhook.CreatedAt
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.webhooks[webhook.ID] = webhook
+
+	return webhook, nil
+}
+
+// UpdateWebhook replaces the URL, secret and event types of an existing webhook
+func (w *webhookRepo) UpdateWebhook(ctx context.Context, webhook models.Webhook) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stored, exists := w.webhooks[webhook.ID]
+	if !exists {
+		return ErrWebhookNotFound
+	}
+
+	stored.URL = webhook.URL
+	stored.Secret = webhook.Secret
+	stored.Events = webhook.Events
+	stored.UpdatedAt = time.Now().UTC()
+	w.webhooks[webhook.ID] = stored
+
+	return nil
+}
+
+// DeleteWebhook deletes a webhook and its delivery log by webhook id
+func (w *webhookRepo) DeleteWebhook(ctx context.Context, webhookID string) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.webhooks[webhookID]; !exists {
+		return ErrWebhookNotFound
+	}
+
+	delete(w.webhooks, webhookID)
+	delete(w.deliveries, webhookID)
+
+	return nil
+}
+
+// RestoreWebhooks stores the webhooks with their ids and times and an empty delivery log,
+// no webhook is stored when one of them already exists
+func (w *webhookRepo) RestoreWebhooks(ctx context.Context, webhooks []models.Webhook) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, webhook := range webhooks {
+		if _, exists := w.webhooks[webhook.ID]; exists {
+			return ErrWebhookExists
+		}
+	}
+
+	for _, webhook := range webhooks {
+		w.webhooks[webhook.ID] = webhook
+	}
+
+	return nil
+}
+
+// AddDelivery appends a delivery to the log of its webhook, only the latest deliveries are kept
+func (w *webhookRepo) AddDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.webhooks[delivery.WebhookID]; !exists {
+		return ErrWebhookNotFound
+	}
+
+	delivery.NewWebhookDeliveryID()
+	deliveries := append(w.deliveries[delivery.WebhookID], delivery)
+	if len(deliveries) > maxDeliveries {
+		deliveries = deliveries[len(deliveries)-maxDeliveries:]
+	}
+	w.deliveries[delivery.WebhookID] = deliveries
+
+	return nil
+}
+
+// GetDeliveries returns the delivery log of a webhook, the latest delivery first
+func (w *webhookRepo) GetDeliveries(ctx context.Context, webhookID string) ([]models.WebhookDelivery, error) {
+	select {
+	case <-ctx.Done():
+		return make([]models.WebhookDelivery, 0), ctx.Err()
+	default:
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if _, exists := w.webhooks[webhookID]; !exists {
+		return make([]models.WebhookDelivery, 0), ErrWebhookNotFound
+	}
+
+	deliveries := w.deliveries[webhookID]
+	result := make([]models.WebhookDelivery, 0, len(deliveries))
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		result = append(result, deliveries[i])
+	}
+
+	return result, nil
+}
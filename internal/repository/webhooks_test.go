@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_webhookRepo(t *testing.T) {
+	repo := NewWebhookRepository()
+	ctx := context.Background()
+
+	ciHook, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci", Events: []models.TaskEventType{models.TaskCompleted}})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ciHook.ID)
+	assert.False(t, ciHook.CreatedAt.IsZero())
+
+	chatHook, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://chat.example.com/hooks", Secret: "chat"})
+	assert.NoError(t, err)
+
+	webhooks, err := repo.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Webhook{ciHook, chatHook}, webhooks)
+
+	chatHook.URL = "https://chat.example.com/v2/hooks"
+	chatHook.Events = []models.TaskEventType{models.TaskDeleted}
+	assert.NoError(t, repo.UpdateWebhook(ctx, chatHook))
+
+	got, err := repo.GetWebhook(ctx, chatHook.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, chatHook.URL, got.URL)
+	assert.Equal(t, chatHook.Events, got.Events)
+	assert.Equal(t, chatHook.CreatedAt, got.CreatedAt)
+
+	assert.NoError(t, repo.DeleteWebhook(ctx, chatHook.ID))
+
+	_, err = repo.GetWebhook(ctx, chatHook.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+	assert.ErrorIs(t, repo.UpdateWebhook(ctx, chatHook), ErrWebhookNotFound)
+	assert.ErrorIs(t, repo.DeleteWebhook(ctx, chatHook.ID), ErrWebhookNotFound)
+}
+
+func Test_webhookRepo_Deliveries(t *testing.T) {
+	repo := NewWebhookRepository()
+	ctx := context.Background()
+
+	webhook, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci"})
+	assert.NoError(t, err)
+
+	for attempt := 1; attempt <= maxDeliveries+2; attempt++ {
+		assert.NoError(t, repo.AddDelivery(ctx, models.WebhookDelivery{WebhookID: webhook.ID, EventID: 1, Attempt: attempt}))
+	}
+
+	deliveries, err := repo.GetDeliveries(ctx, webhook.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, maxDeliveries)
+	assert.Equal(t, maxDeliveries+2, deliveries[0].Attempt)
+	assert.Equal(t, 3, deliveries[len(deliveries)-1].Attempt)
+	assert.NotEmpty(t, deliveries[0].ID)
+
+	assert.ErrorIs(t, repo.AddDelivery(ctx, models.WebhookDelivery{WebhookID: "unknown"}), ErrWebhookNotFound)
+	_, err = repo.GetDeliveries(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+	assert.NoError(t, repo.DeleteWebhook(ctx, webhook.ID))
+	_, err = repo.GetDeliveries(ctx, webhook.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func Test_webhookRepo_ContextCancellation(t *testing.T) {
+	repo := NewWebhookRepository()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks"})
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = repo.GetWebhooks(ctx)
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = repo.GetWebhook(ctx, "webhook")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	_, err = repo.GetDeliveries(ctx, "webhook")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	assert.True(t, errors.Is(repo.UpdateWebhook(ctx, models.Webhook{ID: "webhook"}), context.Canceled))
+	assert.True(t, errors.Is(repo.DeleteWebhook(ctx, "webhook"), context.Canceled))
+	assert.True(t, errors.Is(repo.AddDelivery(ctx, models.WebhookDelivery{WebhookID: "webhook"}), context.Canceled))
+	assert.True(t, errors.Is(repo.RestoreWebhooks(ctx, []models.Webhook{{ID: "webhook"}}), context.Canceled))
+}
+
+func Test_webhookRepo_RestoreWebhooks(t *testing.T) {
+	repo := NewWebhookRepository()
+	ctx := context.Background()
+
+	created, err := repo.CreateWebhook(ctx, models.Webhook{URL: "https://ci.example.com/hooks", Secret: "ci"})
+	assert.NoError(t, err)
+
+	restored := []models.Webhook{
+		{ID: "cv2k1ts2hf8ng030mvc0", URL: "https://chat.example.com/hooks", Secret: "chat", CreatedAt: created.CreatedAt.Add(-time.Hour)},
+		{ID: "cv2k1ts2hf8ng030mvd0", URL: "https://ops.example.com/hooks", Secret: "ops", Events: []models.TaskEventType{models.TaskDeleted}},
+	}
+	assert.NoError(t, repo.RestoreWebhooks(ctx, restored))
+
+	// no webhook is restored when one of them exists
+	err = repo.RestoreWebhooks(ctx, []models.Webhook{{ID: "cv2k1ts2hf8ng030mve0"}, restored[0]})
+	assert.ErrorIs(t, err, ErrWebhookExists)
+
+	webhooks, err := repo.GetWebhooks(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, append(restored, created), webhooks)
+}
@@ -0,0 +1,210 @@
+// Package schema versions the data persisted by a storage and migrates the data of older releases
+// with an ordered registry of migrations. Every storage records the version along with its data, see Store.
+package schema
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the file holding the schema version in a data directory
+const FileName = "schema.json"
+
+// LegacyVersion is the version of the data written before the schema versions were recorded
+const LegacyVersion = 1
+
+var (
+	// ErrOutdated represents an error when the data of a storage must be migrated before it is used
+	ErrOutdated = errors.New("the storage has an older schema version, start the server to migrate it")
+	// ErrNewer represents an error when the data of a storage was written by a newer release
+	ErrNewer = errors.New("the storage has a newer schema version than this release")
+)
+
+// Store represents the data of a storage, which records its schema version along with the data.
+type Store interface {
+	// ReadVersion returns the recorded schema version, or 0 when there is none.
+	ReadVersion(ctx context.Context) (int, error)
+	// WriteVersion records the schema version.
+	WriteVersion(ctx context.Context, version int) error
+}
+
+// Migration upgrades the data of a store from the previous schema version to its version.
+type Migration[S Store] struct {
+	Version     int    // schema version of the data after the migration
+	Description string // what the migration changes
+	// Apply upgrades the data of the store and describes the changes, a dry run only describes
+	// the changes it would make. The store may have no data yet.
+	Apply func(ctx context.Context, store S, dryRun bool) (string, error)
+}
+
+// Step represents a migration run on a store, or planned by a dry run.
+type Step struct {
+	Version     int
+	Description string
+	Changes     string
+}
+
+// Registry represents the ordered migrations of the stores of a storage.
+type Registry[S Store] struct {
+	migrations []Migration[S]
+}
+
+// NewRegistry creates a registry of the migrations, which must upgrade the data one version at a time
+// starting from LegacyVersion. It panics otherwise, as the registry is declared along with the storage.
+func NewRegistry[S Store](migrations ...Migration[S]) *Registry[S] {
+	for i, migration := range migrations {
+		if migration.Version != LegacyVersion+i+1 || migration.Apply == nil {
+			panic(fmt.Sprintf("schema: migration %d of the registry must migrate to version %d", i, LegacyVersion+i+1))
+		}
+	}
+
+	return &Registry[S]{migrations: migrations}
+}
+
+// Current returns the schema version of the data written by this release.
+func (r *Registry[S]) Current() int {
+	return LegacyVersion + len(r.migrations)
+}
+
+// Migrate runs the migrations from the schema version of the store to the current version in order,
+// recording the version after every migration so that a failed migration resumes where it stopped.
+// A dry run only reports the changes of the migrations. It returns the steps run, or planned by a dry run.
+func (r *Registry[S]) Migrate(ctx context.Context, store S, dryRun bool) ([]Step, error) {
+	version, err := r.version(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if version > r.Current() {
+		return nil, fmt.Errorf("%w: %d, expected %d", ErrNewer, version, r.Current())
+	}
+
+	var steps []Step
+	for _, migration := range r.migrations[version-LegacyVersion:] {
+		changes, err := migration.Apply(ctx, store, dryRun)
+		if err != nil {
+			return steps, fmt.Errorf("migrate to schema version %d: %w", migration.Version, err)
+		}
+
+		if !dryRun {
+			if err := store.WriteVersion(ctx, migration.Version); err != nil {
+				return steps, err
+			}
+		}
+
+		steps = append(steps, Step{Version: migration.Version, Description: migration.Description, Changes: changes})
+	}
+
+	return steps, nil
+}
+
+// Check returns an error unless the store has the current schema version.
+func (r *Registry[S]) Check(ctx context.Context, store S) error {
+	version, err := r.version(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case version < r.Current():
+		return fmt.Errorf("%w: %d, expected %d", ErrOutdated, version, r.Current())
+	case version > r.Current():
+		return fmt.Errorf("%w: %d, expected %d", ErrNewer, version, r.Current())
+	}
+
+	return nil
+}
+
+// version returns the schema version of the store, the data of a store without version has the legacy version.
+func (r *Registry[S]) version(ctx context.Context, store S) (int, error) {
+	version, err := store.ReadVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if version == 0 {
+		return LegacyVersion, nil
+	}
+	if version < LegacyVersion {
+		return 0, fmt.Errorf("invalid schema version %d", version)
+	}
+
+	return version, nil
+}
+
+// Dir is a store of the files of a data directory, recording the schema version in its schema file.
+type Dir string
+
+// ReadVersion returns the schema version recorded in the schema file of the directory, or 0 when there is none.
+func (d Dir) ReadVersion(context.Context) (int, error) {
+	return ReadVersion(string(d))
+}
+
+// WriteVersion atomically records the schema version in the schema file of the directory.
+func (d Dir) WriteVersion(_ context.Context, version int) error {
+	return WriteVersion(string(d), version)
+}
+
+// versionFile represents the content of the schema file.
+type versionFile struct {
+	Version int `json:"version"`
+}
+
+// ReadVersion returns the schema version recorded in the data directory, or 0 when there is none.
+func ReadVersion(dir string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var file versionFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return 0, fmt.Errorf("read %s: %w", FileName, err)
+	}
+
+	if file.Version < LegacyVersion {
+		return 0, fmt.Errorf("read %s: invalid schema version %d", FileName, file.Version)
+	}
+
+	return file.Version, nil
+}
+
+// WriteVersion atomically records the schema version in the data directory, creating the directory when missing.
+func WriteVersion(dir string, version int) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(versionFile{Version: version})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, FileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filepath.Join(dir, FileName))
+}
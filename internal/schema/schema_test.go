@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRegistry(t *testing.T) {
+	apply := func(context.Context, Dir, bool) (string, error) { return "", nil }
+
+	assert.Equal(t, LegacyVersion, NewRegistry[Dir]().Current())
+	assert.Equal(t, 3, NewRegistry(Migration[Dir]{Version: 2, Apply: apply}, Migration[Dir]{Version: 3, Apply: apply}).Current())
+	assert.Panics(t, func() { NewRegistry(Migration[Dir]{Version: 3, Apply: apply}) })
+	assert.Panics(t, func() {
+		NewRegistry(Migration[Dir]{Version: 2, Apply: apply}, Migration[Dir]{Version: 2, Apply: apply})
+	})
+	assert.Panics(t, func() { NewRegistry(Migration[Dir]{Version: 2}) })
+}
+
+func TestRegistry_Migrate(t *testing.T) {
+	ctx := context.Background()
+	var applied []int
+	fail := false
+	migration := func(version int) Migration[Dir] {
+		return Migration[Dir]{
+			Version:     version,
+			Description: "migration",
+			Apply: func(_ context.Context, _ Dir, dryRun bool) (string, error) {
+				if version == 3 && fail {
+					return "", errors.New("disk full")
+				}
+				if !dryRun {
+					applied = append(applied, version)
+				}
+				return "changed", nil
+			},
+		}
+	}
+	registry := NewRegistry(migration(2), migration(3))
+
+	dir := t.TempDir()
+	assert.ErrorIs(t, registry.Check(ctx, Dir(dir)), ErrOutdated)
+
+	// a dry run from the legacy version plans every migration
+	steps, err := registry.Migrate(ctx, Dir(dir), true)
+	assert.NoError(t, err)
+	assert.Equal(t, []Step{{Version: 2, Description: "migration", Changes: "changed"}, {Version: 3, Description: "migration", Changes: "changed"}}, steps)
+	assert.Empty(t, applied)
+	version, err := ReadVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	// a failed migration keeps the version of the previous one
+	fail = true
+	steps, err = registry.Migrate(ctx, Dir(dir), false)
+	assert.Error(t, err)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, []int{2}, applied)
+	version, err = ReadVersion(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	// and is resumed by the next run
+	fail = false
+	steps, err = registry.Migrate(ctx, Dir(dir), false)
+	assert.NoError(t, err)
+	assert.Len(t, steps, 1)
+	assert.Equal(t, []int{2, 3}, applied)
+	assert.NoError(t, registry.Check(ctx, Dir(dir)))
+
+	// the data of a newer release is refused
+	assert.NoError(t, WriteVersion(dir, 4))
+	_, err = registry.Migrate(ctx, Dir(dir), false)
+	assert.ErrorIs(t, err, ErrNewer)
+	assert.ErrorIs(t, registry.Check(ctx, Dir(dir)), ErrNewer)
+}
+
+func TestReadVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    int
+		wantErr bool
+	}{
+		{name: "version", content: `{"version":2}`, want: 2},
+		{name: "invalid json", content: `version 2`, wantErr: true},
+		{name: "invalid version", content: `{"version":0}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, FileName), []byte(tt.content), 0o644))
+
+			got, err := ReadVersion(dir)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
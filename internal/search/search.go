@@ -0,0 +1,364 @@
+package search
+
+import (
+	"html"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// HighlightPre is inserted before every matched term by Highlight
+	HighlightPre = "<mark>"
+	// HighlightPost is inserted after every matched term by Highlight
+	HighlightPost = "</mark>"
+
+	// prefixPenalty scales down the score of terms that only match a query term as a prefix
+	prefixPenalty = 0.5
+	// bm25K1 and bm25B are the usual BM25 tuning parameters
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// Hit represents a document matching a query.
+type Hit struct {
+	ID    string
+	Score float64
+	Terms []string // index terms of the document matched by the query
+}
+
+// Index is an in-memory inverted index supporting prefix matching and relevance ranking.
+// It is safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]int // term -> document id -> term frequency
+	docs     map[string][]string       // document id -> tokens
+	terms    []string                  // sorted terms, for prefix lookups
+	totalLen int
+}
+
+// NewIndex creates an empty index.
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+	}
+}
+
+// Tokenize splits text into lower-cased terms made of letters and digits.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Add indexes the text of the document, replacing the previous text of the same document.
+func (idx *Index) Add(id, text string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+
+	tokens := Tokenize(text)
+	idx.docs[id] = tokens
+	idx.totalLen += len(tokens)
+
+	for _, token := range tokens {
+		docs, exists := idx.postings[token]
+		if !exists {
+			docs = make(map[string]int)
+			idx.postings[token] = docs
+			idx.insertTerm(token)
+		}
+		docs[id]++
+	}
+}
+
+// Remove removes the document from the index.
+func (idx *Index) Remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+// Reset removes every document from the index.
+func (idx *Index) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.postings = make(map[string]map[string]int)
+	idx.docs = make(map[string][]string)
+	idx.terms = nil
+	idx.totalLen = 0
+}
+
+func (idx *Index) remove(id string) {
+	tokens, exists := idx.docs[id]
+	if !exists {
+		return
+	}
+
+	for _, token := range tokens {
+		docs := idx.postings[token]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(idx.postings, token)
+			idx.deleteTerm(token)
+		}
+	}
+
+	idx.totalLen -= len(tokens)
+	delete(idx.docs, id)
+}
+
+func (idx *Index) insertTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	idx.terms = append(idx.terms, "")
+	copy(idx.terms[i+1:], idx.terms[i:])
+	idx.terms[i] = term
+}
+
+func (idx *Index) deleteTerm(term string) {
+	i := sort.SearchStrings(idx.terms, term)
+	if i < len(idx.terms) && idx.terms[i] == term {
+		idx.terms = append(idx.terms[:i], idx.terms[i+1:]...)
+	}
+}
+
+// Search returns the documents matching every term of the query, ordered by relevance.
+// Every query term matches the index terms it is a prefix of, exact matches scoring higher.
+// Documents with the same score are ordered by id.
+func (idx *Index) Search(query string) []Hit {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	docCount := float64(len(idx.docs))
+	avgLen := float64(idx.totalLen) / math.Max(docCount, 1)
+
+	var candidates map[string]*Hit
+	for _, queryTerm := range queryTerms {
+		matches := make(map[string]*Hit)
+
+		for _, term := range idx.prefixed(queryTerm) {
+			docs := idx.postings[term]
+			idf := inverseDocFreq(len(docs), docCount)
+			weight := termWeight(term, queryTerm)
+
+			for id, tf := range docs {
+				score := weight * idf * termScore(tf, len(idx.docs[id]), avgLen)
+
+				hit, exists := matches[id]
+				if !exists {
+					hit = &Hit{ID: id}
+					matches[id] = hit
+				}
+				hit.Score += score
+				hit.Terms = append(hit.Terms, term)
+			}
+		}
+
+		if candidates == nil {
+			candidates = matches
+			continue
+		}
+
+		for id, hit := range candidates {
+			match, exists := matches[id]
+			if !exists {
+				delete(candidates, id)
+				continue
+			}
+			hit.Score += match.Score
+			hit.Terms = append(hit.Terms, match.Terms...)
+		}
+	}
+
+	return sortHits(candidates)
+}
+
+// Stats represents the statistics of a whole collection of documents, used by Rank to score some of them.
+type Stats struct {
+	DocCount int            // number of documents
+	TotalLen int            // number of tokens of every document
+	DocFreq  map[string]int // number of documents holding the term, for every term of MatchedTerms
+}
+
+// MatchedTerms returns the distinct tokens of the texts prefixed by a term of the query, sorted.
+func MatchedTerms(query string, texts ...string) []string {
+	queryTerms := Tokenize(query)
+
+	var terms []string
+	for _, text := range texts {
+		for _, token := range Tokenize(text) {
+			for _, queryTerm := range queryTerms {
+				if strings.HasPrefix(token, queryTerm) {
+					terms = append(terms, token)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Strings(terms)
+
+	return slices.Compact(terms)
+}
+
+// Rank returns the documents matching every term of the query among the given texts by document id,
+// ordered by relevance. The documents are scored against the statistics of the whole collection, like Search
+// scores the documents of an index, so that a store can rank the documents it selected without indexing
+// the other ones.
+func Rank(query string, docs map[string]string, stats Stats) []Hit {
+	queryTerms := Tokenize(query)
+	if len(queryTerms) == 0 {
+		return nil
+	}
+
+	docCount := float64(stats.DocCount)
+	avgLen := float64(stats.TotalLen) / math.Max(docCount, 1)
+
+	candidates := make(map[string]*Hit, len(docs))
+	for id, text := range docs {
+		tokens := Tokenize(text)
+		freqs := make(map[string]int, len(tokens))
+		for _, token := range tokens {
+			freqs[token]++
+		}
+
+		terms := make([]string, 0, len(freqs))
+		for term := range freqs {
+			terms = append(terms, term)
+		}
+		sort.Strings(terms)
+
+		hit := &Hit{ID: id}
+		for i, queryTerm := range queryTerms {
+			matched := false
+			var score float64
+			for _, term := range terms {
+				if !strings.HasPrefix(term, queryTerm) {
+					continue
+				}
+
+				score += termWeight(term, queryTerm) * inverseDocFreq(stats.DocFreq[term], docCount) * termScore(freqs[term], len(tokens), avgLen)
+				hit.Terms = append(hit.Terms, term)
+				matched = true
+			}
+
+			if !matched {
+				hit = nil
+				break
+			}
+
+			if i == 0 {
+				hit.Score = score
+			} else {
+				hit.Score += score
+			}
+		}
+
+		if hit != nil {
+			candidates[id] = hit
+		}
+	}
+
+	return sortHits(candidates)
+}
+
+// inverseDocFreq returns the BM25 inverse document frequency of a term held by docFreq documents of docCount.
+func inverseDocFreq(docFreq int, docCount float64) float64 {
+	return math.Log(1 + (docCount-float64(docFreq)+0.5)/(float64(docFreq)+0.5))
+}
+
+// termScore returns the BM25 score of a term appearing tf times in a document of docLen tokens, before its
+// inverse document frequency.
+func termScore(tf, docLen int, avgLen float64) float64 {
+	return (float64(tf) * (bm25K1 + 1)) / (float64(tf) + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgLen))
+}
+
+// termWeight returns the weight of an index term matched by a query term.
+func termWeight(term, queryTerm string) float64 {
+	if term != queryTerm {
+		return prefixPenalty
+	}
+
+	return 1
+}
+
+// sortHits returns the hits ordered by relevance, then by id.
+func sortHits(candidates map[string]*Hit) []Hit {
+	hits := make([]Hit, 0, len(candidates))
+	for _, hit := range candidates {
+		sort.Strings(hit.Terms)
+		hit.Terms = slices.Compact(hit.Terms)
+		hits = append(hits, *hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	return hits
+}
+
+// prefixed returns the index terms starting with prefix.
+func (idx *Index) prefixed(prefix string) []string {
+	start := sort.SearchStrings(idx.terms, prefix)
+	end := start
+	for end < len(idx.terms) && strings.HasPrefix(idx.terms[end], prefix) {
+		end++
+	}
+
+	return idx.terms[start:end]
+}
+
+// Highlight HTML-escapes text and wraps the words matching any of the terms with HighlightPre and HighlightPost.
+func Highlight(text string, terms []string) string {
+	matched := make(map[string]struct{}, len(terms))
+	for _, term := range terms {
+		matched[term] = struct{}{}
+	}
+
+	var b strings.Builder
+	word := -1
+	flush := func(end int) {
+		if word < 0 {
+			return
+		}
+
+		token := text[word:end]
+		if _, ok := matched[strings.ToLower(token)]; ok {
+			b.WriteString(HighlightPre + html.EscapeString(token) + HighlightPost)
+		} else {
+			b.WriteString(html.EscapeString(token))
+		}
+		word = -1
+	}
+
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if word < 0 {
+				word = i
+			}
+			continue
+		}
+
+		flush(i)
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush(len(text))
+
+	return b.String()
+}
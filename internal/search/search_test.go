@@ -0,0 +1,152 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	assert.Equal(t, []string{"fix", "ci", "pipeline", "v2"}, Tokenize("Fix CI-pipeline (v2)!"))
+	assert.Equal(t, []string{"café", "東京"}, Tokenize("Café, 東京"))
+	assert.Empty(t, Tokenize(" -- "))
+}
+
+func TestIndex_Search(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("1", "Deploy the infra")
+	idx.Add("2", "Write infrastructure docs")
+	idx.Add("3", "Review docs")
+	idx.Add("4", "Infra infra infra")
+
+	tests := []struct {
+		name      string
+		query     string
+		wantIDs   []string
+		wantTerms map[string][]string
+	}{
+		{
+			name:    "exact matches rank above prefix matches",
+			query:   "infra",
+			wantIDs: []string{"4", "1", "2"},
+			wantTerms: map[string][]string{
+				"1": {"infra"},
+				"2": {"infrastructure"},
+			},
+		},
+		{
+			name:    "every query term must match",
+			query:   "inf docs",
+			wantIDs: []string{"2"},
+			wantTerms: map[string][]string{
+				"2": {"docs", "infrastructure"},
+			},
+		},
+		{
+			name:    "no matches",
+			query:   "release",
+			wantIDs: []string{},
+		},
+		{
+			name:    "empty query",
+			query:   "  ",
+			wantIDs: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := idx.Search(tt.query)
+
+			ids := make([]string, 0, len(hits))
+			for _, hit := range hits {
+				ids = append(ids, hit.ID)
+				if terms, ok := tt.wantTerms[hit.ID]; ok {
+					assert.Equal(t, terms, hit.Terms)
+				}
+			}
+
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestIndex_AddAndRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add("1", "Old name")
+
+	idx.Add("1", "New name")
+	assert.Empty(t, idx.Search("old"))
+	assert.Len(t, idx.Search("new"), 1)
+
+	idx.Remove("1")
+	assert.Empty(t, idx.Search("new"))
+	assert.Empty(t, idx.terms)
+	assert.Zero(t, idx.totalLen)
+
+	idx.Add("2", "Another")
+	idx.Reset()
+	assert.Empty(t, idx.Search("another"))
+}
+
+func TestRank(t *testing.T) {
+	texts := map[string]string{
+		"1": "Deploy the infra",
+		"2": "Write infrastructure docs",
+		"3": "Review docs",
+		"4": "Infra infra infra",
+	}
+	idx := NewIndex()
+	stats := Stats{DocCount: len(texts), DocFreq: make(map[string]int)}
+	for id, text := range texts {
+		idx.Add(id, text)
+		stats.TotalLen += len(Tokenize(text))
+		for _, term := range MatchedTerms(text, text) {
+			stats.DocFreq[term]++
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		docs  []string
+	}{
+		{name: "every document", query: "infra", docs: []string{"1", "2", "3", "4"}},
+		{name: "candidate documents", query: "inf docs", docs: []string{"2", "3"}},
+		{name: "repeated query terms", query: "infra inf", docs: []string{"1", "2", "4"}},
+		{name: "no matches", query: "release", docs: []string{"1"}},
+		{name: "empty query", query: "  ", docs: []string{"1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := make(map[string]string, len(tt.docs))
+			for _, id := range tt.docs {
+				docs[id] = texts[id]
+			}
+
+			// the candidates score like in an index of the whole collection
+			want := make([]Hit, 0)
+			for _, hit := range idx.Search(tt.query) {
+				if _, ok := docs[hit.ID]; ok {
+					want = append(want, hit)
+				}
+			}
+
+			hits := Rank(tt.query, docs, stats)
+			if hits == nil {
+				hits = make([]Hit, 0)
+			}
+			assert.Equal(t, want, hits)
+		})
+	}
+}
+
+func TestMatchedTerms(t *testing.T) {
+	assert.Equal(t, []string{"infra", "infrastructure"}, MatchedTerms("inf", "Deploy the infra", "Infrastructure infra"))
+	assert.Equal(t, []string{"docs", "infra"}, MatchedTerms("infra docs", "Deploy the infra", "Review docs"))
+	assert.Empty(t, MatchedTerms("release", "Deploy the infra"))
+}
+
+func TestHighlight(t *testing.T) {
+	assert.Equal(t, "Deploy the <mark>Infra</mark> &amp; <mark>docs</mark>", Highlight("Deploy the Infra & docs", []string{"docs", "infra"}))
+	assert.Equal(t, "&lt;b&gt;", Highlight("<b>", []string{"x"}))
+}
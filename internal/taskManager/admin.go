@@ -0,0 +1,123 @@
+package taskmanager
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/backup"
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrAdminTokenInvalid represents an error when an admin request has no token or an invalid one
+var ErrAdminTokenInvalid = errors.New("missing or invalid admin token")
+
+// adminAuth only lets through the requests authorized by the admin token as a bearer token.
+func adminAuth(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			given, found := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if !found || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				return problem.Respond(c, http.StatusUnauthorized, ErrAdminTokenInvalid)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// Backup godoc
+// @Summary      Back up every task.
+// @Description  Download an archive of every task, saved view and webhook subscription, with the schema version
+// @Description  of the archive and its checksum. The webhooks are archived with their secrets.
+// @Description  The admin endpoints are only served when the server has an admin token.
+// @Tags         Admin
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Success      200  {object}  backup.Archive  "backup archive"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get tasks"
+// @Router       /admin/backup [get]
+// Backup downloads an archive of every task.
+func (h *Handler) Backup(c echo.Context) error {
+	archive, err := backup.New(c.Request().Context(), h.repo, time.Now(), backup.WithViews(h.views), backup.WithWebhooks(h.webhooks))
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	filename := fmt.Sprintf("tasks-backup-%s.json", archive.CreatedAt.Format("20060102T150405Z"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	return c.JSON(http.StatusOK, archive)
+}
+
+// Restore godoc
+// @Summary      Restore the tasks of a backup.
+// @Description  Restore the tasks, views and webhooks of a backup archive with their ids. The archive must have
+// @Description  a schema version up to the one of the server and match its checksum. The stores must be empty,
+// @Description  unless replace is set to delete their tasks, views and webhooks first. The archive is checked
+// @Description  before anything is deleted, and the deleted contents are put back when the archive cannot be
+// @Description  restored. The replace is not atomic, the clients must not write while it runs.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 replace  query  bool  false  "delete the current tasks, views and webhooks before restoring"	default(false)
+// @Param 		 archive  body  backup.Archive  true  "backup archive"
+// @Success      200  {object}  models.RestoreResponse  "restored archive"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid archive, invalid task, checksum mismatch or invalid replace"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      409  {object}  models.ErrorResponse  "Task, view or webhook store not empty"
+// @Failure      422  {object}  models.ErrorResponse  "Incompatible schema version"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to restore the tasks"
+// @Router       /admin/restore [post]
+// Restore restores the tasks of a backup archive.
+func (h *Handler) Restore(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	replace := false
+	if c.QueryParams().Has("replace") {
+		value, err := strconv.ParseBool(c.QueryParam("replace"))
+		if err != nil {
+			return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: "replace", Err: err})
+		}
+		replace = value
+	}
+
+	archive, err := backup.Read(c.Request().Body)
+	if err != nil {
+		if errors.Is(err, backup.ErrIncompatibleVersion) {
+			return problem.Respond(c, http.StatusUnprocessableEntity, err)
+		}
+
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if err := archive.Restore(ctx, h.repo, replace, backup.WithViews(h.views), backup.WithWebhooks(h.webhooks)); err != nil {
+		if errors.Is(err, backup.ErrInvalidArchive) {
+			return problem.Respond(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, backup.ErrStoreNotEmpty) {
+			return problem.Respond(c, http.StatusConflict, err)
+		}
+
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &models.RestoreResponse{
+		Version:   archive.Version,
+		CreatedAt: archive.CreatedAt,
+		Tasks:     archive.Count,
+		Views:     len(archive.Views),
+		Webhooks:  len(archive.Webhooks),
+	})
+}
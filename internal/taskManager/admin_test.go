@@ -0,0 +1,242 @@
+package taskmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/backup"
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_BackupRestore(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	views := repository.NewViewRepository()
+	view, err := views.CreateView(context.Background(), models.View{Owner: "alice", Name: "Open tasks", Query: "open"})
+	assert.NoError(t, err)
+	webhooks := repository.NewWebhookRepository()
+	_, err = webhooks.CreateWebhook(context.Background(), models.Webhook{URL: "https://ci.example.com/hooks", Secret: "s3cr3t"})
+	assert.NoError(t, err)
+	e := NewRouter(mockTM, WithAdminToken("secret"), WithViewManager(views), WithWebhookManager(webhooks))
+
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2", Status: 1},
+	}
+	const emptyChecksum = "sha256:4f53cda18c2baa0c0354bb5f9a3ecbe5ed12ab4d8e11ba873c2f11161202b945"
+
+	// archive is the backup of the tasks, set by the backup step for the restore steps
+	var archive []byte
+
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		method             string
+		target             string
+		token              string
+		body               func() string
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name:               "backup without token",
+			method:             http.MethodGet,
+			target:             "/admin/backup",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnauthorized},
+		},
+		{
+			name:               "backup with an invalid token",
+			method:             http.MethodGet,
+			target:             "/admin/backup",
+			token:              "guess",
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnauthorized},
+		},
+		{
+			name: "backup",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(gomock.Any()).Return(tasks, nil)
+			},
+			method:             http.MethodGet,
+			target:             "/admin/backup",
+			token:              "secret",
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "backup failed",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(gomock.Any()).Return(nil, errors.New("storage error"))
+			},
+			method:             http.MethodGet,
+			target:             "/admin/backup",
+			token:              "secret",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+		},
+		{
+			name: "restore into a store with tasks",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(gomock.Any()).Return(tasks[:1], nil)
+			},
+			method:             http.MethodPost,
+			target:             "/admin/restore",
+			token:              "secret",
+			body:               func() string { return string(archive) },
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusConflict},
+		},
+		{
+			name: "restore replacing the tasks",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(gomock.Any()).Return(tasks[:1], nil)
+				mockTM.EXPECT().BatchDeleteTasks(gomock.Any(), []string{tasks[0].ID}).
+					Return([]models.BatchResult{{ID: tasks[0].ID, Outcome: models.BatchOutcomeDeleted}}, nil)
+				mockTM.EXPECT().RestoreTasks(gomock.Any(), tasks).Return(nil)
+			},
+			method:             http.MethodPost,
+			target:             "/admin/restore?replace=true",
+			token:              "secret",
+			body:               func() string { return string(archive) },
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.RestoreResponse{Version: backup.SchemaVersion, Tasks: 2, Views: 1, Webhooks: 1},
+		},
+		{
+			name:               "restore an archive of another schema version",
+			method:             http.MethodPost,
+			target:             "/admin/restore",
+			token:              "secret",
+			body:               func() string { return `{"version":99,"count":0,"checksum":"` + emptyChecksum + `","tasks":[]}` },
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnprocessableEntity},
+		},
+		{
+			name:               "restore a corrupted archive",
+			method:             http.MethodPost,
+			target:             "/admin/restore",
+			token:              "secret",
+			body:               func() string { return strings.Replace(string(archive), "Task 1", "Task 9", 1) },
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest, Message: backup.ErrChecksumMismatch.Error()},
+		},
+		{
+			name:               "restore with an invalid replace",
+			method:             http.MethodPost,
+			target:             "/admin/restore?replace=maybe",
+			token:              "secret",
+			body:               func() string { return string(archive) },
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			body := ""
+			if tt.body != nil {
+				body = tt.body()
+			}
+
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Result().StatusCode)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, expected.Code, resp.Code)
+				if expected.Message != "" {
+					assert.Equal(t, expected.Message, resp.Message)
+				}
+			case models.RestoreResponse:
+				var resp models.RestoreResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, expected.Version, resp.Version)
+				assert.Equal(t, expected.Tasks, resp.Tasks)
+				assert.Equal(t, expected.Views, resp.Views)
+				assert.Equal(t, expected.Webhooks, resp.Webhooks)
+
+				// the views and the webhooks are replaced as well
+				got, err := views.ListViews(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, []models.View{view}, got)
+			case nil:
+				// the backup is restored by the next steps
+				archive = rec.Body.Bytes()
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), `filename="tasks-backup-`)
+
+				read, err := backup.Read(strings.NewReader(string(archive)))
+				assert.NoError(t, err)
+				assert.Equal(t, tasks, read.Tasks)
+				assert.Equal(t, []models.View{view}, read.Views)
+				assert.Len(t, read.Webhooks, 1)
+			}
+		})
+	}
+}
+
+func TestNewRouter_AdminWithoutToken(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	e := NewRouter(mockTM)
+
+	for _, target := range []string{"/admin/backup", "/webhooks"} {
+		t.Run(target, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, target, nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer ")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusNotFound, rec.Result().StatusCode)
+		})
+	}
+}
+
+func TestNewRouter_WebhooksAdminToken(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	e := NewRouter(mockTM, WithAdminToken("secret"))
+
+	tests := []struct {
+		name               string
+		method             string
+		target             string
+		token              string
+		expectedStatusCode int
+	}{
+		{name: "list without token", method: http.MethodGet, target: "/webhooks", expectedStatusCode: http.StatusUnauthorized},
+		{name: "create with an invalid token", method: http.MethodPost, target: "/webhooks", token: "guess", expectedStatusCode: http.StatusUnauthorized},
+		{name: "deliveries without token", method: http.MethodGet, target: "/webhooks/cv2k1ts2hf8ng030mvc0/deliveries", expectedStatusCode: http.StatusUnauthorized},
+		{name: "list", method: http.MethodGet, target: "/webhooks", token: "secret", expectedStatusCode: http.StatusOK},
+		{name: "get a missing webhook", method: http.MethodGet, target: "/webhooks/cv2k1ts2hf8ng030mvc0", token: "secret", expectedStatusCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.token != "" {
+				req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Result().StatusCode)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package taskmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMETextEventStream is the media type of a Server-Sent Events stream
+	MIMETextEventStream = "text/event-stream"
+	// HeaderLastEventID is the request header sent by SSE clients when they reconnect
+	HeaderLastEventID = "Last-Event-ID"
+	// eventReset tells the client that events were missed and that it has to fetch the tasks again
+	eventReset = "reset"
+)
+
+// heartbeatInterval is the interval of the comments keeping idle streams open through proxies
+var heartbeatInterval = 15 * time.Second
+
+// StreamTaskEvents godoc
+// @Summary      Stream the changes of the tasks.
+// @Description  Stream task.created, task.updated, task.completed and task.deleted events as Server-Sent Events.
+// @Description  Reconnecting clients send the Last-Event-ID header to resume from the replay buffer;
+// @Description  a reset event is sent when events were missed and the tasks have to be fetched again.
+// @Tags         Tasks
+// @Produce      text/event-stream
+// @Produce      application/problem+json
+// @Param 		 Last-Event-ID  header  string  false  "id of the last event received"	example("42")
+// @Success      200  {object}  models.TaskEvent  "stream of task events"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid Last-Event-ID"
+// @Router       /tasks/events [get]
+// StreamTaskEvents streams the changes of the tasks as Server-Sent Events.
+func (h *Handler) StreamTaskEvents(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var lastEventID uint64
+	if value := c.Request().Header.Get(HeaderLastEventID); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: HeaderLastEventID, Err: err})
+		}
+		lastEventID = id
+	}
+
+	sub, replay, complete := h.events.Subscribe(lastEventID)
+	defer sub.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, MIMETextEventStream)
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+
+	if !complete {
+		if _, err := fmt.Fprintf(res, "event: %s\ndata: {}\n\n", eventReset); err != nil {
+			return nil
+		}
+	}
+
+	for _, event := range replay {
+		if err := writeEvent(res, event); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				// dropped for being too slow, the client reconnects with its Last-Event-ID
+				return nil
+			}
+
+			if err := writeEvent(res, event); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// writeEvent writes a task event in the Server-Sent Events format.
+func writeEvent(res *echo.Response, event models.TaskEvent) error {
+	data, err := json.Marshal(&event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
@@ -0,0 +1,133 @@
+package taskmanager
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_StreamTaskEvents(t *testing.T) {
+	bus := events.NewBus(2)
+	handler := &Handler{events: bus}
+
+	e := echo.New()
+	e.GET("/tasks/events", handler.StreamTaskEvents)
+
+	task := models.Task{ID: "task1", Name: "Task 1", Status: 0}
+	published := []models.TaskEvent{
+		bus.Publish(models.TaskCreated, task),
+		bus.Publish(models.TaskUpdated, task),
+		bus.Publish(models.TaskDeleted, task),
+	}
+
+	formatEvent := func(event models.TaskEvent) string {
+		data, err := json.Marshal(&event)
+		assert.NoError(t, err)
+
+		return fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	}
+
+	tests := []struct {
+		name               string
+		lastEventID        string
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "stream without Last-Event-ID",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "",
+		},
+		{
+			name:               "resume from the replay buffer",
+			lastEventID:        "2",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       formatEvent(published[2]),
+		},
+		{
+			name:               "resume from the oldest buffered event",
+			lastEventID:        "1",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       formatEvent(published[1]) + formatEvent(published[2]),
+		},
+		{
+			name:               "resume from an unknown event",
+			lastEventID:        "10",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       "event: reset\ndata: {}\n\n",
+		},
+		{
+			name:               "invalid Last-Event-ID",
+			lastEventID:        "abc",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			req := httptest.NewRequest(http.MethodGet, "/tasks/events", nil).WithContext(ctx)
+			if tt.lastEventID != "" {
+				req.Header.Set(HeaderLastEventID, tt.lastEventID)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, MIMETextEventStream, rec.Header().Get(echo.HeaderContentType))
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_StreamTaskEvents_Live(t *testing.T) {
+	bus := events.NewBus(events.DefaultBufferSize)
+	handler := &Handler{events: bus}
+
+	e := echo.New()
+	e.GET("/tasks/events", handler.StreamTaskEvents)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/tasks/events")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// the headers are flushed once subscribed, so the event is not missed
+	task := models.Task{ID: "task1", Name: "Task 1", Status: 1}
+	bus.Publish(models.TaskUpdated, task)
+
+	reader := bufio.NewReader(res.Body)
+	lines := make([]string, 0, 3)
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+
+	assert.Equal(t, "id: 1", lines[0])
+	assert.Equal(t, "event: task.updated", lines[1])
+
+	var event models.TaskEvent
+	assert.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[2], "data: ")), &event))
+	assert.Equal(t, task, event.Task)
+	assert.Equal(t, models.TaskUpdated, event.Type)
+}
@@ -1,32 +1,188 @@
 package taskmanager
 
 import (
+	"time"
+
 	_ "github.com/brionac626/taskManager/docs" // import Swagger documentation for this package.
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/idempotency"
+	"github.com/brionac626/taskManager/internal/problem"
 	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/internal/undo"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
-// Handler handles tasks using the given repository.
+// Handler handles tasks and saved views using the given repositories.
 type Handler struct {
-	repo repository.TaskManager
+	repo     repository.TaskManager
+	views    repository.ViewManager
+	webhooks repository.WebhookManager
+	events   *events.Bus
+	undo     *undo.History
+}
+
+// routerOptions represents the optional settings of the router.
+type routerOptions struct {
+	idempotencyTTL time.Duration
+	idempotency    idempotency.Keeper
+	viewManager    repository.ViewManager
+	webhookManager repository.WebhookManager
+	eventBus       *events.Bus
+	undoLimit      int
+	undoStacks     undo.Stacks
+	adminToken     string
+}
+
+// RouterOption configures the router created by NewRouter.
+type RouterOption func(*routerOptions)
+
+// WithIdempotencyTTL sets how long the first response of an Idempotency-Key is kept for replaying.
+func WithIdempotencyTTL(ttl time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.idempotencyTTL = ttl
+	}
+}
+
+// WithIdempotencyStore sets the store of the responses of the Idempotency-Keys, such as a store shared by the replicas
+// of the server. An in-memory store keeping the responses for the idempotency TTL is used by default.
+func WithIdempotencyStore(store idempotency.Keeper) RouterOption {
+	return func(o *routerOptions) {
+		o.idempotency = store
+	}
+}
+
+// WithViewManager sets the repository of the saved views, an in-memory repository is used by default.
+func WithViewManager(viewManager repository.ViewManager) RouterOption {
+	return func(o *routerOptions) {
+		o.viewManager = viewManager
+	}
+}
+
+// WithWebhookManager sets the repository of the webhooks, an in-memory repository is used by default.
+// It should be the repository of the webhook dispatcher, otherwise no event is delivered.
+func WithWebhookManager(webhookManager repository.WebhookManager) RouterOption {
+	return func(o *routerOptions) {
+		o.webhookManager = webhookManager
+	}
+}
+
+// WithEventBus sets the event bus the task events are streamed from.
+// It should be the publisher of the task manager, otherwise no event is streamed.
+func WithEventBus(bus *events.Bus) RouterOption {
+	return func(o *routerOptions) {
+		o.eventBus = bus
+	}
+}
+
+// WithUndoLimit sets the number of task operations kept for undoing per user.
+func WithUndoLimit(limit int) RouterOption {
+	return func(o *routerOptions) {
+		o.undoLimit = limit
+	}
+}
+
+// WithUndoStacks sets the stacks of the task operations of the users, such as stacks shared by the replicas
+// of the server. The stacks are kept in the memory by default.
+func WithUndoStacks(stacks undo.Stacks) RouterOption {
+	return func(o *routerOptions) {
+		o.undoStacks = stacks
+	}
+}
+
+// WithAdminToken serves the admin endpoints, such as backups and webhooks, to the requests with the token as bearer token.
+// The admin endpoints are not served without token.
+func WithAdminToken(token string) RouterOption {
+	return func(o *routerOptions) {
+		o.adminToken = token
+	}
 }
 
 // NewRouter creates a new Echo router with task manager integration.
-func NewRouter(taskManager repository.TaskManager) *echo.Echo {
-	handler := &Handler{repo: taskManager}
+func NewRouter(taskManager repository.TaskManager, options ...RouterOption) *echo.Echo {
+	opts := routerOptions{idempotencyTTL: idempotency.DefaultTTL, undoLimit: undo.DefaultLimit}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if opts.viewManager == nil {
+		opts.viewManager = repository.NewViewRepository()
+	}
+
+	if opts.webhookManager == nil {
+		opts.webhookManager = repository.NewWebhookRepository()
+	}
+
+	if opts.eventBus == nil {
+		opts.eventBus = events.NewBus(events.DefaultBufferSize)
+	}
+
+	var historyOptions []undo.Option
+	if opts.undoStacks != nil {
+		historyOptions = append(historyOptions, undo.WithStacks(opts.undoStacks))
+	}
+
+	history := undo.NewHistory(taskManager, opts.undoLimit, historyOptions...)
+	handler := &Handler{
+		repo:     undo.Track(history),
+		views:    opts.viewManager,
+		webhooks: opts.webhookManager,
+		events:   opts.eventBus,
+		undo:     history,
+	}
+	if opts.idempotency == nil {
+		opts.idempotency = idempotency.NewStore(opts.idempotencyTTL)
+	}
 
 	e := echo.New()
 	e.HideBanner = true
 	e.Debug = true
+	e.HTTPErrorHandler = problem.HTTPErrorHandler(e.DefaultHTTPErrorHandler)
+	e.Use(trackUser)
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	e.GET("/tasks", handler.GetTasks)
-	e.POST("/tasks", handler.CreateTasks)
+	e.GET("/tasks/search", handler.SearchTasks)
+	e.GET("/tasks/events", handler.StreamTaskEvents)
+	e.GET("/tasks/export", handler.ExportTasks)
+	e.GET("/tasks.ics", handler.ExportCalendar)
+	e.POST("/tasks/import", handler.ImportTasks)
+	e.POST("/tasks", handler.CreateTasks, idempotency.Middleware(opts.idempotency))
 	e.PUT("/tasks/:id", handler.UpdateTask)
+	e.PATCH("/tasks/:id", handler.PatchTask)
 	e.DELETE("/tasks/:id", handler.DeleteTask)
+	e.PATCH("/tasks", handler.BatchUpdateTasks)
+	e.DELETE("/tasks", handler.BatchDeleteTasks)
+	e.GET("/sync", handler.SyncTasks)
+	e.POST("/undo", handler.Undo)
+	e.POST("/redo", handler.Redo)
+
+	e.GET("/views", handler.GetViews)
+	e.POST("/views", handler.CreateView)
+	e.GET("/views/:id", handler.GetView)
+	e.PUT("/views/:id", handler.UpdateView)
+	e.DELETE("/views/:id", handler.DeleteView)
+	e.GET("/views/:id/tasks", handler.GetViewTasks)
+
+	e.GET("/ws", handler.Socket)
+
+	if opts.adminToken != "" {
+		auth := adminAuth(opts.adminToken)
+		admin := e.Group("/admin", auth)
+		admin.GET("/backup", handler.Backup)
+		admin.POST("/restore", handler.Restore)
+
+		// the webhooks make the server send requests to the URLs of their subscribers
+		webhooks := e.Group("/webhooks", auth)
+		webhooks.GET("", handler.GetWebhooks)
+		webhooks.POST("", handler.CreateWebhook)
+		webhooks.GET("/:id", handler.GetWebhook)
+		webhooks.PUT("/:id", handler.UpdateWebhook)
+		webhooks.DELETE("/:id", handler.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", handler.GetWebhookDeliveries)
+	}
 
 	return e
 }
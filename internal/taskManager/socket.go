@@ -0,0 +1,262 @@
+package taskmanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// channelTasks receives the events of every task
+	channelTasks = "tasks"
+	// channelTaskPrefix prefixes the channel receiving the events of a single task
+	channelTaskPrefix = "task:"
+	// channelViewPrefix prefixes the channel receiving the events of the tasks matching a saved view
+	channelViewPrefix = "view:"
+
+	// socketWriteTimeout is the time allowed to write a message to the client
+	socketWriteTimeout = 10 * time.Second
+)
+
+// socketPongWait is the time allowed to read the next pong from the client, pings are sent before it elapses
+var socketPongWait = 60 * time.Second
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+// Socket godoc
+// @Summary      Open the real-time collaboration socket.
+// @Description  Upgrade to a WebSocket exchanging models.SocketRequest and models.SocketMessage JSON messages.
+// @Description  Clients subscribe to the tasks, task:<id> and view:<id> channels to receive their task events,
+// @Description  and send create, update and delete messages validated like the HTTP endpoints.
+// @Description  Every client message is replied with an ack or an error message carrying the same id.
+// @Tags         Tasks
+// @Success      101  {object}  models.SocketMessage  "switching to the WebSocket protocol"
+// @Failure      400  {object}  models.ErrorResponse  "Not a WebSocket handshake"
+// @Router       /ws [get]
+// Socket serves the real-time collaboration socket.
+func (h *Handler) Socket(c echo.Context) error {
+	conn, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		// the upgrader has already replied with the handshake error
+		return nil
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+
+	sub, _, _ := h.events.Subscribe(0)
+	defer sub.Close()
+
+	s := &socketSession{handler: h, conn: conn, logger: c.Logger(), channels: make(map[string]func(models.Task) bool)}
+
+	go s.forwardEvents(ctx, sub.Events())
+
+	conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(socketPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+
+		var req models.SocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			s.replyError(req.ID, http.StatusBadRequest, err)
+			continue
+		}
+
+		if code, err := s.handle(ctx, req); err != nil {
+			s.replyError(req.ID, code, err)
+			continue
+		}
+
+		if err := s.write(models.SocketMessage{ID: req.ID, Type: models.SocketAck}); err != nil {
+			return nil
+		}
+	}
+}
+
+// socketSession holds the subscriptions of a collaboration socket and serializes its writes.
+type socketSession struct {
+	handler *Handler
+	conn    *websocket.Conn
+	logger  echo.Logger
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	channels map[string]func(models.Task) bool
+}
+
+// handle dispatches a client message, it returns the status code matching the error.
+func (s *socketSession) handle(ctx context.Context, req models.SocketRequest) (int, error) {
+	switch req.Type {
+	case models.SocketSubscribe:
+		match, code, err := s.channelMatcher(ctx, req.Channel)
+		if err != nil {
+			return code, err
+		}
+
+		s.mu.Lock()
+		s.channels[req.Channel] = match
+		s.mu.Unlock()
+
+		return http.StatusOK, nil
+	case models.SocketUnsubscribe:
+		s.mu.Lock()
+		delete(s.channels, req.Channel)
+		s.mu.Unlock()
+
+		return http.StatusOK, nil
+	case models.SocketCreateTasks:
+		return s.handler.createTasks(ctx, models.CreateNewTasksRequest{Tasks: req.Tasks})
+	case models.SocketUpdateTask:
+		if req.TaskID == "" {
+			return http.StatusBadRequest, &models.FieldError{Field: "taskId", Err: models.ErrTaskIDEmpty}
+		}
+
+		return s.handler.updateTask(ctx, req.TaskID, req.UpdateTaskRequest)
+	case models.SocketDeleteTask:
+		if req.TaskID == "" {
+			return http.StatusBadRequest, &models.FieldError{Field: "taskId", Err: models.ErrTaskIDEmpty}
+		}
+
+		if err := s.handler.repo.DeleteTask(ctx, req.TaskID); err != nil {
+			return taskErrorCode(err), err
+		}
+
+		return http.StatusOK, nil
+	}
+
+	return http.StatusBadRequest, &models.FieldError{Field: "type", Err: models.ErrUnknownMessageType}
+}
+
+// channelMatcher returns the function selecting the tasks whose events are sent to the channel.
+func (s *socketSession) channelMatcher(ctx context.Context, channel string) (func(models.Task) bool, int, error) {
+	switch {
+	case channel == channelTasks:
+		return func(models.Task) bool { return true }, http.StatusOK, nil
+	case strings.HasPrefix(channel, channelTaskPrefix) && len(channel) > len(channelTaskPrefix):
+		taskID := strings.TrimPrefix(channel, channelTaskPrefix)
+
+		return func(task models.Task) bool { return task.ID == taskID }, http.StatusOK, nil
+	case strings.HasPrefix(channel, channelViewPrefix) && len(channel) > len(channelViewPrefix):
+		view, err := s.handler.views.GetView(ctx, strings.TrimPrefix(channel, channelViewPrefix))
+		if err != nil {
+			return nil, viewErrorCode(err), err
+		}
+
+		if view.Query == "" {
+			return func(models.Task) bool { return true }, http.StatusOK, nil
+		}
+
+		q, err := query.Parse(view.Query)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		return q.Match, http.StatusOK, nil
+	}
+
+	return nil, http.StatusBadRequest, &models.FieldError{Field: "channel", Err: models.ErrInvalidChannel}
+}
+
+// forwardEvents sends the task events to the subscribed channels and keeps the connection alive with pings.
+// The connection is closed when the events cannot be sent anymore, which stops reading the client messages.
+func (s *socketSession) forwardEvents(ctx context.Context, events <-chan models.TaskEvent) {
+	defer s.conn.Close()
+
+	ping := time.NewTicker(socketPongWait * 9 / 10)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ping.C:
+			s.writeMu.Lock()
+			err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteTimeout))
+			s.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				// dropped for being too slow, the client reconnects and fetches the tasks again
+				s.writeMu.Lock()
+				s.conn.WriteControl(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too slow to receive the events"),
+					time.Now().Add(socketWriteTimeout),
+				)
+				s.writeMu.Unlock()
+
+				return
+			}
+
+			for _, channel := range s.matchingChannels(event.Task) {
+				if err := s.write(models.SocketMessage{Type: models.SocketEvent, Channel: channel, Event: &event}); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// matchingChannels returns the subscribed channels the task events are sent to.
+func (s *socketSession) matchingChannels(task models.Task) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var channels []string
+	for channel, match := range s.channels {
+		if match(task) {
+			channels = append(channels, channel)
+		}
+	}
+
+	return channels
+}
+
+// replyError sends the error of a client message, the errors of the server errors are logged instead.
+func (s *socketSession) replyError(id string, code int, err error) {
+	if code >= http.StatusInternalServerError {
+		s.logger.Error(err)
+	}
+
+	s.write(models.SocketMessage{
+		ID:   id,
+		Type: models.SocketError,
+		Error: &models.ErrorResponse{
+			Code:    code,
+			Message: problem.Message(code, err),
+		},
+	})
+}
+
+// write sends a message to the client.
+func (s *socketSession) write(msg models.SocketMessage) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+
+	return s.conn.WriteJSON(&msg)
+}
@@ -0,0 +1,203 @@
+package taskmanager
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Socket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTM := mocks.NewMockTaskManager(ctrl)
+	mockVM := mocks.NewMockViewManager(ctrl)
+	bus := events.NewBus(events.DefaultBufferSize)
+	handler := &Handler{repo: mockTM, views: mockVM, events: bus}
+
+	e := echo.New()
+	e.GET("/ws", handler.Socket)
+
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	name := "Updated Task"
+	tests := []struct {
+		name             string
+		mockSetup        func()
+		request          models.SocketRequest
+		expectedResponse models.SocketMessage
+	}{
+		{
+			name:             "subscribe to every task",
+			mockSetup:        func() {},
+			request:          models.SocketRequest{ID: "1", Type: models.SocketSubscribe, Channel: "tasks"},
+			expectedResponse: models.SocketMessage{ID: "1", Type: models.SocketAck},
+		},
+		{
+			name:             "subscribe to a task",
+			mockSetup:        func() {},
+			request:          models.SocketRequest{ID: "2", Type: models.SocketSubscribe, Channel: "task:task2"},
+			expectedResponse: models.SocketMessage{ID: "2", Type: models.SocketAck},
+		},
+		{
+			name: "subscribe to a view",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(gomock.Any(), "v1").Return(models.View{ID: "v1", Query: "open"}, nil)
+			},
+			request:          models.SocketRequest{ID: "3", Type: models.SocketSubscribe, Channel: "view:v1"},
+			expectedResponse: models.SocketMessage{ID: "3", Type: models.SocketAck},
+		},
+		{
+			name: "subscribe to an unknown view",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(gomock.Any(), "unknown").Return(models.View{}, repository.ErrViewNotFound)
+			},
+			request: models.SocketRequest{ID: "4", Type: models.SocketSubscribe, Channel: "view:unknown"},
+			expectedResponse: models.SocketMessage{ID: "4", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusNotFound, Message: repository.ErrViewNotFound.Error(),
+			}},
+		},
+		{
+			name:      "subscribe to an invalid channel",
+			mockSetup: func() {},
+			request:   models.SocketRequest{ID: "5", Type: models.SocketSubscribe, Channel: "task:"},
+			expectedResponse: models.SocketMessage{ID: "5", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusBadRequest, Message: models.ErrInvalidChannel.Error(),
+			}},
+		},
+		{
+			name: "create tasks",
+			mockSetup: func() {
+				mockTM.EXPECT().CreateTasks(gomock.Any(), []models.Task{{Name: "Task 1", Status: 0}}).Return(nil)
+			},
+			request:          models.SocketRequest{ID: "6", Type: models.SocketCreateTasks, Tasks: []models.NewTask{{Name: "Task 1", Status: 0}}},
+			expectedResponse: models.SocketMessage{ID: "6", Type: models.SocketAck},
+		},
+		{
+			name:      "create invalid tasks",
+			mockSetup: func() {},
+			request:   models.SocketRequest{ID: "7", Type: models.SocketCreateTasks, Tasks: []models.NewTask{{Name: "", Status: 0}}},
+			expectedResponse: models.SocketMessage{ID: "7", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusBadRequest, Message: models.ErrTaskNameEmpty.Error(),
+			}},
+		},
+		{
+			name: "update a task",
+			mockSetup: func() {
+				mockTM.EXPECT().UpdateTask(gomock.Any(), "task1", &name, nil).Return(nil)
+			},
+			request: models.SocketRequest{
+				ID: "8", Type: models.SocketUpdateTask, TaskID: "task1", UpdateTaskRequest: models.UpdateTaskRequest{Name: &name},
+			},
+			expectedResponse: models.SocketMessage{ID: "8", Type: models.SocketAck},
+		},
+		{
+			name:      "update without task id",
+			mockSetup: func() {},
+			request:   models.SocketRequest{ID: "9", Type: models.SocketUpdateTask, UpdateTaskRequest: models.UpdateTaskRequest{Name: &name}},
+			expectedResponse: models.SocketMessage{ID: "9", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusBadRequest, Message: models.ErrTaskIDEmpty.Error(),
+			}},
+		},
+		{
+			name: "delete a task",
+			mockSetup: func() {
+				mockTM.EXPECT().DeleteTask(gomock.Any(), "task1").Return(nil)
+			},
+			request:          models.SocketRequest{ID: "10", Type: models.SocketDeleteTask, TaskID: "task1"},
+			expectedResponse: models.SocketMessage{ID: "10", Type: models.SocketAck},
+		},
+		{
+			name: "delete a missing task",
+			mockSetup: func() {
+				mockTM.EXPECT().DeleteTask(gomock.Any(), "unknown").Return(repository.ErrTaskNotFound)
+			},
+			request: models.SocketRequest{ID: "11", Type: models.SocketDeleteTask, TaskID: "unknown"},
+			expectedResponse: models.SocketMessage{ID: "11", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusNotFound, Message: repository.ErrTaskNotFound.Error(),
+			}},
+		},
+		{
+			name: "delete a task failed",
+			mockSetup: func() {
+				mockTM.EXPECT().DeleteTask(gomock.Any(), "unknown").Return(errors.New("database is locked"))
+			},
+			request: models.SocketRequest{ID: "12", Type: models.SocketDeleteTask, TaskID: "unknown"},
+			expectedResponse: models.SocketMessage{ID: "12", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError),
+			}},
+		},
+		{
+			name:      "unknown message type",
+			mockSetup: func() {},
+			request:   models.SocketRequest{ID: "13", Type: "rename"},
+			expectedResponse: models.SocketMessage{ID: "13", Type: models.SocketError, Error: &models.ErrorResponse{
+				Code: http.StatusBadRequest, Message: models.ErrUnknownMessageType.Error(),
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			assert.NoError(t, conn.WriteJSON(&tt.request))
+
+			var msg models.SocketMessage
+			assert.NoError(t, conn.ReadJSON(&msg))
+			assert.Equal(t, tt.expectedResponse, msg)
+		})
+	}
+
+	t.Run("invalid message", func(t *testing.T) {
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+
+		var msg models.SocketMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, models.SocketError, msg.Type)
+		assert.Equal(t, http.StatusBadRequest, msg.Error.Code)
+	})
+
+	t.Run("receive the events of the subscribed channels", func(t *testing.T) {
+		openTask := bus.Publish(models.TaskCreated, models.Task{ID: "task1", Name: "Task 1", Status: 0})
+
+		channels := make([]string, 0, 2)
+		for range 2 {
+			var msg models.SocketMessage
+			assert.NoError(t, conn.ReadJSON(&msg))
+			assert.Equal(t, models.SocketEvent, msg.Type)
+			assert.Equal(t, openTask.ID, msg.Event.ID)
+			channels = append(channels, msg.Channel)
+		}
+		sort.Strings(channels)
+		assert.Equal(t, []string{"tasks", "view:v1"}, channels)
+
+		assert.NoError(t, conn.WriteJSON(&models.SocketRequest{ID: "14", Type: models.SocketUnsubscribe, Channel: "tasks"}))
+		var ack models.SocketMessage
+		assert.NoError(t, conn.ReadJSON(&ack))
+		assert.Equal(t, models.SocketMessage{ID: "14", Type: models.SocketAck}, ack)
+
+		doneTask := bus.Publish(models.TaskUpdated, models.Task{ID: "task2", Name: "Task 2", Status: 1})
+
+		var msg models.SocketMessage
+		assert.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, models.SocketEvent, msg.Type)
+		assert.Equal(t, "task:task2", msg.Channel)
+		assert.Equal(t, doneTask.ID, msg.Event.ID)
+		assert.Equal(t, doneTask.Task, msg.Event.Task)
+	})
+}
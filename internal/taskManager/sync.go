@@ -0,0 +1,56 @@
+package taskmanager
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SyncTasks godoc
+// @Summary      Get the changes of the tasks since a sync token.
+// @Description  Get the tasks created or updated and the ids of the tasks deleted since the token of the previous call,
+// @Description  or every task when no token is given. The returned token is sent as since on the next call.
+// @Description  An expired token answers 410, the client then fetches every task again without a token.
+// @Tags         Tasks
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 since  query  string  false  "token returned by the previous call"	example("42")
+// @Success      200  {object}  models.SyncResponse  "changes since the token"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid sync token"
+// @Failure      410  {object}  models.ErrorResponse  "Expired sync token"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get the changes"
+// @Router       /sync [get]
+// SyncTasks retrieves the changes of the tasks since a sync token.
+func (h *Handler) SyncTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var since uint64
+	if token := c.QueryParam("since"); token != "" {
+		seq, err := strconv.ParseUint(token, 10, 64)
+		if err != nil {
+			return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: "since", Err: models.ErrInvalidSyncToken})
+		}
+		since = seq
+	}
+
+	changes, err := h.repo.SyncTasks(ctx, since)
+	if err != nil {
+		if errors.Is(err, repository.ErrSyncTokenExpired) {
+			return problem.Respond(c, http.StatusGone, err)
+		}
+
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &models.SyncResponse{
+		Tasks:   changes.Tasks,
+		Deleted: changes.Deleted,
+		Token:   strconv.FormatUint(changes.Seq, 10),
+	})
+}
@@ -0,0 +1,125 @@
+package taskmanager
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_SyncTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.GET("/sync", handler.SyncTasks)
+
+	tasks := []models.Task{
+		{ID: "1", Name: "Task 1", Status: 0},
+		{ID: "2", Name: "Task 2", Status: 1},
+	}
+
+	type args struct {
+		req *http.Request
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name: "sync every task",
+			mockSetup: func() {
+				mockTM.EXPECT().SyncTasks(context.Background(), uint64(0)).Return(models.TaskChanges{Tasks: tasks, Deleted: []string{}, Seq: 5}, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/sync", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.SyncResponse{Tasks: tasks, Deleted: []string{}, Token: "5"},
+		},
+		{
+			name: "sync the changes since a token",
+			mockSetup: func() {
+				mockTM.EXPECT().SyncTasks(context.Background(), uint64(5)).Return(models.TaskChanges{Tasks: tasks[1:], Deleted: []string{"3"}, Seq: 7}, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/sync?since=5", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.SyncResponse{Tasks: tasks[1:], Deleted: []string{"3"}, Token: "7"},
+		},
+		{
+			name: "sync with an invalid token",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/sync?since=abc", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest, Message: models.ErrInvalidSyncToken.Error()},
+		},
+		{
+			name: "sync with an expired token",
+			mockSetup: func() {
+				mockTM.EXPECT().SyncTasks(context.Background(), uint64(99)).Return(models.TaskChanges{}, repository.ErrSyncTokenExpired)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/sync?since=99", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusGone,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusGone, Message: repository.ErrSyncTokenExpired.Error()},
+		},
+		{
+			name: "sync with internal error",
+			mockSetup: func() {
+				mockTM.EXPECT().SyncTasks(context.Background(), uint64(0)).Return(models.TaskChanges{}, repository.ErrTaskType)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/sync", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			e.ServeHTTP(tt.args.rec, tt.args.req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			case models.SyncResponse:
+				var resp models.SyncResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			}
+		})
+	}
+}
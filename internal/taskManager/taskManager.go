@@ -1,9 +1,17 @@
 package taskmanager
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"time"
 
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
 	"github.com/brionac626/taskManager/models"
 
 	"github.com/labstack/echo/v4"
@@ -11,28 +19,114 @@ import (
 
 // GetTasks godoc
 // @Summary      Get all tasks from the local storage.
-// @Description  Get all tasks.
+// @Description  Get all tasks, or the tasks matching a query such as: open AND (name:deploy OR name:release).
+// @Description  Predicates compare the id, name and status fields and can be combined with AND, OR, NOT and parentheses.
+// @Description  With as_of, the tasks are rebuilt from the recorded history as they were at that time.
 // @Tags         Tasks
 // @Produce      json
+// @Produce      application/problem+json
+// @Param 		 q  query  string  false  "task query"	example("open AND name:deploy")
+// @Param 		 as_of  query  string  false  "RFC 3339 timestamp to get the tasks at"	example("2026-10-12T09:00:00Z")
 // @Success      200  {array}  []models.Task  "tasks retrieved successfully"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task query or timestamp"
+// @Failure      410  {object}  models.ErrorResponse  "Timestamp before the retained history"
 // @Failure      500  {object}  models.ErrorResponse  "Filed to get tasks"
 // @Router       /tasks [get]
-// GetTasks retrieves all tasks.
+// GetTasks retrieves all tasks, or the tasks matching the query, now or at a point in time.
 func (h *Handler) GetTasks(c echo.Context) error {
+	tasks, code, err := h.findTasks(c)
+	if err != nil {
+		return problem.Respond(c, code, err)
+	}
+
+	return c.JSON(http.StatusOK, &tasks)
+}
+
+// findTasks returns the tasks matching the q query parameter, as of the time of the as_of query parameter
+// when given. It returns the status code matching the error.
+func (h *Handler) findTasks(c echo.Context) ([]models.Task, int, error) {
 	ctx := c.Request().Context()
 
+	var q *query.Query
+	if c.QueryParams().Has("q") {
+		parsed, err := query.Parse(c.QueryParam("q"))
+		if err != nil {
+			return nil, http.StatusBadRequest, &models.FieldError{Field: "q", Err: err}
+		}
+		q = parsed
+	}
+
+	if c.QueryParams().Has("as_of") {
+		at, err := time.Parse(time.RFC3339, c.QueryParam("as_of"))
+		if err != nil {
+			return nil, http.StatusBadRequest, &models.FieldError{Field: "as_of", Err: models.ErrInvalidTimestamp}
+		}
+
+		tasks, err := h.repo.TasksAsOf(ctx, at)
+		if err != nil {
+			if errors.Is(err, repository.ErrHistoryExpired) {
+				return nil, http.StatusGone, err
+			}
+
+			return nil, http.StatusInternalServerError, err
+		}
+
+		if q != nil {
+			tasks = q.Filter(tasks)
+		}
+
+		return tasks, http.StatusOK, nil
+	}
+
+	if q != nil {
+		tasks, err := h.repo.QueryTasks(ctx, q)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+
+		return tasks, http.StatusOK, nil
+	}
+
 	tasks, err := h.repo.GetTasks(ctx)
 	if err != nil {
-		return c.JSON(
-			http.StatusInternalServerError,
-			&models.ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			},
-		)
+		return nil, http.StatusInternalServerError, err
 	}
 
-	return c.JSON(http.StatusOK, &tasks)
+	return tasks, http.StatusOK, nil
+}
+
+// SearchTasks godoc
+// @Summary      Search tasks by the words in their names.
+// @Description  Full-text search over the task names. Every query word matches the words it is a prefix of,
+// @Description  and the results are ordered by relevance with the matched words highlighted.
+// @Tags         Tasks
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 q  query  string  true  "search query"	example("task")
+// @Param 		 limit  query  int  false  "maximum number of results"	default(20)	maximum(100)
+// @Success      200  {array}  models.SearchResult  "matching tasks ordered by relevance"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid query parameters"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to search tasks"
+// @Router       /tasks/search [get]
+// SearchTasks searches tasks by the words in their names.
+func (h *Handler) SearchTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.SearchTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	results, err := h.repo.SearchTasks(ctx, req.Query, req.Limit)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &results)
 }
 
 // CreateTasks godoc
@@ -40,11 +134,15 @@ func (h *Handler) GetTasks(c echo.Context) error {
 // @Description  crate new tasks.
 // @Tags         Tasks
 // @Accept		 json
+// @Produce      application/problem+json
+// @Param 		 Idempotency-Key  header  string  false  "client generated key to safely retry the request"
 // @Param 		 req  body  models.CreateNewTasksRequest  true  "tasks to create"
 // @Success      201  "no content returned when successful"
 // @Failure      400  {object}  models.ErrorResponse  "Invalid request body"
 // @Failure      400  {object}  models.ErrorResponse  "No tasks provided"
 // @Failure      400  {object}  models.ErrorResponse  "Invalid task fields values"
+// @Failure      409  {object}  models.ErrorResponse  "A request with the same idempotency key is in progress"
+// @Failure      422  {object}  models.ErrorResponse  "Idempotency key reused with a different request body"
 // @Failure      500  {object}  models.ErrorResponse  "Failed to create tasks"
 // @Router       /tasks [post]
 // CreateTasks creates new tasks from the client request.
@@ -53,51 +151,33 @@ func (h *Handler) CreateTasks(c echo.Context) error {
 
 	var req models.CreateNewTasksRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(
-			http.StatusBadRequest,
-			&models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: err.Error(),
-			},
-		)
+		return problem.Respond(c, http.StatusBadRequest, err)
 	}
 
-	if len(req.Tasks) == 0 {
-		return c.JSON(
-			http.StatusBadRequest,
-			&models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: "No tasks provided",
-			},
-		)
+	if code, err := h.createTasks(ctx, req); err != nil {
+		return problem.Respond(c, code, err)
+	}
+
+	return c.NoContent(http.StatusCreated)
+}
+
+// createTasks validates the request and creates its tasks, it returns the status code matching the error.
+func (h *Handler) createTasks(ctx context.Context, req models.CreateNewTasksRequest) (int, error) {
+	if err := req.Validate(); err != nil {
+		log.Println("invalid err", err)
+		return http.StatusBadRequest, err
 	}
 
 	newTasks := make([]models.Task, 0)
 	for _, task := range req.Tasks {
-		if err := task.Validate(); err != nil {
-			log.Println("invalid err", err)
-			return c.JSON(
-				http.StatusBadRequest,
-				&models.ErrorResponse{
-					Code:    http.StatusBadRequest,
-					Message: err.Error(),
-				},
-			)
-		}
 		newTasks = append(newTasks, models.Task{Name: task.Name, Status: task.Status})
 	}
 
 	if err := h.repo.CreateTasks(ctx, newTasks); err != nil {
-		return c.JSON(
-			http.StatusInternalServerError,
-			&models.ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			},
-		)
+		return http.StatusInternalServerError, err
 	}
 
-	return c.NoContent(http.StatusCreated)
+	return http.StatusCreated, nil
 }
 
 // UpdateTask godoc
@@ -105,6 +185,7 @@ func (h *Handler) CreateTasks(c echo.Context) error {
 // @Description  Update an existing task fields' values.
 // @Tags         Tasks
 // @Accept		 json
+// @Produce      application/problem+json
 // @Param 		 id  path  string  true  "target task id"	example("9bsv0s2hf8ng030mva9g")	default("9bsv0s2hf8ng030mva9g")
 // @Param 		 req  body  models.UpdateTaskRequest  true  "task fields to update"
 // @Success      200  "no content returned when successful"
@@ -120,46 +201,116 @@ func (h *Handler) UpdateTask(c echo.Context) error {
 	taskID := c.Param("id")
 	var req models.UpdateTaskRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(
-			http.StatusBadRequest,
-			&models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: err.Error(),
-			},
-		)
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if code, err := h.updateTask(ctx, taskID, req); err != nil {
+		return problem.Respond(c, code, err)
 	}
 
+	return c.NoContent(http.StatusOK)
+}
+
+// updateTask validates the request and updates the task, it returns the status code matching the error.
+func (h *Handler) updateTask(ctx context.Context, taskID string, req models.UpdateTaskRequest) (int, error) {
 	if req.IsNoChanges() {
-		return c.NoContent(http.StatusOK)
+		return http.StatusOK, nil
 	}
 
 	if err := req.Validate(); err != nil {
-		return c.JSON(
-			http.StatusBadRequest,
-			&models.ErrorResponse{
-				Code:    http.StatusBadRequest,
-				Message: err.Error(),
-			},
-		)
+		return http.StatusBadRequest, err
 	}
 
 	if err := h.repo.UpdateTask(ctx, taskID, req.Name, req.Status); err != nil {
-		return c.JSON(
-			http.StatusInternalServerError,
-			&models.ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			},
-		)
+		return http.StatusInternalServerError, err
 	}
 
-	return c.NoContent(http.StatusOK)
+	return http.StatusOK, nil
+}
+
+// PatchTask godoc
+// @Summary      Patch an existing task by task id.
+// @Description  Apply a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) document to an existing task.
+// @Description  The patched task is validated against the task model before it is stored.
+// @Tags         Tasks
+// @Accept		 application/merge-patch+json
+// @Accept		 application/json-patch+json
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 id  path  string  true  "target task id"	example("9bsv0s2hf8ng030mva9g")	default("9bsv0s2hf8ng030mva9g")
+// @Param 		 req  body  object  true  "patch document"
+// @Success      200  {object}  models.Task  "the patched task"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid patch document"
+// @Failure      404  {object}  models.ErrorResponse  "Task not found"
+// @Failure      409  {object}  models.ErrorResponse  "Task changed since it was read, patch it again"
+// @Failure      415  {object}  models.ErrorResponse  "Unsupported patch media type"
+// @Failure      422  {object}  models.ErrorResponse  "Patch cannot be applied or the patched task is invalid"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to patch a task"
+// @Router       /tasks/:id [patch]
+// PatchTask applies a patch document to an existing task by task id.
+func (h *Handler) PatchTask(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	taskID := c.Param("id")
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err != nil || (mediaType != models.MIMEApplicationMergePatchJSON && mediaType != models.MIMEApplicationJSONPatchJSON) {
+		return problem.Respond(c, http.StatusUnsupportedMediaType, models.ErrUnsupportedPatchType)
+	}
+
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	task, err := h.repo.GetTask(ctx, taskID)
+	if err != nil {
+		return problem.Respond(c, taskErrorCode(err), err)
+	}
+
+	var patched models.Task
+	if mediaType == models.MIMEApplicationMergePatchJSON {
+		patched, err = task.ApplyMergePatch(patch)
+	} else {
+		patched, err = task.ApplyJSONPatch(patch)
+	}
+	if err != nil {
+		code := http.StatusUnprocessableEntity
+		if errors.Is(err, models.ErrInvalidPatch) {
+			code = http.StatusBadRequest
+		}
+
+		return problem.Respond(c, code, err)
+	}
+
+	if patched == task {
+		return c.JSON(http.StatusOK, &patched)
+	}
+
+	// the task may be changed or deleted since it was read
+	if err := h.repo.CompareAndSwapTask(ctx, task, patched); err != nil {
+		return problem.Respond(c, taskErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &patched)
+}
+
+// taskErrorCode returns the status code of an error returned by the repository while changing a task.
+func taskErrorCode(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrTaskChanged):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
 }
 
 // DeleteTask godoc
 // @Summary      Delete an existing task by task id.
 // @Description  Delete an existing task.
 // @Tags         Tasks
+// @Produce      application/problem+json
 // @Param 		 id  path  string  true  "target task id"	example("9bsv0s2hf8ng030mva9g")	default("9bsv0s2hf8ng030mva9g")
 // @Success      200  "no content returned when successful"
 // @Failure      500  {object}  models.ErrorResponse  "Failed to update a task fields"
@@ -170,14 +321,107 @@ func (h *Handler) DeleteTask(c echo.Context) error {
 
 	taskID := c.Param("id")
 	if err := h.repo.DeleteTask(ctx, taskID); err != nil {
-		return c.JSON(
-			http.StatusInternalServerError,
-			&models.ErrorResponse{
-				Code:    http.StatusInternalServerError,
-				Message: err.Error(),
-			},
-		)
+		return problem.Respond(c, http.StatusInternalServerError, err)
 	}
 
 	return c.NoContent(http.StatusOK)
 }
+
+// BatchUpdateTasks godoc
+// @Summary      Update several tasks at once.
+// @Description  Update the fields' values of the tasks selected by an id list or a filter, and return the outcome for every task.
+// @Tags         Tasks
+// @Accept		 json
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 req  body  models.BatchUpdateTasksRequest  true  "tasks to update and fields to update"
+// @Success      200  {object}  models.BatchResponse  "per-task outcomes"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid request body"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task fields values"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to update tasks"
+// @Router       /tasks [patch]
+// BatchUpdateTasks updates the tasks selected by an id list or a filter.
+func (h *Handler) BatchUpdateTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.BatchUpdateTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	taskIDs, err := h.resolveBatchTarget(ctx, req.BatchTarget)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	results, err := h.repo.BatchUpdateTasks(ctx, taskIDs, req.Name, req.Status)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &models.BatchResponse{Results: results})
+}
+
+// BatchDeleteTasks godoc
+// @Summary      Delete several tasks at once.
+// @Description  Delete the tasks selected by an id list or a filter, and return the outcome for every task.
+// @Tags         Tasks
+// @Accept		 json
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 req  body  models.BatchDeleteTasksRequest  true  "tasks to delete"
+// @Success      200  {object}  models.BatchResponse  "per-task outcomes"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid request body"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to delete tasks"
+// @Router       /tasks [delete]
+// BatchDeleteTasks deletes the tasks selected by an id list or a filter.
+func (h *Handler) BatchDeleteTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var req models.BatchDeleteTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if err := req.Validate(); err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	taskIDs, err := h.resolveBatchTarget(ctx, req.BatchTarget)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	results, err := h.repo.BatchDeleteTasks(ctx, taskIDs)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &models.BatchResponse{Results: results})
+}
+
+// resolveBatchTarget returns the task ids selected by the batch target.
+// An explicit id list is returned as is, while a filter is evaluated against the current tasks.
+func (h *Handler) resolveBatchTarget(ctx context.Context, target models.BatchTarget) ([]string, error) {
+	if target.Filter == nil {
+		return target.IDs, nil
+	}
+
+	tasks, err := h.repo.GetTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]string, 0)
+	for _, task := range tasks {
+		if target.Filter.Match(task) {
+			taskIDs = append(taskIDs, task.ID)
+		}
+	}
+
+	return taskIDs, nil
+}
@@ -9,7 +9,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/brionac626/taskManager/internal/query"
 	"github.com/brionac626/taskManager/internal/repository"
 	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
 	"github.com/brionac626/taskManager/models"
@@ -68,6 +70,93 @@ func TestHandler_GetTasks(t *testing.T) {
 			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
 			wantErr:            false,
 		},
+		{
+			name: "get tasks by query",
+			mockSetup: func() {
+				mockTM.EXPECT().QueryTasks(context.Background(), gomock.Cond(func(q *query.Query) bool {
+					return q.String() == "open AND name:task"
+				})).Return(expectedTasks[:1], nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?q=open+AND+name%3Atask", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   expectedTasks[:1],
+			wantErr:            false,
+		},
+		{
+			name: "get tasks with invalid query",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?q=open+AND+tag%3Ainfra", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "get tasks by query with internal error",
+			mockSetup: func() {
+				mockTM.EXPECT().QueryTasks(context.Background(), gomock.Any()).Return(nil, repository.ErrGetTasksFailed)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?q=open", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+		{
+			name: "get tasks as of a time",
+			mockSetup: func() {
+				mockTM.EXPECT().TasksAsOf(context.Background(), time.Date(2026, time.October, 12, 9, 0, 0, 0, time.UTC)).Return(expectedTasks, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?as_of=2026-10-12T09:00:00Z", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   expectedTasks,
+			wantErr:            false,
+		},
+		{
+			name: "get tasks by query as of a time",
+			mockSetup: func() {
+				mockTM.EXPECT().TasksAsOf(context.Background(), gomock.Any()).Return(expectedTasks, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?q=done&as_of=2026-10-12T11:00:00%2B02:00", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   expectedTasks[1:],
+			wantErr:            false,
+		},
+		{
+			name: "get tasks as of an invalid time",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?as_of=last+monday", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "get tasks as of a time before the retained history",
+			mockSetup: func() {
+				mockTM.EXPECT().TasksAsOf(context.Background(), gomock.Any()).Return(nil, repository.ErrHistoryExpired)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks?as_of=2020-01-01T00:00:00Z", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusGone,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusGone},
+			wantErr:            false,
+		},
 		{
 			name: "get tasks with wrong path",
 			args: args{
@@ -123,6 +212,133 @@ func TestHandler_GetTasks(t *testing.T) {
 	}
 }
 
+func TestHandler_SearchTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.GET("/tasks/search", handler.SearchTasks)
+
+	expectedResults := []models.SearchResult{
+		{Task: models.Task{ID: "1", Name: "Deploy infra", Status: 0}, Score: 1.5, Highlight: "Deploy <mark>infra</mark>"},
+	}
+
+	type args struct {
+		req *http.Request
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+		wantErr            bool
+	}{
+		{
+			name: "search tasks",
+			mockSetup: func() {
+				mockTM.EXPECT().SearchTasks(context.Background(), "infra", models.DefaultSearchLimit).Return(expectedResults, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search?q=infra", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   expectedResults,
+			wantErr:            false,
+		},
+		{
+			name: "search tasks with limit",
+			mockSetup: func() {
+				mockTM.EXPECT().SearchTasks(context.Background(), "infra", 5).Return(expectedResults, nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search?q=infra&limit=5", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   expectedResults,
+			wantErr:            false,
+		},
+		{
+			name: "search tasks without query",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "search tasks with invalid limit",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search?q=infra&limit=1000", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "search tasks with non-numeric limit",
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search?q=infra&limit=ten", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "search tasks with internal error",
+			mockSetup: func() {
+				mockTM.EXPECT().SearchTasks(context.Background(), "infra", models.DefaultSearchLimit).Return(nil, repository.ErrTaskType)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodGet, "/tasks/search?q=infra", nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			e.ServeHTTP(tt.args.rec, tt.args.req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			if tt.wantErr {
+				t.Log(string(body))
+				return
+			}
+
+			if tt.args.rec.Result().StatusCode != http.StatusOK {
+				var resp models.ErrorResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.rec.Result().StatusCode, resp.Code)
+				return
+			}
+
+			var results []models.SearchResult
+			err = json.Unmarshal(body, &results)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResponse.([]models.SearchResult), results)
+		})
+	}
+}
+
 func TestHandler_CreateTasks(t *testing.T) {
 	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
 	handler := &Handler{repo: mockTM}
@@ -598,91 +814,713 @@ func TestHandler_UpdateTask(t *testing.T) {
 	}
 }
 
-func TestHandler_DeleteTask(t *testing.T) {
+func TestHandler_PatchTask(t *testing.T) {
 	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
 	handler := &Handler{repo: mockTM}
 
 	e := echo.New()
-	e.DELETE("/tasks/:id", handler.DeleteTask)
+	e.PATCH("/tasks/:id", handler.PatchTask)
 
 	taskID := "1"
+	task := models.Task{ID: taskID, Name: "Task 1", Status: 0}
+	name := "Updated Task"
+	status := 1
 
 	type args struct {
-		req *http.Request
 		rec *httptest.ResponseRecorder
 	}
 	tests := []struct {
 		name               string
-		mockSetup          func()
+		mockSetup          func() *http.Request
 		args               args
 		expectedStatusCode int
 		expectedResponse   any
 		wantErr            bool
 	}{
 		{
-			name: "delete task",
-			mockSetup: func() {
-				mockTM.EXPECT().DeleteTask(context.Background(), taskID).Return(nil)
+			name: "merge patch task",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+				mockTM.EXPECT().CompareAndSwapTask(context.Background(), task, models.Task{ID: taskID, Name: name, Status: task.Status}).Return(nil)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
 			},
 			args: args{
-				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%s", taskID), nil),
 				rec: httptest.NewRecorder(),
 			},
 			expectedStatusCode: http.StatusOK,
-			expectedResponse:   nil,
+			expectedResponse:   models.Task{ID: taskID, Name: name, Status: task.Status},
 			wantErr:            false,
 		},
 		{
-			name: "delete task not found",
-			mockSetup: func() {
-				mockTM.EXPECT().DeleteTask(context.Background(), taskID).Return(repository.ErrTaskNotFound)
+			name: "json patch task",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+				mockTM.EXPECT().CompareAndSwapTask(context.Background(), task, models.Task{ID: taskID, Name: task.Name, Status: status}).Return(nil)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`[{"op":"test","path":"/status","value":0},{"op":"replace","path":"/status","value":1}]`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationJSONPatchJSON)
+
+				return req
 			},
 			args: args{
-				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%s", taskID), nil),
 				rec: httptest.NewRecorder(),
 			},
-			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.Task{ID: taskID, Name: task.Name, Status: status},
 			wantErr:            false,
 		},
 		{
-			name: "update tasks with wrong path",
+			name: "patch task with no changes",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
 			args: args{
-				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks1234/%s", taskID), nil),
 				rec: httptest.NewRecorder(),
 			},
-			expectedStatusCode: http.StatusNotFound,
-			wantErr:            true,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   task,
+			wantErr:            false,
 		},
 		{
-			name: "update tasks with wrong method",
+			name: "patch task with unsupported media type",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
 			args: args{
-				req: httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), nil),
 				rec: httptest.NewRecorder(),
 			},
-			expectedStatusCode: http.StatusMethodNotAllowed,
-			wantErr:            true,
+			expectedStatusCode: http.StatusUnsupportedMediaType,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnsupportedMediaType},
+			wantErr:            false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.mockSetup != nil {
-				tt.mockSetup()
-			}
+		{
+			name: "patch task with malformed patch document",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
 
-			e.ServeHTTP(tt.args.rec, tt.args.req)
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString("invalid_json"))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationJSONPatchJSON)
 
-			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "patch task with failed test operation",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
 
-			body, err := io.ReadAll(tt.args.rec.Body)
-			assert.NoError(t, err)
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`[{"op":"test","path":"/status","value":1}]`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationJSONPatchJSON)
 
-			if tt.wantErr {
-				t.Log(string(body))
-				return
-			}
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnprocessableEntity},
+			wantErr:            false,
+		},
+		{
+			name: "patch task clearing the name",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
 
-			if tt.args.rec.Result().StatusCode == http.StatusCreated {
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":null}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnprocessableEntity},
+			wantErr:            false,
+		},
+		{
+			name: "patch task changing the id",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`[{"op":"replace","path":"/id","value":"2"}]`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationJSONPatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnprocessableEntity},
+			wantErr:            false,
+		},
+		{
+			name: "patch task adding an unknown field",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"owner":"someone"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnprocessableEntity},
+			wantErr:            false,
+		},
+		{
+			name: "patch task not found",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(models.Task{}, repository.ErrTaskNotFound)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+			wantErr:            false,
+		},
+		{
+			name: "patch task deleted while patching",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+				mockTM.EXPECT().CompareAndSwapTask(context.Background(), task, models.Task{ID: taskID, Name: name, Status: task.Status}).Return(repository.ErrTaskNotFound)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+			wantErr:            false,
+		},
+		{
+			name: "patch task changed while patching",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+				mockTM.EXPECT().CompareAndSwapTask(context.Background(), task, models.Task{ID: taskID, Name: name, Status: task.Status}).Return(repository.ErrTaskChanged)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusConflict},
+			wantErr:            false,
+		},
+		{
+			name: "patch task with internal error",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(context.Background(), taskID).Return(task, nil)
+				mockTM.EXPECT().CompareAndSwapTask(context.Background(), task, models.Task{ID: taskID, Name: name, Status: task.Status}).Return(repository.ErrTaskType)
+
+				req := httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), bytes.NewBufferString(`{"name":"Updated Task"}`))
+				req.Header.Set(echo.HeaderContentType, models.MIMEApplicationMergePatchJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+
+			e.ServeHTTP(tt.args.rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			if tt.wantErr {
+				t.Log(string(body))
+				return
+			}
+
+			if tt.args.rec.Result().StatusCode == http.StatusOK {
+				var task models.Task
+				err := json.Unmarshal(body, &task)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse.(models.Task), task)
+				return
+			}
+
+			if tt.expectedResponse != nil {
+				var resp models.ErrorResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.rec.Result().StatusCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteTask(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.DELETE("/tasks/:id", handler.DeleteTask)
+
+	taskID := "1"
+
+	type args struct {
+		req *http.Request
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+		wantErr            bool
+	}{
+		{
+			name: "delete task",
+			mockSetup: func() {
+				mockTM.EXPECT().DeleteTask(context.Background(), taskID).Return(nil)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%s", taskID), nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   nil,
+			wantErr:            false,
+		},
+		{
+			name: "delete task not found",
+			mockSetup: func() {
+				mockTM.EXPECT().DeleteTask(context.Background(), taskID).Return(repository.ErrTaskNotFound)
+			},
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks/%s", taskID), nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+		{
+			name: "update tasks with wrong path",
+			args: args{
+				req: httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/tasks1234/%s", taskID), nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusNotFound,
+			wantErr:            true,
+		},
+		{
+			name: "update tasks with wrong method",
+			args: args{
+				req: httptest.NewRequest(http.MethodPatch, fmt.Sprintf("/tasks/%s", taskID), nil),
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusMethodNotAllowed,
+			wantErr:            true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			e.ServeHTTP(tt.args.rec, tt.args.req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			if tt.wantErr {
+				t.Log(string(body))
+				return
+			}
+
+			if tt.args.rec.Result().StatusCode == http.StatusCreated {
+				return
+			}
+
+			if tt.expectedResponse != nil {
+				var resp models.ErrorResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.rec.Result().StatusCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_BatchUpdateTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.PATCH("/tasks", handler.BatchUpdateTasks)
+
+	status := 1
+	openStatus := 0
+	taskIDs := []string{"1", "2"}
+	results := []models.BatchResult{
+		{ID: "1", Outcome: models.BatchOutcomeUpdated},
+		{ID: "2", Outcome: models.BatchOutcomeNotFound},
+	}
+	existingTasks := []models.Task{
+		{ID: "1", Name: "Task 1", Status: 0},
+		{ID: "2", Name: "Task 2", Status: 1},
+		{ID: "3", Name: "Task 3", Status: 0},
+	}
+	byIDsReqBody, err := json.Marshal(models.BatchUpdateTasksRequest{BatchTarget: models.BatchTarget{IDs: taskIDs}, Status: &status})
+	assert.NoError(t, err)
+	byFilterReqBody, err := json.Marshal(models.BatchUpdateTasksRequest{BatchTarget: models.BatchTarget{Filter: &models.TaskFilter{Status: &openStatus}}, Status: &status})
+	assert.NoError(t, err)
+	noTargetReqBody, err := json.Marshal(models.BatchUpdateTasksRequest{Status: &status})
+	assert.NoError(t, err)
+	ambiguousTargetReqBody, err := json.Marshal(models.BatchUpdateTasksRequest{BatchTarget: models.BatchTarget{IDs: taskIDs, Filter: &models.TaskFilter{Status: &openStatus}}, Status: &status})
+	assert.NoError(t, err)
+	noChangesReqBody, err := json.Marshal(models.BatchUpdateTasksRequest{BatchTarget: models.BatchTarget{IDs: taskIDs}})
+	assert.NoError(t, err)
+
+	type args struct {
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func() *http.Request
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+		wantErr            bool
+	}{
+		{
+			name: "batch update tasks by ids",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().BatchUpdateTasks(context.Background(), taskIDs, nil, &status).Return(results, nil)
+
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(byIDsReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.BatchResponse{Results: results},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks by filter",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(existingTasks, nil)
+				mockTM.EXPECT().BatchUpdateTasks(context.Background(), []string{"1", "3"}, nil, &status).Return(results, nil)
+
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(byFilterReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.BatchResponse{Results: results},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks with invalid request body",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBufferString("invalid_json"))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks without ids or filter",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(noTargetReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks with both ids and filter",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(ambiguousTargetReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks with no changes",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(noChangesReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks with internal error",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().BatchUpdateTasks(context.Background(), taskIDs, nil, &status).Return(nil, repository.ErrTaskType)
+
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(byIDsReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+		{
+			name: "batch update tasks by filter with internal error",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(nil, repository.ErrGetTasksFailed)
+
+				req := httptest.NewRequest(http.MethodPatch, "/tasks", bytes.NewBuffer(byFilterReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+
+			e.ServeHTTP(tt.args.rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			if tt.wantErr {
+				t.Log(string(body))
+				return
+			}
+
+			if tt.args.rec.Result().StatusCode == http.StatusOK {
+				var resp models.BatchResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse.(models.BatchResponse), resp)
+				return
+			}
+
+			if tt.expectedResponse != nil {
+				var resp models.ErrorResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.args.rec.Result().StatusCode, resp.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_BatchDeleteTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.DELETE("/tasks", handler.BatchDeleteTasks)
+
+	doneStatus := 1
+	taskIDs := []string{"1", "2"}
+	results := []models.BatchResult{
+		{ID: "1", Outcome: models.BatchOutcomeDeleted},
+		{ID: "2", Outcome: models.BatchOutcomeNotFound},
+	}
+	existingTasks := []models.Task{
+		{ID: "1", Name: "Task 1", Status: 0},
+		{ID: "2", Name: "Task 2", Status: 1},
+	}
+	byIDsReqBody, err := json.Marshal(models.BatchDeleteTasksRequest{BatchTarget: models.BatchTarget{IDs: taskIDs}})
+	assert.NoError(t, err)
+	byFilterReqBody, err := json.Marshal(models.BatchDeleteTasksRequest{BatchTarget: models.BatchTarget{Filter: &models.TaskFilter{Status: &doneStatus}}})
+	assert.NoError(t, err)
+
+	type args struct {
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func() *http.Request
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+		wantErr            bool
+	}{
+		{
+			name: "batch delete tasks by ids",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().BatchDeleteTasks(context.Background(), taskIDs).Return(results, nil)
+
+				req := httptest.NewRequest(http.MethodDelete, "/tasks", bytes.NewBuffer(byIDsReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.BatchResponse{Results: results},
+			wantErr:            false,
+		},
+		{
+			name: "batch delete tasks by filter",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(existingTasks, nil)
+				mockTM.EXPECT().BatchDeleteTasks(context.Background(), []string{"2"}).Return(results[1:], nil)
+
+				req := httptest.NewRequest(http.MethodDelete, "/tasks", bytes.NewBuffer(byFilterReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.BatchResponse{Results: results[1:]},
+			wantErr:            false,
+		},
+		{
+			name: "batch delete tasks without ids or filter",
+			mockSetup: func() *http.Request {
+				req := httptest.NewRequest(http.MethodDelete, "/tasks", bytes.NewBufferString("{}"))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+			wantErr:            false,
+		},
+		{
+			name: "batch delete tasks with internal error",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().BatchDeleteTasks(context.Background(), taskIDs).Return(nil, context.Canceled)
+
+				req := httptest.NewRequest(http.MethodDelete, "/tasks", bytes.NewBuffer(byIDsReqBody))
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+				return req
+			},
+			args: args{
+				rec: httptest.NewRecorder(),
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+			wantErr:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+
+			e.ServeHTTP(tt.args.rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			if tt.wantErr {
+				t.Log(string(body))
+				return
+			}
+
+			if tt.args.rec.Result().StatusCode == http.StatusOK {
+				var resp models.BatchResponse
+				err := json.Unmarshal(body, &resp)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse.(models.BatchResponse), resp)
 				return
 			}
 
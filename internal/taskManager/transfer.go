@@ -0,0 +1,278 @@
+package taskmanager
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/brionac626/taskManager/internal/codec"
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// formatCSV is the format of comma-separated values with a header row
+	formatCSV = "csv"
+	// formatICal is the iCalendar format, with a VTODO component per task
+	formatICal = "ics"
+	// formatTodoTxt is the todo.txt format, with a line per task
+	formatTodoTxt = "todotxt"
+	// formatMarkdown is the format of Markdown checklists, with a checklist item per task
+	formatMarkdown = "markdown"
+)
+
+// encodeFunc writes tasks in a file format.
+type encodeFunc func(w io.Writer, tasks []models.Task) error
+
+// exporter writes tasks in a file format.
+type exporter struct {
+	contentType string
+	extension   string
+	// encoder returns the encoding of the tasks with the options of the query parameters of the request
+	encoder func(c echo.Context) (encodeFunc, error)
+}
+
+// importer reads tasks in a file format from the body of the request, using its query parameters as options.
+// The tasks are validated, their ids are empty unless the format keeps the ids of the exported tasks.
+type importer func(c echo.Context) ([]models.Task, error)
+
+// exporters are the file formats tasks can be exported to, by format name
+var exporters = map[string]exporter{
+	formatCSV:      {contentType: "text/csv; charset=utf-8", extension: "csv", encoder: withoutOptions(codec.EncodeCSV)},
+	formatICal:     {contentType: "text/calendar; charset=utf-8", extension: "ics", encoder: withoutOptions(codec.EncodeICal)},
+	formatTodoTxt:  {contentType: "text/plain; charset=utf-8", extension: "txt", encoder: withoutOptions(codec.EncodeTodoTxt)},
+	formatMarkdown: {contentType: "text/markdown; charset=utf-8", extension: "md", encoder: exportMarkdown},
+}
+
+// importers are the file formats tasks can be imported from, by format name
+var importers = map[string]importer{
+	formatCSV:      importCSV,
+	formatICal:     importICal,
+	formatTodoTxt:  importTodoTxt,
+	formatMarkdown: importMarkdown,
+}
+
+// ExportTasks godoc
+// @Summary      Export tasks to a file.
+// @Description  Stream all tasks, or the tasks matching the query, as a file to download.
+// @Description  The csv format has a header row followed by the id, name and status of every task,
+// @Description  the ics format has a VTODO component per task like GET /tasks.ics,
+// @Description  the todotxt format has a todo.txt line per task with the task id in an id:<id> tag,
+// @Description  and the markdown format has a checklist item per task, grouped under a heading per +project
+// @Description  or #tag word of the task names with the group parameter.
+// @Tags         Tasks
+// @Produce      text/csv
+// @Produce      text/calendar
+// @Produce      text/plain
+// @Produce      text/markdown
+// @Produce      application/problem+json
+// @Param 		 format  query  string  false  "file format"	default(csv)	Enums(csv, ics, todotxt, markdown)
+// @Param 		 group  query  string  false  "marker grouping the tasks of the markdown format"	Enums(project, tag)
+// @Param 		 q  query  string  false  "task query"	example("open AND name:deploy")
+// @Param 		 as_of  query  string  false  "RFC 3339 timestamp to export the tasks at"	example("2026-10-12T09:00:00Z")
+// @Success      200  {file}  file  "exported tasks"
+// @Failure      400  {object}  models.ErrorResponse  "Unsupported format, invalid group, task query or timestamp"
+// @Failure      410  {object}  models.ErrorResponse  "Timestamp before the retained history"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get tasks"
+// @Router       /tasks/export [get]
+// ExportTasks exports the tasks to a file.
+func (h *Handler) ExportTasks(c echo.Context) error {
+	format := c.QueryParam("format")
+	if format == "" {
+		format = formatCSV
+	}
+
+	return h.exportTasks(c, format)
+}
+
+// ExportCalendar godoc
+// @Summary      Export tasks as an iCalendar file.
+// @Description  Stream all tasks, or the tasks matching the query, as the VTODO components of an iCalendar file (RFC 5545).
+// @Description  The UID of a todo is the task id, and the status is NEEDS-ACTION or COMPLETED.
+// @Tags         Tasks
+// @Produce      text/calendar
+// @Produce      application/problem+json
+// @Param 		 q  query  string  false  "task query"	example("open AND name:deploy")
+// @Param 		 as_of  query  string  false  "RFC 3339 timestamp to export the tasks at"	example("2026-10-12T09:00:00Z")
+// @Success      200  {file}  file  "exported tasks"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid task query or timestamp"
+// @Failure      410  {object}  models.ErrorResponse  "Timestamp before the retained history"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get tasks"
+// @Router       /tasks.ics [get]
+// ExportCalendar exports the tasks as an iCalendar file.
+func (h *Handler) ExportCalendar(c echo.Context) error {
+	return h.exportTasks(c, formatICal)
+}
+
+// exportTasks streams the tasks found by the query parameters as a file of the format.
+func (h *Handler) exportTasks(c echo.Context, format string) error {
+	exporter, supported := exporters[format]
+	if !supported {
+		return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: "format", Err: models.ErrUnsupportedFormat})
+	}
+
+	encode, err := exporter.encoder(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	tasks, code, err := h.findTasks(c)
+	if err != nil {
+		return problem.Respond(c, code, err)
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, exporter.contentType)
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="tasks.%s"`, exporter.extension))
+	resp.WriteHeader(http.StatusOK)
+
+	// the status is sent, a failure can only interrupt the download
+	return encode(resp, tasks)
+}
+
+// withoutOptions returns the encoder of a file format without options.
+func withoutOptions(encode encodeFunc) func(c echo.Context) (encodeFunc, error) {
+	return func(echo.Context) (encodeFunc, error) {
+		return encode, nil
+	}
+}
+
+// exportMarkdown returns the encoding of a Markdown checklist grouped by the group query parameter.
+func exportMarkdown(c echo.Context) (encodeFunc, error) {
+	group, err := codec.ParseMarkdownGroup(c.QueryParam("group"))
+	if err != nil {
+		return nil, &models.FieldError{Field: "group", Err: err}
+	}
+
+	return func(w io.Writer, tasks []models.Task) error {
+		return codec.EncodeMarkdown(w, tasks, group)
+	}, nil
+}
+
+// ImportTasks godoc
+// @Summary      Import tasks from a file.
+// @Description  Create the tasks of a file. Every task is validated like the created tasks before any task is created,
+// @Description  and the errors are reported per row as rows[<line>] fields with the line numbers of the file.
+// @Description  The csv format reads the name and status columns of the header row, or the columns mapped by
+// @Description  the columns parameter; a missing status is an open task.
+// @Description  The ics format reads the VTODO components of an iCalendar file: a todo whose UID is the id of
+// @Description  an existing task updates the task, and the other UIDs created by GET /tasks.ics restore their task,
+// @Description  so that a calendar can be exported, edited and imported back.
+// @Description  The todotxt format reads the lines of a todo.txt file, the lines starting with "x " are completed
+// @Description  tasks, and the id:<id> tags written by the export update or restore their task like the UIDs of the ics format.
+// @Description  Priorities and dates are skipped, the projects and contexts are kept in the task name.
+// @Description  The markdown format reads the "- [ ]" and "- [x]" checklist items of a Markdown document, nested
+// @Description  items included, and ignores the other lines.
+// @Description  A dry run only validates the tasks and returns them without creating them.
+// @Tags         Tasks
+// @Accept       text/csv
+// @Accept       text/calendar
+// @Accept       text/plain
+// @Accept       text/markdown
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 format  query  string  false  "file format"	default(csv)	Enums(csv, ics, todotxt, markdown)
+// @Param 		 columns  query  string  false  "csv header names of the task fields"	example("name:Title,status:Done")
+// @Param 		 dry_run  query  bool  false  "validate without creating the tasks"	default(false)
+// @Param 		 file  body  string  true  "file to import"
+// @Success      200  {object}  models.ImportResponse  "tasks validated by a dry run"
+// @Success      201  {object}  models.ImportResponse  "created tasks"
+// @Failure      400  {object}  models.ErrorResponse  "Unsupported format, invalid options or invalid rows"
+// @Failure      409  {object}  models.ErrorResponse  "Task id repeated in the file"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to create the tasks"
+// @Router       /tasks/import [post]
+// ImportTasks creates the tasks of a file.
+func (h *Handler) ImportTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = formatCSV
+	}
+
+	decode, supported := importers[format]
+	if !supported {
+		return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: "format", Err: models.ErrUnsupportedFormat})
+	}
+
+	dryRun := false
+	if c.QueryParams().Has("dry_run") {
+		value, err := strconv.ParseBool(c.QueryParam("dry_run"))
+		if err != nil {
+			return problem.Respond(c, http.StatusBadRequest, &models.FieldError{Field: "dry_run", Err: err})
+		}
+		dryRun = value
+	}
+
+	tasks, err := decode(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	if dryRun {
+		return c.JSON(http.StatusOK, &models.ImportResponse{DryRun: true, Tasks: tasks})
+	}
+
+	if err := repository.ImportTasks(ctx, h.repo, tasks); err != nil {
+		if errors.Is(err, repository.ErrTaskID) {
+			return problem.Respond(c, http.StatusBadRequest, err)
+		}
+		if errors.Is(err, repository.ErrTaskExists) {
+			return problem.Respond(c, http.StatusConflict, err)
+		}
+
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, &models.ImportResponse{Tasks: tasks})
+}
+
+// importCSV reads new tasks from a CSV body, with the header names of the columns query parameter.
+func importCSV(c echo.Context) ([]models.Task, error) {
+	columns, err := codec.ParseCSVColumns(c.QueryParam("columns"))
+	if err != nil {
+		return nil, &models.FieldError{Field: "columns", Err: err}
+	}
+
+	newTasks, err := codec.DecodeCSV(c.Request().Body, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTasks(newTasks), nil
+}
+
+// importICal reads tasks from the VTODO components of an iCalendar body.
+func importICal(c echo.Context) ([]models.Task, error) {
+	return codec.DecodeICal(c.Request().Body)
+}
+
+// importTodoTxt reads tasks from the lines of a todo.txt body.
+func importTodoTxt(c echo.Context) ([]models.Task, error) {
+	return codec.DecodeTodoTxt(c.Request().Body)
+}
+
+// importMarkdown reads new tasks from the checklist items of a Markdown body.
+func importMarkdown(c echo.Context) ([]models.Task, error) {
+	newTasks, err := codec.DecodeMarkdown(c.Request().Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return toTasks(newTasks), nil
+}
+
+// toTasks converts new tasks to tasks without id.
+func toTasks(newTasks []models.NewTask) []models.Task {
+	tasks := make([]models.Task, 0, len(newTasks))
+	for _, task := range newTasks {
+		tasks = append(tasks, models.Task{Name: task.Name, Status: task.Status})
+	}
+
+	return tasks
+}
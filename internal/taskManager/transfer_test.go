@@ -0,0 +1,354 @@
+package taskmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_ExportTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.GET("/tasks/export", handler.ExportTasks)
+	e.GET("/tasks.ics", handler.ExportCalendar)
+
+	tasks := []models.Task{
+		{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 0},
+		{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task, 2", Status: 1},
+	}
+
+	tests := []struct {
+		name                string
+		mockSetup           func()
+		target              string
+		expectedStatusCode  int
+		expectedContentType string
+		expectedFilename    string
+		expectedBody        string
+	}{
+		{
+			name: "export tasks as csv",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(tasks, nil)
+			},
+			target:              "/tasks/export?format=csv",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,name,status\n9bsv0s2hf8ng030mva9g,Task 1,0\n9bsv0s2hf8ng030mvaa0,\"Task, 2\",1\n",
+		},
+		{
+			name: "export filtered tasks in the default format",
+			mockSetup: func() {
+				mockTM.EXPECT().QueryTasks(context.Background(), gomock.Cond(func(q *query.Query) bool {
+					return q.String() == "done"
+				})).Return(tasks[1:], nil)
+			},
+			target:              "/tasks/export?q=done",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,name,status\n9bsv0s2hf8ng030mvaa0,\"Task, 2\",1\n",
+		},
+		{
+			name: "export tasks as icalendar",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(tasks[:1], nil)
+			},
+			target:              "/tasks.ics",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/calendar; charset=utf-8",
+			expectedFilename:    "tasks.ics",
+			expectedBody: "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//brionac626//taskManager//EN\r\n" +
+				"BEGIN:VTODO\r\nUID:9bsv0s2hf8ng030mva9g\r\nDTSTAMP:20091110T230000Z\r\nSUMMARY:Task 1\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+		},
+		{
+			name: "export tasks as todo.txt",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(tasks, nil)
+			},
+			target:              "/tasks/export?format=todotxt",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedFilename:    "tasks.txt",
+			expectedBody:        "2009-11-10 Task 1 id:9bsv0s2hf8ng030mva9g\nx 2009-11-10 2009-11-10 Task, 2 id:9bsv0s2hf8ng030mvaa0\n",
+		},
+		{
+			name: "export tasks as a markdown checklist grouped by project",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(context.Background()).Return([]models.Task{
+					{ID: "9bsv0s2hf8ng030mva9g", Name: "Write the notes +Release", Status: 0},
+					{ID: "9bsv0s2hf8ng030mvaa0", Name: "Water the plants", Status: 1},
+				}, nil)
+			},
+			target:              "/tasks/export?format=markdown&group=project",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/markdown; charset=utf-8",
+			expectedFilename:    "tasks.md",
+			expectedBody:        "## +Release\n\n- [ ] Write the notes +Release\n\n## No project\n\n- [x] Water the plants\n",
+		},
+		{
+			name:               "export tasks grouped by an unknown marker",
+			target:             "/tasks/export?format=markdown&group=owner",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "export tasks in an unsupported format",
+			target:             "/tasks/export?format=xlsx",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "export tasks with invalid query",
+			target:             "/tasks/export?q=tag%3Ainfra",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name: "export tasks failed",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTasks(context.Background()).Return(nil, errors.New("storage error"))
+			},
+			target:             "/tasks/export",
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Result().StatusCode)
+			if tt.expectedStatusCode != http.StatusOK {
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedStatusCode, resp.Code)
+				return
+			}
+
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get(echo.HeaderContentType))
+			filename := tt.expectedFilename
+			if filename == "" {
+				filename = "tasks.csv"
+			}
+			assert.Equal(t, `attachment; filename="`+filename+`"`, rec.Header().Get(echo.HeaderContentDisposition))
+			assert.Equal(t, tt.expectedBody, rec.Body.String())
+		})
+	}
+}
+
+func TestHandler_ImportTasks(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	handler := &Handler{repo: mockTM}
+
+	e := echo.New()
+	e.POST("/tasks/import", handler.ImportTasks)
+
+	const validCSV = "Title,Done\nTask 1,0\nTask 2,1\n"
+	updatedName, updatedStatus := "Task 1", 1
+
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		target             string
+		body               string
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name: "import tasks",
+			mockSetup: func() {
+				mockTM.EXPECT().CreateTasks(context.Background(), []models.Task{{Name: "Task 1", Status: 0}, {Name: "Task 2", Status: 1}}).
+					DoAndReturn(func(_ context.Context, tasks []models.Task) error {
+						tasks[0].ID, tasks[1].ID = "9bsv0s2hf8ng030mva9g", "9bsv0s2hf8ng030mvaa0"
+						return nil
+					})
+			},
+			target:             "/tasks/import?format=csv&columns=name:Title,status:Done",
+			body:               validCSV,
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: models.ImportResponse{Tasks: []models.Task{
+				{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 0},
+				{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2", Status: 1},
+			}},
+		},
+		{
+			name: "import icalendar todos",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTask(context.Background(), "9bsv0s2hf8ng030mva9g").Return(models.Task{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1"}, nil)
+				mockTM.EXPECT().UpdateTask(context.Background(), "9bsv0s2hf8ng030mva9g", &updatedName, &updatedStatus).Return(nil)
+				mockTM.EXPECT().GetTask(context.Background(), "9bsv0s2hf8ng030mvaa0").Return(models.Task{}, repository.ErrTaskNotFound)
+				mockTM.EXPECT().RestoreTasks(context.Background(), []models.Task{{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2"}}).Return(nil)
+				mockTM.EXPECT().CreateTasks(context.Background(), []models.Task{{Name: "Task 3"}}).
+					DoAndReturn(func(_ context.Context, tasks []models.Task) error {
+						tasks[0].ID = "9bsv0s2hf8ng030mvab0"
+						return nil
+					})
+			},
+			target: "/tasks/import?format=ics",
+			body: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VTODO\r\nUID:9bsv0s2hf8ng030mva9g\r\nSUMMARY:Task 1\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:9bsv0s2hf8ng030mvaa0\r\nSUMMARY:Task 2\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:task-3@example.com\r\nSUMMARY:Task 3\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: models.ImportResponse{Tasks: []models.Task{
+				{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1", Status: 1},
+				{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2", Status: 0},
+				{ID: "9bsv0s2hf8ng030mvab0", Name: "Task 3", Status: 0},
+			}},
+		},
+		{
+			name: "import icalendar todo repeated",
+			mockSetup: func() {
+				// rejected before anything is written
+				mockTM.EXPECT().GetTask(context.Background(), "9bsv0s2hf8ng030mvaa0").Return(models.Task{}, repository.ErrTaskNotFound)
+			},
+			target: "/tasks/import?format=ics",
+			body: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VTODO\r\nUID:9bsv0s2hf8ng030mvaa0\r\nSUMMARY:Task 2\r\nEND:VTODO\r\n" +
+				"BEGIN:VTODO\r\nUID:9bsv0s2hf8ng030mvaa0\r\nSUMMARY:Task 2\r\nEND:VTODO\r\n" +
+				"END:VCALENDAR\r\n",
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusConflict},
+		},
+		{
+			name:               "import invalid icalendar",
+			target:             "/tasks/import?format=ics",
+			body:               validCSV,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name: "import todo.txt lines",
+			mockSetup: func() {
+				mockTM.EXPECT().GetTask(context.Background(), "9bsv0s2hf8ng030mva9g").Return(models.Task{}, repository.ErrTaskNotFound)
+				mockTM.EXPECT().RestoreTasks(context.Background(), []models.Task{{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1 +Release", Status: 1}}).Return(nil)
+				mockTM.EXPECT().CreateTasks(context.Background(), []models.Task{{Name: "Task 2 @phone"}}).
+					DoAndReturn(func(_ context.Context, tasks []models.Task) error {
+						tasks[0].ID = "9bsv0s2hf8ng030mvaa0"
+						return nil
+					})
+			},
+			target:             "/tasks/import?format=todotxt",
+			body:               "x 2026-10-18 Task 1 +Release id:9bsv0s2hf8ng030mva9g\n(A) 2026-10-17 Task 2 @phone\n",
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse: models.ImportResponse{Tasks: []models.Task{
+				{ID: "9bsv0s2hf8ng030mva9g", Name: "Task 1 +Release", Status: 1},
+				{ID: "9bsv0s2hf8ng030mvaa0", Name: "Task 2 @phone", Status: 0},
+			}},
+		},
+		{
+			name:               "dry run of a markdown checklist",
+			target:             "/tasks/import?format=markdown&dry_run=true",
+			body:               "## Release\n\n- [x] Write the notes\n  - [ ] Review them\n",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: models.ImportResponse{DryRun: true, Tasks: []models.Task{
+				{Name: "Write the notes", Status: 1},
+				{Name: "Review them", Status: 0},
+			}},
+		},
+		{
+			name:               "dry run",
+			target:             "/tasks/import?columns=name:Title,status:Done&dry_run=true",
+			body:               validCSV,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: models.ImportResponse{DryRun: true, Tasks: []models.Task{
+				{Name: "Task 1", Status: 0},
+				{Name: "Task 2", Status: 1},
+			}},
+		},
+		{
+			name:               "import invalid rows",
+			target:             "/tasks/import?dry_run=false",
+			body:               "name,status\nTask 1,0\n,3\n",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest, Message: "task name is empty; invalid status"},
+		},
+		{
+			name:               "import without mapped column",
+			target:             "/tasks/import",
+			body:               validCSV,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest, Message: "missing column: name"},
+		},
+		{
+			name:               "import with invalid column mapping",
+			target:             "/tasks/import?columns=title",
+			body:               validCSV,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name:               "import with invalid dry run",
+			target:             "/tasks/import?dry_run=maybe",
+			body:               validCSV,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name:               "import in an unsupported format",
+			target:             "/tasks/import?format=xlsx",
+			body:               validCSV,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name: "import failed",
+			mockSetup: func() {
+				mockTM.EXPECT().CreateTasks(context.Background(), gomock.Any()).Return(errors.New("storage error"))
+			},
+			target:             "/tasks/import",
+			body:               "name\nTask 1\n",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.mockSetup != nil {
+				tt.mockSetup()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, "text/csv")
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Result().StatusCode)
+
+			body, err := io.ReadAll(rec.Body)
+			assert.NoError(t, err)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected.Code, resp.Code)
+				if expected.Message != "" {
+					assert.Equal(t, expected.Message, resp.Message)
+				}
+			case models.ImportResponse:
+				var resp models.ImportResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package taskmanager
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/undo"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Undo godoc
+// @Summary      Undo the last task operation of the user.
+// @Description  Revert the last create, update or delete operation made by the user identified by the X-User-ID header.
+// @Description  An operation whose tasks were changed by someone else since is discarded and answers 409.
+// @Tags         Undo
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Success      200  {object}  models.Operation  "the undone operation"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      404  {object}  models.ErrorResponse  "Nothing to undo"
+// @Failure      409  {object}  models.ErrorResponse  "Task changed since the operation"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to undo the operation"
+// @Router       /undo [post]
+// Undo reverts the last task operation of the user.
+func (h *Handler) Undo(c echo.Context) error {
+	user, err := userID(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, err)
+	}
+
+	op, err := h.undo.Undo(c.Request().Context(), user)
+	if err != nil {
+		return problem.Respond(c, undoErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &op)
+}
+
+// Redo godoc
+// @Summary      Redo the last task operation undone by the user.
+// @Description  Make again the last operation undone by the user identified by the X-User-ID header.
+// @Description  Any new operation of the user clears the operations to redo.
+// @Description  An operation whose tasks were changed by someone else since the undo is discarded and answers 409.
+// @Tags         Undo
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Success      200  {object}  models.Operation  "the redone operation"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      404  {object}  models.ErrorResponse  "Nothing to redo"
+// @Failure      409  {object}  models.ErrorResponse  "Task changed since the undo"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to redo the operation"
+// @Router       /redo [post]
+// Redo makes again the last task operation undone by the user.
+func (h *Handler) Redo(c echo.Context) error {
+	user, err := userID(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, err)
+	}
+
+	op, err := h.undo.Redo(c.Request().Context(), user)
+	if err != nil {
+		return problem.Respond(c, undoErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &op)
+}
+
+// undoErrorCode returns the status code matching an error of the undo history.
+func undoErrorCode(err error) int {
+	switch {
+	case errors.Is(err, undo.ErrNothingToUndo), errors.Is(err, undo.ErrNothingToRedo):
+		return http.StatusNotFound
+	case errors.Is(err, undo.ErrConflict):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
+
+// trackUser carries the user sending the request, if any, in the request context,
+// so that the task operations of the request are recorded for undoing.
+func trackUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if user, err := userID(c); err == nil {
+			req := c.Request()
+			c.SetRequest(req.WithContext(undo.WithUser(req.Context(), user)))
+		}
+
+		return next(c)
+	}
+}
@@ -0,0 +1,155 @@
+package taskmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/internal/undo"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_UndoRedo(t *testing.T) {
+	mockTM := mocks.NewMockTaskManager(gomock.NewController(t))
+	history := undo.NewHistory(mockTM, undo.DefaultLimit)
+	handler := &Handler{repo: undo.Track(history), undo: history}
+
+	e := echo.New()
+	e.Use(trackUser)
+	e.PUT("/tasks/:id", handler.UpdateTask)
+	e.POST("/undo", handler.Undo)
+	e.POST("/redo", handler.Redo)
+
+	task := models.Task{ID: "1", Name: "Task 1", Status: 0}
+	name, status := "Renamed Task 1", 1
+	updated := models.Task{ID: task.ID, Name: name, Status: status}
+	updateReqBody, err := json.Marshal(models.UpdateTaskRequest{Name: &name, Status: &status})
+	assert.NoError(t, err)
+
+	newRequest := func(method, target, user string, body []byte) *http.Request {
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if user != "" {
+			req.Header.Set(HeaderUserID, user)
+		}
+
+		return req
+	}
+
+	// the steps run in order, each one depends on the operations recorded by the previous ones
+	tests := []struct {
+		name               string
+		mockSetup          func() *http.Request
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name: "undo without user id",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/undo", "", nil)
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnauthorized},
+		},
+		{
+			name: "nothing to undo",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/undo", "alice", nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+		{
+			name: "update task of a user",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(gomock.Any(), task.ID).Return(task, nil)
+				mockTM.EXPECT().UpdateTask(gomock.Any(), task.ID, &name, &status).Return(nil)
+
+				return newRequest(http.MethodPut, "/tasks/1", "alice", updateReqBody)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "undo of another user",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/undo", "bob", nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+		{
+			name: "undo update",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(gomock.Any(), task.ID).Return(updated, nil)
+				mockTM.EXPECT().UpdateTask(gomock.Any(), task.ID, &task.Name, &task.Status).Return(nil)
+
+				return newRequest(http.MethodPost, "/undo", "alice", nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: models.Operation{
+				Kind:    models.OperationUpdate,
+				Changes: []models.TaskChange{{Before: &task, After: &updated}},
+			},
+		},
+		{
+			name: "redo update failed",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(gomock.Any(), task.ID).Return(models.Task{}, errors.New("storage error"))
+
+				return newRequest(http.MethodPost, "/redo", "alice", nil)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+		},
+		{
+			name: "redo update changed by someone else",
+			mockSetup: func() *http.Request {
+				mockTM.EXPECT().GetTask(gomock.Any(), task.ID).Return(models.Task{ID: task.ID, Name: "Renamed by bob"}, nil)
+
+				return newRequest(http.MethodPost, "/redo", "alice", nil)
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusConflict},
+		},
+		{
+			name: "nothing to redo after a conflict",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/redo", "alice", nil)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, tt.mockSetup())
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Result().StatusCode)
+
+			body, err := io.ReadAll(rec.Body)
+			assert.NoError(t, err)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected.Code, resp.Code)
+			case models.Operation:
+				var resp models.Operation
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.NotEmpty(t, resp.ID)
+				assert.Equal(t, expected.Kind, resp.Kind)
+				assert.Equal(t, expected.Changes, resp.Changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package taskmanager
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HeaderUserID is the request header identifying the user owning the saved views
+const HeaderUserID = "X-User-ID"
+
+var (
+	// ErrUserIDMissing represents an error when the request does not identify the user
+	ErrUserIDMissing = errors.New("missing " + HeaderUserID + " header")
+	// ErrNotViewOwner represents an error when a user changes a view owned by someone else
+	ErrNotViewOwner = errors.New("only the owner can change the view")
+)
+
+// GetViews godoc
+// @Summary      Get the saved views of the user.
+// @Description  Get the saved views owned by the user identified by the X-User-ID header.
+// @Tags         Views
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Success      200  {array}  models.View  "views retrieved successfully"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get views"
+// @Router       /views [get]
+// GetViews retrieves the saved views of the user.
+func (h *Handler) GetViews(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	owner, err := userID(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, err)
+	}
+
+	views, err := h.views.GetViews(ctx, owner)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &views)
+}
+
+// GetView godoc
+// @Summary      Get a saved view by view id.
+// @Description  Get a saved view. Views can be read by anyone knowing their id, so they can be shared by link.
+// @Tags         Views
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 id  path  string  true  "target view id"	example("cv1h8ms2hf8ng030mvb0")
+// @Success      200  {object}  models.View  "view retrieved successfully"
+// @Failure      404  {object}  models.ErrorResponse  "View not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get a view"
+// @Router       /views/:id [get]
+// GetView retrieves a saved view by view id.
+func (h *Handler) GetView(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	view, err := h.views.GetView(ctx, c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, viewErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &view)
+}
+
+// CreateView godoc
+// @Summary      Save a new view.
+// @Description  Save a named filter, sort and column configuration for the user identified by the X-User-ID header.
+// @Tags         Views
+// @Accept		 json
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Param 		 req  body  models.SaveViewRequest  true  "view to save"
+// @Success      201  {object}  models.View  "the saved view"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid view fields values"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to save a view"
+// @Router       /views [post]
+// CreateView saves a new view for the user.
+func (h *Handler) CreateView(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	owner, err := userID(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusUnauthorized, err)
+	}
+
+	req, err := bindSaveViewRequest(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	view, err := h.views.CreateView(ctx, models.View{
+		Owner:   owner,
+		Name:    req.Name,
+		Query:   req.Query,
+		Sort:    req.Sort,
+		Columns: req.Columns,
+	})
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, &view)
+}
+
+// UpdateView godoc
+// @Summary      Replace a saved view by view id.
+// @Description  Replace the name, query, sort and columns of a view owned by the user identified by the X-User-ID header.
+// @Tags         Views
+// @Accept		 json
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Param 		 id  path  string  true  "target view id"	example("cv1h8ms2hf8ng030mvb0")
+// @Param 		 req  body  models.SaveViewRequest  true  "view to save"
+// @Success      200  "no content returned when successful"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid view fields values"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      403  {object}  models.ErrorResponse  "View owned by another user"
+// @Failure      404  {object}  models.ErrorResponse  "View not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to save a view"
+// @Router       /views/:id [put]
+// UpdateView replaces a saved view by view id.
+func (h *Handler) UpdateView(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	view, code, err := h.ownedView(c)
+	if err != nil {
+		return problem.Respond(c, code, err)
+	}
+
+	req, err := bindSaveViewRequest(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	view.Name = req.Name
+	view.Query = req.Query
+	view.Sort = req.Sort
+	view.Columns = req.Columns
+	if err := h.views.UpdateView(ctx, view); err != nil {
+		return problem.Respond(c, viewErrorCode(err), err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// DeleteView godoc
+// @Summary      Delete a saved view by view id.
+// @Description  Delete a view owned by the user identified by the X-User-ID header.
+// @Tags         Views
+// @Produce      application/problem+json
+// @Param 		 X-User-ID  header  string  true  "user id"	example("alice")
+// @Param 		 id  path  string  true  "target view id"	example("cv1h8ms2hf8ng030mvb0")
+// @Success      200  "no content returned when successful"
+// @Failure      401  {object}  models.ErrorResponse  "Missing user id"
+// @Failure      403  {object}  models.ErrorResponse  "View owned by another user"
+// @Failure      404  {object}  models.ErrorResponse  "View not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to delete a view"
+// @Router       /views/:id [delete]
+// DeleteView deletes a saved view by view id.
+func (h *Handler) DeleteView(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	view, code, err := h.ownedView(c)
+	if err != nil {
+		return problem.Respond(c, code, err)
+	}
+
+	if err := h.views.DeleteView(ctx, view.ID); err != nil {
+		return problem.Respond(c, viewErrorCode(err), err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetViewTasks godoc
+// @Summary      Get the tasks of a saved view.
+// @Description  Execute the query of a saved view and return the matching tasks, sorted and limited to the columns of the view.
+// @Description  Views can be executed by anyone knowing their id, so they can be shared by link.
+// @Tags         Views
+// @Produce      json
+// @Produce      application/problem+json
+// @Param 		 id  path  string  true  "target view id"	example("cv1h8ms2hf8ng030mvb0")
+// @Success      200  {array}  object  "tasks with the columns of the view"
+// @Failure      404  {object}  models.ErrorResponse  "View not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get the tasks of a view"
+// @Router       /views/:id/tasks [get]
+// GetViewTasks executes a saved view and returns its tasks.
+func (h *Handler) GetViewTasks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	view, err := h.views.GetView(ctx, c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, viewErrorCode(err), err)
+	}
+
+	var tasks []models.Task
+	if view.Query == "" {
+		tasks, err = h.repo.GetTasks(ctx)
+	} else {
+		var q *query.Query
+		if q, err = query.Parse(view.Query); err == nil {
+			tasks, err = h.repo.QueryTasks(ctx, q)
+		}
+	}
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	if view.Sort != "" {
+		models.SortTasks(tasks, view.Sort)
+	}
+
+	result := make([]map[string]any, 0, len(tasks))
+	for _, task := range tasks {
+		result = append(result, models.ProjectTask(task, view.Columns))
+	}
+
+	return c.JSON(http.StatusOK, &result)
+}
+
+// ownedView loads the view targeted by the request and checks that it is owned by the requesting user.
+// The status code of the error response is returned along with the error.
+func (h *Handler) ownedView(c echo.Context) (models.View, int, error) {
+	owner, err := userID(c)
+	if err != nil {
+		return models.View{}, http.StatusUnauthorized, err
+	}
+
+	view, err := h.views.GetView(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return models.View{}, viewErrorCode(err), err
+	}
+
+	if view.Owner != owner {
+		return models.View{}, http.StatusForbidden, ErrNotViewOwner
+	}
+
+	return view, http.StatusOK, nil
+}
+
+// bindSaveViewRequest binds and validates a view, including its query.
+func bindSaveViewRequest(c echo.Context) (models.SaveViewRequest, error) {
+	var req models.SaveViewRequest
+	if err := c.Bind(&req); err != nil {
+		return req, err
+	}
+
+	errs := []error{req.Validate()}
+	if req.Query != "" {
+		if _, err := query.Parse(req.Query); err != nil {
+			errs = append(errs, &models.FieldError{Field: "query", Err: err})
+		}
+	}
+
+	return req, errors.Join(errs...)
+}
+
+// userID returns the id of the user sending the request.
+func userID(c echo.Context) (string, error) {
+	id := strings.TrimSpace(c.Request().Header.Get(HeaderUserID))
+	if id == "" {
+		return "", ErrUserIDMissing
+	}
+
+	return id, nil
+}
+
+// viewErrorCode returns the status code of an error returned by the view manager.
+func viewErrorCode(err error) int {
+	if errors.Is(err, repository.ErrViewNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,271 @@
+package taskmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/query"
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Views(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTM := mocks.NewMockTaskManager(ctrl)
+	mockVM := mocks.NewMockViewManager(ctrl)
+	handler := &Handler{repo: mockTM, views: mockVM}
+
+	e := echo.New()
+	e.GET("/views", handler.GetViews)
+	e.POST("/views", handler.CreateView)
+	e.GET("/views/:id", handler.GetView)
+	e.PUT("/views/:id", handler.UpdateView)
+	e.DELETE("/views/:id", handler.DeleteView)
+
+	view := models.View{ID: "v1", Owner: "alice", Name: "Open tasks", Query: "open", Sort: "-name", Columns: []string{"name"}}
+	saveReqBody, err := json.Marshal(models.SaveViewRequest{Name: view.Name, Query: view.Query, Sort: view.Sort, Columns: view.Columns})
+	assert.NoError(t, err)
+	invalidSaveReqBody, err := json.Marshal(models.SaveViewRequest{Name: "", Query: "tag:infra", Sort: "due", Columns: []string{"owner"}})
+	assert.NoError(t, err)
+
+	newRequest := func(method, target string, body []byte, user string) *http.Request {
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if user != "" {
+			req.Header.Set(HeaderUserID, user)
+		}
+
+		return req
+	}
+
+	type args struct {
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func() *http.Request
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name: "get views",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetViews(context.Background(), "alice").Return([]models.View{view}, nil)
+
+				return newRequest(http.MethodGet, "/views", nil, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   []models.View{view},
+		},
+		{
+			name: "get views without user",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodGet, "/views", nil, "")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusUnauthorized},
+		},
+		{
+			name: "get shared view",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), view.ID).Return(view, nil)
+
+				return newRequest(http.MethodGet, fmt.Sprintf("/views/%s", view.ID), nil, "")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   view,
+		},
+		{
+			name: "get view not found",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), "unknown").Return(models.View{}, repository.ErrViewNotFound)
+
+				return newRequest(http.MethodGet, "/views/unknown", nil, "")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+		{
+			name: "create view",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().CreateView(context.Background(), models.View{
+					Owner: "alice", Name: view.Name, Query: view.Query, Sort: view.Sort, Columns: view.Columns,
+				}).Return(view, nil)
+
+				return newRequest(http.MethodPost, "/views", saveReqBody, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   view,
+		},
+		{
+			name: "create view with invalid fields",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/views", invalidSaveReqBody, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name: "update view",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), view.ID).Return(view, nil)
+				mockVM.EXPECT().UpdateView(context.Background(), view).Return(nil)
+
+				return newRequest(http.MethodPut, fmt.Sprintf("/views/%s", view.ID), saveReqBody, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "update view owned by another user",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), view.ID).Return(view, nil)
+
+				return newRequest(http.MethodPut, fmt.Sprintf("/views/%s", view.ID), saveReqBody, "bob")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusForbidden,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusForbidden},
+		},
+		{
+			name: "delete view",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), view.ID).Return(view, nil)
+				mockVM.EXPECT().DeleteView(context.Background(), view.ID).Return(nil)
+
+				return newRequest(http.MethodDelete, fmt.Sprintf("/views/%s", view.ID), nil, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "delete view not found",
+			mockSetup: func() *http.Request {
+				mockVM.EXPECT().GetView(context.Background(), "unknown").Return(models.View{}, repository.ErrViewNotFound)
+
+				return newRequest(http.MethodDelete, "/views/unknown", nil, "alice")
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+
+			e.ServeHTTP(tt.args.rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected.Code, resp.Code)
+			case models.View:
+				var resp models.View
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			case []models.View:
+				var resp []models.View
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			}
+		})
+	}
+}
+
+func TestHandler_GetViewTasks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTM := mocks.NewMockTaskManager(ctrl)
+	mockVM := mocks.NewMockViewManager(ctrl)
+	handler := &Handler{repo: mockTM, views: mockVM}
+
+	e := echo.New()
+	e.GET("/views/:id/tasks", handler.GetViewTasks)
+
+	tasks := []models.Task{
+		{ID: "1", Name: "Alpha", Status: 0},
+		{ID: "2", Name: "Beta", Status: 0},
+	}
+
+	tests := []struct {
+		name               string
+		mockSetup          func()
+		expectedStatusCode int
+		expectedResponse   []map[string]any
+	}{
+		{
+			name: "get tasks of a view with query, sort and columns",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(context.Background(), "v1").Return(models.View{ID: "v1", Query: "open", Sort: "-name", Columns: []string{"name"}}, nil)
+				mockTM.EXPECT().QueryTasks(context.Background(), gomock.Cond(func(q *query.Query) bool {
+					return q.String() == "open"
+				})).Return(append([]models.Task(nil), tasks...), nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   []map[string]any{{"name": "Beta"}, {"name": "Alpha"}},
+		},
+		{
+			name: "get tasks of a view without query",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(context.Background(), "v1").Return(models.View{ID: "v1"}, nil)
+				mockTM.EXPECT().GetTasks(context.Background()).Return(tasks[:1], nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   []map[string]any{{"id": "1", "name": "Alpha", "status": float64(0)}},
+		},
+		{
+			name: "get tasks of a view not found",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(context.Background(), "v1").Return(models.View{}, repository.ErrViewNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name: "get tasks of a view with internal error",
+			mockSetup: func() {
+				mockVM.EXPECT().GetView(context.Background(), "v1").Return(models.View{ID: "v1"}, nil)
+				mockTM.EXPECT().GetTasks(context.Background()).Return(nil, repository.ErrGetTasksFailed)
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/views/v1/tasks", nil))
+
+			assert.Equal(t, tt.expectedStatusCode, rec.Code)
+
+			if tt.expectedResponse != nil {
+				var resp []map[string]any
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+				assert.Equal(t, tt.expectedResponse, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package taskmanager
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/brionac626/taskManager/internal/problem"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetWebhooks godoc
+// @Summary      Get the webhook subscriptions.
+// @Description  Get every webhook subscription, their secrets are never returned.
+// @Description  The webhook endpoints are only served when the server has an admin token.
+// @Tags         Webhooks
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Success      200  {array}  models.Webhook  "webhooks retrieved successfully"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get webhooks"
+// @Router       /webhooks [get]
+// GetWebhooks retrieves the webhook subscriptions.
+func (h *Handler) GetWebhooks(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	webhooks, err := h.webhooks.GetWebhooks(ctx)
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusOK, &webhooks)
+}
+
+// GetWebhook godoc
+// @Summary      Get a webhook subscription by webhook id.
+// @Tags         Webhooks
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 id  path  string  true  "target webhook id"	example("cv2k1ts2hf8ng030mvc0")
+// @Success      200  {object}  models.Webhook  "webhook retrieved successfully"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      404  {object}  models.ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get a webhook"
+// @Router       /webhooks/:id [get]
+// GetWebhook retrieves a webhook subscription by webhook id.
+func (h *Handler) GetWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	webhook, err := h.webhooks.GetWebhook(ctx, c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, webhookErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &webhook)
+}
+
+// CreateWebhook godoc
+// @Summary      Subscribe an URL to the task events.
+// @Description  Deliver the task events of the given types, or of every type when none is given, to the URL.
+// @Description  Every delivery is a JSON models.TaskEvent signed with the X-Webhook-Signature header,
+// @Description  the hex encoded HMAC-SHA256 of the body keyed by the secret and prefixed with sha256=.
+// @Description  Deliveries answered with a non-2xx status are retried with an exponential backoff.
+// @Description  The deliveries to the loopback, private and link-local addresses of the URL host are refused.
+// @Tags         Webhooks
+// @Accept		 json
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 req  body  models.SaveWebhookRequest  true  "webhook to subscribe"
+// @Success      201  {object}  models.Webhook  "the subscribed webhook"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook fields values"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to subscribe a webhook"
+// @Router       /webhooks [post]
+// CreateWebhook subscribes an URL to the task events.
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req, err := bindSaveWebhookRequest(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	webhook, err := h.webhooks.CreateWebhook(ctx, models.Webhook{URL: req.URL, Secret: req.Secret, Events: req.Events})
+	if err != nil {
+		return problem.Respond(c, http.StatusInternalServerError, err)
+	}
+
+	return c.JSON(http.StatusCreated, &webhook)
+}
+
+// UpdateWebhook godoc
+// @Summary      Replace a webhook subscription by webhook id.
+// @Description  Replace the URL, secret and event types of a webhook.
+// @Tags         Webhooks
+// @Accept		 json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 id  path  string  true  "target webhook id"	example("cv2k1ts2hf8ng030mvc0")
+// @Param 		 req  body  models.SaveWebhookRequest  true  "webhook to subscribe"
+// @Success      200  "no content returned when successful"
+// @Failure      400  {object}  models.ErrorResponse  "Invalid webhook fields values"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      404  {object}  models.ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to update a webhook"
+// @Router       /webhooks/:id [put]
+// UpdateWebhook replaces a webhook subscription by webhook id.
+func (h *Handler) UpdateWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	req, err := bindSaveWebhookRequest(c)
+	if err != nil {
+		return problem.Respond(c, http.StatusBadRequest, err)
+	}
+
+	webhook := models.Webhook{ID: c.Param("id"), URL: req.URL, Secret: req.Secret, Events: req.Events}
+	if err := h.webhooks.UpdateWebhook(ctx, webhook); err != nil {
+		return problem.Respond(c, webhookErrorCode(err), err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete a webhook subscription by webhook id.
+// @Description  Delete a webhook and its delivery log, the pending retries are abandoned.
+// @Tags         Webhooks
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 id  path  string  true  "target webhook id"	example("cv2k1ts2hf8ng030mvc0")
+// @Success      200  "no content returned when successful"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      404  {object}  models.ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to delete a webhook"
+// @Router       /webhooks/:id [delete]
+// DeleteWebhook deletes a webhook subscription by webhook id.
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	if err := h.webhooks.DeleteWebhook(ctx, c.Param("id")); err != nil {
+		return problem.Respond(c, webhookErrorCode(err), err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      Get the delivery log of a webhook.
+// @Description  Get the latest delivery attempts of a webhook, the latest attempt first.
+// @Tags         Webhooks
+// @Produce      json
+// @Produce      application/problem+json
+// @Security     AdminToken
+// @Param 		 id  path  string  true  "target webhook id"	example("cv2k1ts2hf8ng030mvc0")
+// @Success      200  {array}  models.WebhookDelivery  "deliveries retrieved successfully"
+// @Failure      401  {object}  models.ErrorResponse  "Missing or invalid admin token"
+// @Failure      404  {object}  models.ErrorResponse  "Webhook not found"
+// @Failure      500  {object}  models.ErrorResponse  "Failed to get the deliveries"
+// @Router       /webhooks/:id/deliveries [get]
+// GetWebhookDeliveries retrieves the delivery log of a webhook.
+func (h *Handler) GetWebhookDeliveries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	deliveries, err := h.webhooks.GetDeliveries(ctx, c.Param("id"))
+	if err != nil {
+		return problem.Respond(c, webhookErrorCode(err), err)
+	}
+
+	return c.JSON(http.StatusOK, &deliveries)
+}
+
+// bindSaveWebhookRequest binds and validates a webhook.
+func bindSaveWebhookRequest(c echo.Context) (models.SaveWebhookRequest, error) {
+	var req models.SaveWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return req, err
+	}
+
+	return req, req.Validate()
+}
+
+// webhookErrorCode returns the status code of an error returned by the webhook manager.
+func webhookErrorCode(err error) int {
+	if errors.Is(err, repository.ErrWebhookNotFound) {
+		return http.StatusNotFound
+	}
+
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,214 @@
+package taskmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	mocks "github.com/brionac626/taskManager/internal/repository/mocks"
+	"github.com/brionac626/taskManager/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandler_Webhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockWM := mocks.NewMockWebhookManager(ctrl)
+	handler := &Handler{webhooks: mockWM}
+
+	e := echo.New()
+	e.GET("/webhooks", handler.GetWebhooks)
+	e.POST("/webhooks", handler.CreateWebhook)
+	e.GET("/webhooks/:id", handler.GetWebhook)
+	e.PUT("/webhooks/:id", handler.UpdateWebhook)
+	e.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	e.GET("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
+
+	webhook := models.Webhook{ID: "w1", URL: "https://ci.example.com/hooks", Secret: "s3cr3t", Events: []models.TaskEventType{models.TaskCompleted}}
+	saveReqBody, err := json.Marshal(models.SaveWebhookRequest{URL: webhook.URL, Secret: webhook.Secret, Events: webhook.Events})
+	assert.NoError(t, err)
+	invalidSaveReqBody, err := json.Marshal(models.SaveWebhookRequest{URL: "ftp://ci.example.com", Events: []models.TaskEventType{"task.renamed"}})
+	assert.NoError(t, err)
+	deliveries := []models.WebhookDelivery{{ID: "d1", WebhookID: webhook.ID, EventID: 1, EventType: models.TaskCompleted, Attempt: 1, StatusCode: http.StatusOK, Succeeded: true}}
+
+	newRequest := func(method, target string, body []byte) *http.Request {
+		req := httptest.NewRequest(method, target, bytes.NewBuffer(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+		return req
+	}
+
+	type args struct {
+		rec *httptest.ResponseRecorder
+	}
+	tests := []struct {
+		name               string
+		mockSetup          func() *http.Request
+		args               args
+		expectedStatusCode int
+		expectedResponse   any
+	}{
+		{
+			name: "get webhooks",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetWebhooks(context.Background()).Return([]models.Webhook{webhook}, nil)
+
+				return newRequest(http.MethodGet, "/webhooks", nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   []models.Webhook{{ID: webhook.ID, URL: webhook.URL, Events: webhook.Events}},
+		},
+		{
+			name: "get webhooks failed",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetWebhooks(context.Background()).Return(nil, errors.New("storage error"))
+
+				return newRequest(http.MethodGet, "/webhooks", nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusInternalServerError},
+		},
+		{
+			name: "get webhook without its secret",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetWebhook(context.Background(), webhook.ID).Return(webhook, nil)
+
+				return newRequest(http.MethodGet, fmt.Sprintf("/webhooks/%s", webhook.ID), nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   models.Webhook{ID: webhook.ID, URL: webhook.URL, Events: webhook.Events},
+		},
+		{
+			name: "get webhook not found",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetWebhook(context.Background(), "unknown").Return(models.Webhook{}, repository.ErrWebhookNotFound)
+
+				return newRequest(http.MethodGet, "/webhooks/unknown", nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+		{
+			name: "create webhook",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().CreateWebhook(context.Background(), models.Webhook{
+					URL: webhook.URL, Secret: webhook.Secret, Events: webhook.Events,
+				}).Return(webhook, nil)
+
+				return newRequest(http.MethodPost, "/webhooks", saveReqBody)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusCreated,
+			expectedResponse:   models.Webhook{ID: webhook.ID, URL: webhook.URL, Events: webhook.Events},
+		},
+		{
+			name: "create webhook with invalid fields",
+			mockSetup: func() *http.Request {
+				return newRequest(http.MethodPost, "/webhooks", invalidSaveReqBody)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusBadRequest},
+		},
+		{
+			name: "update webhook",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().UpdateWebhook(context.Background(), models.Webhook{
+					ID: webhook.ID, URL: webhook.URL, Secret: webhook.Secret, Events: webhook.Events,
+				}).Return(nil)
+
+				return newRequest(http.MethodPut, fmt.Sprintf("/webhooks/%s", webhook.ID), saveReqBody)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "update webhook not found",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().UpdateWebhook(context.Background(), gomock.Any()).Return(repository.ErrWebhookNotFound)
+
+				return newRequest(http.MethodPut, "/webhooks/unknown", saveReqBody)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+		{
+			name: "delete webhook",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().DeleteWebhook(context.Background(), webhook.ID).Return(nil)
+
+				return newRequest(http.MethodDelete, fmt.Sprintf("/webhooks/%s", webhook.ID), nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name: "get webhook deliveries",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetDeliveries(context.Background(), webhook.ID).Return(deliveries, nil)
+
+				return newRequest(http.MethodGet, fmt.Sprintf("/webhooks/%s/deliveries", webhook.ID), nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   deliveries,
+		},
+		{
+			name: "get deliveries of unknown webhook",
+			mockSetup: func() *http.Request {
+				mockWM.EXPECT().GetDeliveries(context.Background(), "unknown").Return(nil, repository.ErrWebhookNotFound)
+
+				return newRequest(http.MethodGet, "/webhooks/unknown/deliveries", nil)
+			},
+			args:               args{rec: httptest.NewRecorder()},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   models.ErrorResponse{Code: http.StatusNotFound},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.mockSetup()
+
+			e.ServeHTTP(tt.args.rec, req)
+
+			assert.Equal(t, tt.expectedStatusCode, tt.args.rec.Result().StatusCode)
+
+			body, err := io.ReadAll(tt.args.rec.Body)
+			assert.NoError(t, err)
+
+			switch expected := tt.expectedResponse.(type) {
+			case models.ErrorResponse:
+				var resp models.ErrorResponse
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected.Code, resp.Code)
+			case models.Webhook:
+				var resp models.Webhook
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+				assert.NotContains(t, string(body), webhook.Secret)
+			case []models.Webhook:
+				var resp []models.Webhook
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+				assert.NotContains(t, string(body), webhook.Secret)
+			case []models.WebhookDelivery:
+				var resp []models.WebhookDelivery
+				assert.NoError(t, json.Unmarshal(body, &resp))
+				assert.Equal(t, expected, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package undo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisStacks keeps the stacks of every user in Redis, shared by the replicas of the server. The stacks of a user
+// are the lists <prefix>:undo:<user> and <prefix>:redo:<user> of the encoded operations, last one at the tail.
+type RedisStacks struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ Stacks = (*RedisStacks)(nil)
+
+// NewRedisStacks creates stacks kept in Redis under the keys with the prefix.
+func NewRedisStacks(client redis.UniversalClient, prefix string) *RedisStacks {
+	return &RedisStacks{client: client, prefix: prefix}
+}
+
+// key returns the key of the stack of the user.
+func (r *RedisStacks) key(user string, stack Stack) string {
+	return r.prefix + ":" + string(stack) + ":" + user
+}
+
+// Record pushes the operation onto the undo stack of the user keeping its last limit operations,
+// and empties the redo stack of the user, in a single transaction.
+func (r *RedisStacks) Record(ctx context.Context, user string, op models.Operation, limit int) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.RPush(ctx, r.key(user, UndoStack), data)
+	pipe.LTrim(ctx, r.key(user, UndoStack), int64(-limit), -1)
+	pipe.Del(ctx, r.key(user, RedoStack))
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
+
+// Push pushes the operation onto the stack of the user.
+func (r *RedisStacks) Push(ctx context.Context, user string, stack Stack, op models.Operation) error {
+	data, err := json.Marshal(op)
+	if err != nil {
+		return err
+	}
+
+	return r.client.RPush(ctx, r.key(user, stack), data).Err()
+}
+
+// Pop removes the last operation of the stack of the user and returns it, or false when the stack is empty.
+// The operation is removed atomically, so that a single replica undoes or redoes it.
+func (r *RedisStacks) Pop(ctx context.Context, user string, stack Stack) (models.Operation, bool, error) {
+	data, err := r.client.RPop(ctx, r.key(user, stack)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return models.Operation{}, false, nil
+	}
+	if err != nil {
+		return models.Operation{}, false, err
+	}
+
+	var op models.Operation
+	if err := json.Unmarshal(data, &op); err != nil {
+		return models.Operation{}, false, err
+	}
+
+	return op, true, nil
+}
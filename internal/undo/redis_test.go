@@ -0,0 +1,57 @@
+package undo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/brionac626/taskManager/models"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_RedisStacks(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// the replicas share the stacks and the task manager
+	tracker, _ := newTracker(t, 2)
+	history := NewHistory(tracker.TaskManager, 2, WithStacks(NewRedisStacks(client, "{tasks}")))
+	replica := NewHistory(tracker.TaskManager, 2, WithStacks(NewRedisStacks(client, "{tasks}")))
+	tracker = Track(history)
+	alice := WithUser(ctx, "alice")
+
+	for _, name := range []string{"Task 1", "Task 2", "Task 3"} {
+		assert.NoError(t, tracker.CreateTasks(alice, []models.Task{{Name: name}}))
+	}
+
+	op, err := replica.Undo(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "Task 3", op.Changes[0].After.Name)
+
+	op, err = history.Redo(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "Task 3", op.Changes[0].After.Name)
+
+	tasks, err := tracker.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 3)
+
+	// only the last two operations are kept
+	for _, expected := range []string{"Task 3", "Task 2"} {
+		op, err := replica.Undo(ctx, "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, op.Changes[0].After.Name)
+	}
+
+	_, err = history.Undo(ctx, "alice")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	// a new operation can no longer redo the undone operations
+	assert.NoError(t, tracker.CreateTasks(alice, []models.Task{{Name: "Task 4"}}))
+	_, err = replica.Redo(ctx, "alice")
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+	assert.False(t, server.Exists("{tasks}:redo:alice"))
+}
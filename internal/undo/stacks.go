@@ -0,0 +1,90 @@
+package undo
+
+import (
+	"context"
+	"sync"
+
+	"github.com/brionac626/taskManager/models"
+)
+
+// userStacks are the operations of a user.
+type userStacks struct {
+	undo []models.Operation
+	redo []models.Operation
+}
+
+// MemoryStacks keeps the stacks of every user in the memory.
+type MemoryStacks struct {
+	mu    sync.Mutex
+	users map[string]*userStacks
+}
+
+var _ Stacks = (*MemoryStacks)(nil)
+
+// NewMemoryStacks creates empty stacks kept in the memory.
+func NewMemoryStacks() *MemoryStacks {
+	return &MemoryStacks{users: make(map[string]*userStacks)}
+}
+
+// Record pushes the operation onto the undo stack of the user keeping its last limit operations,
+// and empties the redo stack of the user.
+func (m *MemoryStacks) Record(_ context.Context, user string, op models.Operation, limit int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.user(user)
+	s.undo = append(s.undo, op)
+	if len(s.undo) > limit {
+		s.undo = append(s.undo[:0:0], s.undo[len(s.undo)-limit:]...)
+	}
+	s.redo = nil
+
+	return nil
+}
+
+// Push pushes the operation onto the stack of the user.
+func (m *MemoryStacks) Push(_ context.Context, user string, stack Stack, op models.Operation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ops := m.stack(user, stack)
+	*ops = append(*ops, op)
+
+	return nil
+}
+
+// Pop removes the last operation of the stack of the user and returns it, or false when the stack is empty.
+func (m *MemoryStacks) Pop(_ context.Context, user string, stack Stack) (models.Operation, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ops := m.stack(user, stack)
+	if len(*ops) == 0 {
+		return models.Operation{}, false, nil
+	}
+
+	op := (*ops)[len(*ops)-1]
+	*ops = (*ops)[:len(*ops)-1]
+
+	return op, true, nil
+}
+
+// user returns the stacks of the user, creating them when missing. The stacks must be locked.
+func (m *MemoryStacks) user(user string) *userStacks {
+	s, exists := m.users[user]
+	if !exists {
+		s = &userStacks{}
+		m.users[user] = s
+	}
+
+	return s
+}
+
+// stack returns the stack of the user. The stacks must be locked.
+func (m *MemoryStacks) stack(user string, stack Stack) *[]models.Operation {
+	if stack == RedoStack {
+		return &m.user(user).redo
+	}
+
+	return &m.user(user).undo
+}
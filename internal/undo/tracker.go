@@ -0,0 +1,193 @@
+package undo
+
+import (
+	"context"
+	"log"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+)
+
+type userKey struct{}
+
+// WithUser returns a copy of the context carrying the user making the task operations.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the user carried by the context, or an empty string when there is none.
+func UserFrom(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// Tracker is a task manager recording the operations made with a user in the context to a history.
+// Operations without a user are not recorded, and operations that change nothing are ignored.
+type Tracker struct {
+	repository.TaskManager
+	history *History
+}
+
+var _ repository.TaskManager = (*Tracker)(nil)
+
+// Track wraps the task manager of the history, so that the operations of every user are recorded to the history.
+func Track(history *History) *Tracker {
+	return &Tracker{TaskManager: history.repo, history: history}
+}
+
+// CreateTasks creates the tasks and records their creation
+func (t *Tracker) CreateTasks(ctx context.Context, tasks []models.Task) error {
+	if err := t.TaskManager.CreateTasks(ctx, tasks); err != nil {
+		return err
+	}
+
+	t.record(ctx, models.OperationCreate, createChanges(tasks))
+
+	return nil
+}
+
+// RestoreTasks restores the tasks and records their creation
+func (t *Tracker) RestoreTasks(ctx context.Context, tasks []models.Task) error {
+	if err := t.TaskManager.RestoreTasks(ctx, tasks); err != nil {
+		return err
+	}
+
+	t.record(ctx, models.OperationCreate, createChanges(tasks))
+
+	return nil
+}
+
+// UpdateTask updates the task and records its states before and after the update
+func (t *Tracker) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	before := t.before(ctx, []string{taskID})
+
+	if err := t.TaskManager.UpdateTask(ctx, taskID, name, status); err != nil {
+		return err
+	}
+
+	if task, exists := before[taskID]; exists {
+		t.record(ctx, models.OperationUpdate, updateChanges([]models.Task{task}, name, status))
+	}
+
+	return nil
+}
+
+// CompareAndSwapTask replaces the task if it is still the previous task and records its states before and after
+func (t *Tracker) CompareAndSwapTask(ctx context.Context, previous, task models.Task) error {
+	if err := t.TaskManager.CompareAndSwapTask(ctx, previous, task); err != nil {
+		return err
+	}
+
+	t.record(ctx, models.OperationUpdate, updateChanges([]models.Task{previous}, &task.Name, &task.Status))
+
+	return nil
+}
+
+// DeleteTask deletes the task and records its state before the deletion
+func (t *Tracker) DeleteTask(ctx context.Context, taskID string) error {
+	before := t.before(ctx, []string{taskID})
+
+	if err := t.TaskManager.DeleteTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	if task, exists := before[taskID]; exists {
+		t.record(ctx, models.OperationDelete, []models.TaskChange{{Before: &task}})
+	}
+
+	return nil
+}
+
+// BatchUpdateTasks updates the tasks and records the states of the updated tasks as a single operation
+func (t *Tracker) BatchUpdateTasks(ctx context.Context, taskIDs []string, name *string, status *int) ([]models.BatchResult, error) {
+	before := t.before(ctx, taskIDs)
+
+	results, err := t.TaskManager.BatchUpdateTasks(ctx, taskIDs, name, status)
+
+	updated := make([]models.Task, 0, len(results))
+	for _, result := range results {
+		if task, exists := before[result.ID]; exists && result.Outcome == models.BatchOutcomeUpdated {
+			updated = append(updated, task)
+		}
+	}
+	t.record(ctx, models.OperationUpdate, updateChanges(updated, name, status))
+
+	return results, err
+}
+
+// BatchDeleteTasks deletes the tasks and records the states of the deleted tasks as a single operation
+func (t *Tracker) BatchDeleteTasks(ctx context.Context, taskIDs []string) ([]models.BatchResult, error) {
+	before := t.before(ctx, taskIDs)
+
+	results, err := t.TaskManager.BatchDeleteTasks(ctx, taskIDs)
+
+	changes := make([]models.TaskChange, 0, len(results))
+	for _, result := range results {
+		if task, exists := before[result.ID]; exists && result.Outcome == models.BatchOutcomeDeleted {
+			changes = append(changes, models.TaskChange{Before: &task})
+		}
+	}
+	t.record(ctx, models.OperationDelete, changes)
+
+	return results, err
+}
+
+// before returns the current states of the tasks by id when the context has a user, missing tasks are skipped.
+func (t *Tracker) before(ctx context.Context, taskIDs []string) map[string]models.Task {
+	if UserFrom(ctx) == "" {
+		return nil
+	}
+
+	tasks := make(map[string]models.Task, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if task, err := t.TaskManager.GetTask(ctx, taskID); err == nil {
+			tasks[taskID] = task
+		}
+	}
+
+	return tasks
+}
+
+// record records the changes to the history of the user of the context, if any.
+func (t *Tracker) record(ctx context.Context, kind models.OperationKind, changes []models.TaskChange) {
+	user := UserFrom(ctx)
+	if user == "" || len(changes) == 0 {
+		return
+	}
+
+	// the operation is made, it only cannot be undone
+	if _, err := t.history.Record(ctx, user, kind, changes); err != nil {
+		log.Println("record task operation failed", err)
+	}
+}
+
+// updateChanges returns the changes of the tasks updated with the name and the status, skipping the unchanged tasks.
+func updateChanges(tasks []models.Task, name *string, status *int) []models.TaskChange {
+	changes := make([]models.TaskChange, 0, len(tasks))
+	for _, task := range tasks {
+		before, after := task, task
+		if name != nil {
+			after.Name = *name
+		}
+
+		if status != nil {
+			after.Status = *status
+		}
+
+		if before != after {
+			changes = append(changes, models.TaskChange{Before: &before, After: &after})
+		}
+	}
+
+	return changes
+}
+
+// createChanges returns the changes creating the tasks.
+func createChanges(tasks []models.Task) []models.TaskChange {
+	changes := make([]models.TaskChange, 0, len(tasks))
+	for _, task := range tasks {
+		changes = append(changes, models.TaskChange{After: &task})
+	}
+
+	return changes
+}
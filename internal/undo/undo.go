@@ -0,0 +1,263 @@
+// Package undo records the task operations of every user, so that each user can undo and redo their own operations.
+package undo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+)
+
+// DefaultLimit is the default number of operations kept for undoing per user
+const DefaultLimit = 50
+
+var (
+	// ErrNothingToUndo represents an error when the user has no operation to undo
+	ErrNothingToUndo = errors.New("nothing to undo")
+	// ErrNothingToRedo represents an error when the user has no undone operation to redo
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrConflict represents an error when a task was changed by someone else since the operation
+	ErrConflict = errors.New("task changed since the operation, it can no longer be undone or redone")
+	// ErrPartiallyApplied represents an error when an operation failed half way and its changes could not be reverted
+	ErrPartiallyApplied = errors.New("operation partially applied, it can no longer be undone or redone")
+)
+
+// Stack names one of the two stacks of the operations of a user.
+type Stack string
+
+const (
+	// UndoStack is the stack of the operations to undo
+	UndoStack Stack = "undo"
+	// RedoStack is the stack of the undone operations to redo
+	RedoStack Stack = "redo"
+)
+
+// Stacks keeps the undo and redo stacks of every user, the last operation of each stack is the next one
+// to undo or redo.
+type Stacks interface {
+	// Record pushes the operation onto the undo stack of the user keeping its last limit operations,
+	// and empties the redo stack of the user.
+	Record(ctx context.Context, user string, op models.Operation, limit int) error
+	// Push pushes the operation onto the stack of the user.
+	Push(ctx context.Context, user string, stack Stack, op models.Operation) error
+	// Pop removes the last operation of the stack of the user and returns it, or false when the stack is empty.
+	Pop(ctx context.Context, user string, stack Stack) (models.Operation, bool, error)
+}
+
+// History keeps the last operations of every user, and undoes and redoes them on a task manager.
+type History struct {
+	// mu serializes the undos and the redos of the history, the stacks pop every operation only once
+	mu     sync.Mutex
+	repo   repository.TaskManager
+	limit  int
+	stacks Stacks
+	now    func() time.Time
+}
+
+// Option configures the history created by NewHistory.
+type Option func(*History)
+
+// WithStacks sets the stacks of the operations, such as stacks shared by the replicas of the server.
+// The stacks are kept in the memory by default.
+func WithStacks(stacks Stacks) Option {
+	return func(h *History) {
+		h.stacks = stacks
+	}
+}
+
+// NewHistory creates a history undoing and redoing the operations on the task manager,
+// keeping at most limit operations per user. A non-positive limit falls back to DefaultLimit.
+// The task manager must not record the operations itself, otherwise undoing is recorded as a new operation.
+func NewHistory(repo repository.TaskManager, limit int, options ...Option) *History {
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	h := &History{
+		repo:   repo,
+		limit:  limit,
+		stacks: NewMemoryStacks(),
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(h)
+	}
+
+	return h
+}
+
+// Record records an operation of the user, it can no longer redo the operations undone before.
+func (h *History) Record(ctx context.Context, user string, kind models.OperationKind, changes []models.TaskChange) (models.Operation, error) {
+	op := models.Operation{Kind: kind, Changes: changes, Time: h.now().UTC()}
+	op.NewOperationID()
+
+	if err := h.stacks.Record(ctx, user, op, h.limit); err != nil {
+		return models.Operation{}, err
+	}
+
+	return op, nil
+}
+
+// Undo reverts the last operation of the user and returns it, the operation can then be redone.
+// When one of its tasks was changed since the operation, nothing is reverted and ErrConflict is returned:
+// the operation is discarded since it can no longer be undone. It is discarded as well when it was partially
+// reverted, otherwise it is kept for retrying. An error is returned along with the undone operation
+// when it cannot be pushed onto the redo stack.
+func (h *History) Undo(ctx context.Context, user string) (models.Operation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op, ok, err := h.stacks.Pop(ctx, user, UndoStack)
+	if err != nil {
+		return models.Operation{}, err
+	}
+
+	if !ok {
+		return models.Operation{}, ErrNothingToUndo
+	}
+
+	// the changes are reverted in the reverse order they were made
+	transitions := make([]transition, 0, len(op.Changes))
+	for i := len(op.Changes) - 1; i >= 0; i-- {
+		transitions = append(transitions, transition{from: op.Changes[i].After, to: op.Changes[i].Before})
+	}
+
+	if err := h.apply(ctx, transitions); err != nil {
+		return op, h.keep(ctx, user, UndoStack, op, err)
+	}
+
+	return op, h.stacks.Push(context.WithoutCancel(ctx), user, RedoStack, op)
+}
+
+// Redo makes again the last operation undone by the user and returns it, the operation can then be undone again.
+// When one of its tasks was changed since the undo, nothing is changed and ErrConflict is returned:
+// the operation is discarded since it can no longer be redone. It is discarded as well when it was partially
+// made again, otherwise it is kept for retrying. An error is returned along with the redone operation
+// when it cannot be pushed onto the undo stack.
+func (h *History) Redo(ctx context.Context, user string) (models.Operation, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	op, ok, err := h.stacks.Pop(ctx, user, RedoStack)
+	if err != nil {
+		return models.Operation{}, err
+	}
+
+	if !ok {
+		return models.Operation{}, ErrNothingToRedo
+	}
+
+	transitions := make([]transition, 0, len(op.Changes))
+	for _, change := range op.Changes {
+		transitions = append(transitions, transition{from: change.Before, to: change.After})
+	}
+
+	if err := h.apply(ctx, transitions); err != nil {
+		return op, h.keep(ctx, user, RedoStack, op, err)
+	}
+
+	return op, h.stacks.Push(context.WithoutCancel(ctx), user, UndoStack, op)
+}
+
+// keep pushes the operation that failed with err back onto its stack for retrying when nothing was changed,
+// and returns err.
+func (h *History) keep(ctx context.Context, user string, stack Stack, op models.Operation, err error) error {
+	if errors.Is(err, ErrConflict) || errors.Is(err, ErrPartiallyApplied) {
+		return err
+	}
+
+	if pushErr := h.stacks.Push(context.WithoutCancel(ctx), user, stack, op); pushErr != nil {
+		return errors.Join(err, pushErr)
+	}
+
+	return err
+}
+
+// transition changes a task from a state to another, a nil state is a task that does not exist.
+type transition struct {
+	from *models.Task
+	to   *models.Task
+}
+
+// taskID returns the id of the task changed by the transition.
+func (t transition) taskID() string {
+	if t.from != nil {
+		return t.from.ID
+	}
+
+	return t.to.ID
+}
+
+// apply checks that every task is still in its from state, then changes every task to its to state.
+// Every task is checked again just before it is changed. When a change fails, the changes already made are
+// reverted so that nothing is applied, and ErrPartiallyApplied is returned if they cannot be reverted.
+func (h *History) apply(ctx context.Context, transitions []transition) error {
+	for _, t := range transitions {
+		if err := h.check(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	for i, t := range transitions {
+		// the first task was just checked
+		var err error
+		if i > 0 {
+			err = h.check(ctx, t)
+		}
+		if err == nil {
+			err = h.write(ctx, t)
+		}
+
+		if err != nil {
+			if rollbackErr := h.rollback(ctx, transitions[:i]); rollbackErr != nil {
+				return errors.Join(err, ErrPartiallyApplied, rollbackErr)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check returns ErrConflict when the task of the transition is no longer in its from state.
+func (h *History) check(ctx context.Context, t transition) error {
+	current, err := h.repo.GetTask(ctx, t.taskID())
+	if err != nil && !errors.Is(err, repository.ErrTaskNotFound) {
+		return err
+	}
+
+	exists := err == nil
+	if exists != (t.from != nil) || (exists && current != *t.from) {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+// write changes the task of the transition to its to state.
+func (h *History) write(ctx context.Context, t transition) error {
+	switch {
+	case t.to == nil:
+		return h.repo.DeleteTask(ctx, t.from.ID)
+	case t.from == nil:
+		return h.repo.RestoreTasks(ctx, []models.Task{*t.to})
+	default:
+		return h.repo.UpdateTask(ctx, t.to.ID, &t.to.Name, &t.to.Status)
+	}
+}
+
+// rollback reverts the applied transitions in the reverse order, even when the context of the request is done.
+func (h *History) rollback(ctx context.Context, applied []transition) error {
+	ctx = context.WithoutCancel(ctx)
+	for i := len(applied) - 1; i >= 0; i-- {
+		if err := h.write(ctx, transition{from: applied[i].to, to: applied[i].from}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,276 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTracker returns a tracker recording to a new history, with the tasks of the in-memory repository deleted.
+func newTracker(t *testing.T, limit int) (*Tracker, *History) {
+	repo := repository.NewRepository()
+	tasks, err := repo.GetTasks(context.Background())
+	assert.NoError(t, err)
+	for _, task := range tasks {
+		assert.NoError(t, repo.DeleteTask(context.Background(), task.ID))
+	}
+
+	history := NewHistory(repo, limit)
+
+	return Track(history), history
+}
+
+func TestHistory_UndoRedo(t *testing.T) {
+	tracker, history := newTracker(t, 0)
+	alice := WithUser(context.Background(), "alice")
+
+	created := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+	assert.NoError(t, tracker.CreateTasks(alice, created))
+	task1, task2 := created[0], created[1]
+
+	name, status := "Renamed Task 1", 1
+	assert.NoError(t, tracker.UpdateTask(alice, task1.ID, &name, &status))
+	renamed := models.Task{ID: task1.ID, Name: name, Status: status}
+	assert.NoError(t, tracker.DeleteTask(alice, task2.ID))
+
+	tests := []struct {
+		name     string
+		do       func(ctx context.Context, user string) (models.Operation, error)
+		kind     models.OperationKind
+		expected []models.Task
+	}{
+		{name: "undo delete", do: history.Undo, kind: models.OperationDelete, expected: []models.Task{renamed, task2}},
+		{name: "undo update", do: history.Undo, kind: models.OperationUpdate, expected: []models.Task{task1, task2}},
+		{name: "undo create", do: history.Undo, kind: models.OperationCreate, expected: []models.Task{}},
+		{name: "redo create", do: history.Redo, kind: models.OperationCreate, expected: []models.Task{task1, task2}},
+		{name: "redo update", do: history.Redo, kind: models.OperationUpdate, expected: []models.Task{renamed, task2}},
+		{name: "redo delete", do: history.Redo, kind: models.OperationDelete, expected: []models.Task{renamed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op, err := tt.do(context.Background(), "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.kind, op.Kind)
+
+			tasks, err := tracker.GetTasks(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, tasks)
+		})
+	}
+
+	_, err := history.Redo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+
+	// a new operation clears the operations to redo
+	_, err = history.Undo(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.CreateTasks(alice, []models.Task{{Name: "Task 3"}}))
+	_, err = history.Redo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrNothingToRedo)
+}
+
+func TestHistory_Users(t *testing.T) {
+	tracker, history := newTracker(t, 0)
+	ctx := context.Background()
+
+	assert.NoError(t, tracker.CreateTasks(WithUser(ctx, "alice"), []models.Task{{Name: "Task 1"}}))
+	assert.NoError(t, tracker.CreateTasks(ctx, []models.Task{{Name: "Task 2"}}))
+
+	_, err := history.Undo(ctx, "bob")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	op, err := history.Undo(ctx, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, "Task 1", op.Changes[0].After.Name)
+
+	// operations without a user are not recorded
+	_, err = history.Undo(ctx, "alice")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	tasks, err := tracker.GetTasks(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "Task 2", tasks[0].Name)
+}
+
+func TestHistory_Conflict(t *testing.T) {
+	tracker, history := newTracker(t, 0)
+	alice, bob := WithUser(context.Background(), "alice"), WithUser(context.Background(), "bob")
+
+	created := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, tracker.CreateTasks(alice, created))
+	name := "Renamed by alice"
+	assert.NoError(t, tracker.UpdateTask(alice, created[0].ID, &name, nil))
+
+	other := "Renamed by bob"
+	assert.NoError(t, tracker.UpdateTask(bob, created[0].ID, &other, nil))
+
+	_, err := history.Undo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrConflict)
+
+	task, err := tracker.GetTask(context.Background(), created[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, other, task.Name)
+
+	// the conflicting operation is discarded, the creation is next but the task changed since too
+	_, err = history.Undo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = history.Undo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+
+	// bob undoes his own rename, then alice deletes the task before bob redoes it
+	_, err = history.Undo(context.Background(), "bob")
+	assert.NoError(t, err)
+	assert.NoError(t, tracker.DeleteTask(alice, created[0].ID))
+	_, err = history.Redo(context.Background(), "bob")
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestHistory_Batch(t *testing.T) {
+	tracker, history := newTracker(t, 0)
+	alice := WithUser(context.Background(), "alice")
+
+	created := []models.Task{{Name: "Task 1"}, {Name: "Task 2", Status: 1}, {Name: "Task 3"}}
+	assert.NoError(t, tracker.CreateTasks(alice, created))
+
+	status := 1
+	_, err := tracker.BatchUpdateTasks(alice, []string{created[0].ID, created[1].ID, "unknown"}, nil, &status)
+	assert.NoError(t, err)
+	_, err = tracker.BatchDeleteTasks(alice, []string{created[0].ID, created[2].ID})
+	assert.NoError(t, err)
+
+	op, err := history.Undo(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OperationDelete, op.Kind)
+	assert.Len(t, op.Changes, 2)
+
+	// the second task was already done, only the first one changed
+	op, err = history.Undo(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OperationUpdate, op.Kind)
+	assert.Equal(t, []models.TaskChange{{Before: &created[0], After: &models.Task{ID: created[0].ID, Name: "Task 1", Status: 1}}}, op.Changes)
+
+	tasks, err := tracker.GetTasks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, created, tasks)
+}
+
+func TestHistory_Limit(t *testing.T) {
+	tracker, history := newTracker(t, 2)
+	alice := WithUser(context.Background(), "alice")
+
+	for _, name := range []string{"Task 1", "Task 2", "Task 3"} {
+		assert.NoError(t, tracker.CreateTasks(alice, []models.Task{{Name: name}}))
+	}
+
+	for _, expected := range []string{"Task 3", "Task 2"} {
+		op, err := history.Undo(context.Background(), "alice")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, op.Changes[0].After.Name)
+	}
+
+	_, err := history.Undo(context.Background(), "alice")
+	assert.ErrorIs(t, err, ErrNothingToUndo)
+}
+
+var errUpdateFailed = errors.New("update failed")
+
+// failingRepo fails the updates of the task manager whose number, counted from 1, is in fail.
+type failingRepo struct {
+	repository.TaskManager
+	updates int
+	fail    map[int]bool
+}
+
+func (f *failingRepo) UpdateTask(ctx context.Context, taskID string, name *string, status *int) error {
+	f.updates++
+	if f.fail[f.updates] {
+		return errUpdateFailed
+	}
+
+	return f.TaskManager.UpdateTask(ctx, taskID, name, status)
+}
+
+func TestHistory_FailedWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    map[int]bool
+		wantErr error
+		kept    bool
+	}{
+		// reverting the first task fails, the second task is updated again and the operation is kept for retrying
+		{name: "rolled back", fail: map[int]bool{2: true}, wantErr: errUpdateFailed, kept: true},
+		// updating the second task again fails too, the operation is discarded
+		{name: "partially applied", fail: map[int]bool{2: true, 3: true}, wantErr: ErrPartiallyApplied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker, _ := newTracker(t, 0)
+			repo := &failingRepo{TaskManager: tracker.TaskManager}
+			tracker = Track(NewHistory(repo, 0))
+			history := tracker.history
+			alice := WithUser(context.Background(), "alice")
+
+			created := []models.Task{{Name: "Task 1"}, {Name: "Task 2"}}
+			assert.NoError(t, tracker.CreateTasks(alice, created))
+			status := 1
+			_, err := tracker.BatchUpdateTasks(alice, []string{created[0].ID, created[1].ID}, nil, &status)
+			assert.NoError(t, err)
+			done, err := tracker.GetTasks(context.Background())
+			assert.NoError(t, err)
+
+			// the second task is reverted first
+			repo.updates, repo.fail = 0, tt.fail
+			_, err = history.Undo(context.Background(), "alice")
+			assert.ErrorIs(t, err, tt.wantErr)
+			repo.fail = nil
+
+			if tt.kept {
+				tasks, err := tracker.GetTasks(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, done, tasks)
+			}
+
+			op, err := history.Undo(context.Background(), "alice")
+			if tt.kept {
+				assert.NoError(t, err)
+				assert.Equal(t, models.OperationUpdate, op.Kind)
+
+				tasks, err := tracker.GetTasks(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, created, tasks)
+			} else {
+				// the creation is next, but the first task is still done
+				assert.ErrorIs(t, err, ErrConflict)
+			}
+		})
+	}
+}
+
+func TestTracker_CompareAndSwapTask(t *testing.T) {
+	tracker, history := newTracker(t, 0)
+	alice := WithUser(context.Background(), "alice")
+
+	created := []models.Task{{Name: "Task 1"}}
+	assert.NoError(t, tracker.CreateTasks(alice, created))
+	task := created[0]
+	patched := models.Task{ID: task.ID, Name: "Patched Task 1", Status: 1}
+
+	// a stale previous task is neither replaced nor recorded
+	err := tracker.CompareAndSwapTask(alice, models.Task{ID: task.ID, Name: "Stale Task 1"}, patched)
+	assert.ErrorIs(t, err, repository.ErrTaskChanged)
+
+	assert.NoError(t, tracker.CompareAndSwapTask(alice, task, patched))
+
+	op, err := history.Undo(context.Background(), "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, models.OperationUpdate, op.Kind)
+
+	tasks, err := tracker.GetTasks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Task{task}, tasks)
+}
@@ -0,0 +1,353 @@
+// Package webhook delivers the task events to the webhook subscriptions.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+)
+
+const (
+	// HeaderSignature carries the hex encoded HMAC-SHA256 of the payload keyed by the webhook secret, prefixed with sha256=
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderEvent carries the type of the delivered event
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderEventID carries the id of the delivered event, the same for every attempt
+	HeaderEventID = "X-Webhook-Event-ID"
+	// HeaderAttempt carries the attempt number of the delivery, starting at 1
+	HeaderAttempt = "X-Webhook-Attempt"
+
+	signaturePrefix = "sha256="
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts to deliver an event
+	DefaultMaxAttempts = 5
+	// DefaultBackoff is the default delay before the first retry, doubled after every failed attempt
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff is the default upper bound of the delay between two attempts
+	DefaultMaxBackoff = time.Minute
+	// DefaultTimeout is the default time allowed for the receiver to respond
+	DefaultTimeout = 10 * time.Second
+)
+
+// ErrAddressNotAllowed represents an error when a webhook URL resolves to an address of the internal network
+var ErrAddressNotAllowed = errors.New("webhook address is not a public address")
+
+// internalPrefixes are the ranges of the addresses that are not routed on the internet but are global unicast
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),     // this network
+	netip.MustParsePrefix("100.64.0.0/10"), // shared address space
+}
+
+// NewClient returns the HTTP client of the dispatchers by default. It refuses to connect to the loopback,
+// private, link-local and other internal addresses once the host of the URL is resolved, so that a webhook
+// cannot reach the services of the internal network, redirects included. No proxy is used for the same reason.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: checkAddress}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// checkAddress refuses the connections to an address that is not public, it is called with the resolved address.
+func checkAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+
+	if !IsPublic(addr) {
+		return fmt.Errorf("%w: %s", ErrAddressNotAllowed, addr)
+	}
+
+	return nil
+}
+
+// IsPublic reports whether the address is a global unicast address of the internet, which excludes the loopback,
+// private, link-local, multicast and unspecified addresses.
+func IsPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sign returns the signature of the payload sent in the X-Webhook-Signature header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that the signature of the X-Webhook-Signature header matches the payload.
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// Claimer claims the delivery of an event to a webhook, so that the dispatchers of the replicas sharing
+// the webhooks and the events deliver every event to a webhook once.
+type Claimer interface {
+	// ClaimDelivery returns true for the first claim of the delivery of the event to the webhook.
+	ClaimDelivery(ctx context.Context, webhookID string, eventID uint64) (bool, error)
+}
+
+// Dispatcher delivers the task events of a bus to the webhooks accepting them.
+type Dispatcher struct {
+	webhooks    repository.WebhookManager
+	claimer     Claimer
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+
+	wg sync.WaitGroup
+}
+
+// Option configures the dispatcher created by NewDispatcher.
+type Option func(*Dispatcher)
+
+// WithClient sets the HTTP client sending the deliveries, instead of the client of NewClient
+// refusing the internal addresses.
+func WithClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithClaimer delivers an event to a webhook only when the dispatcher claims the delivery from the claimer,
+// such as the webhook manager shared by the replicas. Every event is delivered by default.
+func WithClaimer(claimer Claimer) Option {
+	return func(d *Dispatcher) {
+		d.claimer = claimer
+	}
+}
+
+// WithMaxAttempts sets the number of attempts to deliver an event before giving up.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the upper bound of the delay between two attempts.
+func WithBackoff(backoff, maxBackoff time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.backoff = backoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// NewDispatcher creates a dispatcher delivering the task events to the webhooks of the manager.
+func NewDispatcher(webhooks repository.WebhookManager, options ...Option) *Dispatcher {
+	d := &Dispatcher{
+		webhooks:    webhooks,
+		client:      NewClient(DefaultTimeout),
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+	}
+	for _, option := range options {
+		option(d)
+	}
+
+	if d.maxAttempts < 1 {
+		d.maxAttempts = 1
+	}
+
+	return d
+}
+
+// Start delivers the events published on the bus from now on until the context is done.
+// Events published while the dispatcher is too slow to receive them are resumed from the replay buffer of the bus.
+func (d *Dispatcher) Start(ctx context.Context, bus *events.Bus) {
+	// the events published since LastEventID are replayed by Subscribe
+	lastEventID := bus.LastEventID()
+	sub, replay, _ := bus.Subscribe(lastEventID)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		for {
+			for _, event := range replay {
+				d.Dispatch(ctx, event)
+				lastEventID = event.ID
+			}
+
+			if !d.receive(ctx, sub, &lastEventID) {
+				break
+			}
+
+			var complete bool
+			sub, replay, complete = bus.Subscribe(lastEventID)
+			if !complete {
+				log.Println("webhook deliveries missed, events were dropped from the replay buffer")
+			}
+		}
+		sub.Close()
+	}()
+}
+
+// receive dispatches the events of the subscription, it returns false when the context is done.
+func (d *Dispatcher) receive(ctx context.Context, sub *events.Subscription, lastEventID *uint64) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-sub.Events():
+			if !ok {
+				return true
+			}
+
+			d.Dispatch(ctx, event)
+			*lastEventID = event.ID
+		}
+	}
+}
+
+// Dispatch starts the delivery of the event to every webhook accepting its type.
+func (d *Dispatcher) Dispatch(ctx context.Context, event models.TaskEvent) {
+	webhooks, err := d.webhooks.GetWebhooks(ctx)
+	if err != nil {
+		log.Println("get webhooks failed", err)
+		return
+	}
+
+	payload, err := json.Marshal(&event)
+	if err != nil {
+		log.Println("encode webhook payload failed", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		if !webhook.Accepts(event.Type) {
+			continue
+		}
+
+		if d.claimer != nil {
+			claimed, err := d.claimer.ClaimDelivery(ctx, webhook.ID, event.ID)
+			if err != nil {
+				log.Println("claim webhook delivery failed", err)
+				continue
+			}
+
+			if !claimed {
+				// delivered by another replica
+				continue
+			}
+		}
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(ctx, webhook, event, payload)
+		}()
+	}
+}
+
+// Wait waits for the dispatcher to stop and for the pending deliveries.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// deliver sends the payload to the webhook until it is accepted, retrying with an exponential backoff.
+// Every attempt is recorded in the delivery log of the webhook.
+func (d *Dispatcher) deliver(ctx context.Context, webhook models.Webhook, event models.TaskEvent, payload []byte) {
+	backoff := d.backoff
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		delivery := d.attempt(ctx, webhook, event, payload, attempt)
+		if err := d.webhooks.AddDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+			// the webhook was deleted, stop delivering to it
+			return
+		}
+
+		if delivery.Succeeded || attempt == d.maxAttempts {
+			return
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		backoff = min(backoff*2, d.maxBackoff)
+	}
+}
+
+// attempt sends the payload to the webhook once, any 2xx response is a success.
+func (d *Dispatcher) attempt(ctx context.Context, webhook models.Webhook, event models.TaskEvent, payload []byte, attempt int) models.WebhookDelivery {
+	delivery := models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		Attempt:   attempt,
+		Time:      time.Now().UTC(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, payload))
+	req.Header.Set(HeaderEvent, string(event.Type))
+	req.Header.Set(HeaderEventID, strconv.FormatUint(event.ID, 10))
+	req.Header.Set(HeaderAttempt, strconv.Itoa(attempt))
+
+	res, err := d.client.Do(req)
+	delivery.Duration = time.Since(delivery.Time)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+
+	delivery.StatusCode = res.StatusCode
+	delivery.Succeeded = res.StatusCode >= 200 && res.StatusCode < 300
+	if !delivery.Succeeded {
+		delivery.Error = fmt.Sprintf("unexpected status %d", res.StatusCode)
+	}
+
+	return delivery
+}
@@ -0,0 +1,302 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/brionac626/taskManager/internal/events"
+	"github.com/brionac626/taskManager/internal/repository"
+	"github.com/brionac626/taskManager/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiver records the deliveries of a local webhook endpoint, failing the first attempts.
+type receiver struct {
+	t        *testing.T
+	secret   string
+	failures int
+
+	mu       sync.Mutex
+	received []models.TaskEvent
+	attempts []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	assert.NoError(r.t, err)
+	assert.True(r.t, Verify(r.secret, body, req.Header.Get(HeaderSignature)), "invalid signature")
+
+	attempt, err := strconv.Atoi(req.Header.Get(HeaderAttempt))
+	assert.NoError(r.t, err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.attempts = append(r.attempts, attempt)
+	if attempt <= r.failures {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	var event models.TaskEvent
+	assert.NoError(r.t, json.Unmarshal(body, &event))
+	assert.Equal(r.t, string(event.Type), req.Header.Get(HeaderEvent))
+	assert.Equal(r.t, strconv.FormatUint(event.ID, 10), req.Header.Get(HeaderEventID))
+	r.received = append(r.received, event)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestSign(t *testing.T) {
+	payload := []byte(`{"id":1}`)
+	signature := Sign("secret", payload)
+
+	assert.Equal(t, "sha256=03def589620c813f198fd03d7967e292b163ef0435ebf43071ce0e9519763cb7", signature)
+	assert.True(t, Verify("secret", payload, signature))
+	assert.False(t, Verify("other", payload, signature))
+	assert.False(t, Verify("secret", []byte(`{"id":2}`), signature))
+}
+
+func TestDispatcher_Dispatch(t *testing.T) {
+	task := models.Task{ID: "task1", Name: "Task 1", Status: 1}
+
+	tests := []struct {
+		name             string
+		failures         int
+		events           []models.TaskEventType
+		eventType        models.TaskEventType
+		wantAttempts     []int
+		wantDeliveries   []models.WebhookDelivery
+		wantReceivedOnce bool
+	}{
+		{
+			name:         "deliver on first attempt",
+			eventType:    models.TaskCompleted,
+			wantAttempts: []int{1},
+			wantDeliveries: []models.WebhookDelivery{
+				{EventID: 1, EventType: models.TaskCompleted, Attempt: 1, StatusCode: http.StatusNoContent, Succeeded: true},
+			},
+			wantReceivedOnce: true,
+		},
+		{
+			name:         "retry failed deliveries",
+			failures:     2,
+			eventType:    models.TaskCompleted,
+			wantAttempts: []int{1, 2, 3},
+			wantDeliveries: []models.WebhookDelivery{
+				{EventID: 1, EventType: models.TaskCompleted, Attempt: 3, StatusCode: http.StatusNoContent, Succeeded: true},
+				{EventID: 1, EventType: models.TaskCompleted, Attempt: 2, StatusCode: http.StatusServiceUnavailable, Error: "unexpected status 503"},
+				{EventID: 1, EventType: models.TaskCompleted, Attempt: 1, StatusCode: http.StatusServiceUnavailable, Error: "unexpected status 503"},
+			},
+			wantReceivedOnce: true,
+		},
+		{
+			name:         "give up after the last attempt",
+			failures:     10,
+			eventType:    models.TaskDeleted,
+			wantAttempts: []int{1, 2, 3},
+			wantDeliveries: []models.WebhookDelivery{
+				{EventID: 1, EventType: models.TaskDeleted, Attempt: 3, StatusCode: http.StatusServiceUnavailable, Error: "unexpected status 503"},
+				{EventID: 1, EventType: models.TaskDeleted, Attempt: 2, StatusCode: http.StatusServiceUnavailable, Error: "unexpected status 503"},
+				{EventID: 1, EventType: models.TaskDeleted, Attempt: 1, StatusCode: http.StatusServiceUnavailable, Error: "unexpected status 503"},
+			},
+		},
+		{
+			name:           "skip filtered event types",
+			events:         []models.TaskEventType{models.TaskCompleted, models.TaskDeleted},
+			eventType:      models.TaskCreated,
+			wantDeliveries: []models.WebhookDelivery{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &receiver{t: t, secret: "s3cr3t", failures: tt.failures}
+			server := httptest.NewServer(rec)
+			defer server.Close()
+
+			webhooks := repository.NewWebhookRepository()
+			webhook, err := webhooks.CreateWebhook(context.Background(), models.Webhook{URL: server.URL, Secret: rec.secret, Events: tt.events})
+			assert.NoError(t, err)
+
+			d := NewDispatcher(webhooks, WithClient(server.Client()), WithMaxAttempts(3), WithBackoff(time.Millisecond, 2*time.Millisecond))
+			d.Dispatch(context.Background(), models.TaskEvent{ID: 1, Type: tt.eventType, Task: task})
+			d.Wait()
+
+			assert.Equal(t, tt.wantAttempts, rec.attempts)
+			if tt.wantReceivedOnce {
+				assert.Equal(t, []models.TaskEvent{{ID: 1, Type: tt.eventType, Task: task}}, rec.received)
+			} else {
+				assert.Empty(t, rec.received)
+			}
+
+			deliveries, err := webhooks.GetDeliveries(context.Background(), webhook.ID)
+			assert.NoError(t, err)
+			for i := range deliveries {
+				assert.NotEmpty(t, deliveries[i].ID)
+				assert.Equal(t, webhook.ID, deliveries[i].WebhookID)
+				deliveries[i].ID = ""
+				deliveries[i].WebhookID = ""
+				deliveries[i].Duration = 0
+				deliveries[i].Time = time.Time{}
+			}
+			assert.Equal(t, tt.wantDeliveries, deliveries)
+		})
+	}
+}
+
+func TestDispatcher_DeliveryFailure(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	webhooks := repository.NewWebhookRepository()
+	webhook, err := webhooks.CreateWebhook(context.Background(), models.Webhook{URL: server.URL, Secret: "s3cr3t"})
+	assert.NoError(t, err)
+
+	d := NewDispatcher(webhooks, WithClient(server.Client()), WithMaxAttempts(1))
+	d.Dispatch(context.Background(), models.TaskEvent{ID: 1, Type: models.TaskCreated})
+	d.Wait()
+
+	deliveries, err := webhooks.GetDeliveries(context.Background(), webhook.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Succeeded)
+	assert.Zero(t, deliveries[0].StatusCode)
+	assert.NotEmpty(t, deliveries[0].Error)
+}
+
+func TestDispatcher_Start(t *testing.T) {
+	rec := &receiver{t: t, secret: "s3cr3t"}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	webhooks := repository.NewWebhookRepository()
+	_, err := webhooks.CreateWebhook(context.Background(), models.Webhook{URL: server.URL, Secret: rec.secret})
+	assert.NoError(t, err)
+
+	bus := events.NewBus(events.DefaultBufferSize)
+	bus.Publish(models.TaskCreated, models.Task{ID: "before"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d := NewDispatcher(webhooks, WithClient(server.Client()))
+	d.Start(ctx, bus)
+
+	published := bus.Publish(models.TaskDeleted, models.Task{ID: "task1"})
+	assert.Eventually(t, func() bool {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+
+		return len(rec.received) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	d.Wait()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	assert.Equal(t, published.ID, rec.received[0].ID)
+	assert.Equal(t, "task1", rec.received[0].Task.ID)
+}
+
+// claimer claims every delivery once, like the webhooks shared by the replicas.
+type claimer struct {
+	mu      sync.Mutex
+	claimed map[string]bool
+}
+
+func (c *claimer) ClaimDelivery(_ context.Context, webhookID string, eventID uint64) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := webhookID + ":" + strconv.FormatUint(eventID, 10)
+	if c.claimed[key] {
+		return false, nil
+	}
+	c.claimed[key] = true
+
+	return true, nil
+}
+
+func TestDispatcher_Claimer(t *testing.T) {
+	rec := &receiver{t: t, secret: "s3cr3t"}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	webhooks := repository.NewWebhookRepository()
+	_, err := webhooks.CreateWebhook(context.Background(), models.Webhook{URL: server.URL, Secret: rec.secret})
+	assert.NoError(t, err)
+
+	// the dispatchers of two replicas receive the same events
+	claims := &claimer{claimed: make(map[string]bool)}
+	replicas := []*Dispatcher{
+		NewDispatcher(webhooks, WithClient(server.Client()), WithClaimer(claims)),
+		NewDispatcher(webhooks, WithClient(server.Client()), WithClaimer(claims)),
+	}
+	for _, event := range []models.TaskEvent{{ID: 1, Type: models.TaskCreated}, {ID: 2, Type: models.TaskDeleted}} {
+		for _, d := range replicas {
+			d.Dispatch(context.Background(), event)
+		}
+	}
+	for _, d := range replicas {
+		d.Wait()
+	}
+
+	assert.Equal(t, []int{1, 1}, rec.attempts)
+	assert.ElementsMatch(t, []models.TaskEvent{{ID: 1, Type: models.TaskCreated}, {ID: 2, Type: models.TaskDeleted}}, rec.received)
+}
+
+func TestDispatcher_InternalAddress(t *testing.T) {
+	rec := &receiver{t: t, secret: "s3cr3t"}
+	server := httptest.NewServer(rec)
+	defer server.Close()
+
+	webhooks := repository.NewWebhookRepository()
+	webhook, err := webhooks.CreateWebhook(context.Background(), models.Webhook{URL: server.URL, Secret: rec.secret})
+	assert.NoError(t, err)
+
+	// the default client refuses the loopback address of the test server
+	d := NewDispatcher(webhooks, WithMaxAttempts(1))
+	d.Dispatch(context.Background(), models.TaskEvent{ID: 1, Type: models.TaskCreated})
+	d.Wait()
+
+	assert.Empty(t, rec.attempts)
+	deliveries, err := webhooks.GetDeliveries(context.Background(), webhook.ID)
+	assert.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+	assert.False(t, deliveries[0].Succeeded)
+	assert.Contains(t, deliveries[0].Error, ErrAddressNotAllowed.Error())
+}
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{addr: "93.184.215.14", want: true},
+		{addr: "2606:2800:21f:cb07:6820:80da:af6b:8b2c", want: true},
+		{addr: "127.0.0.1", want: false},
+		{addr: "::1", want: false},
+		{addr: "10.0.0.1", want: false},
+		{addr: "172.16.0.1", want: false},
+		{addr: "192.168.1.1", want: false},
+		{addr: "fd00::1", want: false},
+		{addr: "169.254.169.254", want: false},
+		{addr: "fe80::1", want: false},
+		{addr: "0.0.0.0", want: false},
+		{addr: "::", want: false},
+		{addr: "100.64.0.1", want: false},
+		{addr: "224.0.0.1", want: false},
+		{addr: "::ffff:127.0.0.1", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsPublic(netip.MustParseAddr(tt.addr)))
+		})
+	}
+}
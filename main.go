@@ -10,6 +10,7 @@ import (
 // @title Task Manager API
 // @version 1.0
 // @description This is a sample task manager server.
+// @description Errors are served as RFC 7807 problem details when the client accepts application/problem+json.
 
 // @contact.name API support
 // @contact.url https://github.com/brionac626/taskManager
@@ -21,6 +22,11 @@ import (
 // @host localhost:8080
 // @BasePath
 
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Admin token of the server as a bearer token: "Bearer <token>".
+
 func main() {
 	if err := cmd.Execute(); err != nil {
 		log.Println("Execute command error", err)
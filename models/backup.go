@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// RestoreResponse represents a restored backup archive.
+type RestoreResponse struct {
+	Version   int       `json:"version" example:"2"`                      // schema version of the archive
+	CreatedAt time.Time `json:"createdAt" example:"2026-10-18T09:00:00Z"` // time of the backup
+	Tasks     int       `json:"tasks" example:"42"`                       // number of restored tasks
+	Views     int       `json:"views" example:"3"`                        // number of restored views
+	Webhooks  int       `json:"webhooks" example:"1"`                     // number of restored webhooks
+}
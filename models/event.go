@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// TaskEventType represents the kind of change of a task event.
+type TaskEventType string
+
+const (
+	// TaskCreated represents the creation of a task
+	TaskCreated TaskEventType = "task.created"
+	// TaskUpdated represents a change of the fields of a task
+	TaskUpdated TaskEventType = "task.updated"
+	// TaskCompleted represents a task marked as done, published after its task.updated event
+	TaskCompleted TaskEventType = "task.completed"
+	// TaskDeleted represents the deletion of a task
+	TaskDeleted TaskEventType = "task.deleted"
+)
+
+// TaskEvent represents a change of a task published by the repository.
+type TaskEvent struct {
+	ID   uint64        `json:"id" example:"42"` // monotonically increasing event id
+	Type TaskEventType `json:"type" example:"task.created" enums:"task.created,task.updated,task.completed,task.deleted"`
+	Task Task          `json:"task"` // the task after the change, or its last state when deleted
+	Time time.Time     `json:"time" example:"2025-01-01T00:00:00Z"`
+}
+
+// TaskEventTypes lists every type of task event.
+var TaskEventTypes = []TaskEventType{TaskCreated, TaskUpdated, TaskCompleted, TaskDeleted}
@@ -12,8 +12,32 @@ var (
 	ErrTaskNameEmpty = errors.New("task name is empty")
 	// ErrInvalidStatus represents an error when the task status is invalid (not 0 or 1)
 	ErrInvalidStatus = errors.New("invalid status")
+	// ErrNoChanges represents an error when a request contains no changes
+	ErrNoChanges = errors.New("no changes provided")
+	// ErrBatchTargetMissing represents an error when a batch request has neither task ids nor a filter
+	ErrBatchTargetMissing = errors.New("either ids or filter must be provided")
+	// ErrBatchTargetAmbiguous represents an error when a batch request has both task ids and a filter
+	ErrBatchTargetAmbiguous = errors.New("ids and filter cannot be provided together")
+	// ErrNoTasksProvided represents an error when a request contains no tasks
+	ErrNoTasksProvided = errors.New("no tasks provided")
+	// ErrSearchQueryEmpty represents an error when the search query is empty
+	ErrSearchQueryEmpty = errors.New("search query is empty")
+	// ErrInvalidLimit represents an error when the requested number of results is out of range
+	ErrInvalidLimit = errors.New("invalid limit")
+	// ErrInvalidTimestamp represents an error when a timestamp is not in the RFC 3339 format
+	ErrInvalidTimestamp = errors.New("invalid timestamp, expected RFC 3339")
 )
 
+// FieldError represents a validation error of a single field.
+// Field errors can be nested, e.g. a task field error wrapped by the index of the task in a request.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (fe *FieldError) Error() string { return fe.Err.Error() }
+func (fe *FieldError) Unwrap() error { return fe.Err }
+
 // Task represents a task
 type Task struct {
 	ID     string `json:"id" example:"9bsv0s2hf8ng030mva9g"` // task id
@@ -26,23 +50,15 @@ func (t *Task) NewTaskID() {
 	t.ID = xid.New().String()
 }
 
-// Validate validates the task name and status and returns an error if the name is empty or the status is invalid
+// Validate validates the task name and status and returns the errors of every invalid field
 func (t *Task) Validate() error {
-	if err := t.ValidateName(); err != nil {
-		return err
-	}
-
-	if err := t.ValidateStatus(); err != nil {
-		return err
-	}
-
-	return nil
+	return errors.Join(t.ValidateName(), t.ValidateStatus())
 }
 
 // ValidateName validates the task name and returns an error if the name is empty
 func (t *Task) ValidateName() error {
 	if t.Name == "" {
-		return ErrTaskNameEmpty
+		return &FieldError{Field: "name", Err: ErrTaskNameEmpty}
 	}
 
 	return nil
@@ -55,7 +71,7 @@ func (t *Task) ValidateStatus() error {
 		return nil
 	}
 
-	return ErrInvalidStatus
+	return &FieldError{Field: "status", Err: ErrInvalidStatus}
 }
 
 // TasksByID attaches the methods of sort.Interface to []Task, sorting by ID
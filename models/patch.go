@@ -0,0 +1,90 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+const (
+	// MIMEApplicationMergePatchJSON is the media type of a JSON Merge Patch document (RFC 7396)
+	MIMEApplicationMergePatchJSON = "application/merge-patch+json"
+	// MIMEApplicationJSONPatchJSON is the media type of a JSON Patch document (RFC 6902)
+	MIMEApplicationJSONPatchJSON = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupportedPatchType represents an error when the patch document has an unsupported media type
+	ErrUnsupportedPatchType = errors.New("unsupported patch media type")
+	// ErrInvalidPatch represents an error when the patch document is malformed
+	ErrInvalidPatch = errors.New("invalid patch document")
+	// ErrPatchFailed represents an error when the patch document cannot be applied to the task
+	ErrPatchFailed = errors.New("failed to apply patch")
+	// ErrTaskIDImmutable represents an error when a patch tries to change the task id
+	ErrTaskIDImmutable = errors.New("task id cannot be changed")
+)
+
+// ApplyMergePatch applies a JSON Merge Patch document (RFC 7396) to the task and returns the validated result.
+// The task itself is left unchanged.
+func (t *Task) ApplyMergePatch(patch []byte) (Task, error) {
+	if !json.Valid(patch) {
+		return Task{}, ErrInvalidPatch
+	}
+
+	doc, err := json.Marshal(t)
+	if err != nil {
+		return Task{}, err
+	}
+
+	patched, err := jsonpatch.MergePatch(doc, patch)
+	if err != nil {
+		return Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return t.decodePatched(patched)
+}
+
+// ApplyJSONPatch applies a JSON Patch document (RFC 6902) to the task and returns the validated result.
+// The task itself is left unchanged.
+func (t *Task) ApplyJSONPatch(patch []byte) (Task, error) {
+	operations, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return Task{}, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	doc, err := json.Marshal(t)
+	if err != nil {
+		return Task{}, err
+	}
+
+	patched, err := operations.Apply(doc)
+	if err != nil {
+		return Task{}, fmt.Errorf("%w: %v", ErrPatchFailed, err)
+	}
+
+	return t.decodePatched(patched)
+}
+
+// decodePatched decodes a patched task document and validates it against the task model.
+func (t *Task) decodePatched(doc []byte) (Task, error) {
+	decoder := json.NewDecoder(bytes.NewReader(doc))
+	decoder.DisallowUnknownFields()
+
+	var patched Task
+	if err := decoder.Decode(&patched); err != nil {
+		return Task{}, fmt.Errorf("%w: %v", ErrPatchFailed, err)
+	}
+
+	if patched.ID != t.ID {
+		return Task{}, ErrTaskIDImmutable
+	}
+
+	if err := patched.Validate(); err != nil {
+		return Task{}, err
+	}
+
+	return patched, nil
+}
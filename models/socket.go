@@ -0,0 +1,53 @@
+package models
+
+import "errors"
+
+var (
+	// ErrUnknownMessageType represents an error when the type of a socket message is unknown
+	ErrUnknownMessageType = errors.New("unknown message type, expected one of subscribe, unsubscribe, create, update, delete")
+	// ErrInvalidChannel represents an error when a socket channel cannot be subscribed to
+	ErrInvalidChannel = errors.New("invalid channel, expected one of tasks, task:<id>, view:<id>")
+	// ErrTaskIDEmpty represents an error when the target task id of a socket message is empty
+	ErrTaskIDEmpty = errors.New("task id is empty")
+)
+
+// SocketMessageType represents the kind of message exchanged over the collaboration socket.
+type SocketMessageType string
+
+const (
+	// SocketSubscribe subscribes the socket to the events of a channel
+	SocketSubscribe SocketMessageType = "subscribe"
+	// SocketUnsubscribe unsubscribes the socket from the events of a channel
+	SocketUnsubscribe SocketMessageType = "unsubscribe"
+	// SocketCreateTasks creates new tasks
+	SocketCreateTasks SocketMessageType = "create"
+	// SocketUpdateTask updates the fields of an existing task
+	SocketUpdateTask SocketMessageType = "update"
+	// SocketDeleteTask deletes an existing task
+	SocketDeleteTask SocketMessageType = "delete"
+	// SocketAck acknowledges that a client message was handled
+	SocketAck SocketMessageType = "ack"
+	// SocketError reports that a client message was rejected
+	SocketError SocketMessageType = "error"
+	// SocketEvent carries a task event of a subscribed channel
+	SocketEvent SocketMessageType = "event"
+)
+
+// SocketRequest represents a message sent by a client over the collaboration socket.
+type SocketRequest struct {
+	ID      string            `json:"id,omitempty" example:"1"` // echoed in the reply to correlate it with the request
+	Type    SocketMessageType `json:"type" example:"subscribe" enums:"subscribe,unsubscribe,create,update,delete"`
+	Channel string            `json:"channel,omitempty" example:"task:9bsv0s2hf8ng030mva9g"` // channel to (un)subscribe
+	TaskID  string            `json:"taskId,omitempty" example:"9bsv0s2hf8ng030mva9g"`       // task to update or delete
+	Tasks   []NewTask         `json:"tasks,omitempty"`                                       // tasks to create
+	UpdateTaskRequest
+}
+
+// SocketMessage represents a message sent by the server over the collaboration socket.
+type SocketMessage struct {
+	ID      string            `json:"id,omitempty" example:"1"` // id of the request replied to
+	Type    SocketMessageType `json:"type" example:"event" enums:"ack,error,event"`
+	Channel string            `json:"channel,omitempty" example:"tasks"` // channel of the event
+	Event   *TaskEvent        `json:"event,omitempty"`
+	Error   *ErrorResponse    `json:"error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "errors"
+
+// ErrInvalidSyncToken represents an error when a sync token is malformed
+var ErrInvalidSyncToken = errors.New("invalid sync token")
+
+// TaskChanges represents the changes of the tasks after a sequence number.
+type TaskChanges struct {
+	Tasks   []Task   // tasks created or updated, ordered by their last change
+	Deleted []string // ids of the deleted tasks, ordered by their deletion
+	Seq     uint64   // sequence number of the last change
+}
+
+// SyncResponse represents the changes of the tasks since a sync token.
+type SyncResponse struct {
+	Tasks   []Task   `json:"tasks"`                                  // created or updated tasks
+	Deleted []string `json:"deleted" example:"9bsv0s2hf8ng030mva9g"` // tombstones, the ids of the deleted tasks
+	Token   string   `json:"token" example:"42"`                     // opaque token to send as since on the next call
+}
@@ -1,33 +1,47 @@
 package models
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // CreateNewTasksRequest represents the request body for creating new tasks.
 type CreateNewTasksRequest struct {
 	Tasks []NewTask `json:"tasks"`
 }
 
+// Validate validates every task of the request and returns the errors of every invalid task field
+func (cntr *CreateNewTasksRequest) Validate() error {
+	if len(cntr.Tasks) == 0 {
+		return ErrNoTasksProvided
+	}
+
+	var errs []error
+	for i := range cntr.Tasks {
+		if err := cntr.Tasks[i].Validate(); err != nil {
+			errs = append(errs, &FieldError{Field: fmt.Sprintf("tasks[%d]", i), Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 // NewTask represents a new task for the client to create new tasks.
 type NewTask struct {
 	Name   string `json:"name" validate:"required" example:"Task 1"`
 	Status int    `json:"status" validate:"required" example:"1" enums:"0,1"`
 }
 
-// Validate validates the task name and status and returns an error if the name is empty or the status is invalid
+// Validate validates the task name and status and returns the errors of every invalid field
 func (nt *NewTask) Validate() error {
-	if err := nt.ValidateName(); err != nil {
-		return err
-	}
-
-	if err := nt.ValidateStatus(); err != nil {
-		return err
-	}
-
-	return nil
+	return errors.Join(nt.ValidateName(), nt.ValidateStatus())
 }
 
 // ValidateName validates the task name and returns an error if the name is empty
 func (nt *NewTask) ValidateName() error {
 	if nt.Name == "" {
-		return ErrTaskNameEmpty
+		return &FieldError{Field: "name", Err: ErrTaskNameEmpty}
 	}
 
 	return nil
@@ -40,7 +54,7 @@ func (nt *NewTask) ValidateStatus() error {
 		return nil
 	}
 
-	return ErrInvalidStatus
+	return &FieldError{Field: "status", Err: ErrInvalidStatus}
 }
 
 // UpdateTaskRequest represents the request body for updating an existing task.
@@ -49,16 +63,18 @@ type UpdateTaskRequest struct {
 	Status *int    `json:"status,omitempty" enums:"0,1"`
 }
 
+// Validate validates the fields to update and returns the errors of every invalid field
 func (utr *UpdateTaskRequest) Validate() error {
+	var errs []error
 	if utr.Name != nil && *utr.Name == "" {
-		return ErrTaskNameEmpty
+		errs = append(errs, &FieldError{Field: "name", Err: ErrTaskNameEmpty})
 	}
 
 	if utr.Status != nil && (*utr.Status != 0 && *utr.Status != 1) {
-		return ErrInvalidStatus
+		errs = append(errs, &FieldError{Field: "status", Err: ErrInvalidStatus})
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
 // IsNoChanges checks if the UpdateTaskRequest contains no changes.
@@ -71,3 +87,163 @@ type ErrorResponse struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
+
+// TaskFilter represents a filter expression for selecting tasks.
+// Only the fields that are set are used for matching.
+type TaskFilter struct {
+	Name   *string `json:"name,omitempty" example:"Task"`            // case-insensitive substring of the task name
+	Status *int    `json:"status,omitempty" example:"0" enums:"0,1"` // exact task status
+}
+
+// Validate validates the filter fields and returns an error if the status is invalid
+func (tf *TaskFilter) Validate() error {
+	if tf.Status != nil && (*tf.Status != 0 && *tf.Status != 1) {
+		return &FieldError{Field: "status", Err: ErrInvalidStatus}
+	}
+
+	return nil
+}
+
+// Match reports whether the task matches the filter.
+func (tf *TaskFilter) Match(task Task) bool {
+	if tf.Name != nil && !strings.Contains(strings.ToLower(task.Name), strings.ToLower(*tf.Name)) {
+		return false
+	}
+
+	if tf.Status != nil && task.Status != *tf.Status {
+		return false
+	}
+
+	return true
+}
+
+// BatchTarget represents the tasks selected by a batch request, either by an explicit id list or by a filter.
+type BatchTarget struct {
+	IDs    []string    `json:"ids,omitempty" example:"9bsv0s2hf8ng030mva9g"`
+	Filter *TaskFilter `json:"filter,omitempty"`
+}
+
+// Validate validates that exactly one of the id list and the filter is provided.
+func (bt *BatchTarget) Validate() error {
+	if len(bt.IDs) == 0 && bt.Filter == nil {
+		return ErrBatchTargetMissing
+	}
+
+	if len(bt.IDs) != 0 && bt.Filter != nil {
+		return ErrBatchTargetAmbiguous
+	}
+
+	if bt.Filter != nil {
+		if err := bt.Filter.Validate(); err != nil {
+			return &FieldError{Field: "filter", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// BatchUpdateTasksRequest represents the request body for updating several tasks at once.
+type BatchUpdateTasksRequest struct {
+	BatchTarget
+	Name   *string `json:"name,omitempty"`
+	Status *int    `json:"status,omitempty" enums:"0,1"`
+}
+
+// Validate validates the batch target and the task fields to update.
+func (butr *BatchUpdateTasksRequest) Validate() error {
+	if err := butr.BatchTarget.Validate(); err != nil {
+		return err
+	}
+
+	update := UpdateTaskRequest{Name: butr.Name, Status: butr.Status}
+	if update.IsNoChanges() {
+		return ErrNoChanges
+	}
+
+	return update.Validate()
+}
+
+// BatchDeleteTasksRequest represents the request body for deleting several tasks at once.
+type BatchDeleteTasksRequest struct {
+	BatchTarget
+}
+
+// BatchOutcome represents the outcome of a batch operation for a single task.
+type BatchOutcome string
+
+const (
+	// BatchOutcomeUpdated represents a task that was updated
+	BatchOutcomeUpdated BatchOutcome = "updated"
+	// BatchOutcomeDeleted represents a task that was deleted
+	BatchOutcomeDeleted BatchOutcome = "deleted"
+	// BatchOutcomeNotFound represents a task that does not exist
+	BatchOutcomeNotFound BatchOutcome = "not_found"
+	// BatchOutcomeConflict represents a task that was changed concurrently or targeted more than once
+	BatchOutcomeConflict BatchOutcome = "conflict"
+)
+
+// BatchResult represents the outcome of a batch operation for a single task.
+type BatchResult struct {
+	ID      string       `json:"id" example:"9bsv0s2hf8ng030mva9g"`
+	Outcome BatchOutcome `json:"outcome" example:"updated" enums:"updated,deleted,not_found,conflict"`
+}
+
+// BatchResponse represents the response body of a batch operation.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// ProblemDetails represents an error response in the RFC 7807 problem details format.
+type ProblemDetails struct {
+	Type     string           `json:"type" example:"about:blank"`
+	Title    string           `json:"title" example:"Bad Request"`
+	Status   int              `json:"status" example:"400"`
+	Detail   string           `json:"detail,omitempty" example:"task name is empty"`
+	Instance string           `json:"instance,omitempty" example:"/tasks"`
+	Errors   []FieldViolation `json:"errors,omitempty"`
+}
+
+// FieldViolation represents a violation of the rules of a single request field.
+type FieldViolation struct {
+	Field    string `json:"field" example:"tasks[0].name"`
+	Message  string `json:"message" example:"task name is empty"`
+	Position int    `json:"position,omitempty" example:"12"` // 1-based position of the error within the field value, if known
+}
+
+const (
+	// DefaultSearchLimit is the number of search results returned when no limit is requested
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the maximum number of search results returned by a single request
+	MaxSearchLimit = 100
+)
+
+// SearchTasksRequest represents the query parameters of a full-text search.
+type SearchTasksRequest struct {
+	Query string `query:"q"`
+	Limit int    `query:"limit"`
+}
+
+// Validate validates the search query and the limit, and applies the default limit when none is requested
+func (str *SearchTasksRequest) Validate() error {
+	var errs []error
+	if strings.TrimSpace(str.Query) == "" {
+		errs = append(errs, &FieldError{Field: "q", Err: ErrSearchQueryEmpty})
+	}
+
+	if str.Limit < 0 || str.Limit > MaxSearchLimit {
+		errs = append(errs, &FieldError{Field: "limit", Err: ErrInvalidLimit})
+	}
+
+	if str.Limit == 0 {
+		str.Limit = DefaultSearchLimit
+	}
+
+	return errors.Join(errs...)
+}
+
+// SearchResult represents a task matching a full-text search query.
+type SearchResult struct {
+	Task      Task    `json:"task"`
+	Score     float64 `json:"score" example:"1.38"`                    // relevance of the task, higher is better
+	Highlight string  `json:"highlight" example:"<mark>Task</mark> 1"` // HTML-escaped task name with the matched terms wrapped in <mark>
+}
@@ -0,0 +1,12 @@
+package models
+
+import "errors"
+
+// ErrUnsupportedFormat represents an error when tasks are exported or imported in an unknown file format
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ImportResponse represents the tasks of an imported file.
+type ImportResponse struct {
+	DryRun bool   `json:"dryRun"` // true when the tasks were only validated, not created
+	Tasks  []Task `json:"tasks"`  // created tasks, or the tasks that would be created by a dry run (without ids)
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/rs/xid"
+)
+
+// OperationKind represents the kind of a recorded task operation.
+type OperationKind string
+
+const (
+	// OperationCreate creates tasks
+	OperationCreate OperationKind = "create"
+	// OperationUpdate changes the name or the status of tasks
+	OperationUpdate OperationKind = "update"
+	// OperationDelete deletes tasks
+	OperationDelete OperationKind = "delete"
+)
+
+// TaskChange represents the states of a task before and after an operation.
+type TaskChange struct {
+	Before *Task `json:"before,omitempty"` // nil when the operation created the task
+	After  *Task `json:"after,omitempty"`  // nil when the operation deleted the task
+}
+
+// Operation represents a change of one or more tasks made by a user, which can be undone and redone.
+type Operation struct {
+	ID      string        `json:"id" example:"9bsv0s2hf8ng030mva9g"`
+	Kind    OperationKind `json:"kind" example:"update" enums:"create,update,delete"`
+	Changes []TaskChange  `json:"changes"`
+	Time    time.Time     `json:"time"` // when the operation was made
+}
+
+// NewOperationID generates a new operation id
+func (o *Operation) NewOperationID() {
+	o.ID = xid.New().String()
+}
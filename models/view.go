@@ -0,0 +1,124 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+var (
+	// ErrViewNameEmpty represents an error when the view name is empty
+	ErrViewNameEmpty = errors.New("view name is empty")
+	// ErrInvalidSort represents an error when the sort field is unknown
+	ErrInvalidSort = errors.New("invalid sort, expected one of id, name, status, optionally prefixed with -")
+	// ErrInvalidColumn represents an error when a column is unknown
+	ErrInvalidColumn = errors.New("invalid column, expected one of id, name, status")
+)
+
+// TaskColumns lists the task fields that can be used as view columns and sort keys.
+var TaskColumns = []string{"id", "name", "status"}
+
+// View represents a saved filter, sort and column configuration of a user.
+type View struct {
+	ID        string    `json:"id" example:"cv1h8ms2hf8ng030mvb0"`
+	Owner     string    `json:"owner" example:"alice"`
+	Name      string    `json:"name" example:"My open tasks"`
+	Query     string    `json:"query" example:"open AND name:deploy"` // task query, empty for every task
+	Sort      string    `json:"sort" example:"-name"`                 // sort field, prefixed with - for descending order
+	Columns   []string  `json:"columns" example:"id,name"`            // task fields to return, empty for every field
+	CreatedAt time.Time `json:"createdAt" example:"2025-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2025-01-01T00:00:00Z"`
+}
+
+// NewViewID generates a new view id
+func (v *View) NewViewID() {
+	v.ID = xid.New().String()
+}
+
+// SaveViewRequest represents the request body for creating or replacing a view.
+type SaveViewRequest struct {
+	Name    string   `json:"name" example:"My open tasks"`
+	Query   string   `json:"query" example:"open AND name:deploy"`
+	Sort    string   `json:"sort" example:"-name"`
+	Columns []string `json:"columns" example:"id,name"`
+}
+
+// Validate validates the view name, sort and columns and returns the errors of every invalid field.
+// The query is validated by the query parser.
+func (svr *SaveViewRequest) Validate() error {
+	var errs []error
+	if strings.TrimSpace(svr.Name) == "" {
+		errs = append(errs, &FieldError{Field: "name", Err: ErrViewNameEmpty})
+	}
+
+	if svr.Sort != "" && !isTaskColumn(strings.TrimPrefix(svr.Sort, "-")) {
+		errs = append(errs, &FieldError{Field: "sort", Err: ErrInvalidSort})
+	}
+
+	for i, column := range svr.Columns {
+		if !isTaskColumn(column) {
+			errs = append(errs, &FieldError{Field: "columns", Err: &FieldError{Field: "[" + strconv.Itoa(i) + "]", Err: ErrInvalidColumn}})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func isTaskColumn(column string) bool {
+	for _, c := range TaskColumns {
+		if c == column {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SortTasks sorts the tasks by the given field, prefixed with - for descending order.
+// Tasks with equal values keep their order by ID.
+func SortTasks(tasks []Task, field string) {
+	descending := strings.HasPrefix(field, "-")
+	field = strings.TrimPrefix(field, "-")
+
+	var less func(a, b Task) bool
+	switch field {
+	case "name":
+		less = func(a, b Task) bool { return strings.ToLower(a.Name) < strings.ToLower(b.Name) }
+	case "status":
+		less = func(a, b Task) bool { return a.Status < b.Status }
+	default:
+		less = func(a, b Task) bool { return a.ID < b.ID }
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if descending {
+			return less(tasks[j], tasks[i])
+		}
+		return less(tasks[i], tasks[j])
+	})
+}
+
+// ProjectTask returns the given columns of the task, or every column when none is given.
+func ProjectTask(task Task, columns []string) map[string]any {
+	if len(columns) == 0 {
+		columns = TaskColumns
+	}
+
+	projected := make(map[string]any, len(columns))
+	for _, column := range columns {
+		switch column {
+		case "id":
+			projected[column] = task.ID
+		case "name":
+			projected[column] = task.Name
+		case "status":
+			projected[column] = task.Status
+		}
+	}
+
+	return projected
+}
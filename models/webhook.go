@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/xid"
+)
+
+var (
+	// ErrInvalidWebhookURL represents an error when the webhook URL is not an absolute http or https URL
+	ErrInvalidWebhookURL = errors.New("invalid url, expected an absolute http or https url")
+	// ErrWebhookSecretEmpty represents an error when the webhook secret is empty
+	ErrWebhookSecretEmpty = errors.New("webhook secret is empty")
+	// ErrInvalidEventType represents an error when an event type is unknown
+	ErrInvalidEventType = errors.New("invalid event type, expected one of task.created, task.updated, task.completed, task.deleted")
+)
+
+// Webhook represents a subscription of an URL to the task events.
+type Webhook struct {
+	ID        string          `json:"id" example:"cv2k1ts2hf8ng030mvc0"`
+	URL       string          `json:"url" example:"https://ci.example.com/hooks/tasks"`
+	Secret    string          `json:"-"`                                            // key of the HMAC-SHA256 signature, never returned
+	Events    []TaskEventType `json:"events" example:"task.completed,task.deleted"` // event types to deliver, empty for every type
+	CreatedAt time.Time       `json:"createdAt" example:"2025-01-01T00:00:00Z"`
+	UpdatedAt time.Time       `json:"updatedAt" example:"2025-01-01T00:00:00Z"`
+}
+
+// NewWebhookID generates a new webhook id
+func (w *Webhook) NewWebhookID() {
+	w.ID = xid.New().String()
+}
+
+// Accepts checks if the event type is delivered to the webhook.
+func (w *Webhook) Accepts(eventType TaskEventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SaveWebhookRequest represents the request body for creating or replacing a webhook.
+type SaveWebhookRequest struct {
+	URL    string          `json:"url" example:"https://ci.example.com/hooks/tasks"`
+	Secret string          `json:"secret" example:"s3cr3t"`
+	Events []TaskEventType `json:"events" example:"task.completed,task.deleted"`
+}
+
+// Validate validates the webhook URL, secret and event types and returns the errors of every invalid field.
+func (swr *SaveWebhookRequest) Validate() error {
+	var errs []error
+	if u, err := url.Parse(swr.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, &FieldError{Field: "url", Err: ErrInvalidWebhookURL})
+	}
+
+	if swr.Secret == "" {
+		errs = append(errs, &FieldError{Field: "secret", Err: ErrWebhookSecretEmpty})
+	}
+
+	for i, eventType := range swr.Events {
+		if !isTaskEventType(eventType) {
+			errs = append(errs, &FieldError{Field: "events", Err: &FieldError{Field: "[" + strconv.Itoa(i) + "]", Err: ErrInvalidEventType}})
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func isTaskEventType(eventType TaskEventType) bool {
+	for _, e := range TaskEventTypes {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery represents an attempt to deliver a task event to a webhook.
+type WebhookDelivery struct {
+	ID         string        `json:"id" example:"cv2k2bs2hf8ng030mvcg"`
+	WebhookID  string        `json:"webhookId" example:"cv2k1ts2hf8ng030mvc0"`
+	EventID    uint64        `json:"eventId" example:"42"`
+	EventType  TaskEventType `json:"eventType" example:"task.completed"`
+	Attempt    int           `json:"attempt" example:"1"`                          // 1 for the first attempt, incremented on every retry
+	StatusCode int           `json:"statusCode,omitempty" example:"200"`           // status code of the response, if any
+	Error      string        `json:"error,omitempty" example:"connection refused"` // why the attempt failed
+	Succeeded  bool          `json:"succeeded" example:"true"`
+	Duration   time.Duration `json:"duration" swaggertype:"integer" example:"12000000"` // in nanoseconds
+	Time       time.Time     `json:"time" example:"2025-01-01T00:00:00Z"`
+}
+
+// NewWebhookDeliveryID generates a new webhook delivery id
+func (wd *WebhookDelivery) NewWebhookDeliveryID() {
+	wd.ID = xid.New().String()
+}